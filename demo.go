@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"net/http"
+
+	httpSwagger "github.com/swaggo/http-swagger"
+	"go.uber.org/zap"
+
+	bookservice "github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// demoBooks are the books the demo server starts with.
+var demoBooks = []booktypes.AddBookRequest{
+	{
+		Title:       "Pride and Prejudice",
+		Author:      "Jane Austen",
+		Description: "The turbulent relationship between Elizabeth Bennet and Fitzwilliam Darcy.",
+		ISBN13:      "9780141439518",
+		BookMetadata: booktypes.BookMetadata{
+			Publisher: "Penguin Classics", PublishedDate: "2002-12-31", Language: "en-GB", PageCount: 480,
+			Format: booktypes.BOOK_FORMAT_PAPERBACK, Price: 899, Currency: "GBP",
+		},
+	},
+	{
+		Title:       "Nineteen Eighty-Four",
+		Author:      "George Orwell",
+		Description: "A dystopian novel of a society under total surveillance.",
+		ISBN13:      "9780451524935",
+		BookMetadata: booktypes.BookMetadata{
+			Publisher: "Signet Classics", PublishedDate: "1961-01-01", Language: "en-US", PageCount: 328,
+			Format: booktypes.BOOK_FORMAT_PAPERBACK, Price: 999, Currency: "USD",
+		},
+	},
+	{
+		Title:       "The Hobbit",
+		Author:      "J. R. R. Tolkien",
+		Description: "Bilbo Baggins is swept into a quest to reclaim the Lonely Mountain.",
+		ISBN13:      "9780547928227",
+		BookMetadata: booktypes.BookMetadata{
+			Publisher: "Houghton Mifflin Harcourt", PublishedDate: "2012-09-18", Language: "en-US", PageCount: 300,
+			Format: booktypes.BOOK_FORMAT_PAPERBACK, Price: 1499, Currency: "USD",
+		},
+	},
+	{
+		Title:       "Dune",
+		Author:      "Frank Herbert",
+		Description: "The son of a noble family is entrusted with the protection of the desert planet Arrakis.",
+		ISBN13:      "9780441172719",
+		BookMetadata: booktypes.BookMetadata{
+			Publisher: "Ace", PublishedDate: "1990-09-01", Language: "en-US", PageCount: 535,
+			Format: booktypes.BOOK_FORMAT_PAPERBACK, SeriesName: "Dune", SeriesPosition: 1, Price: 1099, Currency: "USD",
+		},
+	},
+}
+
+// runDemo serves the books API from memory, starting with the demo books, so it can be tried
+// without a database. The other APIs are not served and the changes are lost when it stops.
+func runDemo(conf config.Config, logger *zap.Logger) error {
+	repository := bookservice.NewMemoryRepository(logger)
+	service := bookservice.NewService(&repository, logger)
+
+	ctx := tenant.NewContext(context.Background(), tenant.DEFAULT)
+	for _, req := range demoBooks {
+		if _, err := service.WithContext(ctx).AddBook(ctx, req); err != nil {
+			return err
+		}
+	}
+
+	router := http.NewServeMux()
+	router = bookservice.InitializeRoutes(router, bookservice.NewHandler(&service, logger))
+	router.HandleFunc("GET /api/v1/swagger/", httpSwagger.WrapHandler)
+
+	server := &http.Server{
+		Addr:    conf.App.Port,
+		Handler: middleware.Wraps(tenant.Middleware(conf.Tenancy, logger)(router), logger),
+	}
+	logger.Info("Listening with the demo books in memory...")
+
+	return server.ListenAndServe()
+}
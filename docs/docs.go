@@ -24,13 +24,13 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/books": {
+        "/audit": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "get list of books' information from the system",
-                "operationId": "get-books",
+                "summary": "get list of audit log entries of the book changes",
+                "operationId": "get-audit-events",
                 "parameters": [
                     {
                         "type": "integer",
@@ -55,13 +55,136 @@ const docTemplate = `{
                         "description": "order of items to be sorted (by id)",
                         "name": "sort_type",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "caller identity",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "create",
+                            "update",
+                            "delete",
+                            "restore"
+                        ],
+                        "type": "string",
+                        "description": "operation made to the book",
+                        "name": "operation",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request ID",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest time of the entries (RFC 3339), inclusive",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest time of the entries (RFC 3339), exclusive",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.GetBooksResponse"
+                            "$ref": "#/definitions/types.GetAuditEventsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit/verify": {
+            "get": {
+                "description": "Every entry is checked to link to the previous one and to match its hash;\nan invalid chain means the audit log was tampered with.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "verify the hash chain of the audit log",
+                "operationId": "verify-audit",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.VerifyAuditResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/authors": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get list of authors",
+                "operationId": "get-authors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "part of the author name",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetAuthorsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
                         }
                     },
                     "500": {
@@ -76,16 +199,16 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "add book into the system",
-                "operationId": "add-book",
+                "summary": "add a new author into the system",
+                "operationId": "add-author",
                 "parameters": [
                     {
-                        "description": "Book information that needs to be added",
+                        "description": "Author information that needs to be added",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.AddBookRequest"
+                            "$ref": "#/definitions/types.AddAuthorRequest"
                         }
                     }
                 ],
@@ -93,7 +216,19 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/types.AddBookResponse"
+                            "$ref": "#/definitions/types.AddAuthorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
                         }
                     },
                     "500": {
@@ -105,17 +240,17 @@ const docTemplate = `{
                 }
             }
         },
-        "/books/{id}": {
+        "/authors/{id}": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "get book information from given id",
-                "operationId": "get-book",
+                "summary": "get author information from given id",
+                "operationId": "get-author",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -125,7 +260,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.Book"
+                            "$ref": "#/definitions/types.Author"
                         }
                     },
                     "404": {
@@ -146,23 +281,23 @@ const docTemplate = `{
                 "produces": [
                     "application/json"
                 ],
-                "summary": "update book information in the system with the given id",
-                "operationId": "add-book",
+                "summary": "update author information in the system with the given id",
+                "operationId": "update-author",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Book information that needs to be updated",
+                        "description": "Author information that needs to be updated",
                         "name": "Body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/types.UpdateBookRequest"
+                            "$ref": "#/definitions/types.UpdateAuthorRequest"
                         }
                     }
                 ],
@@ -170,7 +305,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.Book"
+                            "$ref": "#/definitions/types.Author"
                         }
                     },
                     "404": {
@@ -179,6 +314,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.Error"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -188,15 +329,16 @@ const docTemplate = `{
                 }
             },
             "delete": {
+                "description": "Only an author without books can be deleted.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "delete book id from the system",
-                "operationId": "delete-book",
+                "summary": "delete author id from the system",
+                "operationId": "delete-author",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Book ID",
+                        "description": "Author ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -206,7 +348,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/types.DeleteBookResponse"
+                            "$ref": "#/definitions/types.DeleteAuthorResponse"
                         }
                     },
                     "404": {
@@ -215,6 +357,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/errors.Error"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -223,113 +371,6561 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "db.Pagination": {
-            "type": "object",
-            "properties": {
-                "limit": {
-                    "type": "integer",
-                    "example": 10
-                },
-                "page": {
-                    "type": "integer",
-                    "example": 1
-                },
-                "sort": {
-                    "type": "string",
-                    "example": "Id desc"
-                },
-                "total_pages": {
-                    "type": "integer",
-                    "example": 10
-                },
-                "total_rows": {
-                    "type": "integer",
-                    "example": 100
-                }
-            }
         },
-        "errors.Error": {
-            "type": "object",
-            "properties": {
-                "message": {
-                    "type": "string"
-                }
-            }
-        },
-        "types.AddBookRequest": {
-            "type": "object",
-            "required": [
-                "author",
-                "description",
-                "title"
-            ],
-            "properties": {
-                "author": {
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "description": {
-                    "type": "string",
-                    "example": "this is an example description"
-                },
-                "title": {
-                    "type": "string",
-                    "example": "example-title"
-                }
-            }
-        },
-        "types.AddBookResponse": {
-            "type": "object",
-            "properties": {
-                "id": {
-                    "type": "integer",
-                    "example": 1
+        "/authors/{id}/books": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get books of an author with the role of the author",
+                "operationId": "get-author-books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Author ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetAuthorBooksResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
                 }
             }
         },
-        "types.Book": {
-            "type": "object",
-            "required": [
-                "author",
-                "description",
-                "title"
-            ],
-            "properties": {
-                "author": {
-                    "type": "string",
-                    "example": "John Doe"
-                },
-                "description": {
-                    "type": "string",
-                    "example": "this is an example description"
-                },
+        "/books": {
+            "get": {
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "text/xml"
+                ],
+                "summary": "get list of books' information from the system",
+                "operationId": "get-books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "order of items to be sorted",
+                        "name": "sort_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "rating",
+                            "rating_count"
+                        ],
+                        "type": "string",
+                        "description": "field the items are sorted by, then by id; id by default",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "tags the books must all have",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subject ID the books are classified in, including its descendants",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "publisher of the books, regardless of case",
+                        "name": "publisher",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "series of the books, regardless of case",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag of the books, including its subtags",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hardcover",
+                            "paperback",
+                            "ebook",
+                            "audio"
+                        ],
+                        "type": "string",
+                        "description": "format of the books",
+                        "name": "book_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest publication date as YYYY-MM-DD",
+                        "name": "published_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest publication date as YYYY-MM-DD",
+                        "name": "published_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency of the price, required with a price range",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lowest price in the minor unit of the currency",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "highest price in the minor unit of the currency",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "tags"
+                        ],
+                        "type": "string",
+                        "description": "counts of the matching books to include",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "yaml",
+                            "xml"
+                        ],
+                        "type": "string",
+                        "description": "response representation, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBooksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "add book into the system",
+                "operationId": "add-book",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Book information that needs to be added",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AddBookResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/batch": {
+            "post": {
+                "description": "Operations run in order within a single transaction. Each result holds the status and body\nthat the single-item endpoint of the operation would have returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "run several create, update and delete operations at once",
+                "operationId": "batch-books",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Operations to be run",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.BatchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/types.BatchResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/export": {
+            "get": {
+                "description": "Books are streamed from the database and flushed to the client periodically.\nThe books are filtered and sorted as in the list of books.",
+                "produces": [
+                    "application/x-ndjson",
+                    "text/csv"
+                ],
+                "summary": "export every book in the system as a stream",
+                "operationId": "export-books",
+                "parameters": [
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "order of items to be sorted",
+                        "name": "sort_type",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "id",
+                            "rating",
+                            "rating_count"
+                        ],
+                        "type": "string",
+                        "description": "field the items are sorted by, then by id; id by default",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "tags the books must all have",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subject ID the books are classified in, including its descendants",
+                        "name": "subject",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "publisher of the books, regardless of case",
+                        "name": "publisher",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "series of the books, regardless of case",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "BCP 47 language tag of the books, including its subtags",
+                        "name": "language",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hardcover",
+                            "paperback",
+                            "ebook",
+                            "audio"
+                        ],
+                        "type": "string",
+                        "description": "format of the books",
+                        "name": "book_format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "earliest publication date as YYYY-MM-DD",
+                        "name": "published_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "latest publication date as YYYY-MM-DD",
+                        "name": "published_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ISO 4217 currency of the price, required with a price range",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "lowest price in the minor unit of the currency",
+                        "name": "min_price",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "highest price in the minor unit of the currency",
+                        "name": "max_price",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "ndjson",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "format of the export, defaults to ndjson",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stream of books",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/import": {
+            "post": {
+                "description": "CSV streams need a header row with title, author and description columns.\nEvery row is validated with the rules of adding a book and the result of each row is reported.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "import books into the system from a CSV or JSON lines stream",
+                "operationId": "import-books",
+                "parameters": [
+                    {
+                        "enum": [
+                            "atomic",
+                            "partial"
+                        ],
+                        "type": "string",
+                        "description": "atomic writes nothing if any row is invalid, partial skips invalid rows",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "validate the rows without writing them",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "key that makes retries of the request return the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "CSV or JSON lines stream of books",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportBooksResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportBooksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/types.ImportBooksResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/isbn/{isbn}": {
+            "get": {
+                "description": "Hyphens and spaces in the ISBN are ignored; an ISBN-10 is converted into its ISBN-13.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "text/xml"
+                ],
+                "summary": "get book information from given ISBN-10 or ISBN-13",
+                "operationId": "get-book-by-isbn",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ISBN-10 or ISBN-13",
+                        "name": "isbn",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "yaml",
+                            "xml"
+                        ],
+                        "type": "string",
+                        "description": "response representation, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/stream": {
+            "get": {
+                "description": "Every book created, updated or deleted is sent as an event with the outbox event id.\nReconnecting clients resume after the Last-Event-ID header; a ` + "`" + `reset` + "`" + ` event tells\nthem that some events were missed. A comment is sent as heartbeat.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "stream book changes as server-sent events",
+                "operationId": "stream-books",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "id of the last received event, for clients that cannot set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "stream of book events",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}": {
+            "get": {
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/yaml",
+                    "text/xml"
+                ],
+                "summary": "get book information from given id",
+                "operationId": "get-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "yaml",
+                            "xml"
+                        ],
+                        "type": "string",
+                        "description": "response representation, overrides the Accept header",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update book information in the system with the given id",
+                "operationId": "add-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Book information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateBookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete book id from the system",
+                "operationId": "delete-book",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteBookResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/authors": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get authors of a book with their roles",
+                "operationId": "get-book-authors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBookAuthorsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Authors are ordered as given. Changing the author text of the book replaces its\nauthors in the ` + "`" + `author` + "`" + ` role again.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "replace authors of a book",
+                "operationId": "set-book-authors",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Authors of the book with their roles",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetBookAuthorsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBookAuthorsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/copies": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get the copies of a book",
+                "operationId": "get-book-copies",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "branch holding the copies",
+                        "name": "branch",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "available",
+                            "on_loan",
+                            "on_hold",
+                            "lost",
+                            "repair"
+                        ],
+                        "type": "string",
+                        "description": "status of the copies",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetCopiesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The barcode is unique across all branches.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "add a new copy of a book",
+                "operationId": "add-book-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy information",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddCopyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AddCopyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/copies/{copy_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a copy of a book",
+                "operationId": "get-book-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "copy_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Copy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update a copy of a book",
+                "operationId": "update-book-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "copy_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Copy information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateCopyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Copy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete a copy of a book",
+                "operationId": "delete-book-copy",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Copy ID",
+                        "name": "copy_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteCopyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/cover": {
+            "get": {
+                "description": "A request with the version of the cover in the v parameter, as in the URL of the book cover,\ncan be cached for good. Any other request is revalidated with the ETag.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/webp"
+                ],
+                "summary": "get the cover image of a book",
+                "operationId": "get-book-cover",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "version of the cover",
+                        "name": "v",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "The image is either the request body or the \"cover\" field of a multipart form. Its type is\nfound from its content, whatever the Content-Type given. JPEG and PNG covers get thumbnails.",
+                "consumes": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/webp",
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "upload the cover image of a book",
+                "operationId": "set-book-cover",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.SetCoverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete the cover image of a book",
+                "operationId": "delete-book-cover",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteCoverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/cover/{size}": {
+            "get": {
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "summary": "get a thumbnail of the cover image of a book",
+                "operationId": "get-book-cover-thumbnail",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "small",
+                            "medium",
+                            "large"
+                        ],
+                        "type": "string",
+                        "description": "thumbnail size",
+                        "name": "size",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "version of the cover",
+                        "name": "v",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/reviews": {
+            "get": {
+                "description": "The latest reviews come first. Only the approved reviews are listed unless another status is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a list of reviews of a book",
+                "operationId": "get-reviews",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "approved",
+                            "rejected"
+                        ],
+                        "type": "string",
+                        "description": "moderation status of the reviews",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetReviewsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The reviewer is the caller given by the X-Actor header; a reader reviews a book once. A new review is pending until it is approved.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "review a book",
+                "operationId": "add-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reviewer",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Review",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AddReviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/reviews/{review_id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a review of a book",
+                "operationId": "get-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "review_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Only the reviewer edits a review, which is pending again until it is approved.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "edit a review",
+                "operationId": "update-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "review_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reviewer",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Review information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Only the reviewer deletes a review.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete a review",
+                "operationId": "delete-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "review_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reviewer",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteReviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/reviews/{review_id}/status": {
+            "put": {
+                "description": "Only the approved reviews are listed by default and counted in the rating of the book.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "moderate a review",
+                "operationId": "moderate-review",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Review ID",
+                        "name": "review_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Moderation status",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ModerateReviewRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Review"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/revisions": {
+            "get": {
+                "description": "Revisions of a deleted book are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get revisions of a book, latest first",
+                "operationId": "get-book-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetRevisionsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/revisions/diff": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get the fields changed between two revisions of a book",
+                "operationId": "diff-book-revisions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision to compare from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision to compare to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.RevisionDiffResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/revisions/{rev}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a revision of a book",
+                "operationId": "get-book-revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.BookRevision"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/revisions/{rev}/restore": {
+            "post": {
+                "description": "A deleted book is created again. The restore is recorded as a new revision.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "restore a book to one of its revisions",
+                "operationId": "restore-book-revision",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Revision",
+                        "name": "rev",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Book"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/subjects": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get subjects of a book",
+                "operationId": "get-book-subjects",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBookSubjectsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "replace subjects of a book",
+                "operationId": "set-book-subjects",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subjects the book is classified in",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetBookSubjectsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBookSubjectsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/books/{id}/tags": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get tags of a book",
+                "operationId": "get-book-tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBookTagsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Tags are compared in lower case with single spaces; missing tags are created.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "replace tags of a book",
+                "operationId": "set-book-tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags of the book",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.SetBookTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetBookTagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds": {
+            "get": {
+                "description": "The holds are listed in the order they were placed; waiting holds hold their position in the queue of their book.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a list of holds",
+                "operationId": "get-holds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "waiting",
+                            "ready",
+                            "fulfilled",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "status of the holds",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "member who placed the holds",
+                        "name": "member_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "book held",
+                        "name": "book_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetHoldsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "A hold is placed on a book without available copies and waits in its queue. A returned copy is kept for the first waiting hold until the end of the pickup period.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "place a hold on a book",
+                "operationId": "place-hold",
+                "parameters": [
+                    {
+                        "description": "Member and book",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.PlaceHoldRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a hold",
+                "operationId": "get-hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/holds/{id}/cancel": {
+            "post": {
+                "description": "The copy kept for a ready hold is passed on to the next hold of the book.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "cancel a hold",
+                "operationId": "cancel-hold",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Hold ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Hold"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/lists": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get the lists of the caller",
+                "operationId": "get-lists",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetListsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The owner is the caller given by the X-Actor header. The slug is made from the owner and the name unless given, and is unique.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "create a list",
+                "operationId": "create-list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "List information",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.CreateListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/lists/{id}": {
+            "get": {
+                "description": "A private list is only found by its owner.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a list with its books",
+                "operationId": "get-list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caller",
+                        "name": "X-Actor",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update a list",
+                "operationId": "update-list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "List information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateListRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete a list",
+                "operationId": "delete-list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/lists/{id}/items": {
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "reorder the books of a list",
+                "operationId": "reorder-list-items",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Every book of the list in the new order",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.ReorderListItemsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The book is added at the end of the list unless a position is given, moving the books from there down by one.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "add a book into a list",
+                "operationId": "add-list-item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Book to add",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddListItemRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/lists/{id}/items/{book_id}": {
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "remove a book from a list",
+                "operationId": "remove-list-item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "List ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Book ID",
+                        "name": "book_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner",
+                        "name": "X-Actor",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.List"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a list of loans",
+                "operationId": "get-loans",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "overdue",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "status of the loans",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "member who borrowed the copies",
+                        "name": "member_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "book of the borrowed copies",
+                        "name": "book_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetLoansResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The copy is given by its id or its barcode and must be available, or on hold for the member. The loan is due at the end of the loan period.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "check out a copy of a book",
+                "operationId": "checkout",
+                "parameters": [
+                    {
+                        "description": "Member and copy",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.CheckoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a loan",
+                "operationId": "get-loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/renew": {
+            "post": {
+                "description": "The loan is due at the end of a new loan period from now. Overdue loans cannot be renewed.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "renew a loan",
+                "operationId": "renew-loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/loans/{id}/return": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "return a borrowed copy",
+                "operationId": "return-loan",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Loan ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Loan"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/members": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a list of members",
+                "operationId": "get-members",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "part of the member name or email",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetMembersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The email is unique and stored in lower case. The tier is one of the configured tiers, the default one unless given.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "add a new member",
+                "operationId": "add-member",
+                "parameters": [
+                    {
+                        "description": "Member information",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AddMemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a member",
+                "operationId": "get-member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Members are never deleted; a suspended member cannot borrow copies or place holds.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update a member",
+                "operationId": "update-member",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Member"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/account": {
+            "get": {
+                "description": "The balance is the sum of the fines of late returns less the payments. A member whose balance is over the limit is blocked from borrowing.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get the account of a member",
+                "operationId": "get-member-account",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetAccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/holds": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get the holds of a member",
+                "operationId": "get-member-holds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "waiting",
+                            "ready",
+                            "fulfilled",
+                            "cancelled",
+                            "expired"
+                        ],
+                        "type": "string",
+                        "description": "status of the holds",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetHoldsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/loans": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get the loans of a member",
+                "operationId": "get-member-loans",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "active",
+                            "overdue",
+                            "returned"
+                        ],
+                        "type": "string",
+                        "description": "status of the loans",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetLoansResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/members/{id}/payments": {
+            "post": {
+                "description": "The amount is in the minor unit of the currency of the fines and must not exceed the balance.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "pay fines of a member",
+                "operationId": "pay-member-fines",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Member ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payment",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.PayRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.Account"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/shared-lists/{slug}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get a public list with its books by its slug",
+                "operationId": "get-shared-list",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "List slug",
+                        "name": "slug",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.List"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/subjects": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get list of subjects, ordered along the tree",
+                "operationId": "get-subjects",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "part of the subject name or code",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dewey",
+                            "bisac"
+                        ],
+                        "type": "string",
+                        "description": "classification scheme of the subject code",
+                        "name": "scheme",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Subject ID whose subtree is listed, itself included",
+                        "name": "under",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetSubjectsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "add a new subject into the tree",
+                "operationId": "add-subject",
+                "parameters": [
+                    {
+                        "description": "Subject information that needs to be added",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddSubjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AddSubjectResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/subjects/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get subject information from given id",
+                "operationId": "get-subject",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subject ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Subject"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "A ` + "`" + `parent_id` + "`" + ` moves the subject with its subtree, ` + "`" + `0` + "`" + ` moving it to the root.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update subject information with the given id",
+                "operationId": "update-subject",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subject ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subject information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateSubjectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Subject"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Only a subject without children and books can be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete subject id from the tree",
+                "operationId": "delete-subject",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subject ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteSubjectResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get list of tags with their number of books",
+                "operationId": "get-tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "part of the tag",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetTagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get list of webhook subscriptions",
+                "operationId": "get-webhooks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetSubscriptionsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Deliveries are signed with the secret in the X-Webhook-Signature header.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "subscribe a webhook to book events",
+                "operationId": "add-webhook",
+                "parameters": [
+                    {
+                        "description": "Subscription that needs to be added",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.AddSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/types.AddSubscriptionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/deliveries/{id}/redeliver": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "send a webhook delivery again, including a dead one",
+                "operationId": "redeliver-webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/types.Delivery"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get webhook subscription information from given id",
+                "operationId": "get-webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Subscription"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "update webhook subscription with the given id",
+                "operationId": "update-webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Subscription information that needs to be updated",
+                        "name": "Body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/types.UpdateSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.Subscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "delete webhook subscription and its delivery log",
+                "operationId": "delete-webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.DeleteSubscriptionResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "get delivery log of a webhook subscription, latest first",
+                "operationId": "get-webhook-deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit per page",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/types.GetDeliveriesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/errors.Error"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "db.Pagination": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "Id desc"
+                },
+                "total_pages": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "total_rows": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "errors.Error": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID is the id of the existing resource that a conflict is with.",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.Account": {
+            "type": "object",
+            "properties": {
+                "accrued": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "balance": {
+                    "type": "integer",
+                    "example": 75
+                },
+                "blocked": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "USD"
+                },
+                "limit": {
+                    "type": "integer",
+                    "example": 1000
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AccountEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount is positive for a fine and negative for a payment, in the minor unit of the\ncurrency of the fines.",
+                    "type": "integer",
+                    "example": 75
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "kind": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.EntryKind"
+                        }
+                    ],
+                    "example": "fine"
+                },
+                "loan_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddAuthorRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "example": "British author"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "J. K. Rowling"
+                }
+            }
+        },
+        "types.AddAuthorResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddBookRequest": {
+            "type": "object",
+            "required": [
+                "author",
+                "description",
+                "title"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "GBP"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "this is an example description"
+                },
+                "edition": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "1st"
+                },
+                "format": {
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook",
+                        "audio"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookFormat"
+                        }
+                    ],
+                    "example": "hardcover"
+                },
+                "isbn10": {
+                    "type": "string",
+                    "example": "0-306-40615-2"
+                },
+                "isbn13": {
+                    "type": "string",
+                    "example": "978-0-306-40615-7"
+                },
+                "language": {
+                    "description": "Language is a BCP 47 language tag in its canonical form, e.g. \"en-GB\".",
+                    "type": "string",
+                    "example": "en-GB"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 223
+                },
+                "price": {
+                    "description": "Price is in the minor unit of the currency, e.g. 1299 for 12.99 GBP.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1299
+                },
+                "published_date": {
+                    "description": "PublishedDate is the publication date as YYYY-MM-DD.",
+                    "type": "string",
+                    "example": "1997-06-26"
+                },
+                "publisher": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Bloomsbury"
+                },
+                "series_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Harry Potter"
+                },
+                "series_position": {
+                    "description": "SeriesPosition is the position of the book in the series from 1.",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "example-title"
+                }
+            }
+        },
+        "types.AddBookResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddCopyRequest": {
+            "type": "object",
+            "required": [
+                "barcode",
+                "branch"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "LIB000123"
+                },
+                "branch": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Central"
+                },
+                "condition": {
+                    "enum": [
+                        "new",
+                        "good",
+                        "fair",
+                        "poor",
+                        "damaged"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CopyCondition"
+                        }
+                    ],
+                    "example": "good"
+                },
+                "location": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Shelf 4B"
+                },
+                "status": {
+                    "enum": [
+                        "available",
+                        "lost",
+                        "repair"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CopyStatus"
+                        }
+                    ],
+                    "example": "available"
+                }
+            }
+        },
+        "types.AddCopyResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddListItemRequest": {
+            "type": "object",
+            "required": [
+                "book_id"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "position": {
+                    "description": "Position is the position of the book in the list, the end of the list unless given.",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "types.AddMemberRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Jane Doe"
+                },
+                "tier": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "standard"
+                }
+            }
+        },
+        "types.AddMemberResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddReviewRequest": {
+            "type": "object",
+            "required": [
+                "rating"
+            ],
+            "properties": {
+                "rating": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 4
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 5000,
+                    "example": "A classic."
+                }
+            }
+        },
+        "types.AddReviewResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddSubjectRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "FIC009000"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Fantasy"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "scheme": {
+                    "enum": [
+                        "dewey",
+                        "bisac"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.SubjectScheme"
+                        }
+                    ],
+                    "example": "bisac"
+                }
+            }
+        },
+        "types.AddSubjectResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AddSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "secret",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "book.created",
+                        "book.updated"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "minLength": 16,
+                    "example": "a-secret-of-at-least-16-chars"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "types.AddSubscriptionResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "description": "Before and After are stored as json instead of jsonb, which keeps the hashed text as is.",
+                    "type": "object"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "operation": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ChangeOperation"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "prev_hash": {
+                    "type": "string",
+                    "example": ""
+                },
+                "remote_ip": {
+                    "type": "string",
+                    "example": "127.0.0.1:52144"
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "6f1c2d8e-1b7a-4f0e-9a55-3d2b8c1e0f42"
+                }
+            }
+        },
+        "types.Author": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "example": "British author"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "J. K. Rowling"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.AuthorBook": {
+            "type": "object",
+            "properties": {
+                "book": {
+                    "$ref": "#/definitions/types.Book"
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AuthorRole"
+                        }
+                    ],
+                    "example": "author"
+                }
+            }
+        },
+        "types.AuthorRole": {
+            "type": "string",
+            "enum": [
+                "author",
+                "editor",
+                "translator"
+            ],
+            "x-enum-varnames": [
+                "AUTHOR_ROLE_AUTHOR",
+                "AUTHOR_ROLE_EDITOR",
+                "AUTHOR_ROLE_TRANSLATOR"
+            ]
+        },
+        "types.BatchMode": {
+            "type": "string",
+            "enum": [
+                "atomic",
+                "per_item"
+            ],
+            "x-enum-varnames": [
+                "BATCH_MODE_ATOMIC",
+                "BATCH_MODE_PER_ITEM"
+            ]
+        },
+        "types.BatchOperation": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "object"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "op": {
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BatchOperationType"
+                        }
+                    ],
+                    "example": "update"
+                }
+            }
+        },
+        "types.BatchOperationResult": {
+            "type": "object",
+            "properties": {
+                "body": {},
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "types.BatchOperationType": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete"
+            ],
+            "x-enum-varnames": [
+                "BATCH_OP_CREATE",
+                "BATCH_OP_UPDATE",
+                "BATCH_OP_DELETE"
+            ]
+        },
+        "types.BatchRequest": {
+            "type": "object",
+            "properties": {
+                "mode": {
+                    "enum": [
+                        "atomic",
+                        "per_item"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BatchMode"
+                        }
+                    ],
+                    "example": "atomic"
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.BatchOperation"
+                    }
+                }
+            }
+        },
+        "types.BatchResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "mode": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BatchMode"
+                        }
+                    ],
+                    "example": "atomic"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.BatchOperationResult"
+                    }
+                }
+            }
+        },
+        "types.Book": {
+            "type": "object",
+            "required": [
+                "author",
+                "description",
+                "title"
+            ],
+            "properties": {
+                "author": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "availability": {
+                    "description": "Availability counts the copies of the book held by the inventory service. It is set on the\nbooks loaded by id, by ISBN and as a list.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookAvailability"
+                        }
+                    ]
+                },
+                "cover": {
+                    "description": "Cover is nil for a book without a cover image; it is set by the covers service.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookCover"
+                        }
+                    ]
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "GBP"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "this is an example description"
+                },
+                "edition": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "1st"
+                },
+                "format": {
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook",
+                        "audio"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookFormat"
+                        }
+                    ],
+                    "example": "hardcover"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "isbn10": {
+                    "description": "ISBN10 is empty for an ISBN-13 without ISBN-10.",
+                    "type": "string",
+                    "example": "0306406152"
+                },
+                "isbn13": {
+                    "description": "ISBN13 identifies the book for the suppliers; the ISBN-10 of a book is converted into it.",
+                    "type": "string",
+                    "example": "9780306406157"
+                },
+                "language": {
+                    "description": "Language is a BCP 47 language tag in its canonical form, e.g. \"en-GB\".",
+                    "type": "string",
+                    "example": "en-GB"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 223
+                },
+                "price": {
+                    "description": "Price is in the minor unit of the currency, e.g. 1299 for 12.99 GBP.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1299
+                },
+                "published_date": {
+                    "description": "PublishedDate is the publication date as YYYY-MM-DD.",
+                    "type": "string",
+                    "example": "1997-06-26"
+                },
+                "publisher": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Bloomsbury"
+                },
+                "rating_average": {
+                    "type": "number",
+                    "example": 4.5
+                },
+                "rating_count": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "series_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Harry Potter"
+                },
+                "series_position": {
+                    "description": "SeriesPosition is the position of the book in the series from 1.",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "example-title"
+                }
+            }
+        },
+        "types.BookAuthorRequest": {
+            "type": "object",
+            "required": [
+                "author_id",
+                "role"
+            ],
+            "properties": {
+                "author_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "role": {
+                    "enum": [
+                        "author",
+                        "editor",
+                        "translator"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AuthorRole"
+                        }
+                    ],
+                    "example": "author"
+                }
+            }
+        },
+        "types.BookAvailability": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "lost": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "on_hold": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "on_loan": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "repair": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "types.BookContributor": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "$ref": "#/definitions/types.Author"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AuthorRole"
+                        }
+                    ],
+                    "example": "author"
+                }
+            }
+        },
+        "types.BookCover": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "description": "Checksum is the hex SHA-256 of the image.",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 1800
+                },
+                "size": {
+                    "description": "Size is the size of the image in bytes.",
+                    "type": "integer",
+                    "example": 245310
+                },
+                "thumbnails": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.CoverThumbnail"
+                    }
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/api/v1/books/1/cover?v=9f86d081884c7d65"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 1200
+                }
+            }
+        },
+        "types.BookFacets": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.TagFacet"
+                    }
+                }
+            }
+        },
+        "types.BookFormat": {
+            "type": "string",
+            "enum": [
+                "hardcover",
+                "paperback",
+                "ebook",
+                "audio"
+            ],
+            "x-enum-varnames": [
+                "BOOK_FORMAT_HARDCOVER",
+                "BOOK_FORMAT_PAPERBACK",
+                "BOOK_FORMAT_EBOOK",
+                "BOOK_FORMAT_AUDIO"
+            ]
+        },
+        "types.BookRevision": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "alice"
+                },
+                "author": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string",
+                    "example": "GBP"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "this is an example description"
+                },
+                "edition": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "1st"
+                },
+                "format": {
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook",
+                        "audio"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookFormat"
+                        }
+                    ],
+                    "example": "hardcover"
+                },
+                "isbn10": {
+                    "type": "string",
+                    "example": "0306406152"
+                },
+                "isbn13": {
+                    "type": "string",
+                    "example": "9780306406157"
+                },
+                "language": {
+                    "description": "Language is a BCP 47 language tag in its canonical form, e.g. \"en-GB\".",
+                    "type": "string",
+                    "example": "en-GB"
+                },
+                "op": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ChangeOperation"
+                        }
+                    ],
+                    "example": "update"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 223
+                },
+                "price": {
+                    "description": "Price is in the minor unit of the currency, e.g. 1299 for 12.99 GBP.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1299
+                },
+                "published_date": {
+                    "description": "PublishedDate is the publication date as YYYY-MM-DD.",
+                    "type": "string",
+                    "example": "1997-06-26"
+                },
+                "publisher": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Bloomsbury"
+                },
+                "restored_from": {
+                    "description": "RestoredFrom is the revision brought back by a restore.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "rev": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "series_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Harry Potter"
+                },
+                "series_position": {
+                    "description": "SeriesPosition is the position of the book in the series from 1.",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
+                "title": {
+                    "type": "string",
+                    "example": "example-title"
+                }
+            }
+        },
+        "types.ChangeOperation": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "delete",
+                "restore"
+            ],
+            "x-enum-varnames": [
+                "CHANGE_OP_CREATE",
+                "CHANGE_OP_UPDATE",
+                "CHANGE_OP_DELETE",
+                "CHANGE_OP_RESTORE"
+            ]
+        },
+        "types.CheckoutRequest": {
+            "type": "object",
+            "required": [
+                "member_id"
+            ],
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "LIB000123"
+                },
+                "copy_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.Copy": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "description": "Barcode identifies the copy across all branches, see NormalizeBarcode.",
+                    "type": "string",
+                    "example": "LIB000123"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "branch": {
+                    "type": "string",
+                    "example": "Central"
+                },
+                "condition": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CopyCondition"
+                        }
+                    ],
+                    "example": "good"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "location": {
+                    "description": "Location is the place of the copy within its branch, e.g. a shelf.",
+                    "type": "string",
+                    "example": "Shelf 4B"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CopyStatus"
+                        }
+                    ],
+                    "example": "available"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.CopyCondition": {
+            "type": "string",
+            "enum": [
+                "new",
+                "good",
+                "fair",
+                "poor",
+                "damaged"
+            ],
+            "x-enum-varnames": [
+                "COPY_CONDITION_NEW",
+                "COPY_CONDITION_GOOD",
+                "COPY_CONDITION_FAIR",
+                "COPY_CONDITION_POOR",
+                "COPY_CONDITION_DAMAGED"
+            ]
+        },
+        "types.CopyStatus": {
+            "type": "string",
+            "enum": [
+                "available",
+                "on_loan",
+                "on_hold",
+                "lost",
+                "repair"
+            ],
+            "x-enum-varnames": [
+                "COPY_STATUS_AVAILABLE",
+                "COPY_STATUS_ON_LOAN",
+                "COPY_STATUS_ON_HOLD",
+                "COPY_STATUS_LOST",
+                "COPY_STATUS_REPAIR"
+            ]
+        },
+        "types.CoverThumbnail": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "image/jpeg"
+                },
+                "height": {
+                    "type": "integer",
+                    "example": 150
+                },
+                "name": {
+                    "type": "string",
+                    "example": "small"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 4120
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/api/v1/books/1/cover/small?v=9f86d081884c7d65"
+                },
+                "width": {
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "types.CreateListRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "To read"
+                },
+                "slug": {
+                    "description": "Slug is made from the owner and the name of the list unless given.",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "alice-to-read"
+                },
+                "visibility": {
+                    "enum": [
+                        "private",
+                        "public"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Visibility"
+                        }
+                    ],
+                    "example": "public"
+                }
+            }
+        },
+        "types.CreateListResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "alice-to-read"
+                }
+            }
+        },
+        "types.DeleteAuthorResponse": {
+            "type": "object"
+        },
+        "types.DeleteBookResponse": {
+            "type": "object"
+        },
+        "types.DeleteCopyResponse": {
+            "type": "object"
+        },
+        "types.DeleteCoverResponse": {
+            "type": "object"
+        },
+        "types.DeleteListResponse": {
+            "type": "object"
+        },
+        "types.DeleteReviewResponse": {
+            "type": "object"
+        },
+        "types.DeleteSubjectResponse": {
+            "type": "object"
+        },
+        "types.DeleteSubscriptionResponse": {
+            "type": "object"
+        },
+        "types.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "event_type": {
+                    "type": "string",
+                    "example": "book.created"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_attempt_at": {
+                    "type": "string"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "webhook responded with status 500"
+                },
+                "last_status_code": {
+                    "type": "integer",
+                    "example": 500
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.DeliveryStatus"
+                        }
+                    ],
+                    "example": "pending"
+                },
+                "subscription_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "DELIVERY_STATUS_PENDING",
+                "DELIVERY_STATUS_SUCCEEDED",
+                "DELIVERY_STATUS_DEAD"
+            ]
+        },
+        "types.EntryKind": {
+            "type": "string",
+            "enum": [
+                "fine",
+                "payment"
+            ],
+            "x-enum-varnames": [
+                "ENTRY_KIND_FINE",
+                "ENTRY_KIND_PAYMENT"
+            ]
+        },
+        "types.FieldChange": {
+            "type": "object",
+            "properties": {
+                "new": {},
+                "old": {}
+            }
+        },
+        "types.GetAccountResponse": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "$ref": "#/definitions/types.Account"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.AccountEntry"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetAuditEventsResponse": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.AuditEvent"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetAuthorBooksResponse": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.AuthorBook"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetAuthorsResponse": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Author"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetBookAuthorsResponse": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.BookContributor"
+                    }
+                }
+            }
+        },
+        "types.GetBookSubjectsResponse": {
+            "type": "object",
+            "properties": {
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Subject"
+                    }
+                }
+            }
+        },
+        "types.GetBookTagsResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fantasy"
+                    ]
+                }
+            }
+        },
+        "types.GetBooksResponse": {
+            "type": "object",
+            "properties": {
+                "books": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Book"
+                    }
+                },
+                "facets": {
+                    "description": "Facets are only given when requested.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookFacets"
+                        }
+                    ]
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetCopiesResponse": {
+            "type": "object",
+            "properties": {
+                "copies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Copy"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Delivery"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetHoldsResponse": {
+            "type": "object",
+            "properties": {
+                "holds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Hold"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetListsResponse": {
+            "type": "object",
+            "properties": {
+                "lists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.List"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetLoansResponse": {
+            "type": "object",
+            "properties": {
+                "loans": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Loan"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetMembersResponse": {
+            "type": "object",
+            "properties": {
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Member"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                }
+            }
+        },
+        "types.GetReviewsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                },
+                "reviews": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Review"
+                    }
+                }
+            }
+        },
+        "types.GetRevisionsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                },
+                "revisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.BookRevision"
+                    }
+                }
+            }
+        },
+        "types.GetSubjectsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                },
+                "subjects": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Subject"
+                    }
+                }
+            }
+        },
+        "types.GetSubscriptionsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                },
+                "subscriptions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Subscription"
+                    }
+                }
+            }
+        },
+        "types.GetTagsResponse": {
+            "type": "object",
+            "properties": {
+                "pagination": {
+                    "$ref": "#/definitions/db.Pagination"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.Tag"
+                    }
+                }
+            }
+        },
+        "types.Hold": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "closed_at": {
+                    "description": "ClosedAt is set once the hold is fulfilled, cancelled or expired.",
+                    "type": "string"
+                },
+                "copy_id": {
+                    "description": "CopyID is the copy kept for the member once the hold is ready.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "position": {
+                    "description": "Position is the place of a waiting hold in the queue of its book, starting at 1.",
+                    "type": "integer",
+                    "example": 1
+                },
+                "ready_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.HoldStatus"
+                        }
+                    ],
+                    "example": "waiting"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.HoldStatus": {
+            "type": "string",
+            "enum": [
+                "waiting",
+                "ready",
+                "fulfilled",
+                "cancelled",
+                "expired"
+            ],
+            "x-enum-varnames": [
+                "HOLD_STATUS_WAITING",
+                "HOLD_STATUS_READY",
+                "HOLD_STATUS_FULFILLED",
+                "HOLD_STATUS_CANCELLED",
+                "HOLD_STATUS_EXPIRED"
+            ]
+        },
+        "types.ImportBookRowResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "invalid json"
+                },
+                "errors": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "line": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "types.ImportBooksResponse": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "dry_run": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ImportMode"
+                        }
+                    ],
+                    "example": "atomic"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ImportBookRowResult"
+                    }
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "valid": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.ImportMode": {
+            "type": "string",
+            "enum": [
+                "atomic",
+                "partial"
+            ],
+            "x-enum-varnames": [
+                "IMPORT_MODE_ATOMIC",
+                "IMPORT_MODE_PARTIAL"
+            ]
+        },
+        "types.List": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "items": {
+                    "description": "Items are the books of the list in order; they are only loaded for a single list.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/types.ListItem"
+                    }
+                },
+                "name": {
+                    "type": "string",
+                    "example": "To read"
+                },
+                "owner": {
+                    "description": "Owner is the identity of the caller who created the list.",
+                    "type": "string",
+                    "example": "alice"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "alice-to-read"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "visibility": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Visibility"
+                        }
+                    ],
+                    "example": "public"
+                }
+            }
+        },
+        "types.ListItem": {
+            "type": "object",
+            "properties": {
+                "added_at": {
+                    "type": "string"
+                },
+                "author": {
+                    "type": "string",
+                    "example": "J. R. R. Tolkien"
+                },
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "title": {
+                    "description": "Title and Author are read from the book.",
+                    "type": "string",
+                    "example": "The Hobbit"
+                }
+            }
+        },
+        "types.Loan": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "checked_out_at": {
+                    "type": "string"
+                },
+                "copy_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "due_at": {
+                    "type": "string"
+                },
+                "fine": {
+                    "description": "Fine is the amount charged when the copy was returned late.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "renewals": {
+                    "description": "Renewals is the number of times the due date was pushed back.",
+                    "type": "integer",
+                    "example": 0
+                },
+                "returned_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.LoanStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.LoanStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "overdue",
+                "returned"
+            ],
+            "x-enum-varnames": [
+                "LOAN_STATUS_ACTIVE",
+                "LOAN_STATUS_OVERDUE",
+                "LOAN_STATUS_RETURNED"
+            ]
+        },
+        "types.Member": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email identifies the member, see NormalizeEmail.",
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Jane Doe"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.MemberStatus"
+                        }
+                    ],
+                    "example": "active"
+                },
+                "tier": {
+                    "description": "Tier is the membership tier, which caps the number of loans of the member, see the loans\nconfiguration.",
+                    "type": "string",
+                    "example": "standard"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.MemberStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "suspended"
+            ],
+            "x-enum-varnames": [
+                "MEMBER_STATUS_ACTIVE",
+                "MEMBER_STATUS_SUSPENDED"
+            ]
+        },
+        "types.ModerateReviewRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "pending",
+                        "approved",
+                        "rejected"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReviewStatus"
+                        }
+                    ],
+                    "example": "approved"
+                }
+            }
+        },
+        "types.PayRequest": {
+            "type": "object",
+            "required": [
+                "amount"
+            ],
+            "properties": {
+                "amount": {
+                    "type": "integer",
+                    "example": 75
+                }
+            }
+        },
+        "types.PlaceHoldRequest": {
+            "type": "object",
+            "required": [
+                "book_id",
+                "member_id"
+            ],
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "member_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "types.ReorderListItemsRequest": {
+            "type": "object",
+            "required": [
+                "book_ids"
+            ],
+            "properties": {
+                "book_ids": {
+                    "description": "BookIDs are every book of the list in their new order.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        3,
+                        1,
+                        2
+                    ]
+                }
+            }
+        },
+        "types.Review": {
+            "type": "object",
+            "properties": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "title": {
+                "rating": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "reviewer": {
+                    "description": "Reviewer is the identity of the caller who wrote the review.",
                     "type": "string",
-                    "example": "example-title"
+                    "example": "alice"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ReviewStatus"
+                        }
+                    ],
+                    "example": "approved"
+                },
+                "text": {
+                    "type": "string",
+                    "example": "A classic."
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "types.DeleteBookResponse": {
-            "type": "object"
+        "types.ReviewStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "approved",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "REVIEW_STATUS_PENDING",
+                "REVIEW_STATUS_APPROVED",
+                "REVIEW_STATUS_REJECTED"
+            ]
         },
-        "types.GetBooksResponse": {
+        "types.RevisionDiffResponse": {
             "type": "object",
             "properties": {
-                "books": {
+                "book_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "changes": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/types.FieldChange"
+                    }
+                },
+                "from": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "to": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "types.SetBookAuthorsRequest": {
+            "type": "object",
+            "properties": {
+                "authors": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/types.Book"
+                        "$ref": "#/definitions/types.BookAuthorRequest"
                     }
+                }
+            }
+        },
+        "types.SetBookSubjectsRequest": {
+            "type": "object",
+            "required": [
+                "subject_ids"
+            ],
+            "properties": {
+                "subject_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1
+                    ]
+                }
+            }
+        },
+        "types.SetBookTagsRequest": {
+            "type": "object",
+            "required": [
+                "tags"
+            ],
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "fantasy"
+                    ]
+                }
+            }
+        },
+        "types.SetCoverResponse": {
+            "type": "object",
+            "properties": {
+                "cover": {
+                    "$ref": "#/definitions/types.BookCover"
+                }
+            }
+        },
+        "types.Subject": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "FIC009000"
                 },
-                "pagination": {
-                    "$ref": "#/definitions/db.Pagination"
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Fantasy"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "path": {
+                    "description": "Path is the ids of the ancestors of the subject and its own id, each followed by a\nslash, e.g. \"1/4/9/\". The subtree of a subject is the subjects whose path starts with\nits path.",
+                    "type": "string",
+                    "example": "1/4/9/"
+                },
+                "scheme": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.SubjectScheme"
+                        }
+                    ],
+                    "example": "bisac"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "types.SubjectScheme": {
+            "type": "string",
+            "enum": [
+                "",
+                "dewey",
+                "bisac"
+            ],
+            "x-enum-varnames": [
+                "SUBJECT_SCHEME_NONE",
+                "SUBJECT_SCHEME_DEWEY",
+                "SUBJECT_SCHEME_BISAC"
+            ]
+        },
+        "types.Subscription": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "description": "Events is the event filter; ` + "`" + `*` + "`" + ` matches every event and ` + "`" + `book.*` + "`" + ` matches every book event.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "book.created",
+                        "book.updated"
+                    ]
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "types.Tag": {
+            "type": "object",
+            "properties": {
+                "book_count": {
+                    "description": "BookCount is the number of books having the tag, only read when listing the tags.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "fantasy"
+                }
+            }
+        },
+        "types.TagFacet": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "tag": {
+                    "type": "string",
+                    "example": "fantasy"
+                }
+            }
+        },
+        "types.UpdateAuthorRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "example": "British author"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "J. K. Rowling"
                 }
             }
         },
@@ -340,15 +6936,294 @@ const docTemplate = `{
                     "type": "string",
                     "example": "John Doe"
                 },
+                "currency": {
+                    "type": "string",
+                    "example": "GBP"
+                },
                 "description": {
                     "type": "string",
                     "example": "this is an example description"
                 },
+                "edition": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "1st"
+                },
+                "format": {
+                    "enum": [
+                        "hardcover",
+                        "paperback",
+                        "ebook",
+                        "audio"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.BookFormat"
+                        }
+                    ],
+                    "example": "hardcover"
+                },
+                "isbn10": {
+                    "type": "string",
+                    "example": "0-306-40615-2"
+                },
+                "isbn13": {
+                    "type": "string",
+                    "example": "978-0-306-40615-7"
+                },
+                "language": {
+                    "description": "Language is a BCP 47 language tag in its canonical form, e.g. \"en-GB\".",
+                    "type": "string",
+                    "example": "en-GB"
+                },
+                "page_count": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 223
+                },
+                "price": {
+                    "description": "Price is in the minor unit of the currency, e.g. 1299 for 12.99 GBP.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 1299
+                },
+                "published_date": {
+                    "description": "PublishedDate is the publication date as YYYY-MM-DD.",
+                    "type": "string",
+                    "example": "1997-06-26"
+                },
+                "publisher": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Bloomsbury"
+                },
+                "series_name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Harry Potter"
+                },
+                "series_position": {
+                    "description": "SeriesPosition is the position of the book in the series from 1.",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
+                },
                 "title": {
                     "type": "string",
                     "example": "example-title"
                 }
             }
+        },
+        "types.UpdateCopyRequest": {
+            "type": "object",
+            "properties": {
+                "barcode": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "LIB000123"
+                },
+                "branch": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Central"
+                },
+                "condition": {
+                    "enum": [
+                        "new",
+                        "good",
+                        "fair",
+                        "poor",
+                        "damaged"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CopyCondition"
+                        }
+                    ],
+                    "example": "good"
+                },
+                "location": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Shelf 4B"
+                },
+                "status": {
+                    "enum": [
+                        "available",
+                        "lost",
+                        "repair"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.CopyStatus"
+                        }
+                    ],
+                    "example": "repair"
+                }
+            }
+        },
+        "types.UpdateListRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Course syllabus"
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "alice-course-syllabus"
+                },
+                "visibility": {
+                    "enum": [
+                        "private",
+                        "public"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.Visibility"
+                        }
+                    ],
+                    "example": "private"
+                }
+            }
+        },
+        "types.UpdateMemberRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "jane@example.com"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Jane Doe"
+                },
+                "status": {
+                    "enum": [
+                        "active",
+                        "suspended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.MemberStatus"
+                        }
+                    ],
+                    "example": "suspended"
+                },
+                "tier": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "premium"
+                }
+            }
+        },
+        "types.UpdateReviewRequest": {
+            "type": "object",
+            "properties": {
+                "rating": {
+                    "type": "integer",
+                    "maximum": 5,
+                    "minimum": 1,
+                    "example": 5
+                },
+                "text": {
+                    "type": "string",
+                    "maxLength": 5000,
+                    "example": "A classic, better on a second read."
+                }
+            }
+        },
+        "types.UpdateSubjectRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "FIC009000"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Fantasy"
+                },
+                "parent_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "scheme": {
+                    "enum": [
+                        "dewey",
+                        "bisac"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.SubjectScheme"
+                        }
+                    ],
+                    "example": "bisac"
+                }
+            }
+        },
+        "types.UpdateSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "events"
+            ],
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "book.deleted"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "minLength": 16,
+                    "example": "a-secret-of-at-least-16-chars"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/webhooks"
+                }
+            }
+        },
+        "types.VerifyAuditResponse": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "invalid_id": {
+                    "description": "InvalidID is the first entry whose hash does not match, if any.",
+                    "type": "integer",
+                    "example": 42
+                },
+                "valid": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "types.Visibility": {
+            "type": "string",
+            "enum": [
+                "private",
+                "public"
+            ],
+            "x-enum-varnames": [
+                "LIST_VISIBILITY_PRIVATE",
+                "LIST_VISIBILITY_PUBLIC"
+            ]
         }
     },
     "securityDefinitions": {
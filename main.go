@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,11 +10,33 @@ import (
 	httpSwagger "github.com/swaggo/http-swagger"
 	"go.uber.org/zap"
 
+	auditservice "github.com/nkitlabs/go-http-gorm-example/pkg/audit/service"
+	audittypes "github.com/nkitlabs/go-http-gorm-example/pkg/audit/types"
+	authorservice "github.com/nkitlabs/go-http-gorm-example/pkg/authors/service"
+	authortypes "github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/blob"
 	bookservice "github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
 	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	classificationservice "github.com/nkitlabs/go-http-gorm-example/pkg/classification/service"
+	classificationtypes "github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	coverservice "github.com/nkitlabs/go-http-gorm-example/pkg/covers/service"
 	dbstore "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/feed"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/idempotency"
+	inventoryservice "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/service"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	listservice "github.com/nkitlabs/go-http-gorm-example/pkg/lists/service"
+	listtypes "github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+	loanservice "github.com/nkitlabs/go-http-gorm-example/pkg/loans/service"
+	loantypes "github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	reviewservice "github.com/nkitlabs/go-http-gorm-example/pkg/reviews/service"
+	reviewtypes "github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+	webhookservice "github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/service"
+	webhooktypes "github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
 )
 
 // @title           Swagger Example API
@@ -42,6 +66,9 @@ func main() {
 		}
 	}()
 
+	demo := flag.Bool("demo", false, "serve the books API from memory with sample books, without a database")
+	flag.Parse()
+
 	config_filename := os.Getenv("CONFIG_FILE")
 	if config_filename == "" {
 		config_filename = "config.dev.yaml"
@@ -54,28 +81,120 @@ func main() {
 		return
 	}
 
-	db, err := dbstore.Init(conf.Conn)
+	if *demo {
+		if err := runDemo(conf, logger); err != nil {
+			logger.Error(err.Error())
+		}
+		return
+	}
+
+	db, err := dbstore.Init(conf.Conn, logger)
 	if err != nil {
 		logger.Error(err.Error())
 		return
 	}
+	if err := db.Use(tenant.Plugin{}); err != nil {
+		logger.Error(err.Error())
+		return
+	}
 
-	if err := db.AutoMigrate(&booktypes.Book{}); err != nil {
+	// the schema and the data of every tenant are migrated at once.
+	migrationDB := db.WithContext(tenant.AllTenants(context.Background()))
+	if err := migrationDB.AutoMigrate(&booktypes.Book{}, &booktypes.BookRevision{}, &idempotency.Record{}, &outbox.Event{}, &webhooktypes.Subscription{}, &webhooktypes.Delivery{}, &audittypes.AuditEvent{}, &authortypes.Author{}, &authortypes.BookAuthor{}, &classificationtypes.Tag{}, &classificationtypes.BookTag{}, &classificationtypes.Subject{}, &classificationtypes.BookSubject{}, &inventorytypes.Copy{}, &loantypes.Member{}, &loantypes.Loan{}, &loantypes.Hold{}, &loantypes.AccountEntry{}, &reviewtypes.Review{}, &listtypes.List{}, &listtypes.ListItem{}); err != nil {
 		logger.Error(err.Error())
 		return
 	}
 
-	bookRepository := bookservice.NewRepository(db, logger)
+	if err := dbstore.Migrate(migrationDB, authorservice.ConvertAuthorStrings, tenant.ScopeUniqueIndexes); err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	bookRepository := bookservice.NewRepository(db, logger, classificationservice.BookClassifier{}, inventoryservice.BookInventory{}, outbox.WriteBookChange, auditservice.WriteBookChange, authorservice.WriteBookChange, classificationservice.WriteBookChange, inventoryservice.WriteBookChange, reviewservice.WriteBookChange, listservice.WriteBookChange)
 	bookService := bookservice.NewService(&bookRepository, logger)
 	h := bookservice.NewHandler(&bookService, logger)
 
+	blobStore, err := blob.NewStore(conf.Blob)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	coverService := coverservice.NewService(&bookRepository, blobStore, conf.Covers, logger)
+	coverHandler := coverservice.NewHandler(&coverService, logger)
+
+	auditRepository := auditservice.NewRepository(db, logger)
+	auditService := auditservice.NewService(&auditRepository, logger)
+	auditHandler := auditservice.NewHandler(&auditService, logger)
+
+	authorRepository := authorservice.NewRepository(db, logger)
+	authorService := authorservice.NewService(&authorRepository, logger)
+	authorHandler := authorservice.NewHandler(&authorService, logger)
+
+	classificationRepository := classificationservice.NewRepository(db, logger)
+	classificationService := classificationservice.NewService(&classificationRepository, logger)
+	classificationHandler := classificationservice.NewHandler(&classificationService, logger)
+
+	inventoryRepository := inventoryservice.NewRepository(db, logger)
+	inventoryService := inventoryservice.NewService(&inventoryRepository, logger)
+	inventoryHandler := inventoryservice.NewHandler(&inventoryService, logger)
+
+	loanRepository := loanservice.NewRepository(db, logger)
+	loanService := loanservice.NewService(&loanRepository, conf.Loans, logger)
+	loanHandler := loanservice.NewHandler(&loanService, logger)
+
+	reviewRepository := reviewservice.NewRepository(db, logger)
+	reviewService := reviewservice.NewService(&reviewRepository, logger)
+	reviewHandler := reviewservice.NewHandler(&reviewService, logger)
+
+	listRepository := listservice.NewRepository(db, logger)
+	listService := listservice.NewService(&listRepository, logger)
+	listHandler := listservice.NewHandler(&listService, logger)
+
+	webhookRepository := webhookservice.NewRepository(db, logger)
+	webhookService := webhookservice.NewService(&webhookRepository, logger)
+	webhookHandler := webhookservice.NewHandler(&webhookService, logger)
+
 	router := http.NewServeMux()
 	router = bookservice.InitializeRoutes(router, h)
+	router = webhookservice.InitializeRoutes(router, webhookHandler)
+	router = auditservice.InitializeRoutes(router, auditHandler)
+	router = authorservice.InitializeRoutes(router, authorHandler)
+	router = classificationservice.InitializeRoutes(router, classificationHandler)
+	router = coverservice.InitializeRoutes(router, coverHandler)
+	router = inventoryservice.InitializeRoutes(router, inventoryHandler)
+	router = loanservice.InitializeRoutes(router, loanHandler)
+	router = reviewservice.InitializeRoutes(router, reviewHandler)
+	router = listservice.InitializeRoutes(router, listHandler)
+
+	broker := feed.NewBroker(conf.Feed.BufferSize)
+	router = feed.InitializeRoutes(router, feed.NewHandler(broker, conf.Feed.Heartbeat, logger))
 	router.HandleFunc("GET /api/v1/swagger/", httpSwagger.WrapHandler)
 
+	sinks := append(outbox.NewSinks(conf.Outbox.Sinks, logger), webhookservice.NewSink(&webhookService))
+	// the replicas are notified by Postgres; the other databases are used by a single replica.
+	if conf.Feed.Backend == feed.BACKEND_MEMORY || !dbstore.IsPostgres(db) {
+		sinks = append(sinks, feed.NewBrokerSink(broker))
+	} else {
+		sinks = append(sinks, feed.NewNotifySink(db, conf.Feed.Channel))
+		go feed.NewListener(db, conf.Feed.Channel, broker, logger).Run(context.Background())
+	}
+	relay := outbox.NewRelay(db, sinks, conf.Outbox, logger)
+	go relay.Run(context.Background())
+
+	dispatcher := webhookservice.NewDispatcher(&webhookRepository, &http.Client{}, conf.Webhooks, logger)
+	go dispatcher.Run(context.Background())
+
+	go loanservice.RunOverdueCheck(context.Background(), &loanService, conf.Loans.OverdueCheckInterval, logger)
+	go loanservice.RunHoldExpiry(context.Background(), &loanService, conf.Loans.HoldCheckInterval, logger)
+
+	idempotencyStore := idempotency.NewRepository(db, logger)
+	go idempotency.RunPurger(context.Background(), &idempotencyStore, conf.Idempotency.PurgeInterval, logger)
+	handler := idempotency.Middleware(&idempotencyStore, conf.Idempotency, logger)(router)
+	handler = tenant.Middleware(conf.Tenancy, logger)(handler)
+
 	server := &http.Server{
 		Addr:    conf.App.Port,
-		Handler: middleware.Wraps(router, logger),
+		Handler: middleware.Wraps(handler, logger),
 	}
 	logger.Info("Listening...")
 
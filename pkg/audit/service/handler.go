@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/audit/types"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the audit service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the audit service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/audit", h.GetEvents)
+	mux.HandleFunc("GET /api/v1/audit/verify", h.Verify)
+	return mux
+}
+
+// @Summary get list of audit log entries of the book changes
+// @ID get-audit-events
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param sort_type query string false "order of items to be sorted (by id)" enums(asc,desc)
+// @Param actor query string false "caller identity"
+// @Param book_id query int false "Book ID"
+// @Param operation query string false "operation made to the book" enums(create,update,delete,restore)
+// @Param request_id query string false "Request ID"
+// @Param from query string false "earliest time of the entries (RFC 3339), inclusive"
+// @Param to query string false "latest time of the entries (RFC 3339), exclusive"
+// @Produce json
+// @Success 200 {object} types.GetAuditEventsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /audit [get]
+func (h Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	page, err := strconv.ParseInt(query.Get("page"), 10, 0)
+	if err != nil {
+		response.WriteError(ctx, w, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid page: %s", query.Get("page"))), h.log)
+		return
+	}
+
+	limit, err := strconv.ParseInt(query.Get("limit"), 10, 0)
+	if err != nil {
+		response.WriteError(ctx, w, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid limit: %s", query.Get("limit"))), h.log)
+		return
+	}
+
+	filter, err := parseFilter(query)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	sortType := db.ToSortType(query.Get("sort_type"))
+
+	result, err := h.serv.WithContext(ctx).GetEvents(filter, int(page), int(limit), sortType)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary verify the hash chain of the audit log
+// @Description Every entry is checked to link to the previous one and to match its hash;
+// @Description an invalid chain means the audit log was tampered with.
+// @ID verify-audit
+// @Produce json
+// @Success 200 {object} types.VerifyAuditResponse
+// @Failure 500 {object} errors.Error
+// @Router /audit/verify [get]
+func (h Handler) Verify(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result, err := h.serv.WithContext(ctx).Verify()
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// parseFilter reads the filter of the audit log from the query parameters
+func parseFilter(query url.Values) (types.AuditFilter, error) {
+	filter := types.AuditFilter{
+		Actor:     query.Get("actor"),
+		RequestID: query.Get("request_id"),
+	}
+
+	if s := query.Get("book_id"); s != "" {
+		id, err := strconv.ParseInt(s, 10, 0)
+		if err != nil {
+			return types.AuditFilter{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid book_id: %s", s))
+		}
+		filter.BookID = int(id)
+	}
+
+	switch op := booktypes.ChangeOperation(query.Get("operation")); op {
+	case "", booktypes.CHANGE_OP_CREATE, booktypes.CHANGE_OP_UPDATE, booktypes.CHANGE_OP_DELETE, booktypes.CHANGE_OP_RESTORE:
+		filter.Operation = op
+	default:
+		return types.AuditFilter{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid operation: %s", op))
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	} {
+		s := query.Get(param.name)
+		if s == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return types.AuditFilter{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", param.name, s))
+		}
+		*param.dst = &t
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,185 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/audit/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/audit/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/audit/types"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+func TestGetEvents(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type output struct {
+		code   int
+		body   types.GetAuditEventsResponse
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		query      string
+		output     output
+	}{
+		{
+			name:  "invalid operation",
+			query: "?page=1&limit=10&operation=read",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid operation: read",
+			},
+		},
+		{
+			name:  "invalid from",
+			query: "?page=1&limit=10&from=yesterday",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid from: yesterday",
+			},
+		},
+		{
+			name:  "success",
+			query: "?page=1&limit=10&sort_type=asc&actor=alice&book_id=1&operation=update&from=2024-01-01T00:00:00Z",
+			output: output{
+				code: http.StatusOK,
+				body: types.GetAuditEventsResponse{
+					Events:     []types.AuditEvent{{ID: 1, Actor: "alice", Operation: booktypes.CHANGE_OP_UPDATE, BookID: 1}},
+					Pagination: &db.Pagination{Page: 1, Limit: 10, TotalRows: 1, TotalPages: 1},
+				},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetEvents(types.AuditFilter{
+					Actor:     "alice",
+					BookID:    1,
+					Operation: booktypes.CHANGE_OP_UPDATE,
+					From:      &from,
+				}, 1, 10, db.SORT_ASC).Return(
+					&db.Pagination{Page: 1, Limit: 10, TotalRows: 1, TotalPages: 1},
+					[]types.AuditEvent{{ID: 1, Actor: "alice", Operation: booktypes.CHANGE_OP_UPDATE, BookID: 1}},
+					nil,
+				)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/audit"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.GetAuditEventsResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+// newChain returns n audit log entries linked by their hashes.
+func newChain(n int) []types.AuditEvent {
+	events := make([]types.AuditEvent, n)
+	prevHash := ""
+	for i := range events {
+		events[i] = types.AuditEvent{
+			ID:        uint64(i + 1),
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, i, 0, time.UTC),
+			Actor:     "alice",
+			Operation: booktypes.CHANGE_OP_UPDATE,
+			BookID:    1,
+			Before:    json.RawMessage(`{"id":1,"title":"old-title"}`),
+			After:     json.RawMessage(`{"id":1,"title":"new-title"}`),
+			PrevHash:  prevHash,
+		}
+		events[i].Hash = events[i].ComputeHash()
+		prevHash = events[i].Hash
+	}
+
+	return events
+}
+
+func TestVerify(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tamper func(events []types.AuditEvent)
+		output types.VerifyAuditResponse
+	}{
+		{
+			name:   "valid",
+			tamper: func(events []types.AuditEvent) {},
+			output: types.VerifyAuditResponse{Valid: true, Checked: 3},
+		},
+		{
+			name: "changed entry",
+			tamper: func(events []types.AuditEvent) {
+				events[1].After = json.RawMessage(`{"id":1,"title":"forged-title"}`)
+			},
+			output: types.VerifyAuditResponse{Valid: false, Checked: 2, InvalidID: 2},
+		},
+		{
+			name: "rehashed entry",
+			tamper: func(events []types.AuditEvent) {
+				events[1].Actor = "mallory"
+				events[1].Hash = events[1].ComputeHash()
+			},
+			output: types.VerifyAuditResponse{Valid: false, Checked: 3, InvalidID: 3},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			events := newChain(3)
+			tc.tamper(events)
+			s.Repository.EXPECT().GetEventsAfter(uint64(0), 1000).Return(events, nil)
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/audit/verify", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, http.StatusOK, resp.Code)
+
+			var res types.VerifyAuditResponse
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.output, res)
+		})
+	}
+}
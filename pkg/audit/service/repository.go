@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/audit/types"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+var (
+	_ types.DataProvider     = &Repository{}
+	_ booktypes.ChangeWriter = WriteBookChange
+)
+
+// appendLockKey is the prefix of the keys of the advisory locks serializing the appends to the
+// hash chain of each tenant.
+const appendLockKey = 0x61756469 // "audi"
+
+// Repository is the data provider that connect to the database being used in an audit service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new audit-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// GetEvents retrieves a filtered list of audit log entries from the database
+func (r *Repository) GetEvents(filter types.AuditFilter, page int, limit int, sortType db.SortType) (*db.Pagination, []types.AuditEvent, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id " + string(sortType),
+	}
+
+	query := r.db
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.BookID != 0 {
+		query = query.Where("book_id = ?", filter.BookID)
+	}
+	if filter.Operation != "" {
+		query = query.Where("operation = ?", filter.Operation)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	query = query.Session(&gorm.Session{})
+
+	var events []types.AuditEvent
+	if result := query.Scopes(db.Paginate(&events, &p, query)).Find(&events); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, events, nil
+}
+
+// GetEventsAfter retrieves the audit log entries after the given id from the database
+func (r *Repository) GetEventsAfter(id uint64, limit int) ([]types.AuditEvent, error) {
+	var events []types.AuditEvent
+	if result := r.db.Where("id > ?", id).Order("id").Limit(limit).Find(&events); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return events, nil
+}
+
+// WriteBookChange is the change writer that appends the book change into the audit log within
+// the transaction of the change, together with the caller of the request found in ctx.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	event := types.AuditEvent{
+		// the database keeps microseconds, so the hashed time is truncated to match the stored one.
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		Actor:     middleware.GetActor(ctx),
+		RequestID: middleware.GetRequestID(ctx),
+		RemoteIP:  middleware.GetRemoteIP(ctx),
+		Operation: change.Op,
+		BookID:    change.BookID,
+	}
+
+	var err error
+	if event.Before, err = marshalBook(change.Before); err != nil {
+		return err
+	}
+	if event.After, err = marshalBook(change.After); err != nil {
+		return err
+	}
+
+	// the lock is held until the transaction ends, so concurrent changes cannot fork the chain.
+	// Each tenant has its own chain, so the changes of other tenants don't wait for the lock.
+	if err := db.LockTransaction(tx, tenantLockKey(tx.Statement.Context)); err != nil {
+		return err
+	}
+
+	var last types.AuditEvent
+	if err := tx.Order("id desc").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+
+	event.PrevHash = last.Hash
+	event.Hash = event.ComputeHash()
+	return tx.Create(&event).Error
+}
+
+// tenantLockKey returns the key of the advisory lock of the hash chain of the tenant of ctx: the
+// hash of the tenant id below the prefix appendLockKey.
+func tenantLockKey(ctx context.Context) int64 {
+	id, _ := tenant.FromContext(ctx)
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return appendLockKey<<32 | int64(h.Sum32())
+}
+
+func marshalBook(book *booktypes.Book) (json.RawMessage, error) {
+	if book == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(book)
+}
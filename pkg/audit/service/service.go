@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/audit/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// verifyBatchSize is the number of entries loaded at a time while verifying the hash chain.
+const verifyBatchSize = 1000
+
+// Service is the service layer for the audit log
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new audit service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// GetEvents returns a filtered list of audit log entries
+func (s *Service) GetEvents(filter types.AuditFilter, page int, limit int, sortType db.SortType) (types.GetAuditEventsResponse, error) {
+	pagination, events, err := s.dataProvider.GetEvents(filter, page, limit, sortType)
+	if err != nil {
+		return types.GetAuditEventsResponse{}, err
+	}
+
+	return types.GetAuditEventsResponse{
+		Events:     events,
+		Pagination: pagination,
+	}, nil
+}
+
+// Verify walks the audit log in order and checks that every entry links to the previous one
+// and matches its hash. It stops at the first broken entry.
+func (s *Service) Verify() (types.VerifyAuditResponse, error) {
+	resp := types.VerifyAuditResponse{Valid: true}
+
+	var lastID uint64
+	prevHash := ""
+	for {
+		events, err := s.dataProvider.GetEventsAfter(lastID, verifyBatchSize)
+		if err != nil {
+			return types.VerifyAuditResponse{}, err
+		}
+
+		for _, event := range events {
+			resp.Checked++
+			if event.PrevHash != prevHash || event.ComputeHash() != event.Hash {
+				resp.Valid = false
+				resp.InvalidID = event.ID
+				return resp, nil
+			}
+
+			lastID = event.ID
+			prevHash = event.Hash
+		}
+
+		if len(events) < verifyBatchSize {
+			return resp, nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/audit/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/audit/testutil/mock_data_provider.go --source ./pkg/audit/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/audit/types"
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// GetEvents mocks base method.
+func (m *MockDataProvider) GetEvents(filter types.AuditFilter, page, limit int, sortType db.SortType) (*db.Pagination, []types.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEvents", filter, page, limit, sortType)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.AuditEvent)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetEvents indicates an expected call of GetEvents.
+func (mr *MockDataProviderMockRecorder) GetEvents(filter, page, limit, sortType any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEvents", reflect.TypeOf((*MockDataProvider)(nil).GetEvents), filter, page, limit, sortType)
+}
+
+// GetEventsAfter mocks base method.
+func (m *MockDataProvider) GetEventsAfter(id uint64, limit int) ([]types.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventsAfter", id, limit)
+	ret0, _ := ret[0].([]types.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventsAfter indicates an expected call of GetEventsAfter.
+func (mr *MockDataProviderMockRecorder) GetEventsAfter(id, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventsAfter", reflect.TypeOf((*MockDataProvider)(nil).GetEventsAfter), id, limit)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
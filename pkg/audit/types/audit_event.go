@@ -0,0 +1,55 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+// AuditEvent is the model for an entry of the append-only audit log. Every entry holds the hash
+// of the previous one, so changing or removing an entry breaks the chain.
+type AuditEvent struct {
+	ID        uint64                    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID  string                    `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	CreatedAt time.Time                 `json:"created_at" gorm:"not null;index"`
+	Actor     string                    `json:"actor" gorm:"not null;index" example:"alice"`
+	RequestID string                    `json:"request_id" gorm:"index" example:"6f1c2d8e-1b7a-4f0e-9a55-3d2b8c1e0f42"`
+	RemoteIP  string                    `json:"remote_ip" example:"127.0.0.1:52144"`
+	Operation booktypes.ChangeOperation `json:"operation" gorm:"not null" example:"update"`
+	BookID    int                       `json:"book_id" gorm:"not null;index" example:"1"`
+	// Before and After are stored as json instead of jsonb, which keeps the hashed text as is.
+	Before   json.RawMessage `json:"before,omitempty" gorm:"type:json" swaggertype:"object"`
+	After    json.RawMessage `json:"after,omitempty" gorm:"type:json" swaggertype:"object"`
+	PrevHash string          `json:"prev_hash" gorm:"not null" example:""`
+	Hash     string          `json:"hash" gorm:"not null;uniqueIndex" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+// TableName overrides the table name used by gorm.
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// ComputeHash returns the SHA-256 hash of the previous hash and the fields of the entry.
+func (e AuditEvent) ComputeHash() string {
+	h := sha256.New()
+	for _, field := range []string{
+		e.PrevHash,
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+		e.Actor,
+		e.RequestID,
+		e.RemoteIP,
+		string(e.Operation),
+		strconv.Itoa(e.BookID),
+		string(e.Before),
+		string(e.After),
+	} {
+		// fields are length-prefixed, so moving text between fields changes the hash.
+		h.Write([]byte(strconv.Itoa(len(field)) + ":" + field))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
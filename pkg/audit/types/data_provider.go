@@ -0,0 +1,18 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for an audit service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	GetEvents(filter AuditFilter, page int, limit int, sortType db.SortType) (*db.Pagination, []AuditEvent, error)
+	// GetEventsAfter returns up to limit entries with an id greater than the given one, in order.
+	GetEventsAfter(id uint64, limit int) ([]AuditEvent, error)
+}
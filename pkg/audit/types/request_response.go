@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// AuditFilter is the filter of the audit log; empty fields match every entry.
+type AuditFilter struct {
+	Actor     string
+	BookID    int
+	Operation booktypes.ChangeOperation
+	RequestID string
+	From      *time.Time
+	To        *time.Time
+}
+
+// GetAuditEventsResponse is the response for getting a list of audit log entries
+type GetAuditEventsResponse struct {
+	Events     []AuditEvent   `json:"events"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// VerifyAuditResponse is the response for verifying the hash chain of the audit log
+type VerifyAuditResponse struct {
+	Valid   bool `json:"valid" example:"true"`
+	Checked int  `json:"checked" example:"100"`
+	// InvalidID is the first entry whose hash does not match, if any.
+	InvalidID uint64 `json:"invalid_id,omitempty" example:"42"`
+}
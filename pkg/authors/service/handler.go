@@ -0,0 +1,304 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the authors service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the authors service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/authors", h.GetAuthors)
+	mux.HandleFunc("GET /api/v1/authors/{id}", h.GetAuthor)
+	mux.HandleFunc("GET /api/v1/authors/{id}/books", h.GetAuthorBooks)
+	mux.HandleFunc("POST /api/v1/authors", h.AddAuthor)
+	mux.HandleFunc("PUT /api/v1/authors/{id}", h.UpdateAuthor)
+	mux.HandleFunc("DELETE /api/v1/authors/{id}", h.DeleteAuthor)
+	mux.HandleFunc("GET /api/v1/books/{id}/authors", h.GetBookAuthors)
+	mux.HandleFunc("PUT /api/v1/books/{id}/authors", h.SetBookAuthors)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// @Summary get list of authors
+// @ID get-authors
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param q query string false "part of the author name"
+// @Produce json
+// @Success 200 {object} types.GetAuthorsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /authors [get]
+func (h Handler) GetAuthors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetAuthors(r.URL.Query().Get("q"), page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get author information from given id
+// @ID get-author
+// @Param id path int true "Author ID"
+// @Produce json
+// @Success 200 {object} types.Author
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /authors/{id} [get]
+func (h Handler) GetAuthor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetAuthor(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get books of an author with the role of the author
+// @ID get-author-books
+// @Param id path int true "Author ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Produce json
+// @Success 200 {object} types.GetAuthorBooksResponse
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /authors/{id}/books [get]
+func (h Handler) GetAuthorBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetAuthorBooks(id, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary add a new author into the system
+// @ID add-author
+// @Produce json
+// @Param Body body types.AddAuthorRequest true "Author information that needs to be added"
+// @Success 201 {object} types.AddAuthorResponse
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /authors [post]
+func (h Handler) AddAuthor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read to request body
+	var req types.AddAuthorRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).AddAuthor(req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary update author information in the system with the given id
+// @ID update-author
+// @Produce json
+// @Param id path int true "Author ID"
+// @Param Body body types.UpdateAuthorRequest true "Author information that needs to be updated"
+// @Success 200 {object} types.Author
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /authors/{id} [put]
+func (h Handler) UpdateAuthor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateAuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateAuthor(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary delete author id from the system
+// @Description Only an author without books can be deleted.
+// @ID delete-author
+// @Param id path int true "Author ID"
+// @Produce json
+// @Success 200 {object} types.DeleteAuthorResponse
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /authors/{id} [delete]
+func (h Handler) DeleteAuthor(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteAuthor(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get authors of a book with their roles
+// @ID get-book-authors
+// @Param id path int true "Book ID"
+// @Produce json
+// @Success 200 {object} types.GetBookAuthorsResponse
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/authors [get]
+func (h Handler) GetBookAuthors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetBookAuthors(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary replace authors of a book
+// @Description Authors are ordered as given. Changing the author text of the book replaces its
+// @Description authors in the `author` role again.
+// @ID set-book-authors
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param Body body types.SetBookAuthorsRequest true "Authors of the book with their roles"
+// @Success 200 {object} types.GetBookAuthorsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/authors [put]
+func (h Handler) SetBookAuthors(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.SetBookAuthorsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).SetBookAuthors(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
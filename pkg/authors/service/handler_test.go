@@ -0,0 +1,311 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+func TestNormalizeName(t *testing.T) {
+	require.Equal(t, "jkrowling", types.NormalizeName("J. K. Rowling"))
+	require.Equal(t, "jkrowling", types.NormalizeName("JK Rowling"))
+	require.Equal(t, "émilezola", types.NormalizeName("Émile Zola"))
+	require.Equal(t, "", types.NormalizeName(" - "))
+}
+
+func TestParseAuthorNames(t *testing.T) {
+	testCases := []struct {
+		in  string
+		out []string
+	}{
+		{in: "J. K. Rowling", out: []string{"J. K. Rowling"}},
+		{in: "Terry Pratchett & Neil Gaiman", out: []string{"Terry Pratchett", "Neil Gaiman"}},
+		{in: "Brian Kernighan and Dennis Ritchie; ", out: []string{"Brian Kernighan", "Dennis Ritchie"}},
+		{in: "Alexander Anderson", out: []string{"Alexander Anderson"}},
+		{in: "", out: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			require.Equal(t, tc.out, service.ParseAuthorNames(tc.in))
+		})
+	}
+}
+
+func TestAddAuthor(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.AddAuthorResponse
+		errMsg string
+		errID  int
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.AddAuthorRequest
+		output     output
+	}{
+		{
+			name:  "invalid body",
+			input: types.AddAuthorRequest{},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "{\"Name\":\"It is required\"}",
+			},
+		},
+		{
+			name:  "duplicated spelling",
+			input: types.AddAuthorRequest{Name: "JK Rowling"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "author already exists: 1",
+				errID:  1,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetAuthorByNormalizedName("jkrowling").Return(&types.Author{ID: 1, Name: "J. K. Rowling"}, nil)
+			},
+		},
+		{
+			name:  "spelling added concurrently",
+			input: types.AddAuthorRequest{Name: "JK Rowling"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "author already exists: 2",
+				errID:  2,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				gomock.InOrder(
+					s.Repository.EXPECT().GetAuthorByNormalizedName("jkrowling").Return(nil, gorm.ErrRecordNotFound),
+					s.Repository.EXPECT().CreateAuthor(gomock.Any()).Return(types.Author{}, gorm.ErrDuplicatedKey),
+					s.Repository.EXPECT().GetAuthorByNormalizedName("jkrowling").Return(&types.Author{ID: 2, Name: "J. K. Rowling"}, nil),
+				)
+			},
+		},
+		{
+			name:  "success",
+			input: types.AddAuthorRequest{Name: "J. K. Rowling", Bio: "British author"},
+			output: output{
+				code: http.StatusCreated,
+				body: types.AddAuthorResponse{ID: 1},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetAuthorByNormalizedName("jkrowling").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateAuthor(types.Author{
+					Name:           "J. K. Rowling",
+					NormalizedName: "jkrowling",
+					Bio:            "British author",
+				}).Return(types.Author{ID: 1}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/authors", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.AddAuthorResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+				require.Equal(t, tc.output.errID, res.ID)
+			}
+		})
+	}
+}
+
+func TestDeleteAuthor(t *testing.T) {
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+	}{
+		{
+			name: "author with books",
+			code: http.StatusConflict,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetAuthor(1).Return(&types.Author{ID: 1}, nil)
+				s.Repository.EXPECT().CountAuthorBooks(1).Return(int64(2), nil)
+			},
+		},
+		{
+			name: "success",
+			code: http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetAuthor(1).Return(&types.Author{ID: 1}, nil)
+				s.Repository.EXPECT().CountAuthorBooks(1).Return(int64(0), nil)
+				s.Repository.EXPECT().DeleteAuthor(&types.Author{ID: 1}).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+			tc.preProcess(&s)
+
+			req, err := http.NewRequest(http.MethodDelete, "/api/v1/authors/1", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+		})
+	}
+}
+
+func TestSetBookAuthors(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.GetBookAuthorsResponse
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.SetBookAuthorsRequest
+		output     output
+	}{
+		{
+			name: "invalid role",
+			input: types.SetBookAuthorsRequest{Authors: []types.BookAuthorRequest{
+				{AuthorID: 1, Role: "illustrator"},
+			}},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "{\"Role\":\"It is invalid\"}",
+			},
+		},
+		{
+			name: "book not found",
+			input: types.SetBookAuthorsRequest{Authors: []types.BookAuthorRequest{
+				{AuthorID: 1, Role: types.AUTHOR_ROLE_AUTHOR},
+			}},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "book not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(false, nil)
+			},
+		},
+		{
+			name: "author not found",
+			input: types.SetBookAuthorsRequest{Authors: []types.BookAuthorRequest{
+				{AuthorID: 2, Role: types.AUTHOR_ROLE_TRANSLATOR},
+			}},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "author not found: 2",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().GetAuthor(2).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "success",
+			input: types.SetBookAuthorsRequest{Authors: []types.BookAuthorRequest{
+				{AuthorID: 1, Role: types.AUTHOR_ROLE_AUTHOR},
+				{AuthorID: 2, Role: types.AUTHOR_ROLE_TRANSLATOR},
+			}},
+			output: output{
+				code: http.StatusOK,
+				body: types.GetBookAuthorsResponse{Authors: []types.BookContributor{
+					{Author: types.Author{ID: 1, Name: "author-1"}, Role: types.AUTHOR_ROLE_AUTHOR, Position: 0},
+					{Author: types.Author{ID: 2, Name: "author-2"}, Role: types.AUTHOR_ROLE_TRANSLATOR, Position: 1},
+				}},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil).Times(2)
+				s.Repository.EXPECT().GetAuthor(gomock.Any()).Return(&types.Author{}, nil).Times(2)
+				s.Repository.EXPECT().SetBookAuthors(1, []types.BookAuthor{
+					{BookID: 1, AuthorID: 1, Role: types.AUTHOR_ROLE_AUTHOR, Position: 0},
+					{BookID: 1, AuthorID: 2, Role: types.AUTHOR_ROLE_TRANSLATOR, Position: 1},
+				}).Return(nil)
+				s.Repository.EXPECT().GetBookAuthors(1).Return([]types.BookContributor{
+					{Author: types.Author{ID: 1, Name: "author-1"}, Role: types.AUTHOR_ROLE_AUTHOR, Position: 0},
+					{Author: types.Author{ID: 2, Name: "author-2"}, Role: types.AUTHOR_ROLE_TRANSLATOR, Position: 1},
+				}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/books/%d/authors", 1), &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.GetBookAuthorsResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in an authors service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new authors-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// CreateAuthor creates a new author in the database
+func (r *Repository) CreateAuthor(author types.Author) (types.Author, error) {
+	tx := r.db.Create(&author)
+	return author, tx.Error
+}
+
+// UpdateAuthor updates an author in the database
+func (r *Repository) UpdateAuthor(author *types.Author) error {
+	return r.db.Save(author).Error
+}
+
+// DeleteAuthor deletes an author from the database
+func (r *Repository) DeleteAuthor(author *types.Author) error {
+	return r.db.Delete(author).Error
+}
+
+// GetAuthors retrieves a list of authors from the database
+func (r *Repository) GetAuthors(query string, page int, limit int) (*db.Pagination, []types.Author, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "name asc, id asc",
+	}
+
+	tx := r.db
+	if query != "" {
+		tx = tx.Where("LOWER(name) LIKE LOWER(?)", "%"+query+"%")
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var authors []types.Author
+	if result := tx.Scopes(db.Paginate(&authors, &p, tx)).Find(&authors); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, authors, nil
+}
+
+// GetAuthor retrieves an author from the database
+func (r *Repository) GetAuthor(id int) (*types.Author, error) {
+	var author types.Author
+	if result := r.db.First(&author, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &author, nil
+}
+
+// GetAuthorByNormalizedName retrieves an author from the database by its normalized name
+func (r *Repository) GetAuthorByNormalizedName(normalizedName string) (*types.Author, error) {
+	var author types.Author
+	if result := r.db.Where("normalized_name = ?", normalizedName).First(&author); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &author, nil
+}
+
+// GetAuthorBooks retrieves the books of an author with its roles from the database
+func (r *Repository) GetAuthorBooks(authorID int, page int, limit int) (*db.Pagination, []types.AuthorBook, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "books.id asc, book_authors.role asc",
+	}
+
+	query := r.db.Table("book_authors").
+		Joins("JOIN books ON books.id = book_authors.book_id").
+		Where("book_authors.author_id = ?", authorID).
+		Session(&gorm.Session{})
+
+	var rows []struct {
+		booktypes.Book
+		Role types.AuthorRole
+	}
+	err := query.Scopes(db.Paginate(&types.BookAuthor{}, &p, query)).
+		Select("books.*, book_authors.role").
+		Find(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	books := make([]types.AuthorBook, 0, len(rows))
+	for _, row := range rows {
+		books = append(books, types.AuthorBook{Book: row.Book, Role: row.Role})
+	}
+
+	return &p, books, nil
+}
+
+// CountAuthorBooks counts the books related to an author in the database
+func (r *Repository) CountAuthorBooks(authorID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&types.BookAuthor{}).Where("author_id = ?", authorID).Count(&count).Error
+	return count, err
+}
+
+// BookExists checks if the book exists in the database
+func (r *Repository) BookExists(bookID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&booktypes.Book{}).Where("id = ?", bookID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetBookAuthors retrieves the authors of a book from the database in their order
+func (r *Repository) GetBookAuthors(bookID int) ([]types.BookContributor, error) {
+	var links []types.BookAuthor
+	if err := r.db.Where("book_id = ?", bookID).Order("position, role").Find(&links).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(links))
+	for _, link := range links {
+		ids = append(ids, link.AuthorID)
+	}
+
+	var authors []types.Author
+	if err := r.db.Where("id IN ?", ids).Find(&authors).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]types.Author, len(authors))
+	for _, author := range authors {
+		byID[author.ID] = author
+	}
+
+	contributors := make([]types.BookContributor, 0, len(links))
+	for _, link := range links {
+		contributors = append(contributors, types.BookContributor{
+			Author:   byID[link.AuthorID],
+			Role:     link.Role,
+			Position: link.Position,
+		})
+	}
+
+	return contributors, nil
+}
+
+// SetBookAuthors replaces the authors of a book in the database
+func (r *Repository) SetBookAuthors(bookID int, bookAuthors []types.BookAuthor) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&types.BookAuthor{}).Error; err != nil {
+			return err
+		}
+
+		if len(bookAuthors) == 0 {
+			return nil
+		}
+		return tx.Create(&bookAuthors).Error
+	})
+}
@@ -0,0 +1,33 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	bookservice "github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	classificationservice "github.com/nkitlabs/go-http-gorm-example/pkg/classification/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db/dbtest"
+	inventoryservice "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "school-a")
+	for _, driver := range dbtest.Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			testutil.RunDataProviderTests(t, ctx, func(t *testing.T) (types.DataProvider, booktypes.DataProvider) {
+				gdb := dbtest.Open(t, driver, &booktypes.Book{}, &types.Author{}, &types.BookAuthor{})
+
+				repo := service.NewRepository(gdb, zap.NewNop())
+				books := bookservice.NewRepository(gdb, zap.NewNop(), classificationservice.BookClassifier{}, inventoryservice.BookInventory{})
+				return repo.WithContext(ctx), books.WithContext(ctx)
+			})
+		})
+	}
+}
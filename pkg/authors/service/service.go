@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// Service is the service layer for authors
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new authors service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// AddAuthor adds a new author into the system
+func (s *Service) AddAuthor(req types.AddAuthorRequest) (types.AddAuthorResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.AddAuthorResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	author := types.Author{
+		Name:           req.Name,
+		NormalizedName: types.NormalizeName(req.Name),
+		Bio:            req.Bio,
+	}
+	if err := s.checkNameAvailable(author); err != nil {
+		return types.AddAuthorResponse{}, err
+	}
+
+	created, err := s.dataProvider.CreateAuthor(author)
+	if err != nil {
+		return types.AddAuthorResponse{}, s.nameConflict(err, author)
+	}
+
+	return types.AddAuthorResponse{
+		ID: created.ID,
+	}, nil
+}
+
+// UpdateAuthor updates an author in the system
+func (s *Service) UpdateAuthor(id int, req types.UpdateAuthorRequest) (types.Author, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Author{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	author, err := s.GetAuthor(id)
+	if err != nil {
+		return types.Author{}, err
+	}
+
+	if req.Name != "" {
+		author.Name = req.Name
+		author.NormalizedName = types.NormalizeName(req.Name)
+		if err := s.checkNameAvailable(*author); err != nil {
+			return types.Author{}, err
+		}
+	}
+	if req.Bio != "" {
+		author.Bio = req.Bio
+	}
+
+	if err := s.dataProvider.UpdateAuthor(author); err != nil {
+		return types.Author{}, s.nameConflict(err, *author)
+	}
+
+	return *author, nil
+}
+
+// DeleteAuthor deletes an author without books from the system
+func (s *Service) DeleteAuthor(id int) (types.DeleteAuthorResponse, error) {
+	author, err := s.GetAuthor(id)
+	if err != nil {
+		return types.DeleteAuthorResponse{}, err
+	}
+
+	count, err := s.dataProvider.CountAuthorBooks(id)
+	if err != nil {
+		return types.DeleteAuthorResponse{}, err
+	} else if count > 0 {
+		return types.DeleteAuthorResponse{}, apierror.ErrConflict.WithMessage(fmt.Sprintf("author has %d books", count))
+	}
+
+	return types.DeleteAuthorResponse{}, s.dataProvider.DeleteAuthor(author)
+}
+
+// GetAuthors returns a list of authors whose name contains the query
+func (s *Service) GetAuthors(query string, page int, limit int) (types.GetAuthorsResponse, error) {
+	pagination, authors, err := s.dataProvider.GetAuthors(query, page, limit)
+	if err != nil {
+		return types.GetAuthorsResponse{}, err
+	}
+
+	return types.GetAuthorsResponse{
+		Authors:    authors,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetAuthor returns an author from the given id
+func (s *Service) GetAuthor(id int) (*types.Author, error) {
+	author, err := s.dataProvider.GetAuthor(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("author not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return author, nil
+}
+
+// GetAuthorBooks returns the books of an author with its roles
+func (s *Service) GetAuthorBooks(id int, page int, limit int) (types.GetAuthorBooksResponse, error) {
+	if _, err := s.GetAuthor(id); err != nil {
+		return types.GetAuthorBooksResponse{}, err
+	}
+
+	pagination, books, err := s.dataProvider.GetAuthorBooks(id, page, limit)
+	if err != nil {
+		return types.GetAuthorBooksResponse{}, err
+	}
+
+	return types.GetAuthorBooksResponse{
+		Books:      books,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetBookAuthors returns the authors of a book with their roles
+func (s *Service) GetBookAuthors(bookID int) (types.GetBookAuthorsResponse, error) {
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetBookAuthorsResponse{}, err
+	}
+
+	authors, err := s.dataProvider.GetBookAuthors(bookID)
+	if err != nil {
+		return types.GetBookAuthorsResponse{}, err
+	}
+
+	return types.GetBookAuthorsResponse{
+		Authors: authors,
+	}, nil
+}
+
+// SetBookAuthors replaces the authors of a book, in the given order
+func (s *Service) SetBookAuthors(bookID int, req types.SetBookAuthorsRequest) (types.GetBookAuthorsResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.GetBookAuthorsResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetBookAuthorsResponse{}, err
+	}
+
+	bookAuthors := make([]types.BookAuthor, 0, len(req.Authors))
+	seen := make(map[types.BookAuthorRequest]bool)
+	for i, a := range req.Authors {
+		if seen[a] {
+			msg := fmt.Sprintf("author %d is given twice as %s", a.AuthorID, a.Role)
+			return types.GetBookAuthorsResponse{}, apierror.ErrInvalidInput.WithMessage(msg)
+		}
+		seen[a] = true
+
+		if _, err := s.dataProvider.GetAuthor(a.AuthorID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.GetBookAuthorsResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("author not found: %d", a.AuthorID))
+		} else if err != nil {
+			return types.GetBookAuthorsResponse{}, err
+		}
+
+		bookAuthors = append(bookAuthors, types.BookAuthor{
+			BookID:   bookID,
+			AuthorID: a.AuthorID,
+			Role:     a.Role,
+			Position: i,
+		})
+	}
+
+	if err := s.dataProvider.SetBookAuthors(bookID, bookAuthors); err != nil {
+		return types.GetBookAuthorsResponse{}, err
+	}
+
+	return s.GetBookAuthors(bookID)
+}
+
+// checkNameAvailable checks that the name is valid and no other author has the same normalized name
+func (s *Service) checkNameAvailable(author types.Author) error {
+	if author.NormalizedName == "" {
+		return apierror.ErrInvalidInput.WithMessage("name must contain a letter or a digit")
+	}
+
+	existing, err := s.dataProvider.GetAuthorByNormalizedName(author.NormalizedName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.ID != author.ID {
+		return apierror.NewConflictError(fmt.Sprintf("author already exists: %d", existing.ID), existing.ID)
+	}
+	return nil
+}
+
+// nameConflict returns the conflict with the author holding the normalized name of the author if
+// err is a duplicated key, as when another author with the name is added after the name was
+// checked. Otherwise it returns err.
+func (s *Service) nameConflict(err error, author types.Author) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	if conflict := s.checkNameAvailable(author); conflict != nil {
+		return conflict
+	}
+	// the other author is gone already.
+	return apierror.ErrConflict.WithMessage(fmt.Sprintf("author already exists: %s", author.Name))
+}
+
+func (s *Service) checkBookExists(bookID int) error {
+	exists, err := s.dataProvider.BookExists(bookID)
+	if err != nil {
+		return err
+	} else if !exists {
+		return apierror.NewNotFoundError("book not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+var (
+	_ booktypes.ChangeWriter = WriteBookChange
+
+	// authorSeparator separates the names in the free-text author of a book.
+	authorSeparator = regexp.MustCompile(`\s*(?:;|&|\band\b)\s*`)
+)
+
+// ConvertAuthorStrings is the migration relating the existing books to the authors named in
+// their free-text author, creating one author per distinct normalized name.
+var ConvertAuthorStrings = db.Migration{
+	ID:      "0001_convert_author_strings",
+	Migrate: convertAuthorStrings,
+}
+
+// ParseAuthorNames splits the free-text author of a book into the names of its authors,
+// e.g. "Terry Pratchett & Neil Gaiman".
+func ParseAuthorNames(author string) []string {
+	var names []string
+	for _, name := range authorSeparator.Split(author, -1) {
+		if name = strings.TrimSpace(name); types.NormalizeName(name) != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// WriteBookChange is the change writer that keeps the authors in the `author` role of a book
+// in sync with its free-text author. Editors and translators are only set via the endpoints.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	switch {
+	case change.After == nil:
+		return tx.Where("book_id = ?", change.BookID).Delete(&types.BookAuthor{}).Error
+	case change.Before == nil || change.Before.Author != change.After.Author:
+		return linkAuthorNames(tx, change.BookID, change.After.Author)
+	default:
+		return nil
+	}
+}
+
+// linkAuthorNames replaces the authors in the `author` role of the book with the ones named in
+// its free-text author, creating the missing authors.
+func linkAuthorNames(tx *gorm.DB, bookID int, author string) error {
+	err := tx.Where("book_id = ? AND role = ?", bookID, types.AUTHOR_ROLE_AUTHOR).Delete(&types.BookAuthor{}).Error
+	if err != nil {
+		return err
+	}
+
+	var links []types.BookAuthor
+	for i, name := range ParseAuthorNames(author) {
+		a, err := findOrCreateAuthor(tx, name)
+		if err != nil {
+			return err
+		}
+
+		links = append(links, types.BookAuthor{
+			BookID:   bookID,
+			AuthorID: a.ID,
+			Role:     types.AUTHOR_ROLE_AUTHOR,
+			Position: i,
+		})
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+	// the same author named twice keeps its first position.
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// findOrCreateAuthor returns the author with the normalized name, creating it with the name
+// as spelled if it does not exist yet.
+func findOrCreateAuthor(tx *gorm.DB, name string) (types.Author, error) {
+	author := types.Author{
+		Name:           name,
+		NormalizedName: types.NormalizeName(name),
+	}
+
+	err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "tenant_id"}, {Name: "normalized_name"}}, DoNothing: true}).
+		Create(&author).Error
+	if err != nil {
+		return types.Author{}, err
+	}
+
+	if author.ID == 0 {
+		err = tx.Where("normalized_name = ?", author.NormalizedName).First(&author).Error
+	}
+	return author, err
+}
+
+// convertAuthorStrings runs for all tenants; the authors of a book are found and created in the
+// tenant of the book.
+func convertAuthorStrings(tx *gorm.DB) error {
+	var books []booktypes.Book
+	return tx.Select("id", "tenant_id", "author").FindInBatches(&books, 500, func(batch *gorm.DB, _ int) error {
+		for _, book := range books {
+			bookTx := tx.WithContext(tenant.NewContext(tx.Statement.Context, book.TenantID))
+			if err := linkAuthorNames(bookTx, book.ID, book.Author); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
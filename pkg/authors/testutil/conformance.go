@@ -0,0 +1,237 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// RunDataProviderTests checks that a data provider behaves as the authors service expects.
+// newDataProvider returns an empty data provider for every test, along with the books data
+// provider of the same database, creating the books of the authors. ctx is the context of the
+// requests given to the data providers.
+func RunDataProviderTests(t *testing.T, ctx context.Context, newDataProvider func(t *testing.T) (types.DataProvider, booktypes.DataProvider)) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider)
+	}{
+		{"create and get author", testCreateAndGetAuthor},
+		{"duplicate normalized name", testDuplicateName},
+		{"update and delete author", testUpdateAndDeleteAuthor},
+		{"search authors", testSearchAuthors},
+		{"set book authors", testSetBookAuthors},
+		{"tenant isolation", testTenantIsolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, books := newDataProvider(t)
+			tt.test(t, ctx, d, books)
+		})
+	}
+}
+
+func newAuthor(name string) types.Author {
+	return types.Author{
+		Name:           name,
+		NormalizedName: types.NormalizeName(name),
+	}
+}
+
+// createAuthors creates an author of every name.
+func createAuthors(t *testing.T, d types.DataProvider, names ...string) []types.Author {
+	authors := make([]types.Author, 0, len(names))
+	for _, name := range names {
+		author, err := d.CreateAuthor(newAuthor(name))
+		require.NoError(t, err)
+		authors = append(authors, author)
+	}
+
+	return authors
+}
+
+// createBook creates a book for the authors.
+func createBook(t *testing.T, books booktypes.DataProvider, title string) booktypes.Book {
+	book, err := books.CreateBook(booktypes.Book{Title: title, Author: "author", Description: "description"})
+	require.NoError(t, err)
+
+	return book
+}
+
+// authorNames returns the names of the authors in their order.
+func authorNames(authors []types.Author) []string {
+	names := []string{}
+	for _, author := range authors {
+		names = append(names, author.Name)
+	}
+
+	return names
+}
+
+func testCreateAndGetAuthor(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	author := newAuthor("J. K. Rowling")
+	author.Bio = "British author"
+	created, err := d.CreateAuthor(author)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	got, err := d.GetAuthor(created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "J. K. Rowling", got.Name)
+	require.Equal(t, "British author", got.Bio)
+
+	got, err = d.GetAuthorByNormalizedName(types.NormalizeName("JK Rowling"))
+	require.NoError(t, err)
+	require.Equal(t, created.ID, got.ID)
+
+	_, err = d.GetAuthor(created.ID + 1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = d.GetAuthorByNormalizedName("nobody")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testDuplicateName(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	createAuthors(t, d, "J. K. Rowling")
+
+	_, err := d.CreateAuthor(newAuthor("JK Rowling"))
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func testUpdateAndDeleteAuthor(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	authors := createAuthors(t, d, "Terry Pratchett", "Neil Gaiman")
+
+	author := authors[0]
+	author.Bio = "updated-bio"
+	require.NoError(t, d.UpdateAuthor(&author))
+
+	got, err := d.GetAuthor(author.ID)
+	require.NoError(t, err)
+	require.Equal(t, "updated-bio", got.Bio)
+
+	require.NoError(t, d.DeleteAuthor(&author))
+	_, err = d.GetAuthor(author.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	got, err = d.GetAuthor(authors[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, "Neil Gaiman", got.Name)
+}
+
+func testSearchAuthors(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	createAuthors(t, d, "Terry Pratchett", "Neil Gaiman", "Terry Brooks")
+
+	testCases := []struct {
+		name  string
+		query string
+		names []string
+	}{
+		{
+			name:  "every author by name",
+			names: []string{"Neil Gaiman", "Terry Brooks", "Terry Pratchett"},
+		},
+		{
+			name:  "part of the name regardless of case",
+			query: "TERRY",
+			names: []string{"Terry Brooks", "Terry Pratchett"},
+		},
+		{
+			name:  "no match",
+			query: "Rowling",
+			names: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, authors, err := d.GetAuthors(tc.query, 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.names)), p.TotalRows)
+			require.Equal(t, tc.names, authorNames(authors))
+		})
+	}
+
+	p, authors, err := d.GetAuthors("", 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, 2, p.TotalPages)
+	require.Equal(t, []string{"Terry Pratchett"}, authorNames(authors))
+}
+
+func testSetBookAuthors(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	authors := createAuthors(t, d, "Terry Pratchett", "Neil Gaiman")
+	book := createBook(t, books, "Good Omens")
+	other := createBook(t, books, "Mort")
+
+	exists, err := d.BookExists(book.ID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = d.BookExists(other.ID + 1)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, d.SetBookAuthors(book.ID, []types.BookAuthor{
+		{BookID: book.ID, AuthorID: authors[1].ID, Role: types.AUTHOR_ROLE_AUTHOR, Position: 0},
+		{BookID: book.ID, AuthorID: authors[0].ID, Role: types.AUTHOR_ROLE_AUTHOR, Position: 1},
+	}))
+	require.NoError(t, d.SetBookAuthors(other.ID, []types.BookAuthor{
+		{BookID: other.ID, AuthorID: authors[0].ID, Role: types.AUTHOR_ROLE_AUTHOR, Position: 0},
+	}))
+
+	contributors, err := d.GetBookAuthors(book.ID)
+	require.NoError(t, err)
+	require.Len(t, contributors, 2)
+	require.Equal(t, "Neil Gaiman", contributors[0].Author.Name)
+	require.Equal(t, "Terry Pratchett", contributors[1].Author.Name)
+	require.Equal(t, 1, contributors[1].Position)
+
+	count, err := d.CountAuthorBooks(authors[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	p, authorBooks, err := d.GetAuthorBooks(authors[0].ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), p.TotalRows)
+	require.Len(t, authorBooks, 2)
+	require.Equal(t, "Good Omens", authorBooks[0].Book.Title)
+	require.Equal(t, types.AUTHOR_ROLE_AUTHOR, authorBooks[0].Role)
+	require.Equal(t, "Mort", authorBooks[1].Book.Title)
+
+	// the authors are replaced, and none is left when set empty.
+	require.NoError(t, d.SetBookAuthors(book.ID, []types.BookAuthor{
+		{BookID: book.ID, AuthorID: authors[0].ID, Role: types.AUTHOR_ROLE_EDITOR, Position: 0},
+	}))
+	contributors, err = d.GetBookAuthors(book.ID)
+	require.NoError(t, err)
+	require.Len(t, contributors, 1)
+	require.Equal(t, types.AUTHOR_ROLE_EDITOR, contributors[0].Role)
+
+	require.NoError(t, d.SetBookAuthors(book.ID, nil))
+	contributors, err = d.GetBookAuthors(book.ID)
+	require.NoError(t, err)
+	require.Empty(t, contributors)
+
+	count, err = d.CountAuthorBooks(authors[1].ID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func testTenantIsolation(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	authors := createAuthors(t, d, "J. K. Rowling")
+
+	other := d.WithContext(tenant.NewContext(context.Background(), "other-tenant"))
+	_, err := other.GetAuthor(authors[0].ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	p, _, err := other.GetAuthors("", 1, 10)
+	require.NoError(t, err)
+	require.Zero(t, p.TotalRows)
+
+	// a name is used once per tenant.
+	_, err = other.CreateAuthor(newAuthor("J. K. Rowling"))
+	require.NoError(t, err)
+}
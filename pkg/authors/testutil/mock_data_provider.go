@@ -0,0 +1,220 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/authors/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/authors/testutil/mock_data_provider.go --source ./pkg/authors/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/authors/types"
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// BookExists mocks base method.
+func (m *MockDataProvider) BookExists(bookID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookExists", bookID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookExists indicates an expected call of BookExists.
+func (mr *MockDataProviderMockRecorder) BookExists(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookExists", reflect.TypeOf((*MockDataProvider)(nil).BookExists), bookID)
+}
+
+// CountAuthorBooks mocks base method.
+func (m *MockDataProvider) CountAuthorBooks(authorID int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAuthorBooks", authorID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAuthorBooks indicates an expected call of CountAuthorBooks.
+func (mr *MockDataProviderMockRecorder) CountAuthorBooks(authorID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAuthorBooks", reflect.TypeOf((*MockDataProvider)(nil).CountAuthorBooks), authorID)
+}
+
+// CreateAuthor mocks base method.
+func (m *MockDataProvider) CreateAuthor(author types.Author) (types.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAuthor", author)
+	ret0, _ := ret[0].(types.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAuthor indicates an expected call of CreateAuthor.
+func (mr *MockDataProviderMockRecorder) CreateAuthor(author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAuthor", reflect.TypeOf((*MockDataProvider)(nil).CreateAuthor), author)
+}
+
+// DeleteAuthor mocks base method.
+func (m *MockDataProvider) DeleteAuthor(author *types.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthor", author)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAuthor indicates an expected call of DeleteAuthor.
+func (mr *MockDataProviderMockRecorder) DeleteAuthor(author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthor", reflect.TypeOf((*MockDataProvider)(nil).DeleteAuthor), author)
+}
+
+// GetAuthor mocks base method.
+func (m *MockDataProvider) GetAuthor(id int) (*types.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthor", id)
+	ret0, _ := ret[0].(*types.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthor indicates an expected call of GetAuthor.
+func (mr *MockDataProviderMockRecorder) GetAuthor(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthor", reflect.TypeOf((*MockDataProvider)(nil).GetAuthor), id)
+}
+
+// GetAuthorBooks mocks base method.
+func (m *MockDataProvider) GetAuthorBooks(authorID, page, limit int) (*db.Pagination, []types.AuthorBook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorBooks", authorID, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.AuthorBook)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAuthorBooks indicates an expected call of GetAuthorBooks.
+func (mr *MockDataProviderMockRecorder) GetAuthorBooks(authorID, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorBooks", reflect.TypeOf((*MockDataProvider)(nil).GetAuthorBooks), authorID, page, limit)
+}
+
+// GetAuthorByNormalizedName mocks base method.
+func (m *MockDataProvider) GetAuthorByNormalizedName(normalizedName string) (*types.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthorByNormalizedName", normalizedName)
+	ret0, _ := ret[0].(*types.Author)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthorByNormalizedName indicates an expected call of GetAuthorByNormalizedName.
+func (mr *MockDataProviderMockRecorder) GetAuthorByNormalizedName(normalizedName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthorByNormalizedName", reflect.TypeOf((*MockDataProvider)(nil).GetAuthorByNormalizedName), normalizedName)
+}
+
+// GetAuthors mocks base method.
+func (m *MockDataProvider) GetAuthors(query string, page, limit int) (*db.Pagination, []types.Author, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthors", query, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.Author)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAuthors indicates an expected call of GetAuthors.
+func (mr *MockDataProviderMockRecorder) GetAuthors(query, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthors", reflect.TypeOf((*MockDataProvider)(nil).GetAuthors), query, page, limit)
+}
+
+// GetBookAuthors mocks base method.
+func (m *MockDataProvider) GetBookAuthors(bookID int) ([]types.BookContributor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookAuthors", bookID)
+	ret0, _ := ret[0].([]types.BookContributor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookAuthors indicates an expected call of GetBookAuthors.
+func (mr *MockDataProviderMockRecorder) GetBookAuthors(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookAuthors", reflect.TypeOf((*MockDataProvider)(nil).GetBookAuthors), bookID)
+}
+
+// SetBookAuthors mocks base method.
+func (m *MockDataProvider) SetBookAuthors(bookID int, bookAuthors []types.BookAuthor) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBookAuthors", bookID, bookAuthors)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBookAuthors indicates an expected call of SetBookAuthors.
+func (mr *MockDataProviderMockRecorder) SetBookAuthors(bookID, bookAuthors any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBookAuthors", reflect.TypeOf((*MockDataProvider)(nil).SetBookAuthors), bookID, bookAuthors)
+}
+
+// UpdateAuthor mocks base method.
+func (m *MockDataProvider) UpdateAuthor(author *types.Author) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAuthor", author)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAuthor indicates an expected call of UpdateAuthor.
+func (mr *MockDataProviderMockRecorder) UpdateAuthor(author any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuthor", reflect.TypeOf((*MockDataProvider)(nil).UpdateAuthor), author)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
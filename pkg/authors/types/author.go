@@ -0,0 +1,55 @@
+package types
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Author is the model for a person credited for books.
+type Author struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_authors_tenant_normalized_name"`
+	Name     string `json:"name" gorm:"not null" example:"J. K. Rowling"`
+	// NormalizedName identifies the author across the spellings of the name, see NormalizeName.
+	NormalizedName string    `json:"-" gorm:"not null;uniqueIndex:idx_authors_tenant_normalized_name"`
+	Bio            string    `json:"bio" example:"British author"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// NormalizeName returns the key of an author name: its letters and digits in lower case, so
+// "J. K. Rowling" and "JK Rowling" are the same author.
+func NormalizeName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+
+	return b.String()
+}
+
+type AuthorRole string
+
+const (
+	AUTHOR_ROLE_AUTHOR     AuthorRole = "author"
+	AUTHOR_ROLE_EDITOR     AuthorRole = "editor"
+	AUTHOR_ROLE_TRANSLATOR AuthorRole = "translator"
+)
+
+// BookAuthor is the model for the relation of a book and an author in a role. Position orders
+// the authors of a book.
+type BookAuthor struct {
+	BookID   int        `json:"book_id" gorm:"primaryKey" example:"1"`
+	AuthorID int        `json:"author_id" gorm:"primaryKey;index" example:"1"`
+	Role     AuthorRole `json:"role" gorm:"primaryKey" example:"author"`
+	Position int        `json:"position" example:"0"`
+	TenantID string     `json:"-" gorm:"<-:create;not null;default:'default';index"`
+}
+
+// TableName overrides the table name used by gorm.
+func (BookAuthor) TableName() string {
+	return "book_authors"
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for an authors service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	CreateAuthor(author Author) (Author, error)
+	UpdateAuthor(author *Author) error
+	DeleteAuthor(author *Author) error
+
+	// GetAuthors returns the authors whose name contains the query, or every author if it is empty.
+	GetAuthors(query string, page int, limit int) (*db.Pagination, []Author, error)
+	GetAuthor(id int) (*Author, error)
+	GetAuthorByNormalizedName(normalizedName string) (*Author, error)
+
+	GetAuthorBooks(authorID int, page int, limit int) (*db.Pagination, []AuthorBook, error)
+	CountAuthorBooks(authorID int) (int64, error)
+
+	BookExists(bookID int) (bool, error)
+	GetBookAuthors(bookID int) ([]BookContributor, error)
+	// SetBookAuthors replaces the authors of the book.
+	SetBookAuthors(bookID int, bookAuthors []BookAuthor) error
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// AddAuthorRequest is the request for adding a new author
+type AddAuthorRequest struct {
+	Name string `json:"name" example:"J. K. Rowling" validate:"required,max=255"`
+	Bio  string `json:"bio" example:"British author"`
+}
+
+// AddAuthorResponse is the response for adding a new author
+type AddAuthorResponse struct {
+	ID int `json:"id" example:"1"`
+}
+
+// UpdateAuthorRequest is the request for updating an author
+type UpdateAuthorRequest struct {
+	Name string `json:"name" example:"J. K. Rowling" validate:"max=255"`
+	Bio  string `json:"bio" example:"British author"`
+}
+
+// GetAuthorsResponse is the response for getting a list of authors
+type GetAuthorsResponse struct {
+	Authors    []Author       `json:"authors"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// DeleteAuthorResponse is the response for deleting an author
+type DeleteAuthorResponse struct{}
+
+// AuthorBook is a book of an author with the role of the author
+type AuthorBook struct {
+	Book booktypes.Book `json:"book"`
+	Role AuthorRole     `json:"role" example:"author"`
+}
+
+// GetAuthorBooksResponse is the response for getting the books of an author
+type GetAuthorBooksResponse struct {
+	Books      []AuthorBook   `json:"books"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// BookContributor is an author of a book with its role
+type BookContributor struct {
+	Author   Author     `json:"author"`
+	Role     AuthorRole `json:"role" example:"author"`
+	Position int        `json:"position" example:"0"`
+}
+
+// GetBookAuthorsResponse is the response for getting the authors of a book
+type GetBookAuthorsResponse struct {
+	Authors []BookContributor `json:"authors"`
+}
+
+// BookAuthorRequest is an author of a book with its role in SetBookAuthorsRequest
+type BookAuthorRequest struct {
+	AuthorID int        `json:"author_id" example:"1" validate:"required"`
+	Role     AuthorRole `json:"role" example:"author" validate:"required,oneof=author editor translator"`
+}
+
+// SetBookAuthorsRequest is the request for replacing the authors of a book; they are ordered
+// as given
+type SetBookAuthorsRequest struct {
+	Authors []BookAuthorRequest `json:"authors" validate:"dive"`
+}
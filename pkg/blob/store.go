@@ -0,0 +1,44 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+)
+
+const (
+	// BACKEND_FS stores the blobs as files under a local directory.
+	BACKEND_FS = "fs"
+	// BACKEND_S3 stores the blobs as objects of an S3-compatible bucket.
+	BACKEND_S3 = "s3"
+)
+
+// ErrNotFound is returned when the blob of a key does not exist.
+var ErrNotFound = errors.New("blob not found")
+
+// Store is the interface for a store of binary objects addressed by keys. A key is a
+// slash-separated path such as "covers/1/original".
+type Store interface {
+	// Put stores the size bytes read from r under the key, replacing the existing blob.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob of the key. It returns ErrNotFound if the blob does not exist.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob of the key. Deleting a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore creates the store of the backend set in the configuration.
+func NewStore(conf config.Blob) (Store, error) {
+	switch conf.Backend {
+	case BACKEND_FS:
+		return NewFSStore(conf.Dir)
+	case BACKEND_S3:
+		return NewS3Store(conf.S3, &http.Client{Timeout: conf.S3.Timeout})
+	default:
+		return nil, fmt.Errorf("unknown blob backend: %s", conf.Backend)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// MaxBatchOperations is the maximum number of operations in a single batch.
+const MaxBatchOperations = 1000
+
+// errBatchRollback is used to roll back the batch transaction without failing the request.
+var errBatchRollback = errors.New("rollback batch")
+
+// Batch runs the operations in order within a single transaction using the same service methods
+// as the single-item endpoints. In atomic mode every operation is rolled back if any of them
+// fails; in per-item mode each operation runs in its own savepoint and the successful ones are kept.
+func (s *Service) Batch(ctx context.Context, req types.BatchRequest) (types.BatchResponse, error) {
+	switch req.Mode {
+	case "":
+		req.Mode = types.BATCH_MODE_ATOMIC
+	case types.BATCH_MODE_ATOMIC, types.BATCH_MODE_PER_ITEM:
+	default:
+		return types.BatchResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid mode: %s", req.Mode))
+	}
+
+	if len(req.Operations) == 0 {
+		return types.BatchResponse{}, apierror.ErrInvalidInput.WithMessage("no operations")
+	} else if len(req.Operations) > MaxBatchOperations {
+		msg := fmt.Sprintf("too many operations: %d, maximum is %d", len(req.Operations), MaxBatchOperations)
+		return types.BatchResponse{}, apierror.ErrInvalidInput.WithMessage(msg)
+	}
+
+	resp := types.BatchResponse{
+		Mode:    req.Mode,
+		Results: make([]types.BatchOperationResult, len(req.Operations)),
+	}
+
+	failedIdx := -1
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		txServ := NewService(d, s.log)
+		for i, op := range req.Operations {
+			if req.Mode == types.BATCH_MODE_ATOMIC {
+				result, err := txServ.runBatchOperation(ctx, op)
+				resp.Results[i] = result
+				if err != nil {
+					failedIdx = i
+					return errBatchRollback
+				}
+				continue
+			}
+
+			// a failed operation only rolls back its own savepoint.
+			err := d.Transaction(func(sd types.DataProvider) error {
+				itemServ := NewService(sd, s.log)
+				result, err := itemServ.runBatchOperation(ctx, op)
+				resp.Results[i] = result
+				return err
+			})
+			if err != nil && failedIdx == -1 {
+				failedIdx = i
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchRollback) {
+		return types.BatchResponse{}, err
+	}
+
+	resp.Committed = !errors.Is(err, errBatchRollback)
+	if !resp.Committed {
+		for i := range resp.Results {
+			if i == failedIdx {
+				continue
+			}
+
+			msg := fmt.Sprintf("rolled back because operation %d failed", failedIdx)
+			if i > failedIdx {
+				msg = fmt.Sprintf("not executed because operation %d failed", failedIdx)
+			}
+			resp.Results[i], _ = batchErrorResult(apierror.ErrFailedDependency.WithMessage(msg))
+		}
+	}
+
+	return resp, nil
+}
+
+// runBatchOperation runs a single operation and returns the result of the matching
+// single-item endpoint. The error is returned as well, so the caller can roll back.
+func (s *Service) runBatchOperation(ctx context.Context, op types.BatchOperation) (types.BatchOperationResult, error) {
+	var (
+		status int
+		body   any
+		err    error
+	)
+
+	switch op.Op {
+	case types.BATCH_OP_CREATE:
+		var req types.AddBookRequest
+		if err := json.Unmarshal(op.Body, &req); err != nil {
+			return batchErrorResult(apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid body: %v", err)))
+		}
+		status = http.StatusCreated
+		body, err = s.AddBook(ctx, req)
+	case types.BATCH_OP_UPDATE:
+		var req types.UpdateBookRequest
+		if err := json.Unmarshal(op.Body, &req); err != nil {
+			return batchErrorResult(apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid body: %v", err)))
+		}
+		status = http.StatusOK
+		body, err = s.UpdateBook(ctx, op.ID, req)
+	case types.BATCH_OP_DELETE:
+		status = http.StatusOK
+		body, err = s.DeleteBook(ctx, op.ID)
+	default:
+		return batchErrorResult(apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid op: %s", op.Op)))
+	}
+
+	if err != nil {
+		return batchErrorResult(err)
+	}
+
+	return types.BatchOperationResult{Status: status, Body: body}, nil
+}
+
+// batchErrorResult converts the error into the result that an endpoint would have written.
+func batchErrorResult(err error) (types.BatchOperationResult, error) {
+	e := apierror.ToError(err)
+	return types.BatchOperationResult{Status: e.Code, Body: e}, err
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+type ExportFormat string
+
+const (
+	EXPORT_FORMAT_NDJSON ExportFormat = "ndjson"
+	EXPORT_FORMAT_CSV    ExportFormat = "csv"
+)
+
+// ToExportFormat converts the format query parameter, falling back to the Accept header.
+// It returns false if the format is not supported.
+func ToExportFormat(formatStr string, accept string) (ExportFormat, bool) {
+	switch formatStr {
+	case "ndjson", "jsonl":
+		return EXPORT_FORMAT_NDJSON, true
+	case "csv":
+		return EXPORT_FORMAT_CSV, true
+	case "":
+	default:
+		return "", false
+	}
+
+	if accept == "text/csv" {
+		return EXPORT_FORMAT_CSV, true
+	}
+	return EXPORT_FORMAT_NDJSON, true
+}
+
+// BookExportWriter writes exported books into an underlying writer. Written books
+// may be buffered until Flush is called.
+type BookExportWriter interface {
+	ContentType() string
+	Write(book types.Book) error
+	Flush() error
+}
+
+// NewBookExportWriter creates an export writer of the given format.
+func NewBookExportWriter(format ExportFormat, w io.Writer) BookExportWriter {
+	if format == EXPORT_FORMAT_CSV {
+		return &csvExportWriter{w: csv.NewWriter(w)}
+	}
+
+	bw := bufio.NewWriter(w)
+	return &ndjsonExportWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+type ndjsonExportWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (n *ndjsonExportWriter) ContentType() string { return "application/x-ndjson" }
+
+func (n *ndjsonExportWriter) Write(book types.Book) error {
+	return n.enc.Encode(book)
+}
+
+func (n *ndjsonExportWriter) Flush() error {
+	return n.w.Flush()
+}
+
+type csvExportWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvExportWriter) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (c *csvExportWriter) Write(book types.Book) error {
+	if !c.headerWritten {
+		if err := c.w.Write(types.BookCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	return c.w.Write(book.CSVRecord())
+}
+
+func (c *csvExportWriter) Flush() error {
+	// an empty export still has the header row.
+	if !c.headerWritten {
+		if err := c.w.Write(types.BookCSVHeader); err != nil {
+			return err
+		}
+		c.headerWritten = true
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,9 +13,13 @@ import (
 	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
 	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
 )
 
+// exportFlushInterval is the number of exported books written between flushes to the client.
+const exportFlushInterval = 100
+
 type Handler struct {
 	serv *Service
 	log  *zap.Logger
@@ -29,18 +34,31 @@ func NewHandler(serv *Service, log *zap.Logger) Handler {
 func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
 	mux.HandleFunc("GET /api/v1/books", h.GetBooks)
 	mux.HandleFunc("GET /api/v1/books/{id}", h.GetBook)
+	// the lookup by ISBN is routed as /books/{by}/{isbn}: the router would refuse
+	// /books/isbn/{isbn} next to /books/{id}/tags and the like, which are more specific than
+	// /books/{by}/{isbn} and still take precedence over it.
+	mux.HandleFunc("GET /api/v1/books/{by}/{isbn}", h.GetBookByISBN)
+	mux.HandleFunc("GET /api/v1/books/export", h.ExportBooks)
 	mux.HandleFunc("POST /api/v1/books", h.AddBook)
+	mux.HandleFunc("POST /api/v1/books/import", h.ImportBooks)
+	mux.HandleFunc("POST /api/v1/books/batch", h.Batch)
 	mux.HandleFunc("PUT /api/v1/books/{id}", h.UpdateBook)
 	mux.HandleFunc("DELETE /api/v1/books/{id}", h.DeleteBook)
+	mux.HandleFunc("GET /api/v1/books/{id}/revisions", h.GetRevisions)
+	mux.HandleFunc("GET /api/v1/books/{id}/revisions/{rev}", h.GetRevision)
+	mux.HandleFunc("GET /api/v1/books/{id}/revisions/diff", h.DiffRevisions)
+	mux.HandleFunc("POST /api/v1/books/{id}/revisions/{rev}/restore", h.RestoreBook)
 	return mux
 }
 
 // @Summary get book information from given id
 // @ID get-book
 // @Param id path int true "Book ID"
-// @Produce json
+// @Param format query string false "response representation, overrides the Accept header" enums(json,csv,yaml,xml)
+// @Produce json,text/csv,application/yaml,xml
 // @Success 200 {object} types.Book
 // @Failure 404 {object} errors.Error
+// @Failure 406 {object} errors.Error
 // @Failure 500 {object} errors.Error
 // @Router /books/{id} [get]
 func (h Handler) GetBook(w http.ResponseWriter, r *http.Request) {
@@ -53,7 +71,36 @@ func (h Handler) GetBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find book by Id
-	book, err := h.serv.GetBook(int(id))
+	book, err := h.serv.WithContext(ctx).GetBook(int(id))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, book, h.log)
+}
+
+// @Summary get book information from given ISBN-10 or ISBN-13
+// @Description Hyphens and spaces in the ISBN are ignored; an ISBN-10 is converted into its ISBN-13.
+// @ID get-book-by-isbn
+// @Param isbn path string true "ISBN-10 or ISBN-13"
+// @Param format query string false "response representation, overrides the Accept header" enums(json,csv,yaml,xml)
+// @Produce json,text/csv,application/yaml,xml
+// @Success 200 {object} types.Book
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 406 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/isbn/{isbn} [get]
+func (h Handler) GetBookByISBN(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	if r.PathValue("by") != "isbn" {
+		response.WriteError(ctx, w, apierror.NewNotFoundError(http.StatusText(http.StatusNotFound)), h.log)
+		return
+	}
+
+	book, err := h.serv.WithContext(ctx).GetBookByISBN(r.PathValue("isbn"))
 	if err != nil {
 		response.WriteError(ctx, w, err, h.log)
 		return
@@ -66,9 +113,25 @@ func (h Handler) GetBook(w http.ResponseWriter, r *http.Request) {
 // @ID get-books
 // @Param page query int true "Page number"
 // @Param limit query int true "Limit per page"
-// @Param sort_type query string false "order of items to be sorted (by id)" enums(asc,desc)
-// @Produce json
+// @Param sort_type query string false "order of items to be sorted" enums(asc,desc)
+// @Param sort_by query string false "field the items are sorted by, then by id; id by default" enums(id,rating,rating_count)
+// @Param tag query []string false "tags the books must all have" collectionFormat(multi)
+// @Param subject query int false "Subject ID the books are classified in, including its descendants"
+// @Param publisher query string false "publisher of the books, regardless of case"
+// @Param series query string false "series of the books, regardless of case"
+// @Param language query string false "BCP 47 language tag of the books, including its subtags"
+// @Param book_format query string false "format of the books" enums(hardcover,paperback,ebook,audio)
+// @Param published_from query string false "earliest publication date as YYYY-MM-DD"
+// @Param published_to query string false "latest publication date as YYYY-MM-DD"
+// @Param currency query string false "ISO 4217 currency of the price, required with a price range"
+// @Param min_price query int false "lowest price in the minor unit of the currency"
+// @Param max_price query int false "highest price in the minor unit of the currency"
+// @Param facets query string false "counts of the matching books to include" enums(tags)
+// @Param format query string false "response representation, overrides the Accept header" enums(json,csv,yaml,xml)
+// @Produce json,text/csv,application/yaml,xml
 // @Success 200 {object} types.GetBooksResponse
+// @Failure 400 {object} errors.Error
+// @Failure 406 {object} errors.Error
 // @Failure 500 {object} errors.Error
 // @Router /books [get]
 func (h Handler) GetBooks(w http.ResponseWriter, r *http.Request) {
@@ -87,9 +150,23 @@ func (h Handler) GetBooks(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sortType := db.ToSortType(r.URL.Query().Get("sort_type"))
+	sortType, sortBy, filter, err := parseBookQuery(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	withFacets := false
+	switch facets := r.URL.Query().Get("facets"); facets {
+	case "":
+	case "tags":
+		withFacets = true
+	default:
+		response.WriteError(ctx, w, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("unsupported facets: %s", facets)), h.log)
+		return
+	}
 
-	result, err := h.serv.GetBooks(int(page), int(limit), sortType)
+	result, err := h.serv.WithContext(ctx).GetBooks(int(page), int(limit), sortType, sortBy, filter, withFacets)
 	if err != nil {
 		response.WriteError(ctx, w, err, h.log)
 		return
@@ -98,11 +175,151 @@ func (h Handler) GetBooks(w http.ResponseWriter, r *http.Request) {
 	response.Write(ctx, w, http.StatusOK, result, h.log)
 }
 
+// parseBookQuery reads the order and the filter of the listed books from the query parameters.
+func parseBookQuery(r *http.Request) (db.SortType, types.BookSortField, types.BookFilter, error) {
+	query := r.URL.Query()
+
+	sortType := db.ToSortType(query.Get("sort_type"))
+	sortBy := types.BOOK_SORT_ID
+	if value := query.Get("sort_by"); value != "" {
+		sortBy = types.BookSortField(value)
+	}
+
+	filter := types.BookFilter{
+		Tags:          query["tag"],
+		Publisher:     query.Get("publisher"),
+		SeriesName:    query.Get("series"),
+		Language:      query.Get("language"),
+		Format:        types.BookFormat(query.Get("book_format")),
+		PublishedFrom: query.Get("published_from"),
+		PublishedTo:   query.Get("published_to"),
+		Currency:      query.Get("currency"),
+	}
+
+	var minPrice, maxPrice int
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"subject", &filter.SubjectID},
+		{"min_price", &minPrice},
+		{"max_price", &maxPrice},
+	} {
+		if value := query.Get(p.name); value != "" {
+			var err error
+			if *p.dst, err = parseIntParam(p.name, value); err != nil {
+				return "", "", types.BookFilter{}, err
+			}
+		}
+	}
+	filter.MinPrice, filter.MaxPrice = int64(minPrice), int64(maxPrice)
+
+	return sortType, sortBy, filter, nil
+}
+
+// @Summary export every book in the system as a stream
+// @Description Books are streamed from the database and flushed to the client periodically.
+// @Description The books are filtered and sorted as in the list of books.
+// @ID export-books
+// @Param sort_type query string false "order of items to be sorted" enums(asc,desc)
+// @Param sort_by query string false "field the items are sorted by, then by id; id by default" enums(id,rating,rating_count)
+// @Param tag query []string false "tags the books must all have" collectionFormat(multi)
+// @Param subject query int false "Subject ID the books are classified in, including its descendants"
+// @Param publisher query string false "publisher of the books, regardless of case"
+// @Param series query string false "series of the books, regardless of case"
+// @Param language query string false "BCP 47 language tag of the books, including its subtags"
+// @Param book_format query string false "format of the books" enums(hardcover,paperback,ebook,audio)
+// @Param published_from query string false "earliest publication date as YYYY-MM-DD"
+// @Param published_to query string false "latest publication date as YYYY-MM-DD"
+// @Param currency query string false "ISO 4217 currency of the price, required with a price range"
+// @Param min_price query int false "lowest price in the minor unit of the currency"
+// @Param max_price query int false "highest price in the minor unit of the currency"
+// @Param format query string false "format of the export, defaults to ndjson" enums(ndjson,csv)
+// @Produce application/x-ndjson,text/csv
+// @Success 200 {string} string "stream of books"
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/export [get]
+func (h Handler) ExportBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format, ok := ToExportFormat(r.URL.Query().Get("format"), r.Header.Get("Accept"))
+	if !ok {
+		newErr := apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("unsupported export format: %s", r.URL.Query().Get("format")))
+		response.WriteError(ctx, w, newErr, h.log)
+		return
+	}
+
+	sortType, sortBy, filter, err := parseBookQuery(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	exporter := NewBookExportWriter(format, w)
+	w.Header().Set("Content-Type", exporter.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"books.%s\"", format))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	rc := http.NewResponseController(w)
+	flushed := false
+	flush := func() error {
+		flushed = true
+		if err := exporter.Flush(); err != nil {
+			return err
+		}
+
+		// the response writer may not support flushing, then the data is sent when the handler returns.
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		return nil
+	}
+
+	count := 0
+	err = h.serv.WithContext(ctx).ExportBooks(ctx, sortType, sortBy, filter, func(book types.Book) error {
+		// stop reading from the database once the client is gone.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := exporter.Write(book); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushInterval == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+
+	if err != nil {
+		if !flushed {
+			w.Header().Del("Content-Disposition")
+			response.WriteError(ctx, w, err, h.log)
+			return
+		}
+
+		// the status is already sent, so the export can only be cut short.
+		h.log.Error(
+			fmt.Sprintf("export stopped after %d books: %v", count, err),
+			zap.String(middleware.LogKeyID, middleware.GetRequestID(ctx)),
+		)
+	}
+}
+
 // @Summary add book into the system
 // @ID add-book
 // @Produce json
+// @Param Idempotency-Key header string false "key that makes retries of the request return the first response"
 // @Param Body body types.AddBookRequest true "Book information that needs to be added"
 // @Success 201 {object} types.AddBookResponse
+// @Failure 409 {object} errors.Error
+// @Failure 422 {object} errors.Error
 // @Failure 500 {object} errors.Error
 // @Router /books [post]
 func (h Handler) AddBook(w http.ResponseWriter, r *http.Request) {
@@ -117,7 +334,7 @@ func (h Handler) AddBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Append to the Books table
-	resp, err := h.serv.AddBook(req)
+	resp, err := h.serv.WithContext(ctx).AddBook(ctx, req)
 	if err != nil {
 		response.WriteError(ctx, w, err, h.log)
 		return
@@ -126,6 +343,98 @@ func (h Handler) AddBook(w http.ResponseWriter, r *http.Request) {
 	response.Write(ctx, w, http.StatusCreated, resp, h.log)
 }
 
+// @Summary import books into the system from a CSV or JSON lines stream
+// @Description CSV streams need a header row with title, author and description columns.
+// @Description Every row is validated with the rules of adding a book and the result of each row is reported.
+// @ID import-books
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param mode query string false "atomic writes nothing if any row is invalid, partial skips invalid rows" enums(atomic,partial)
+// @Param dry_run query bool false "validate the rows without writing them"
+// @Param Idempotency-Key header string false "key that makes retries of the request return the first response"
+// @Param Body body string true "CSV or JSON lines stream of books"
+// @Success 200 {object} types.ImportBooksResponse
+// @Success 201 {object} types.ImportBooksResponse
+// @Failure 400 {object} errors.Error
+// @Failure 422 {object} types.ImportBooksResponse
+// @Failure 500 {object} errors.Error
+// @Router /books/import [post]
+func (h Handler) ImportBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	opts := types.ImportBooksOptions{
+		Mode: types.ImportMode(r.URL.Query().Get("mode")),
+	}
+	if dryRun := r.URL.Query().Get("dry_run"); dryRun != "" {
+		v, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			newErr := apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid dry_run: %s", dryRun))
+			response.WriteError(ctx, w, newErr, h.log)
+			return
+		}
+		opts.DryRun = v
+	}
+
+	reader, err := NewImportBookReader(r.Header.Get("Content-Type"), r.Body)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).ImportBooks(ctx, reader, opts)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	code := http.StatusCreated
+	if result.DryRun {
+		code = http.StatusOK
+	} else if !result.Committed {
+		code = http.StatusUnprocessableEntity
+	}
+
+	response.Write(ctx, w, code, result, h.log)
+}
+
+// @Summary run several create, update and delete operations at once
+// @Description Operations run in order within a single transaction. Each result holds the status and body
+// @Description that the single-item endpoint of the operation would have returned.
+// @ID batch-books
+// @Produce json
+// @Param Idempotency-Key header string false "key that makes retries of the request return the first response"
+// @Param Body body types.BatchRequest true "Operations to be run"
+// @Success 200 {object} types.BatchResponse
+// @Failure 400 {object} errors.Error
+// @Failure 422 {object} types.BatchResponse
+// @Failure 500 {object} errors.Error
+// @Router /books/batch [post]
+func (h Handler) Batch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read request body
+	var req types.BatchRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).Batch(ctx, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	code := http.StatusOK
+	if !result.Committed {
+		code = http.StatusUnprocessableEntity
+	}
+
+	response.Write(ctx, w, code, result, h.log)
+}
+
 // @Summary delete book id from the system
 // @ID delete-book
 // @Param id path int true "Book ID"
@@ -147,7 +456,7 @@ func (h Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find the book by Id
-	result, err := h.serv.DeleteBook(int(id))
+	result, err := h.serv.WithContext(ctx).DeleteBook(ctx, int(id))
 	if err != nil {
 		response.WriteError(ctx, w, err, h.log)
 		return
@@ -162,7 +471,9 @@ func (h Handler) DeleteBook(w http.ResponseWriter, r *http.Request) {
 // @Param id path int true "Book ID"
 // @Param Body body types.UpdateBookRequest true "Book information that needs to be updated"
 // @Success 200 {object} types.Book
+// @Failure 400 {object} errors.Error
 // @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
 // @Failure 500 {object} errors.Error
 // @Router /books/{id} [put]
 func (h Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
@@ -185,7 +496,167 @@ func (h Handler) UpdateBook(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := h.serv.UpdateBook(int(id), req)
+	result, err := h.serv.WithContext(ctx).UpdateBook(ctx, int(id), req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// @Summary get revisions of a book, latest first
+// @Description Revisions of a deleted book are kept.
+// @ID get-book-revisions
+// @Param id path int true "Book ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Produce json
+// @Success 200 {object} types.GetRevisionsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/revisions [get]
+func (h Handler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetRevisions(id, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a revision of a book
+// @ID get-book-revision
+// @Param id path int true "Book ID"
+// @Param rev path int true "Revision"
+// @Produce json
+// @Success 200 {object} types.BookRevision
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/revisions/{rev} [get]
+func (h Handler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	rev, err := parseIntParam("rev", r.PathValue("rev"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetRevision(id, rev)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get the fields changed between two revisions of a book
+// @ID diff-book-revisions
+// @Param id path int true "Book ID"
+// @Param from query int true "Revision to compare from"
+// @Param to query int true "Revision to compare to"
+// @Produce json
+// @Success 200 {object} types.RevisionDiffResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/revisions/diff [get]
+func (h Handler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	from, err := parseIntParam("from", r.URL.Query().Get("from"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	to, err := parseIntParam("to", r.URL.Query().Get("to"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DiffRevisions(id, from, to)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary restore a book to one of its revisions
+// @Description A deleted book is created again. The restore is recorded as a new revision.
+// @ID restore-book-revision
+// @Param id path int true "Book ID"
+// @Param rev path int true "Revision"
+// @Produce json
+// @Success 200 {object} types.Book
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/revisions/{rev}/restore [post]
+func (h Handler) RestoreBook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	rev, err := parseIntParam("rev", r.PathValue("rev"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).RestoreBook(ctx, id, rev)
 	if err != nil {
 		response.WriteError(ctx, w, err, h.log)
 		return
@@ -2,19 +2,23 @@ package service_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
 	"gorm.io/gorm"
 
 	"github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/books/testutil"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
 	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
 )
 
@@ -56,6 +60,7 @@ func TestAddBook(t *testing.T) {
 				body: types.AddBookResponse{ID: 1},
 			},
 			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
 				s.Repository.EXPECT().CreateBook(types.Book{
 					Author:      "test-author",
 					Title:       "test-title",
@@ -66,6 +71,16 @@ func TestAddBook(t *testing.T) {
 					Title:       "test-title",
 					Description: "test-desc",
 				}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:     types.CHANGE_OP_CREATE,
+					BookID: 1,
+					After: &types.Book{
+						ID:          1,
+						Author:      "test-author",
+						Title:       "test-title",
+						Description: "test-desc",
+					},
+				}).Return(nil)
 			},
 		},
 		{
@@ -77,6 +92,7 @@ func TestAddBook(t *testing.T) {
 				errMsg: "Internal Server Error",
 			},
 			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
 				s.Repository.EXPECT().CreateBook(types.Book{
 					Author:      "test-author",
 					Title:       "test-title",
@@ -155,6 +171,7 @@ func TestDeleteBook(t *testing.T) {
 				errMsg: "book not found",
 			},
 			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
 				s.Repository.EXPECT().GetBook(1).Return(&types.Book{}, gorm.ErrRecordNotFound)
 			},
 		},
@@ -166,6 +183,7 @@ func TestDeleteBook(t *testing.T) {
 				body: types.DeleteBookResponse{},
 			},
 			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
 				s.Repository.EXPECT().GetBook(1).Return(&types.Book{
 					ID:          1,
 					Author:      "test-author",
@@ -178,6 +196,16 @@ func TestDeleteBook(t *testing.T) {
 					Title:       "test-title",
 					Description: "test-desc",
 				}).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:     types.CHANGE_OP_DELETE,
+					BookID: 1,
+					Before: &types.Book{
+						ID:          1,
+						Author:      "test-author",
+						Title:       "test-title",
+						Description: "test-desc",
+					},
+				}).Return(nil)
 			},
 		},
 	}
@@ -252,7 +280,8 @@ func TestUpdateBook(t *testing.T) {
 				},
 			},
 			preProcess: func(s *testutil.TestSuite) {
-				s.Repository.EXPECT().GetBook(1).Return(&types.Book{
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(&types.Book{
 					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
 				}, nil)
 				s.Repository.EXPECT().UpdateBook(&types.Book{
@@ -261,6 +290,42 @@ func TestUpdateBook(t *testing.T) {
 				s.Repository.EXPECT().GetBook(1).Return(&types.Book{
 					ID: 1, Author: "test-author", Title: "new-title", Description: "test-desc",
 				}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:     types.CHANGE_OP_UPDATE,
+					BookID: 1,
+					Before: &types.Book{ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc"},
+					After:  &types.Book{ID: 1, Author: "test-author", Title: "new-title", Description: "test-desc"},
+				}).Return(nil)
+			},
+		},
+		{
+			name:   "update only the currency of the price",
+			pathID: "1",
+			input: types.UpdateBookRequest{
+				BookMetadata: types.BookMetadata{Currency: "EUR"},
+			},
+			output: output{
+				code: http.StatusOK,
+				body: types.Book{
+					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
+					BookMetadata: types.BookMetadata{Price: 1299, Currency: "EUR"},
+				},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(&types.Book{
+					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
+					BookMetadata: types.BookMetadata{Price: 1299, Currency: "GBP"},
+				}, nil)
+				s.Repository.EXPECT().UpdateBook(&types.Book{
+					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
+					BookMetadata: types.BookMetadata{Price: 1299, Currency: "EUR"},
+				}).Return(nil)
+				s.Repository.EXPECT().GetBook(1).Return(&types.Book{
+					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
+					BookMetadata: types.BookMetadata{Price: 1299, Currency: "EUR"},
+				}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
 			},
 		},
 		{
@@ -274,7 +339,8 @@ func TestUpdateBook(t *testing.T) {
 				errMsg: "Internal Server Error",
 			},
 			preProcess: func(s *testutil.TestSuite) {
-				s.Repository.EXPECT().GetBook(1).Return(&types.Book{
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(&types.Book{
 					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
 				}, nil)
 				s.Repository.EXPECT().UpdateBook(&types.Book{
@@ -322,3 +388,1084 @@ func TestUpdateBook(t *testing.T) {
 		})
 	}
 }
+
+func TestImportBooks(t *testing.T) {
+	csvBody := "title,author,description\n" +
+		"title-1,author-1,desc-1\n" +
+		"title-2,,desc-2\n" +
+		"title-3,author-3,desc-3\n"
+
+	type output struct {
+		code   int
+		body   types.ImportBooksResponse
+		errMsg string
+	}
+	testCases := []struct {
+		name        string
+		preProcess  func(s *testutil.TestSuite)
+		query       string
+		contentType string
+		input       string
+		output      output
+	}{
+		{
+			name:        "unsupported content type",
+			contentType: "application/json",
+			input:       "{}",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "unsupported content type: application/json",
+			},
+		},
+		{
+			name:        "invalid mode",
+			query:       "?mode=best-effort",
+			contentType: "text/csv",
+			input:       csvBody,
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid mode: best-effort",
+			},
+		},
+		{
+			name:        "missing csv column",
+			contentType: "text/csv",
+			input:       "title,author\ntitle-1,author-1\n",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "missing csv column: description",
+			},
+		},
+		{
+			name:        "atomic with invalid row",
+			contentType: "text/csv",
+			input:       csvBody,
+			output: output{
+				code: http.StatusUnprocessableEntity,
+				body: types.ImportBooksResponse{
+					Mode: types.IMPORT_MODE_ATOMIC, Total: 3, Valid: 2, Failed: 1,
+					Rows: []types.ImportBookRowResult{
+						{Line: 2},
+						{Line: 3, Errors: map[string]string{"Author": "It is required"}},
+						{Line: 4},
+					},
+				},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+			},
+		},
+		{
+			name:        "partial",
+			query:       "?mode=partial",
+			contentType: "text/csv",
+			input:       csvBody,
+			output: output{
+				code: http.StatusCreated,
+				body: types.ImportBooksResponse{
+					Mode: types.IMPORT_MODE_PARTIAL, Committed: true, Total: 3, Valid: 2, Created: 2, Failed: 1,
+					Rows: []types.ImportBookRowResult{
+						{Line: 2, ID: 1},
+						{Line: 3, Errors: map[string]string{"Author": "It is required"}},
+						{Line: 4, ID: 2},
+					},
+				},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().CreateBooks([]types.Book{
+					{Title: "title-1", Author: "author-1", Description: "desc-1"},
+					{Title: "title-3", Author: "author-3", Description: "desc-3"},
+				}).Return([]types.Book{
+					{ID: 1, Title: "title-1", Author: "author-1", Description: "desc-1"},
+					{ID: 2, Title: "title-3", Author: "author-3", Description: "desc-3"},
+				}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:     types.CHANGE_OP_CREATE,
+					BookID: 1,
+					After:  &types.Book{ID: 1, Title: "title-1", Author: "author-1", Description: "desc-1"},
+				}).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:     types.CHANGE_OP_CREATE,
+					BookID: 2,
+					After:  &types.Book{ID: 2, Title: "title-3", Author: "author-3", Description: "desc-3"},
+				}).Return(nil)
+			},
+		},
+		{
+			name:        "dry run jsonl",
+			query:       "?dry_run=true",
+			contentType: "application/x-ndjson",
+			input: "{\"title\":\"title-1\",\"author\":\"author-1\",\"description\":\"desc-1\"}\n" +
+				"\n" +
+				"{\"title\":\n",
+			output: output{
+				code: http.StatusOK,
+				body: types.ImportBooksResponse{
+					Mode: types.IMPORT_MODE_ATOMIC, DryRun: true, Total: 2, Valid: 1, Failed: 1,
+					Rows: []types.ImportBookRowResult{
+						{Line: 1},
+						{Line: 3, Error: "invalid json: unexpected end of JSON input"},
+					},
+				},
+			},
+		},
+		{
+			name:        "error database",
+			contentType: "application/x-ndjson",
+			input:       "{\"title\":\"title-1\",\"author\":\"author-1\",\"description\":\"desc-1\"}\n",
+			output: output{
+				code:   http.StatusInternalServerError,
+				errMsg: "Internal Server Error",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().CreateBooks(gomock.Any()).Return(nil, errors.New("database error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/books/import"+tc.query, strings.NewReader(tc.input))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", tc.contentType)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code < http.StatusBadRequest || tc.output.code == http.StatusUnprocessableEntity {
+				var res types.ImportBooksResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestExportBooks(t *testing.T) {
+	books := []types.Book{
+		{ID: 2, Author: "author-2", Title: "title-2", Description: "desc-2"},
+		{ID: 1, Author: "author-1", Title: "title-1", Description: "desc-1"},
+	}
+
+	type output struct {
+		code        int
+		contentType string
+		body        string
+		errMsg      string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		query      string
+		output     output
+	}{
+		{
+			name:  "unsupported format",
+			query: "?format=xml",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "unsupported export format: xml",
+			},
+		},
+		{
+			name: "ndjson",
+			output: output{
+				code:        http.StatusOK,
+				contentType: "application/x-ndjson",
+				body: "{\"id\":2,\"title\":\"title-2\",\"author\":\"author-2\",\"description\":\"desc-2\"}\n" +
+					"{\"id\":1,\"title\":\"title-1\",\"author\":\"author-1\",\"description\":\"desc-1\"}\n",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().StreamBooks(gomock.Any(), db.SORT_DESC, types.BOOK_SORT_ID, types.BookFilter{}, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, fn func(types.Book) error) error {
+						for _, book := range books {
+							if err := fn(book); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name:  "csv with the filters of the list",
+			query: "?format=csv&sort_type=asc&sort_by=rating&tag=Science%20Fiction&subject=3&publisher=Bloomsbury&language=EN&currency=GBP&min_price=500",
+			output: output{
+				code:        http.StatusOK,
+				contentType: "text/csv; charset=utf-8",
+				body:        "id,title,author,description,isbn13,isbn10,publisher,published_date,edition,language,page_count,format,series_name,series_position,price,currency\n2,title-2,author-2,desc-2,,,,,,,,,,,,\n1,title-1,author-1,desc-1,,,,,,,,,,,,\n",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				filter := types.BookFilter{Tags: []string{"science fiction"}, SubjectID: 3, Publisher: "Bloomsbury", Language: "en", Currency: "GBP", MinPrice: 500}
+				s.Repository.EXPECT().StreamBooks(gomock.Any(), db.SORT_ASC, types.BOOK_SORT_RATING, filter, gomock.Any()).
+					DoAndReturn(func(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, fn func(types.Book) error) error {
+						for _, book := range books {
+							if err := fn(book); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			},
+		},
+		{
+			name: "error database",
+			output: output{
+				code:   http.StatusInternalServerError,
+				errMsg: "Internal Server Error",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().StreamBooks(gomock.Any(), db.SORT_DESC, types.BOOK_SORT_ID, types.BookFilter{}, gomock.Any()).Return(errors.New("database error"))
+			},
+		},
+		{
+			name:  "unsupported sort",
+			query: "?sort_by=title",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "unsupported sort: title",
+			},
+		},
+		{
+			name:  "invalid filter",
+			query: "?subject=abc",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid subject: abc",
+			},
+		},
+		{
+			name:  "invalid price range",
+			query: "?min_price=500",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Currency":"It is invalid"}`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/books/export"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				require.Equal(t, tc.output.contentType, resp.Header().Get("Content-Type"))
+				require.Equal(t, tc.output.body, resp.Body.String())
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+	operations := []types.BatchOperation{
+		{Op: types.BATCH_OP_CREATE, Body: json.RawMessage(`{"title":"test-title","author":"test-author","description":"test-desc"}`)},
+		{Op: types.BATCH_OP_UPDATE, ID: 2, Body: json.RawMessage(`{"title":"new-title"}`)},
+		{Op: types.BATCH_OP_DELETE, ID: 3},
+	}
+
+	type output struct {
+		code   int
+		body   string
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.BatchRequest
+		output     output
+	}{
+		{
+			name:  "invalid mode",
+			input: types.BatchRequest{Mode: "unknown", Operations: operations},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid mode: unknown",
+			},
+		},
+		{
+			name:  "no operations",
+			input: types.BatchRequest{},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "no operations",
+			},
+		},
+		{
+			name:  "atomic success",
+			input: types.BatchRequest{Operations: operations},
+			output: output{
+				code: http.StatusOK,
+				body: `{"mode":"atomic","committed":true,"results":[` +
+					`{"status":201,"body":{"id":1}},` +
+					`{"status":200,"body":{"id":2,"title":"new-title","author":"test-author","description":"test-desc"}},` +
+					`{"status":200,"body":{}}]}`,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction().Times(4)
+				s.Repository.EXPECT().CreateBook(types.Book{
+					Author: "test-author", Title: "test-title", Description: "test-desc",
+				}).Return(types.Book{
+					ID: 1, Author: "test-author", Title: "test-title", Description: "test-desc",
+				}, nil)
+				s.Repository.EXPECT().LockBook(2).Return(&types.Book{
+					ID: 2, Author: "test-author", Title: "test-title", Description: "test-desc",
+				}, nil)
+				s.Repository.EXPECT().UpdateBook(&types.Book{
+					ID: 2, Author: "test-author", Title: "new-title", Description: "test-desc",
+				}).Return(nil)
+				s.Repository.EXPECT().GetBook(2).Return(&types.Book{
+					ID: 2, Author: "test-author", Title: "new-title", Description: "test-desc",
+				}, nil)
+				s.Repository.EXPECT().GetBook(3).Return(&types.Book{ID: 3}, nil)
+				s.Repository.EXPECT().DeleteBook(&types.Book{ID: 3}).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+			},
+		},
+		{
+			name:  "atomic rollback",
+			input: types.BatchRequest{Mode: types.BATCH_MODE_ATOMIC, Operations: operations},
+			output: output{
+				code: http.StatusUnprocessableEntity,
+				body: `{"mode":"atomic","committed":false,"results":[` +
+					`{"status":424,"body":{"message":"rolled back because operation 1 failed"}},` +
+					`{"status":404,"body":{"message":"book not found"}},` +
+					`{"status":424,"body":{"message":"not executed because operation 1 failed"}}]}`,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction().Times(3)
+				s.Repository.EXPECT().CreateBook(gomock.Any()).Return(types.Book{ID: 1}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+				s.Repository.EXPECT().LockBook(2).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "per item",
+			input: types.BatchRequest{Mode: types.BATCH_MODE_PER_ITEM, Operations: operations},
+			output: output{
+				code: http.StatusOK,
+				body: `{"mode":"per_item","committed":true,"results":[` +
+					`{"status":201,"body":{"id":1}},` +
+					`{"status":404,"body":{"message":"book not found"}},` +
+					`{"status":500,"body":{"message":"Internal Server Error"}}]}`,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction().Times(7)
+				s.Repository.EXPECT().CreateBook(gomock.Any()).Return(types.Book{ID: 1}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+				s.Repository.EXPECT().LockBook(2).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().GetBook(3).Return(&types.Book{ID: 3}, nil)
+				s.Repository.EXPECT().DeleteBook(&types.Book{ID: 3}).Return(errors.New("database error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/books/batch", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code < http.StatusBadRequest || tc.output.code == http.StatusUnprocessableEntity {
+				require.JSONEq(t, tc.output.body, resp.Body.String())
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestRestoreBook(t *testing.T) {
+	revision := &types.BookRevision{
+		BookID:      1,
+		Rev:         1,
+		Op:          types.CHANGE_OP_CREATE,
+		Title:       "old-title",
+		Author:      "test-author",
+		Description: "test-desc",
+	}
+
+	type output struct {
+		code   int
+		body   types.Book
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		path       string
+		output     output
+	}{
+		{
+			name: "invalid rev",
+			path: "/api/v1/books/1/revisions/latest/restore",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid rev: latest",
+			},
+		},
+		{
+			name: "revision not found",
+			path: "/api/v1/books/1/revisions/9/restore",
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "revision not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().GetRevision(1, 9).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "existing book",
+			path: "/api/v1/books/1/revisions/1/restore",
+			output: output{
+				code: http.StatusOK,
+				body: types.Book{ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc"},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().GetRevision(1, 1).Return(revision, nil)
+				s.Repository.EXPECT().LockBook(1).Return(&types.Book{
+					ID: 1, Title: "new-title", Author: "test-author", Description: "test-desc",
+				}, nil)
+				s.Repository.EXPECT().UpdateBook(&types.Book{
+					ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc",
+				}).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:           types.CHANGE_OP_RESTORE,
+					BookID:       1,
+					Before:       &types.Book{ID: 1, Title: "new-title", Author: "test-author", Description: "test-desc"},
+					After:        &types.Book{ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc"},
+					RestoredFrom: 1,
+				}).Return(nil)
+			},
+		},
+		{
+			name: "deleted book",
+			path: "/api/v1/books/1/revisions/1/restore",
+			output: output{
+				code: http.StatusOK,
+				body: types.Book{ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc"},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().GetRevision(1, 1).Return(revision, nil)
+				s.Repository.EXPECT().LockBook(1).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateBook(types.Book{
+					ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc",
+				}).Return(types.Book{ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc"}, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), types.BookChange{
+					Op:           types.CHANGE_OP_RESTORE,
+					BookID:       1,
+					After:        &types.Book{ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc"},
+					RestoredFrom: 1,
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, tc.path, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Book
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+	s.Repository.EXPECT().GetRevision(1, 1).Return(&types.BookRevision{
+		BookID: 1, Rev: 1, Title: "old-title", Author: "test-author", Description: "test-desc",
+	}, nil)
+	s.Repository.EXPECT().GetRevision(1, 3).Return(&types.BookRevision{
+		BookID: 1, Rev: 3, Title: "new-title", Author: "test-author", Description: "new-desc",
+	}, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/books/1/revisions/diff?from=1&to=3", nil)
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router := http.NewServeMux()
+	router = service.InitializeRoutes(router, *s.Handler)
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.JSONEq(t, `{
+		"book_id": 1,
+		"from": 1,
+		"to": 3,
+		"changes": {
+			"title": {"old": "old-title", "new": "new-title"},
+			"description": {"old": "test-desc", "new": "new-desc"}
+		}
+	}`, resp.Body.String())
+}
+
+func TestGetBooksFilter(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+		body       string
+	}{
+		{
+			name:  "invalid subject",
+			query: "page=1&limit=10&subject=fiction",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "unsupported facets",
+			query: "page=1&limit=10&facets=authors",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "tags and subject with facets",
+			query: "page=1&limit=10&tag=Science%20Fiction&tag=classic&subject=4&facets=tags",
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				filter := types.BookFilter{Tags: []string{"science fiction", "classic"}, SubjectID: 4}
+				s.Repository.EXPECT().GetBooks(1, 10, db.SORT_DESC, types.BOOK_SORT_ID, filter).Return(
+					&db.Pagination{Page: 1, Limit: 10, TotalRows: 1, TotalPages: 1},
+					[]types.Book{{ID: 1, Title: "test-title", Author: "test-author", Description: "test-desc"}},
+					nil,
+				)
+				s.Repository.EXPECT().GetTagFacets(filter).Return([]types.TagFacet{
+					{Tag: "classic", Count: 1},
+					{Tag: "science fiction", Count: 1},
+				}, nil)
+			},
+			body: `{
+				"books": [{"id": 1, "title": "test-title", "author": "test-author", "description": "test-desc"}],
+				"pagination": {"page": 1, "limit": 10, "total_rows": 1, "total_pages": 1},
+				"facets": {"tags": [{"tag": "classic", "count": 1}, {"tag": "science fiction", "count": 1}]}
+			}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/books?"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+			if tc.body != "" {
+				require.JSONEq(t, tc.body, resp.Body.String())
+			}
+		})
+	}
+}
+
+func TestISBN(t *testing.T) {
+	require.True(t, types.ValidISBN10("0306406152"))
+	require.True(t, types.ValidISBN10("080442957X"))
+	require.False(t, types.ValidISBN10("0306406153"))
+	require.True(t, types.ValidISBN13("9780306406157"))
+	require.False(t, types.ValidISBN13("9780306406158"))
+
+	require.Equal(t, "9780306406157", types.ISBN10To13(types.NormalizeISBN("0-306-40615-2")))
+	isbn10, ok := types.ISBN13To10("9780804429573")
+	require.True(t, ok)
+	require.Equal(t, "080442957X", isbn10)
+	_, ok = types.ISBN13To10("9791090636071")
+	require.False(t, ok)
+}
+
+func TestAddBookISBN(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      types.AddBookRequest
+		preProcess func(s *testutil.TestSuite)
+		code       int
+		body       string
+	}{
+		{
+			name:  "invalid checksum",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", ISBN13: "978-0-306-40615-8"},
+			code:  http.StatusBadRequest,
+			body:  `{"message": "{\"ISBN13\":\"It is not a valid ISBN\"}"}`,
+		},
+		{
+			name:  "different books",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", ISBN13: "9791090636071", ISBN10: "0306406152"},
+			code:  http.StatusBadRequest,
+			body:  `{"message": "isbn10 0306406152 does not match isbn13 9791090636071"}`,
+		},
+		{
+			name:  "duplicated isbn",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", ISBN10: "0-306-40615-2"},
+			code:  http.StatusConflict,
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(&types.Book{ID: 7}, nil)
+			},
+			body: `{"message": "book already exists: 7", "id": 7}`,
+		},
+		{
+			name:  "isbn added concurrently",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", ISBN13: "9780306406157"},
+			code:  http.StatusConflict,
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				gomock.InOrder(
+					s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(nil, gorm.ErrRecordNotFound),
+					s.Repository.EXPECT().CreateBook(gomock.Any()).Return(types.Book{}, gorm.ErrDuplicatedKey),
+					s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(&types.Book{ID: 9}, nil),
+				)
+			},
+			body: `{"message": "book already exists: 9", "id": 9}`,
+		},
+		{
+			name:  "isbn added and deleted concurrently",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", ISBN13: "9780306406157"},
+			code:  http.StatusConflict,
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				gomock.InOrder(
+					s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(nil, gorm.ErrRecordNotFound),
+					s.Repository.EXPECT().CreateBook(gomock.Any()).Return(types.Book{}, gorm.ErrDuplicatedKey),
+					s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(nil, gorm.ErrRecordNotFound),
+				)
+			},
+			body: `{"message": "isbn already exists: 9780306406157"}`,
+		},
+		{
+			name:  "isbn10 converted into isbn13",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", ISBN10: "0-306-40615-2"},
+			code:  http.StatusCreated,
+			preProcess: func(s *testutil.TestSuite) {
+				book := types.Book{
+					Title:       "test-title",
+					Author:      "test-author",
+					Description: "test-desc",
+					ISBN13:      "9780306406157",
+					ISBN10:      "0306406152",
+				}
+				s.ExpectTransaction()
+				s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(nil, gorm.ErrRecordNotFound)
+				created := book
+				created.ID = 1
+				s.Repository.EXPECT().CreateBook(book).Return(created, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			body: `{"id": 1}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/books", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+			require.JSONEq(t, tc.body, resp.Body.String())
+		})
+	}
+}
+
+func TestGetBookByISBN(t *testing.T) {
+	testCases := []struct {
+		name       string
+		url        string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+	}{
+		{
+			name: "invalid isbn",
+			url:  "/api/v1/books/isbn/12345",
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not found",
+			url:  "/api/v1/books/isbn/9780306406157",
+			code: http.StatusNotFound,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "unknown route of a book",
+			url:  "/api/v1/books/1/unknown",
+			code: http.StatusNotFound,
+		},
+		{
+			name: "found by isbn10",
+			url:  "/api/v1/books/isbn/0-306-40615-2",
+			code: http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBookByISBN("9780306406157").Return(&types.Book{ID: 1, ISBN13: "9780306406157"}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+		})
+	}
+}
+
+func TestAddBookMetadata(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      types.AddBookRequest
+		preProcess func(s *testutil.TestSuite)
+		code       int
+		body       string
+	}{
+		{
+			name: "invalid metadata",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", BookMetadata: types.BookMetadata{
+				PublishedDate: "26/06/1997",
+				Language:      "not a language",
+				Format:        "scroll",
+				Price:         1299,
+				Currency:      "XYZ",
+			}},
+			code: http.StatusBadRequest,
+			body: `{"message": "{\"Currency\":\"It is invalid\",\"Format\":\"It is invalid\",\"Language\":\"It is invalid\",\"PublishedDate\":\"It is invalid\"}"}`,
+		},
+		{
+			name:  "price without currency",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", BookMetadata: types.BookMetadata{Price: 1299}},
+			code:  http.StatusBadRequest,
+			body:  `{"message": "{\"Currency\":\"It is invalid\"}"}`,
+		},
+		{
+			name: "language in canonical form",
+			input: types.AddBookRequest{Title: "test-title", Author: "test-author", Description: "test-desc", BookMetadata: types.BookMetadata{
+				Publisher:     "Bloomsbury",
+				PublishedDate: "1997-06-26",
+				Language:      "en-gb",
+				Format:        types.BOOK_FORMAT_HARDCOVER,
+				Price:         1299,
+				Currency:      "GBP",
+			}},
+			code: http.StatusCreated,
+			preProcess: func(s *testutil.TestSuite) {
+				book := types.Book{
+					Title:       "test-title",
+					Author:      "test-author",
+					Description: "test-desc",
+					BookMetadata: types.BookMetadata{
+						Publisher:     "Bloomsbury",
+						PublishedDate: "1997-06-26",
+						Language:      "en-GB",
+						Format:        types.BOOK_FORMAT_HARDCOVER,
+						Price:         1299,
+						Currency:      "GBP",
+					},
+				}
+				s.ExpectTransaction()
+				created := book
+				created.ID = 1
+				s.Repository.EXPECT().CreateBook(book).Return(created, nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			body: `{"id": 1}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/books", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+			require.JSONEq(t, tc.body, resp.Body.String())
+		})
+	}
+}
+
+func TestGetBooksMetadataFilter(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+	}{
+		{
+			name:  "invalid format",
+			query: "page=1&limit=10&book_format=scroll",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "price without currency",
+			query: "page=1&limit=10&min_price=500",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "invalid price",
+			query: "page=1&limit=10&currency=GBP&max_price=cheap",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "metadata filter",
+			query: "page=1&limit=10&publisher=Bloomsbury&series=Harry%20Potter&language=EN&book_format=hardcover&published_from=1997-01-01&published_to=1999-12-31&currency=GBP&min_price=500&max_price=2000",
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				filter := types.BookFilter{
+					Publisher:     "Bloomsbury",
+					SeriesName:    "Harry Potter",
+					Language:      "en",
+					Format:        types.BOOK_FORMAT_HARDCOVER,
+					PublishedFrom: "1997-01-01",
+					PublishedTo:   "1999-12-31",
+					Currency:      "GBP",
+					MinPrice:      500,
+					MaxPrice:      2000,
+				}
+				s.Repository.EXPECT().GetBooks(1, 10, db.SORT_DESC, types.BOOK_SORT_ID, filter).Return(
+					&db.Pagination{Page: 1, Limit: 10}, []types.Book{}, nil,
+				)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/books?"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+		})
+	}
+}
+
+func TestBookLifecycle(t *testing.T) {
+	s := testutil.NewMemoryTestSuite(t)
+
+	do := func(method string, path string, body any) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			require.NoError(t, json.NewEncoder(&buf).Encode(body))
+		}
+		req, err := http.NewRequest(method, path, &buf)
+		require.NoError(t, err)
+
+		resp := httptest.NewRecorder()
+		s.Router.ServeHTTP(resp, req)
+		return resp
+	}
+	getBook := func(id int) types.Book {
+		resp := do(http.MethodGet, fmt.Sprintf("/api/v1/books/%d", id), nil)
+		require.Equal(t, http.StatusOK, resp.Code)
+
+		var book types.Book
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&book))
+		return book
+	}
+
+	resp := do(http.MethodPost, "/api/v1/books", types.AddBookRequest{Title: "Emma", Author: "Jane Austen", Description: "a novel", ISBN10: "0-306-40615-2"})
+	require.Equal(t, http.StatusCreated, resp.Code)
+	var created types.AddBookResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+
+	// the book is found by its ISBN-13 and its ISBN cannot be taken by another book.
+	resp = do(http.MethodGet, "/api/v1/books/isbn/9780306406157", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	resp = do(http.MethodPost, "/api/v1/books", types.AddBookRequest{Title: "Emma", Author: "Jane Austen", Description: "a copy", ISBN13: "978-0-306-40615-7"})
+	require.Equal(t, http.StatusConflict, resp.Code)
+
+	resp = do(http.MethodPut, fmt.Sprintf("/api/v1/books/%d", created.ID), types.UpdateBookRequest{Title: "Emma (annotated)"})
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "Emma (annotated)", getBook(created.ID).Title)
+
+	// a failed atomic batch changes nothing.
+	resp = do(http.MethodPost, "/api/v1/books/batch", types.BatchRequest{
+		Mode: types.BATCH_MODE_ATOMIC,
+		Operations: []types.BatchOperation{
+			{Op: types.BATCH_OP_UPDATE, ID: created.ID, Body: json.RawMessage(`{"title":"Persuasion"}`)},
+			{Op: types.BATCH_OP_DELETE, ID: 1000},
+		},
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	var batch types.BatchResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batch))
+	require.False(t, batch.Committed)
+	require.Equal(t, "Emma (annotated)", getBook(created.ID).Title)
+
+	resp = do(http.MethodDelete, fmt.Sprintf("/api/v1/books/%d", created.ID), nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	resp = do(http.MethodGet, fmt.Sprintf("/api/v1/books/%d", created.ID), nil)
+	require.Equal(t, http.StatusNotFound, resp.Code)
+
+	// the deleted book is restored as of its first revision.
+	resp = do(http.MethodPost, fmt.Sprintf("/api/v1/books/%d/revisions/1/restore", created.ID), nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	require.Equal(t, "Emma", getBook(created.ID).Title)
+
+	resp = do(http.MethodGet, fmt.Sprintf("/api/v1/books/%d/revisions?page=1&limit=10", created.ID), nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var revisions types.GetRevisionsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&revisions))
+	ops := []types.ChangeOperation{}
+	for _, r := range revisions.Revisions {
+		ops = append(ops, r.Op)
+	}
+	require.Equal(t, []types.ChangeOperation{types.CHANGE_OP_RESTORE, types.CHANGE_OP_DELETE, types.CHANGE_OP_UPDATE, types.CHANGE_OP_CREATE}, ops)
+
+	// a book without any revision is not found.
+	resp = do(http.MethodGet, "/api/v1/books/1000/revisions?page=1&limit=10", nil)
+	require.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = do(http.MethodGet, "/api/v1/books?page=1&limit=10&language=en", nil)
+	require.Equal(t, http.StatusOK, resp.Code)
+	var books types.GetBooksResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&books))
+	require.Empty(t, books.Books)
+	require.Equal(t, int64(0), books.Pagination.TotalRows)
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// importBatchSize is the number of books inserted per statement during a bulk import.
+const importBatchSize = 500
+
+// errImportRollback is used to roll back the import transaction without failing the request.
+var errImportRollback = errors.New("rollback import")
+
+// NewImportBookReader creates a reader of a bulk import stream according to the given content type.
+func NewImportBookReader(contentType string, r io.Reader) (types.ImportBookReader, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid content type: %s", contentType))
+	}
+
+	switch mediaType {
+	case "text/csv":
+		return NewCSVImportReader(r)
+	case "application/jsonl", "application/x-ndjson", "application/x-jsonlines":
+		return NewJSONLImportReader(r), nil
+	default:
+		return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("unsupported content type: %s", mediaType))
+	}
+}
+
+type csvImportReader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+// NewCSVImportReader creates a reader of CSV rows. The first record is the header that
+// names the title, author and description columns in any order, and optionally the isbn13,
+// isbn10 and metadata columns (see types.BookMetadataCSVHeader).
+func NewCSVImportReader(r io.Reader) (types.ImportBookReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, apierror.ErrInvalidInput.WithMessage("missing csv header")
+	} else if err != nil {
+		return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid csv header: %v", err))
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, name := range []string{"title", "author", "description"} {
+		if _, ok := columns[name]; !ok {
+			return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("missing csv column: %s", name))
+		}
+	}
+
+	return &csvImportReader{r: reader, columns: columns}, nil
+}
+
+func (c *csvImportReader) Read() (types.ImportBookRow, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return types.ImportBookRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+		}
+		return types.ImportBookRow{}, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	row := types.ImportBookRow{
+		Line: line,
+		Book: types.AddBookRequest{
+			Title:       c.field(record, "title"),
+			Author:      c.field(record, "author"),
+			Description: c.field(record, "description"),
+			ISBN13:      c.field(record, "isbn13"),
+			ISBN10:      c.field(record, "isbn10"),
+			BookMetadata: types.BookMetadata{
+				Publisher:     c.field(record, "publisher"),
+				PublishedDate: c.field(record, "published_date"),
+				Edition:       c.field(record, "edition"),
+				Language:      c.field(record, "language"),
+				Format:        types.BookFormat(c.field(record, "format")),
+				SeriesName:    c.field(record, "series_name"),
+				Currency:      c.field(record, "currency"),
+			},
+		},
+	}
+
+	metadata := &row.Book.BookMetadata
+	var pageCount, seriesPosition int64
+	for _, f := range []struct {
+		name string
+		dst  *int64
+	}{
+		{"page_count", &pageCount},
+		{"series_position", &seriesPosition},
+		{"price", &metadata.Price},
+	} {
+		if *f.dst, err = c.intField(record, f.name); err != nil {
+			row.Err = err
+			return row, nil
+		}
+	}
+	metadata.PageCount, metadata.SeriesPosition = int(pageCount), int(seriesPosition)
+
+	return row, nil
+}
+
+// field returns the value of the named column, or an empty string if the header has no such
+// column or the record is too short.
+func (c *csvImportReader) field(record []string, name string) string {
+	i, ok := c.columns[name]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return record[i]
+}
+
+// intField returns the integer value of the named column, or 0 if the value is empty.
+func (c *csvImportReader) intField(record []string, name string) (int64, error) {
+	value := c.field(record, name)
+	if value == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return v, nil
+}
+
+type jsonlImportReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// NewJSONLImportReader creates a reader of JSON lines, each one holding an AddBookRequest object.
+// Blank lines are skipped.
+func NewJSONLImportReader(r io.Reader) types.ImportBookReader {
+	return &jsonlImportReader{r: bufio.NewReader(r)}
+}
+
+func (j *jsonlImportReader) Read() (types.ImportBookRow, error) {
+	for {
+		b, err := j.r.ReadBytes('\n')
+		if len(b) == 0 && err != nil {
+			return types.ImportBookRow{}, err
+		} else if err != nil && !errors.Is(err, io.EOF) {
+			return types.ImportBookRow{}, err
+		}
+
+		j.line++
+		b = bytes.TrimSpace(b)
+		if len(b) == 0 {
+			continue
+		}
+
+		row := types.ImportBookRow{Line: j.line}
+		if err := json.Unmarshal(b, &row.Book); err != nil {
+			row.Err = fmt.Errorf("invalid json: %v", err)
+		}
+		return row, nil
+	}
+}
+
+// ImportBooks validates the rows of a bulk import with the rules of AddBookRequest and inserts
+// the valid ones in batches inside a single transaction. In atomic mode nothing is written if
+// any row is invalid; in partial mode invalid rows are skipped. A dry run writes nothing.
+// Every created book is recorded as a change like a book added one by one.
+func (s *Service) ImportBooks(ctx context.Context, reader types.ImportBookReader, opts types.ImportBooksOptions) (types.ImportBooksResponse, error) {
+	switch opts.Mode {
+	case "":
+		opts.Mode = types.IMPORT_MODE_ATOMIC
+	case types.IMPORT_MODE_ATOMIC, types.IMPORT_MODE_PARTIAL:
+	default:
+		return types.ImportBooksResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid mode: %s", opts.Mode))
+	}
+
+	if opts.DryRun {
+		return s.importBooks(ctx, nil, reader, opts)
+	}
+
+	var resp types.ImportBooksResponse
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		var err error
+		resp, err = s.importBooks(ctx, d, reader, opts)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Committed {
+			return errImportRollback
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportRollback) {
+		return types.ImportBooksResponse{}, err
+	}
+
+	if !resp.Committed {
+		// rows inserted before the rollback do not exist anymore.
+		resp.Created = 0
+		for i := range resp.Rows {
+			resp.Rows[i].ID = 0
+		}
+	}
+
+	return resp, nil
+}
+
+// importBooks reads, validates and writes the rows into the given data provider; rows are
+// only validated if the data provider is nil.
+func (s *Service) importBooks(
+	ctx context.Context,
+	d types.DataProvider,
+	reader types.ImportBookReader,
+	opts types.ImportBooksOptions,
+) (types.ImportBooksResponse, error) {
+	resp := types.ImportBooksResponse{
+		Mode:   opts.Mode,
+		DryRun: opts.DryRun,
+		Rows:   []types.ImportBookRowResult{},
+	}
+
+	var batch []types.Book
+	var batchRows []int // indexes of the rows of the batch in resp.Rows
+	flush := func() error {
+		defer func() {
+			batch, batchRows = nil, nil
+		}()
+
+		// an atomic import is rolled back anyway, so there is no need to insert more rows.
+		if d == nil || len(batch) == 0 || (opts.Mode == types.IMPORT_MODE_ATOMIC && resp.Failed > 0) {
+			return nil
+		}
+
+		books, err := d.CreateBooks(batch)
+		if err != nil {
+			return err
+		}
+
+		for i, book := range books {
+			resp.Rows[batchRows[i]].ID = book.ID
+
+			err := d.RecordChange(ctx, types.BookChange{
+				Op:     types.CHANGE_OP_CREATE,
+				BookID: book.ID,
+				After:  &books[i],
+			})
+			if err != nil {
+				return err
+			}
+		}
+		resp.Created += len(books)
+		return nil
+	}
+
+	// the ISBNs of existing books are looked up in the import transaction, if any.
+	checker := s
+	if d != nil {
+		txServ := NewService(d, s.log)
+		checker = &txServ
+	}
+	isbnLines := make(map[string]int)
+
+	validate := types.NewValidator()
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return types.ImportBooksResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("failed to read import: %v", err))
+		}
+
+		resp.Total++
+		result := types.ImportBookRowResult{Line: row.Line}
+		var book types.Book
+		if row.Err != nil {
+			result.Error = row.Err.Error()
+		} else if err := validate.Struct(row.Book); err != nil {
+			errMaps, ok := apierror.ValidatorErrorsToMap(err)
+			if !ok {
+				return types.ImportBooksResponse{}, err
+			}
+			result.Errors = errMaps
+		} else if book, err = checker.importedBook(row.Book, row.Line, isbnLines); err != nil {
+			var apiErr *apierror.Error
+			if !errors.As(err, &apiErr) {
+				return types.ImportBooksResponse{}, err
+			}
+			result.Error = apiErr.Message
+		}
+		resp.Rows = append(resp.Rows, result)
+
+		if result.Error != "" || result.Errors != nil {
+			resp.Failed++
+			continue
+		}
+
+		resp.Valid++
+		batch = append(batch, book)
+		batchRows = append(batchRows, len(resp.Rows)-1)
+
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return types.ImportBooksResponse{}, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return types.ImportBooksResponse{}, err
+	}
+
+	resp.Committed = d != nil && (opts.Mode == types.IMPORT_MODE_PARTIAL || resp.Failed == 0)
+	return resp, nil
+}
+
+// importedBook returns the book of a valid row. It fails if the ISBN of the book is given on an
+// earlier line, recorded in isbnLines, or belongs to an existing book.
+func (s *Service) importedBook(req types.AddBookRequest, line int, isbnLines map[string]int) (types.Book, error) {
+	isbn13, isbn10, err := resolveISBN(req.ISBN13, req.ISBN10)
+	if err != nil {
+		return types.Book{}, err
+	}
+
+	book := types.Book{
+		Title:        req.Title,
+		Author:       req.Author,
+		Description:  req.Description,
+		ISBN13:       isbn13,
+		ISBN10:       isbn10,
+		BookMetadata: req.BookMetadata.Normalize(),
+	}
+	if isbn13 == "" {
+		return book, nil
+	}
+
+	if earlier, ok := isbnLines[isbn13]; ok {
+		return types.Book{}, apierror.ErrConflict.WithMessage(fmt.Sprintf("isbn is already given on line %d", earlier))
+	}
+	if err := s.checkISBNAvailable(book); err != nil {
+		return types.Book{}, err
+	}
+
+	isbnLines[isbn13] = line
+	return book, nil
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// GetBookByISBN returns a book information from the given ISBN-10 or ISBN-13
+func (s *Service) GetBookByISBN(isbn string) (*types.Book, error) {
+	isbn = types.NormalizeISBN(isbn)
+	switch {
+	case types.ValidISBN13(isbn):
+	case types.ValidISBN10(isbn):
+		isbn = types.ISBN10To13(isbn)
+	default:
+		return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid isbn: %s", isbn))
+	}
+
+	book, err := s.dataProvider.GetBookByISBN(isbn)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("book not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// resolveISBN returns the normalized ISBN-13 and ISBN-10 of a book from the validated ones
+// of a request, converting the one that is missing.
+func resolveISBN(isbn13 string, isbn10 string) (string, string, error) {
+	isbn13, isbn10 = types.NormalizeISBN(isbn13), types.NormalizeISBN(isbn10)
+
+	switch {
+	case isbn10 == "":
+		isbn10, _ = types.ISBN13To10(isbn13)
+	case isbn13 == "":
+		isbn13 = types.ISBN10To13(isbn10)
+	case types.ISBN10To13(isbn10) != isbn13:
+		return "", "", apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("isbn10 %s does not match isbn13 %s", isbn10, isbn13))
+	}
+
+	return isbn13, isbn10, nil
+}
+
+// checkISBNAvailable checks that no other book has the ISBN of the book
+func (s *Service) checkISBNAvailable(book types.Book) error {
+	if book.ISBN13 == "" {
+		return nil
+	}
+
+	existing, err := s.dataProvider.GetBookByISBN(book.ISBN13)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.ID != book.ID {
+		return apierror.NewConflictError(fmt.Sprintf("book already exists: %d", existing.ID), existing.ID)
+	}
+	return nil
+}
+
+// isbnConflict returns the conflict with the book holding the ISBN of the book if err is a
+// duplicated key, as when another book with the ISBN is added after the ISBN was checked.
+// Otherwise it returns err.
+func (s *Service) isbnConflict(err error, book types.Book) error {
+	if book.ISBN13 == "" || !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	if conflict := s.checkISBNAvailable(book); conflict != nil {
+		return conflict
+	}
+	// the other book is gone already.
+	return apierror.ErrConflict.WithMessage(fmt.Sprintf("isbn already exists: %s", book.ISBN13))
+}
@@ -0,0 +1,543 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+var (
+	_ types.DataProvider = &MemoryRepository{}
+)
+
+// MemoryRepository is the data provider keeping the books in memory, for the tests and the demo
+// server. It is safe for concurrent use and behaves as Repository: the queries are scoped to the
+// tenant of the context and the missing books are gorm.ErrRecordNotFound. The books have neither
+// copies nor tags, which are kept in the database by the other services.
+type MemoryRepository struct {
+	store *memoryStore
+	// tx is the data of the transaction of the repository, nil outside transactions.
+	tx  *memoryData
+	ctx context.Context
+	log *zap.Logger
+}
+
+type memoryStore struct {
+	mu   sync.RWMutex
+	data memoryData
+}
+
+type memoryData struct {
+	books     map[int]types.Book
+	revisions []types.BookRevision
+	// the ids are increasing and never reused, as the ones generated by the database.
+	lastBookID     int
+	lastRevisionID int
+}
+
+// NewMemoryRepository creates a new books-service repository without any book.
+func NewMemoryRepository(log *zap.Logger) MemoryRepository {
+	store := &memoryStore{data: memoryData{books: make(map[int]types.Book)}}
+	return MemoryRepository{store: store, ctx: context.Background(), log: log}
+}
+
+// clone returns a copy of the data that can be changed without changing d.
+func (d *memoryData) clone() *memoryData {
+	books := make(map[int]types.Book, len(d.books))
+	for id, book := range d.books {
+		books[id] = book
+	}
+
+	return &memoryData{
+		books:          books,
+		revisions:      slices.Clone(d.revisions),
+		lastBookID:     d.lastBookID,
+		lastRevisionID: d.lastRevisionID,
+	}
+}
+
+// read runs fn with the data of the repository, which fn must not change.
+func (r *MemoryRepository) read(fn func(d *memoryData) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+	return fn(&r.store.data)
+}
+
+// write runs fn with the data of the repository. fn checks the changes before making any, so a
+// failed change leaves the data as it is.
+func (r *MemoryRepository) write(fn func(d *memoryData) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	return fn(&r.store.data)
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *MemoryRepository) WithContext(ctx context.Context) types.DataProvider {
+	repo := *r
+	repo.ctx = ctx
+	return &repo
+}
+
+// Transaction runs fn with a repository changing a copy of the data, which replaces the data if fn
+// succeeds. The transactions are serialized, so fn must only use the repository it is given.
+func (r *MemoryRepository) Transaction(fn func(d types.DataProvider) error) error {
+	return r.write(func(d *memoryData) error {
+		repo := *r
+		repo.tx = d.clone()
+		if err := fn(&repo); err != nil {
+			return err
+		}
+
+		*d = *repo.tx
+		return nil
+	})
+}
+
+// RecordChange records the revision of the change
+func (r *MemoryRepository) RecordChange(ctx context.Context, change types.BookChange) error {
+	id, scoped, err := tenant.Scope(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(d *memoryData) error {
+		last := 0
+		for _, revision := range d.revisions {
+			if inScope(revision.TenantID, id, scoped) && revision.BookID == change.BookID {
+				last = max(last, revision.Rev)
+			}
+		}
+
+		for _, revision := range newRevisions(ctx, last, change) {
+			d.lastRevisionID++
+			revision.ID = d.lastRevisionID
+			revision.TenantID = tenantOf(revision.TenantID, id, scoped)
+			revision.CreatedAt = time.Now()
+			d.revisions = append(d.revisions, revision)
+		}
+
+		return nil
+	})
+}
+
+// CreateBook creates a new book in memory
+func (r *MemoryRepository) CreateBook(book types.Book) (types.Book, error) {
+	books, err := r.CreateBooks([]types.Book{book})
+	if err != nil {
+		return book, err
+	}
+
+	return books[0], nil
+}
+
+// CreateBooks creates new books in memory, either all of them or none
+func (r *MemoryRepository) CreateBooks(books []types.Book) ([]types.Book, error) {
+	if len(books) == 0 {
+		return books, nil
+	}
+
+	id, scoped, err := tenant.Scope(r.ctx)
+	if err != nil {
+		return books, err
+	}
+
+	created := make([]types.Book, len(books))
+	err = r.write(func(d *memoryData) error {
+		// the books are checked against each other as well as against the stored ones.
+		batch := d.clone()
+		for i, book := range books {
+			book.TenantID = tenantOf(book.TenantID, id, scoped)
+			if err := batch.insert(&book); err != nil {
+				return err
+			}
+			created[i] = book
+		}
+
+		*d = *batch
+		return nil
+	})
+	if err != nil {
+		return books, err
+	}
+
+	return created, nil
+}
+
+// insert stores a new book, with a new id unless it has one.
+func (d *memoryData) insert(book *types.Book) error {
+	if _, ok := d.books[book.ID]; ok {
+		return fmt.Errorf("%w: book %d", gorm.ErrDuplicatedKey, book.ID)
+	}
+	if err := d.checkISBN(*book); err != nil {
+		return err
+	}
+
+	if book.ID == 0 {
+		book.ID = d.lastBookID + 1
+	}
+	d.lastBookID = max(d.lastBookID, book.ID)
+	d.store(*book, types.BookRating{})
+
+	return nil
+}
+
+// checkISBN checks that no other book of the tenant of the book has its ISBN-13.
+func (d *memoryData) checkISBN(book types.Book) error {
+	if book.ISBN13 == "" {
+		return nil
+	}
+
+	for _, other := range d.books {
+		if other.ID != book.ID && other.TenantID == book.TenantID && other.ISBN13 == book.ISBN13 {
+			return fmt.Errorf("%w: isbn13 %s", gorm.ErrDuplicatedKey, book.ISBN13)
+		}
+	}
+
+	return nil
+}
+
+// store keeps a copy of the book with the given rating, which is not written by the books service.
+func (d *memoryData) store(book types.Book, rating types.BookRating) {
+	book.BookRating = rating
+	book.Cover = cloneCover(book.Cover)
+	book.Availability = nil
+	d.books[book.ID] = book
+}
+
+func cloneCover(cover *types.BookCover) *types.BookCover {
+	if cover == nil {
+		return nil
+	}
+
+	c := *cover
+	c.Thumbnails = slices.Clone(cover.Thumbnails)
+	return &c
+}
+
+// UpdateBook updates a book in memory, creating it if it doesn't exist
+func (r *MemoryRepository) UpdateBook(book *types.Book) error {
+	id, scoped, err := tenant.Scope(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(d *memoryData) error {
+		stored, ok := d.books[book.ID]
+		if !ok || book.ID == 0 {
+			created := *book
+			created.TenantID = tenantOf(created.TenantID, id, scoped)
+			if err := d.insert(&created); err != nil {
+				return err
+			}
+			book.ID = created.ID
+			return nil
+		}
+		// the book of another tenant is left as it is.
+		if !inScope(stored.TenantID, id, scoped) {
+			return nil
+		}
+
+		updated := *book
+		updated.TenantID = stored.TenantID
+		if err := d.checkISBN(updated); err != nil {
+			return err
+		}
+		d.store(updated, stored.BookRating)
+
+		return nil
+	})
+}
+
+// DeleteBook deletes a book from memory
+func (r *MemoryRepository) DeleteBook(book *types.Book) error {
+	if book.ID == 0 {
+		return gorm.ErrMissingWhereClause
+	}
+
+	id, scoped, err := tenant.Scope(r.ctx)
+	if err != nil {
+		return err
+	}
+
+	return r.write(func(d *memoryData) error {
+		if stored, ok := d.books[book.ID]; ok && inScope(stored.TenantID, id, scoped) {
+			delete(d.books, book.ID)
+		}
+
+		return nil
+	})
+}
+
+// GetBooks retrieves a page of the books matching the filter from memory
+func (r *MemoryRepository) GetBooks(page int, limit int, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter) (*db.Pagination, []types.Book, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  fmt.Sprintf("id %s", sortType),
+	}
+	if sortBy != types.BOOK_SORT_ID {
+		p.Sort = fmt.Sprintf("%s %s, id %s", sortBy.Column(), sortType, sortType)
+	}
+
+	books, err := r.findBooks(r.ctx, sortType, sortBy, func(book types.Book) bool {
+		return matchBook(book, filter)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.SetTotalRows(int64(len(books)))
+	start, end := p.Bounds(len(books))
+	books = books[start:end]
+	for i := range books {
+		books[i].Availability = &types.BookAvailability{}
+	}
+
+	return &p, books, nil
+}
+
+// findBooks returns copies of the books of the tenant of ctx accepted by match in the given order.
+func (r *MemoryRepository) findBooks(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, match func(book types.Book) bool) ([]types.Book, error) {
+	id, scoped, err := tenant.Scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	books := []types.Book{}
+	err = r.read(func(d *memoryData) error {
+		for _, book := range d.books {
+			if inScope(book.TenantID, id, scoped) && match(book) {
+				book.Cover = cloneCover(book.Cover)
+				books = append(books, book)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(books, func(a, b types.Book) int {
+		c := 0
+		switch sortBy {
+		case types.BOOK_SORT_RATING:
+			c = cmp.Compare(a.RatingAverage, b.RatingAverage)
+		case types.BOOK_SORT_RATING_COUNT:
+			c = cmp.Compare(a.RatingCount, b.RatingCount)
+		}
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if sortType == db.SORT_DESC {
+			return -c
+		}
+		return c
+	})
+
+	return books, nil
+}
+
+// matchBook reports whether the book matches the filter as the query of Repository.filterBooks.
+func matchBook(book types.Book, filter types.BookFilter) bool {
+	// no book is tagged nor classified in memory.
+	if len(filter.Tags) > 0 || filter.SubjectID != 0 {
+		return false
+	}
+
+	if filter.Publisher != "" && strings.ToLower(book.Publisher) != strings.ToLower(filter.Publisher) {
+		return false
+	}
+	if filter.SeriesName != "" && strings.ToLower(book.SeriesName) != strings.ToLower(filter.SeriesName) {
+		return false
+	}
+	if filter.Language != "" && book.Language != filter.Language && !strings.HasPrefix(book.Language, filter.Language+"-") {
+		return false
+	}
+	if filter.Format != "" && book.Format != filter.Format {
+		return false
+	}
+	if filter.PublishedFrom != "" && book.PublishedDate < filter.PublishedFrom {
+		return false
+	}
+	if filter.PublishedTo != "" && (book.PublishedDate == "" || book.PublishedDate > filter.PublishedTo) {
+		return false
+	}
+	if filter.Currency != "" && book.Currency != filter.Currency {
+		return false
+	}
+	if filter.MinPrice != 0 && book.Price < filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != 0 && book.Price > filter.MaxPrice {
+		return false
+	}
+
+	return true
+}
+
+// GetTagFacets counts the books matching the filter per tag, none as no book is tagged in memory
+func (r *MemoryRepository) GetTagFacets(filter types.BookFilter) ([]types.TagFacet, error) {
+	if _, _, err := tenant.Scope(r.ctx); err != nil {
+		return nil, err
+	}
+
+	return []types.TagFacet{}, nil
+}
+
+// GetBook retrieves a book from memory
+func (r *MemoryRepository) GetBook(id int) (*types.Book, error) {
+	return r.getBook(func(book types.Book) bool {
+		return book.ID == id
+	})
+}
+
+// LockBook retrieves a book from memory by its id. A transaction holds the repository for
+// itself, so the book needs no lock of its own.
+func (r *MemoryRepository) LockBook(id int) (*types.Book, error) {
+	return r.GetBook(id)
+}
+
+// GetBookByISBN retrieves a book from memory by its ISBN-13
+func (r *MemoryRepository) GetBookByISBN(isbn13 string) (*types.Book, error) {
+	return r.getBook(func(book types.Book) bool {
+		return book.ISBN13 == isbn13
+	})
+}
+
+// getBook returns the first book by id accepted by match, or gorm.ErrRecordNotFound.
+func (r *MemoryRepository) getBook(match func(book types.Book) bool) (*types.Book, error) {
+	books, err := r.findBooks(r.ctx, db.SORT_ASC, types.BOOK_SORT_ID, match)
+	if err != nil {
+		return nil, err
+	}
+	if len(books) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	book := books[0]
+	book.Availability = &types.BookAvailability{}
+	return &book, nil
+}
+
+// GetRevisions retrieves the revisions of a book from memory, latest first
+func (r *MemoryRepository) GetRevisions(bookID int, page int, limit int) (*db.Pagination, []types.BookRevision, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "rev desc",
+	}
+
+	revisions, err := r.findRevisions(bookID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.SetTotalRows(int64(len(revisions)))
+	start, end := p.Bounds(len(revisions))
+	return &p, revisions[start:end], nil
+}
+
+// GetRevision retrieves a revision of a book from memory
+func (r *MemoryRepository) GetRevision(bookID int, rev int) (*types.BookRevision, error) {
+	revisions, err := r.findRevisions(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, revision := range revisions {
+		if revision.Rev == rev {
+			return &revision, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// findRevisions returns the revisions of the book in the tenant of the repository, latest first.
+func (r *MemoryRepository) findRevisions(bookID int) ([]types.BookRevision, error) {
+	id, scoped, err := tenant.Scope(r.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []types.BookRevision{}
+	err = r.read(func(d *memoryData) error {
+		for _, revision := range d.revisions {
+			if inScope(revision.TenantID, id, scoped) && revision.BookID == bookID {
+				revisions = append(revisions, revision)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(revisions, func(a, b types.BookRevision) int {
+		return cmp.Compare(b.Rev, a.Rev)
+	})
+	return revisions, nil
+}
+
+// StreamBooks calls fn for a copy of every book matching the filter taken when the stream starts,
+// so fn may take long without blocking the changes.
+func (r *MemoryRepository) StreamBooks(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, fn func(book types.Book) error) error {
+	books, err := r.findBooks(ctx, sortType, sortBy, func(book types.Book) bool {
+		return matchBook(book, filter)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inScope reports whether a row of the tenant is seen by the queries scoped to the tenant id, if
+// scoped, as the tenant plugin does.
+func inScope(tenantID string, id string, scoped bool) bool {
+	return !scoped || tenantID == id
+}
+
+// tenantOf returns the tenant of a created row: the tenant of the queries if scoped, otherwise the
+// tenant of the row, DEFAULT if unset as the default value of the column.
+func tenantOf(tenantID string, id string, scoped bool) string {
+	if scoped {
+		return id
+	}
+	if tenantID == "" {
+		return tenant.DEFAULT
+	}
+
+	return tenantID
+}
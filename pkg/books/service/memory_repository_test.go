@@ -0,0 +1,60 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "school-a")
+	testutil.RunDataProviderTests(t, ctx, func(t *testing.T) types.DataProvider {
+		repo := service.NewMemoryRepository(zap.NewNop())
+		return repo.WithContext(ctx)
+	})
+}
+
+func TestMemoryRepositoryConcurrentChanges(t *testing.T) {
+	repo := service.NewMemoryRepository(zap.NewNop())
+	d := repo.WithContext(tenant.NewContext(context.Background(), tenant.DEFAULT))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := d.Transaction(func(d types.DataProvider) error {
+				book, err := d.CreateBook(types.Book{Title: "title", Author: "author", Description: "description"})
+				if err != nil {
+					return err
+				}
+				return d.RecordChange(context.Background(), types.BookChange{Op: types.CHANGE_OP_CREATE, BookID: book.ID, After: &book})
+			})
+			require.NoError(t, err)
+
+			_, _, err = d.GetBooks(1, 5, db.SORT_DESC, types.BOOK_SORT_ID, types.BookFilter{})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	p, books, err := d.GetBooks(1, 100, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, int64(20), p.TotalRows)
+	for i, book := range books {
+		require.Equal(t, i+1, book.ID)
+
+		_, revisions, err := d.GetRevisions(book.ID, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, revisions, 1)
+	}
+}
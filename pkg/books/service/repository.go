@@ -1,13 +1,16 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
 	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
 )
 
 var (
@@ -16,13 +19,94 @@ var (
 
 // Repository is the data provider that connect to the database being used in a books service.
 type Repository struct {
-	db  *gorm.DB
-	log *zap.Logger
+	db            *gorm.DB
+	log           *zap.Logger
+	classifier    types.BookClassifier
+	inventory     types.BookInventory
+	changeWriters []types.ChangeWriter
 }
 
-// NewRepository creates a new books-service repository
-func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
-	return Repository{db, log}
+// NewRepository creates a new books-service repository. The books are filtered by their tags
+// and subjects with the classifier and listed with the copies counted by the inventory. The
+// change writers are called for every change recorded by the service.
+func NewRepository(db *gorm.DB, log *zap.Logger, classifier types.BookClassifier, inventory types.BookInventory, changeWriters ...types.ChangeWriter) Repository {
+	return Repository{db, log, classifier, inventory, changeWriters}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log, r.classifier, r.inventory, r.changeWriters...)
+	return &repo
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *Repository) Transaction(fn func(d types.DataProvider) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx, r.log, r.classifier, r.inventory, r.changeWriters...)
+		return fn(&repo)
+	})
+}
+
+// RecordChange records the revision of the change and calls the change writers with the current transaction
+func (r *Repository) RecordChange(ctx context.Context, change types.BookChange) error {
+	if err := r.recordRevision(ctx, change); err != nil {
+		return err
+	}
+
+	for _, w := range r.changeWriters {
+		if err := w(ctx, r.db, change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordRevision appends the state of the book after the change to its revisions. Concurrent
+// changes of a book are serialized by the lock of its row, so they get consecutive revisions.
+func (r *Repository) recordRevision(ctx context.Context, change types.BookChange) error {
+	var last types.BookRevision
+	if err := r.db.Where("book_id = ?", change.BookID).Order("rev desc").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+
+	revisions := newRevisions(ctx, last.Rev, change)
+	return r.db.Create(&revisions).Error
+}
+
+// newRevisions returns the revisions recording the change of a book whose last revision is last.
+func newRevisions(ctx context.Context, last int, change types.BookChange) []types.BookRevision {
+	actor := middleware.GetActor(ctx)
+	var revisions []types.BookRevision
+
+	// a book changed for the first time since its creation was recorded keeps its former state
+	// as the first revision.
+	if last == 0 && change.Before != nil {
+		revisions = append(revisions, newRevision(*change.Before, 1, types.CHANGE_OP_CREATE, ""))
+	}
+
+	state := change.After
+	if state == nil {
+		state = change.Before
+	}
+	revision := newRevision(*state, last+len(revisions)+1, change.Op, actor)
+	revision.RestoredFrom = change.RestoredFrom
+	return append(revisions, revision)
+}
+
+func newRevision(book types.Book, rev int, op types.ChangeOperation, actor string) types.BookRevision {
+	return types.BookRevision{
+		BookID:       book.ID,
+		Rev:          rev,
+		Op:           op,
+		Title:        book.Title,
+		Author:       book.Author,
+		Description:  book.Description,
+		ISBN13:       book.ISBN13,
+		ISBN10:       book.ISBN10,
+		BookMetadata: book.BookMetadata,
+		Actor:        actor,
+	}
 }
 
 // CreateBook creates a new book in the database
@@ -31,6 +115,16 @@ func (r *Repository) CreateBook(book types.Book) (types.Book, error) {
 	return book, tx.Error
 }
 
+// CreateBooks creates new books in the database with a single insert statement
+func (r *Repository) CreateBooks(books []types.Book) ([]types.Book, error) {
+	if len(books) == 0 {
+		return books, nil
+	}
+
+	tx := r.db.Create(&books)
+	return books, tx.Error
+}
+
 // UpdateBook updates a book in the database
 func (r *Repository) UpdateBook(book *types.Book) error {
 	return r.db.Save(&book).Error
@@ -42,21 +136,99 @@ func (r *Repository) DeleteBook(book *types.Book) error {
 }
 
 // GetBooks retrieves a list of books from the database
-func (r *Repository) GetBooks(page int, limit int, sortType db.SortType) (*db.Pagination, []types.Book, error) {
+func (r *Repository) GetBooks(page int, limit int, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter) (*db.Pagination, []types.Book, error) {
 	p := db.Pagination{
 		Page:  page,
 		Limit: limit,
-		Sort:  fmt.Sprintf("id %s", sortType),
+		Sort:  bookOrder(sortType, sortBy),
 	}
 
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	query := r.filterBooks(r.db.Model(&types.Book{}), filter).Session(&gorm.Session{})
+
 	var books []types.Book
-	if result := r.db.Scopes(db.Paginate(&books, &p, r.db)).Find(&books); result.Error != nil {
+	if result := query.Scopes(db.Paginate(&books, &p, query)).Find(&books); result.Error != nil {
 		return nil, nil, result.Error
 	}
+	loaded := make([]*types.Book, len(books))
+	for i := range books {
+		loaded[i] = &books[i]
+	}
+	if err := r.loadAvailability(loaded...); err != nil {
+		return nil, nil, err
+	}
 
 	return &p, books, nil
 }
 
+// bookOrder returns the order of the books sorted by the sort field, then by id.
+func bookOrder(sortType db.SortType, sortBy types.BookSortField) string {
+	if sortBy == types.BOOK_SORT_ID {
+		return fmt.Sprintf("id %s", sortType)
+	}
+
+	// books of the same rating keep a stable order across pages.
+	return fmt.Sprintf("%s %s, id %s", sortBy.Column(), sortType, sortType)
+}
+
+// GetTagFacets counts the books matching the filter per tag from the database
+func (r *Repository) GetTagFacets(filter types.BookFilter) ([]types.TagFacet, error) {
+	books := r.filterBooks(r.db.Model(&types.Book{}), filter).Select("books.id")
+
+	facets, err := r.classifier.CountTags(r.db, books)
+	if err != nil {
+		return nil, err
+	}
+	if facets == nil {
+		facets = []types.TagFacet{}
+	}
+
+	return facets, nil
+}
+
+// filterBooks narrows down the books query to the ones matching the filter. The book tags and
+// subjects are found by the classifier.
+func (r *Repository) filterBooks(query *gorm.DB, filter types.BookFilter) *gorm.DB {
+	for _, tag := range filter.Tags {
+		query = query.Where("books.id IN (?)", r.classifier.TaggedBooks(r.db, tag))
+	}
+
+	if filter.SubjectID != 0 {
+		query = query.Where("books.id IN (?)", r.classifier.ClassifiedBooks(r.db, filter.SubjectID))
+	}
+
+	if filter.Publisher != "" {
+		query = query.Where("LOWER(books.publisher) = LOWER(?)", filter.Publisher)
+	}
+	if filter.SeriesName != "" {
+		query = query.Where("LOWER(books.series_name) = LOWER(?)", filter.SeriesName)
+	}
+	if filter.Language != "" {
+		query = query.Where("books.language = ? OR books.language LIKE ?", filter.Language, filter.Language+"-%")
+	}
+	if filter.Format != "" {
+		query = query.Where("books.format = ?", filter.Format)
+	}
+	// an unknown publication date is empty, so it is before any date.
+	if filter.PublishedFrom != "" {
+		query = query.Where("books.published_date >= ?", filter.PublishedFrom)
+	}
+	if filter.PublishedTo != "" {
+		query = query.Where("books.published_date <> '' AND books.published_date <= ?", filter.PublishedTo)
+	}
+	if filter.Currency != "" {
+		query = query.Where("books.currency = ?", filter.Currency)
+	}
+	if filter.MinPrice != 0 {
+		query = query.Where("books.price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice != 0 {
+		query = query.Where("books.price <= ?", filter.MaxPrice)
+	}
+
+	return query
+}
+
 // GetBook retrieves a book from the database
 func (r *Repository) GetBook(id int) (*types.Book, error) {
 	var book types.Book
@@ -64,5 +236,116 @@ func (r *Repository) GetBook(id int) (*types.Book, error) {
 		return nil, result.Error
 	}
 
+	if err := r.loadAvailability(&book); err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+// LockBook retrieves a book from the database with a row lock held until the end of the
+// transaction
+func (r *Repository) LockBook(id int) (*types.Book, error) {
+	var book types.Book
+	if result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&book, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := r.loadAvailability(&book); err != nil {
+		return nil, err
+	}
+
 	return &book, nil
 }
+
+// GetBookByISBN retrieves a book from the database by its ISBN-13
+func (r *Repository) GetBookByISBN(isbn13 string) (*types.Book, error) {
+	var book types.Book
+	if result := r.db.Where("isbn13 = ?", isbn13).First(&book); result.Error != nil {
+		return nil, result.Error
+	}
+
+	if err := r.loadAvailability(&book); err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+// loadAvailability sets the number of copies per status of the books with a single query. The
+// copies are counted by the inventory.
+func (r *Repository) loadAvailability(books ...*types.Book) error {
+	if len(books) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(books))
+	index := make(map[int]*types.BookAvailability, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+		book.Availability = &types.BookAvailability{}
+		index[book.ID] = book.Availability
+	}
+
+	counts, err := r.inventory.CountCopies(r.db, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+		index[c.BookID].Add(c.Status, c.Count)
+	}
+
+	return nil
+}
+
+// GetRevisions retrieves the revisions of a book from the database, latest first
+func (r *Repository) GetRevisions(bookID int, page int, limit int) (*db.Pagination, []types.BookRevision, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "rev desc",
+	}
+
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	query := r.db.Where("book_id = ?", bookID).Session(&gorm.Session{})
+
+	var revisions []types.BookRevision
+	if result := query.Scopes(db.Paginate(&revisions, &p, query)).Find(&revisions); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, revisions, nil
+}
+
+// GetRevision retrieves a revision of a book from the database
+func (r *Repository) GetRevision(bookID int, rev int) (*types.BookRevision, error) {
+	var revision types.BookRevision
+	if result := r.db.Where("book_id = ? AND rev = ?", bookID, rev).First(&revision); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &revision, nil
+}
+
+// StreamBooks iterates over the books matching the filter in the database row by row
+func (r *Repository) StreamBooks(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, fn func(book types.Book) error) error {
+	rows, err := r.filterBooks(r.db.WithContext(ctx).Model(&types.Book{}), filter).Order(bookOrder(sortType, sortBy)).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var book types.Book
+		if err := r.db.ScanRows(rows, &book); err != nil {
+			return err
+		}
+
+		if err := fn(book); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	classificationservice "github.com/nkitlabs/go-http-gorm-example/pkg/classification/service"
+	classificationtypes "github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db/dbtest"
+	inventoryservice "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/service"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "school-a")
+	for _, driver := range dbtest.Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			testutil.RunDataProviderTests(t, ctx, func(t *testing.T) types.DataProvider {
+				// the books are listed with the copies and the tags kept by the other services.
+				gdb := dbtest.Open(t, driver, &types.Book{}, &types.BookRevision{}, &inventorytypes.Copy{}, &classificationtypes.Tag{}, &classificationtypes.BookTag{})
+
+				repo := service.NewRepository(gdb, zap.NewNop(), classificationservice.BookClassifier{}, inventoryservice.BookInventory{})
+				return repo.WithContext(ctx)
+			})
+		})
+	}
+}
+
+// legacyBook is a book as stored before the bibliographic metadata was added.
+type legacyBook struct {
+	ID          int `gorm:"primaryKey;autoIncrement"`
+	TenantID    string
+	Title       string
+	Author      string
+	Description string
+}
+
+// TableName overrides the table name used by gorm.
+func (legacyBook) TableName() string {
+	return "books"
+}
+
+func TestMigrateBooksWithoutMetadata(t *testing.T) {
+	gdb, err := db.Init(config.DBConn{Driver: db.DRIVER_MEMORY}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&legacyBook{}))
+	require.NoError(t, gdb.Create(&legacyBook{TenantID: tenant.DEFAULT, Title: "title", Author: "author", Description: "desc"}).Error)
+
+	// the metadata columns are added with their defaults, which are the unknown metadata of the
+	// existing books, so they need no data migration.
+	require.NoError(t, gdb.AutoMigrate(&types.Book{}, &types.BookRevision{}, &inventorytypes.Copy{}, &classificationtypes.Tag{}, &classificationtypes.BookTag{}))
+
+	repo := service.NewRepository(gdb, zap.NewNop(), classificationservice.BookClassifier{}, inventoryservice.BookInventory{})
+	book, err := repo.WithContext(tenant.NewContext(context.Background(), tenant.DEFAULT)).GetBook(1)
+	require.NoError(t, err)
+	require.Equal(t, "title", book.Title)
+	require.Equal(t, types.BookMetadata{}, book.BookMetadata)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// GetRevisions returns the revisions of a book, latest first. The revisions of a deleted book
+// are kept, so they can still be listed and restored; a book without any revision is not found.
+func (s *Service) GetRevisions(bookID int, page int, limit int) (types.GetRevisionsResponse, error) {
+	pagination, revisions, err := s.dataProvider.GetRevisions(bookID, page, limit)
+	if err != nil {
+		return types.GetRevisionsResponse{}, err
+	}
+	if pagination.TotalRows == 0 {
+		return types.GetRevisionsResponse{}, apierror.NewNotFoundError("revisions not found")
+	}
+
+	return types.GetRevisionsResponse{
+		Revisions:  revisions,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetRevision returns a revision of a book
+func (s *Service) GetRevision(bookID int, rev int) (*types.BookRevision, error) {
+	revision, err := s.dataProvider.GetRevision(bookID, rev)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("revision not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+// DiffRevisions returns the fields changed between two revisions of a book
+func (s *Service) DiffRevisions(bookID int, from int, to int) (types.RevisionDiffResponse, error) {
+	fromRevision, err := s.GetRevision(bookID, from)
+	if err != nil {
+		return types.RevisionDiffResponse{}, err
+	}
+
+	toRevision, err := s.GetRevision(bookID, to)
+	if err != nil {
+		return types.RevisionDiffResponse{}, err
+	}
+
+	fromBook, toBook := fromRevision.Book(), toRevision.Book()
+	change := types.BookChange{Before: &fromBook, After: &toBook}
+
+	return types.RevisionDiffResponse{
+		BookID:  bookID,
+		From:    from,
+		To:      to,
+		Changes: change.Diff(),
+	}, nil
+}
+
+// RestoreBook brings the book back to the given revision, re-creating it if it is deleted.
+// The restore is recorded as a new revision.
+func (s *Service) RestoreBook(ctx context.Context, bookID int, rev int) (types.Book, error) {
+	var restored types.Book
+	err := s.transaction(func(txServ *Service) error {
+		revision, err := txServ.GetRevision(bookID, rev)
+		if err != nil {
+			return err
+		}
+		restored = revision.Book()
+		if err := txServ.checkISBNAvailable(restored); err != nil {
+			return err
+		}
+
+		change := types.BookChange{
+			Op:           types.CHANGE_OP_RESTORE,
+			BookID:       bookID,
+			After:        &restored,
+			RestoredFrom: rev,
+		}
+
+		book, err := txServ.dataProvider.LockBook(bookID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// the deleted book is created again with its former id.
+			if restored, err = txServ.dataProvider.CreateBook(restored); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		} else {
+			before := *book
+			change.Before = &before
+
+			book.Title = restored.Title
+			book.Author = restored.Author
+			book.Description = restored.Description
+			book.ISBN13 = restored.ISBN13
+			book.ISBN10 = restored.ISBN10
+			book.BookMetadata = restored.BookMetadata
+			if err := txServ.dataProvider.UpdateBook(book); err != nil {
+				return err
+			}
+		}
+
+		return txServ.dataProvider.RecordChange(ctx, change)
+	})
+	if err != nil {
+		return types.Book{}, err
+	}
+
+	return restored, nil
+}
@@ -1,9 +1,10 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 
-	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
@@ -26,22 +27,54 @@ func NewService(d types.DataProvider, log *zap.Logger) Service {
 	}
 }
 
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
 // AddBook adds a new book into a system
-func (s *Service) AddBook(req types.AddBookRequest) (types.AddBookResponse, error) {
-	validate := validator.New()
+func (s *Service) AddBook(ctx context.Context, req types.AddBookRequest) (types.AddBookResponse, error) {
+	validate := types.NewValidator()
 	if err := validate.Struct(req); err != nil {
 		return types.AddBookResponse{}, apierror.ConvertValidatorErrorsToError(err)
 	}
 
+	isbn13, isbn10, err := resolveISBN(req.ISBN13, req.ISBN10)
+	if err != nil {
+		return types.AddBookResponse{}, err
+	}
+
 	book := types.Book{
-		Title:       req.Title,
-		Author:      req.Author,
-		Description: req.Description,
+		Title:        req.Title,
+		Author:       req.Author,
+		Description:  req.Description,
+		ISBN13:       isbn13,
+		ISBN10:       isbn10,
+		BookMetadata: req.BookMetadata.Normalize(),
 	}
 
-	book, err := s.dataProvider.CreateBook(book)
+	err = s.transaction(func(txServ *Service) error {
+		if err := txServ.checkISBNAvailable(book); err != nil {
+			return err
+		}
+
+		var err error
+		book, err = txServ.dataProvider.CreateBook(book)
+		if err != nil {
+			return err
+		}
+
+		return txServ.dataProvider.RecordChange(ctx, types.BookChange{
+			Op:     types.CHANGE_OP_CREATE,
+			BookID: book.ID,
+			After:  &book,
+		})
+	})
 	if err != nil {
-		return types.AddBookResponse{}, err
+		return types.AddBookResponse{}, s.isbnConflict(err, types.Book{ISBN13: isbn13})
 	}
 
 	return types.AddBookResponse{
@@ -50,55 +83,145 @@ func (s *Service) AddBook(req types.AddBookRequest) (types.AddBookResponse, erro
 }
 
 // UpdateBook updates a book information in the system
-func (s *Service) UpdateBook(id int, req types.UpdateBookRequest) (types.Book, error) {
-	book, err := s.GetBook(id)
+func (s *Service) UpdateBook(ctx context.Context, id int, req types.UpdateBookRequest) (types.Book, error) {
+	validate := types.NewValidator()
+	if err := validate.Struct(req); err != nil {
+		return types.Book{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	isbn13, isbn10, err := resolveISBN(req.ISBN13, req.ISBN10)
 	if err != nil {
 		return types.Book{}, err
 	}
 
-	if req.Title != "" {
-		book.Title = req.Title
-	}
-	if req.Author != "" {
-		book.Author = req.Author
-	}
-	if req.Description != "" {
-		book.Description = req.Description
-	}
+	var newBook *types.Book
+	err = s.transaction(func(txServ *Service) error {
+		book, err := txServ.lockBook(id)
+		if err != nil {
+			return err
+		}
+		before := *book
 
-	if err := s.dataProvider.UpdateBook(book); err != nil {
-		return types.Book{}, err
-	}
+		if req.Title != "" {
+			book.Title = req.Title
+		}
+		if req.Author != "" {
+			book.Author = req.Author
+		}
+		if req.Description != "" {
+			book.Description = req.Description
+		}
+		book.BookMetadata = book.BookMetadata.Merge(req.BookMetadata.Normalize())
+		if isbn13 != "" {
+			book.ISBN13, book.ISBN10 = isbn13, isbn10
+			if err := txServ.checkISBNAvailable(*book); err != nil {
+				return err
+			}
+		}
+
+		if err := txServ.dataProvider.UpdateBook(book); err != nil {
+			return err
+		}
 
-	newBook, err := s.GetBook(id)
+		newBook, err = txServ.GetBook(id)
+		if err != nil {
+			return err
+		}
+
+		return txServ.dataProvider.RecordChange(ctx, types.BookChange{
+			Op:     types.CHANGE_OP_UPDATE,
+			BookID: id,
+			Before: &before,
+			After:  newBook,
+		})
+	})
 	if err != nil {
-		return types.Book{}, err
+		return types.Book{}, s.isbnConflict(err, types.Book{ID: id, ISBN13: isbn13})
 	}
 
 	return *newBook, nil
 }
 
 // DeleteBook deletes a book id from the system
-func (s *Service) DeleteBook(id int) (types.DeleteBookResponse, error) {
-	book, err := s.GetBook(id)
+func (s *Service) DeleteBook(ctx context.Context, id int) (types.DeleteBookResponse, error) {
+	err := s.transaction(func(txServ *Service) error {
+		book, err := txServ.GetBook(id)
+		if err != nil {
+			return err
+		}
+
+		if err := txServ.dataProvider.DeleteBook(book); err != nil {
+			return err
+		}
+
+		return txServ.dataProvider.RecordChange(ctx, types.BookChange{
+			Op:     types.CHANGE_OP_DELETE,
+			BookID: id,
+			Before: book,
+		})
+	})
 	if err != nil {
 		return types.DeleteBookResponse{}, err
 	}
 
-	return types.DeleteBookResponse{}, s.dataProvider.DeleteBook(book)
+	return types.DeleteBookResponse{}, nil
 }
 
-// GetBooks returns a list of books
-func (s *Service) GetBooks(page int, limit int, sortType db.SortType) (types.GetBooksResponse, error) {
-	pagination, books, err := s.dataProvider.GetBooks(page, limit, sortType)
+// GetBooks returns a list of books matching the filter, with the tag facets if asked
+func (s *Service) GetBooks(page int, limit int, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, withFacets bool) (types.GetBooksResponse, error) {
+	filter, err := normalizeBookQuery(sortBy, filter)
+	if err != nil {
+		return types.GetBooksResponse{}, err
+	}
+
+	pagination, books, err := s.dataProvider.GetBooks(page, limit, sortType, sortBy, filter)
 	if err != nil {
 		return types.GetBooksResponse{}, err
 	}
 
-	return types.GetBooksResponse{
+	resp := types.GetBooksResponse{
 		Books:      books,
 		Pagination: pagination,
-	}, nil
+	}
+
+	if withFacets {
+		tags, err := s.dataProvider.GetTagFacets(filter)
+		if err != nil {
+			return types.GetBooksResponse{}, err
+		}
+		resp.Facets = &types.BookFacets{Tags: tags}
+	}
+
+	return resp, nil
+}
+
+// ExportBooks calls fn for every book matching the filter in the given order
+func (s *Service) ExportBooks(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, fn func(book types.Book) error) error {
+	filter, err := normalizeBookQuery(sortBy, filter)
+	if err != nil {
+		return err
+	}
+
+	return s.dataProvider.StreamBooks(ctx, sortType, sortBy, filter, fn)
+}
+
+// normalizeBookQuery validates the sort field and the filter of the listed books and returns the
+// filter with its values in the form they are stored.
+func normalizeBookQuery(sortBy types.BookSortField, filter types.BookFilter) (types.BookFilter, error) {
+	validate := types.NewValidator()
+	if err := validate.Struct(filter); err != nil {
+		return types.BookFilter{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+	if sortBy.Column() == "" {
+		return types.BookFilter{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("unsupported sort: %s", sortBy))
+	}
+
+	for i, tag := range filter.Tags {
+		filter.Tags[i] = types.NormalizeTag(tag)
+	}
+	filter.Language = types.BookMetadata{Language: filter.Language}.Normalize().Language
+
+	return filter, nil
 }
 
 // GetBook returns a book information from the given id
@@ -112,3 +235,25 @@ func (s *Service) GetBook(id int) (*types.Book, error) {
 
 	return book, nil
 }
+
+// lockBook returns a book from the given id and locks it until the end of the transaction, so a
+// concurrent change of the book waits for this one and starts from the book it leaves
+func (s *Service) lockBook(id int) (*types.Book, error) {
+	book, err := s.dataProvider.LockBook(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("book not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// transaction runs fn with a service bound to a single database transaction, so the
+// changes made by fn are recorded atomically with them.
+func (s *Service) transaction(fn func(txServ *Service) error) error {
+	return s.dataProvider.Transaction(func(d types.DataProvider) error {
+		txServ := NewService(d, s.log)
+		return fn(&txServ)
+	})
+}
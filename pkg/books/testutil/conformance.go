@@ -0,0 +1,530 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// RunDataProviderTests checks that a data provider behaves as the books service expects, so every
+// implementation of types.DataProvider can be used in place of the others. newDataProvider returns
+// an empty data provider for every test. ctx is the context of the requests given to the data
+// providers, as the service passes it along with the changes and the streams.
+func RunDataProviderTests(t *testing.T, ctx context.Context, newDataProvider func(t *testing.T) types.DataProvider) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, d types.DataProvider)
+	}{
+		{"create and get book", testCreateAndGetBook},
+		{"missing book", testMissingBook},
+		{"create books", testCreateBooks},
+		{"duplicate isbn", testDuplicateISBN},
+		{"update book", testUpdateBook},
+		{"delete book", testDeleteBook},
+		{"re-create deleted book", testRecreateDeletedBook},
+		{"tenant isolation", testTenantIsolation},
+		{"paginate books", testPaginateBooks},
+		{"sort books", testSortBooks},
+		{"filter books", testFilterBooks},
+		{"tag facets without tags", testTagFacetsWithoutTags},
+		{"commit transaction", testCommitTransaction},
+		{"rollback transaction", testRollbackTransaction},
+		{"lock book", testLockBook},
+		{"record changes", testRecordChanges},
+		{"paginate revisions", testPaginateRevisions},
+		{"stream books", testStreamBooks},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, ctx, newDataProvider(t))
+		})
+	}
+}
+
+func newBook(n int) types.Book {
+	return types.Book{
+		Title:       fmt.Sprintf("title-%d", n),
+		Author:      fmt.Sprintf("author-%d", n),
+		Description: fmt.Sprintf("description-%d", n),
+	}
+}
+
+// createBooks creates n books one by one and returns them in the order of their creation.
+func createBooks(t *testing.T, d types.DataProvider, n int) []types.Book {
+	books := make([]types.Book, n)
+	for i := range books {
+		book, err := d.CreateBook(newBook(i + 1))
+		require.NoError(t, err)
+		books[i] = book
+	}
+
+	return books
+}
+
+// loaded returns the book as it is loaded by a data provider, with the availability of its copies.
+func loaded(book types.Book) types.Book {
+	book.Availability = &types.BookAvailability{}
+	return book
+}
+
+func ids(books []types.Book) []int {
+	ids := make([]int, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	return ids
+}
+
+func testCreateAndGetBook(t *testing.T, ctx context.Context, d types.DataProvider) {
+	book := newBook(1)
+	book.ISBN13 = "9780306406157"
+	book.ISBN10 = "0306406152"
+	book.BookMetadata = types.BookMetadata{
+		Publisher:      "Bloomsbury",
+		PublishedDate:  "1997-06-26",
+		Edition:        "1st",
+		Language:       "en-GB",
+		PageCount:      223,
+		Format:         types.BOOK_FORMAT_HARDCOVER,
+		SeriesName:     "Harry Potter",
+		SeriesPosition: 1,
+		Price:          1299,
+		Currency:       "GBP",
+	}
+	book.Cover = &types.BookCover{Checksum: "checksum"}
+
+	created, err := d.CreateBook(book)
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	got, err := d.GetBook(created.ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(created), *got)
+
+	got, err = d.GetBookByISBN(book.ISBN13)
+	require.NoError(t, err)
+	require.Equal(t, loaded(created), *got)
+}
+
+func testMissingBook(t *testing.T, ctx context.Context, d types.DataProvider) {
+	createBooks(t, d, 1)
+
+	_, err := d.GetBook(1000)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = d.GetBookByISBN("9780306406157")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = d.GetRevision(1000, 1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testCreateBooks(t *testing.T, ctx context.Context, d types.DataProvider) {
+	first := createBooks(t, d, 1)[0]
+
+	created, err := d.CreateBooks([]types.Book{newBook(2), newBook(3)})
+	require.NoError(t, err)
+	require.Len(t, created, 2)
+	// ids are increasing and never reused.
+	require.Greater(t, created[0].ID, first.ID)
+	require.Greater(t, created[1].ID, created[0].ID)
+
+	for _, book := range created {
+		got, err := d.GetBook(book.ID)
+		require.NoError(t, err)
+		require.Equal(t, loaded(book), *got)
+	}
+
+	created, err = d.CreateBooks(nil)
+	require.NoError(t, err)
+	require.Empty(t, created)
+}
+
+func testDuplicateISBN(t *testing.T, ctx context.Context, d types.DataProvider) {
+	book := newBook(1)
+	book.ISBN13 = "9780306406157"
+	_, err := d.CreateBook(book)
+	require.NoError(t, err)
+
+	_, err = d.CreateBook(book)
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+
+	// books without ISBN don't conflict.
+	_, err = d.CreateBooks([]types.Book{newBook(2), newBook(3)})
+	require.NoError(t, err)
+}
+
+func testUpdateBook(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 2)
+
+	book := books[0]
+	book.Title = "updated-title"
+	book.Publisher = "updated-publisher"
+	require.NoError(t, d.UpdateBook(&book))
+
+	got, err := d.GetBook(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(book), *got)
+
+	got, err = d.GetBook(books[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(books[1]), *got)
+}
+
+func testDeleteBook(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 2)
+
+	require.NoError(t, d.DeleteBook(&books[0]))
+	_, err := d.GetBook(books[0].ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// deleting a missing book is not an error.
+	require.NoError(t, d.DeleteBook(&books[0]))
+
+	got, err := d.GetBook(books[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(books[1]), *got)
+
+	// the id of a deleted book is not reused.
+	created, err := d.CreateBook(newBook(3))
+	require.NoError(t, err)
+	require.Greater(t, created.ID, books[1].ID)
+}
+
+func testRecreateDeletedBook(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 2)
+	require.NoError(t, d.DeleteBook(&books[0]))
+
+	// a restored book keeps its id.
+	created, err := d.CreateBook(books[0])
+	require.NoError(t, err)
+	require.Equal(t, books[0].ID, created.ID)
+
+	got, err := d.GetBook(books[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(books[0]), *got)
+
+	_, err = d.CreateBook(books[1])
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func testTenantIsolation(t *testing.T, ctx context.Context, d types.DataProvider) {
+	book := newBook(1)
+	book.ISBN13 = "9780306406157"
+	book, err := d.CreateBook(book)
+	require.NoError(t, err)
+
+	other := d.WithContext(tenant.NewContext(ctx, "another-tenant"))
+	_, err = other.GetBook(book.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	p, _, err := other.GetBooks(1, 10, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{})
+	require.NoError(t, err)
+	require.Zero(t, p.TotalRows)
+
+	// the book of another tenant is neither updated nor deleted.
+	updated := book
+	updated.Title = "updated-title"
+	require.NoError(t, other.UpdateBook(&updated))
+	require.NoError(t, other.DeleteBook(&book))
+	got, err := d.GetBook(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(book), *got)
+
+	// the ISBN of a book is unique within its tenant only.
+	otherBook := newBook(2)
+	otherBook.ISBN13 = book.ISBN13
+	otherBook, err = other.CreateBook(otherBook)
+	require.NoError(t, err)
+
+	got, err = other.GetBookByISBN(book.ISBN13)
+	require.NoError(t, err)
+	require.Equal(t, otherBook.ID, got.ID)
+
+	_, err = d.WithContext(context.Background()).GetBook(book.ID)
+	require.ErrorIs(t, err, tenant.ErrMissingTenant)
+}
+
+func testPaginateBooks(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 5)
+
+	p, got, err := d.GetBooks(2, 2, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, &db.Pagination{Limit: 2, Page: 2, Sort: "id asc", TotalRows: 5, TotalPages: 3}, p)
+	require.Equal(t, []types.Book{loaded(books[2]), loaded(books[3])}, got)
+
+	p, got, err = d.GetBooks(3, 2, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, 3, p.Page)
+	require.Equal(t, []types.Book{loaded(books[4])}, got)
+
+	// a page past the last one is empty.
+	p, got, err = d.GetBooks(4, 2, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), p.TotalRows)
+	require.Empty(t, got)
+}
+
+func testSortBooks(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 3)
+
+	p, got, err := d.GetBooks(1, 10, db.SORT_DESC, types.BOOK_SORT_ID, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, "id desc", p.Sort)
+	require.Equal(t, []int{books[2].ID, books[1].ID, books[0].ID}, ids(got))
+
+	// the books without rating are ordered by id.
+	p, got, err = d.GetBooks(1, 10, db.SORT_DESC, types.BOOK_SORT_RATING, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, "rating_average desc, id desc", p.Sort)
+	require.Equal(t, []int{books[2].ID, books[1].ID, books[0].ID}, ids(got))
+
+	p, got, err = d.GetBooks(1, 10, db.SORT_ASC, types.BOOK_SORT_RATING_COUNT, types.BookFilter{})
+	require.NoError(t, err)
+	require.Equal(t, "rating_count asc, id asc", p.Sort)
+	require.Equal(t, []int{books[0].ID, books[1].ID, books[2].ID}, ids(got))
+
+	// the books are exported in the order they are listed.
+	for _, sortBy := range []types.BookSortField{types.BOOK_SORT_ID, types.BOOK_SORT_RATING, types.BOOK_SORT_RATING_COUNT} {
+		_, got, err = d.GetBooks(1, 10, db.SORT_DESC, sortBy, types.BookFilter{})
+		require.NoError(t, err)
+		require.Equal(t, ids(got), streamIDs(t, ctx, d, db.SORT_DESC, sortBy, types.BookFilter{}))
+	}
+}
+
+// streamIDs returns the ids of the books streamed by the data provider.
+func streamIDs(t *testing.T, ctx context.Context, d types.DataProvider, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter) []int {
+	streamed := []int{}
+	err := d.StreamBooks(ctx, sortType, sortBy, filter, func(book types.Book) error {
+		streamed = append(streamed, book.ID)
+		return nil
+	})
+	require.NoError(t, err)
+
+	return streamed
+}
+
+func testFilterBooks(t *testing.T, ctx context.Context, d types.DataProvider) {
+	metadata := []types.BookMetadata{
+		{Publisher: "Bloomsbury", Language: "en-GB", Format: types.BOOK_FORMAT_HARDCOVER, PublishedDate: "1997-06-26", Price: 1299, Currency: "GBP", SeriesName: "Harry Potter"},
+		{Publisher: "bloomsbury", Language: "en", Format: types.BOOK_FORMAT_PAPERBACK, PublishedDate: "1998-07-02", Price: 899, Currency: "GBP"},
+		{Publisher: "Scholastic", Language: "eng", Format: types.BOOK_FORMAT_EBOOK, Price: 999, Currency: "USD", SeriesName: "harry potter"},
+		{Language: "fr", Format: types.BOOK_FORMAT_HARDCOVER, PublishedDate: "2001-01-01"},
+	}
+	books := make([]types.Book, len(metadata))
+	for i, m := range metadata {
+		book := newBook(i + 1)
+		book.BookMetadata = m
+		created, err := d.CreateBook(book)
+		require.NoError(t, err)
+		books[i] = created
+	}
+
+	testCases := []struct {
+		name   string
+		filter types.BookFilter
+		want   []int
+	}{
+		{"publisher regardless of case", types.BookFilter{Publisher: "BLOOMSBURY"}, []int{books[0].ID, books[1].ID}},
+		{"series regardless of case", types.BookFilter{SeriesName: "Harry Potter"}, []int{books[0].ID, books[2].ID}},
+		{"language and its subtags", types.BookFilter{Language: "en"}, []int{books[0].ID, books[1].ID}},
+		{"format", types.BookFilter{Format: types.BOOK_FORMAT_HARDCOVER}, []int{books[0].ID, books[3].ID}},
+		{"published from", types.BookFilter{PublishedFrom: "1998-07-02"}, []int{books[1].ID, books[3].ID}},
+		{"published to excludes unknown dates", types.BookFilter{PublishedTo: "1998-07-02"}, []int{books[0].ID, books[1].ID}},
+		{"price range", types.BookFilter{Currency: "GBP", MinPrice: 900, MaxPrice: 1300}, []int{books[0].ID}},
+		{"currency", types.BookFilter{Currency: "USD"}, []int{books[2].ID}},
+		{"all filters", types.BookFilter{Publisher: "bloomsbury", Format: types.BOOK_FORMAT_PAPERBACK, Currency: "GBP", MaxPrice: 1000}, []int{books[1].ID}},
+		{"no match", types.BookFilter{Publisher: "unknown"}, []int{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, got, err := d.GetBooks(1, 10, db.SORT_ASC, types.BOOK_SORT_ID, tc.filter)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, ids(got))
+			require.Equal(t, int64(len(tc.want)), p.TotalRows)
+
+			// the exported books are the listed ones.
+			require.Equal(t, tc.want, streamIDs(t, ctx, d, db.SORT_ASC, types.BOOK_SORT_ID, tc.filter))
+		})
+	}
+}
+
+func testTagFacetsWithoutTags(t *testing.T, ctx context.Context, d types.DataProvider) {
+	createBooks(t, d, 2)
+
+	facets, err := d.GetTagFacets(types.BookFilter{})
+	require.NoError(t, err)
+	require.Empty(t, facets)
+}
+
+func testCommitTransaction(t *testing.T, ctx context.Context, d types.DataProvider) {
+	var created types.Book
+	err := d.Transaction(func(d types.DataProvider) error {
+		var err error
+		created, err = d.CreateBook(newBook(1))
+		if err != nil {
+			return err
+		}
+
+		// the book is visible within the transaction.
+		_, err = d.GetBook(created.ID)
+		return err
+	})
+	require.NoError(t, err)
+
+	got, err := d.GetBook(created.ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(created), *got)
+}
+
+func testRollbackTransaction(t *testing.T, ctx context.Context, d types.DataProvider) {
+	book := createBooks(t, d, 1)[0]
+	errFailed := errors.New("failed")
+
+	var created types.Book
+	err := d.Transaction(func(d types.DataProvider) error {
+		var err error
+		if created, err = d.CreateBook(newBook(2)); err != nil {
+			return err
+		}
+
+		updated := book
+		updated.Title = "updated-title"
+		if err := d.UpdateBook(&updated); err != nil {
+			return err
+		}
+		if err := d.RecordChange(ctx, types.BookChange{Op: types.CHANGE_OP_UPDATE, BookID: book.ID, Before: &book, After: &updated}); err != nil {
+			return err
+		}
+
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	_, err = d.GetBook(created.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	got, err := d.GetBook(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, loaded(book), *got)
+
+	p, _, err := d.GetRevisions(book.ID, 1, 10)
+	require.NoError(t, err)
+	require.Zero(t, p.TotalRows)
+}
+
+func testLockBook(t *testing.T, ctx context.Context, d types.DataProvider) {
+	book := createBooks(t, d, 1)[0]
+
+	err := d.Transaction(func(d types.DataProvider) error {
+		got, err := d.LockBook(book.ID)
+		if err != nil {
+			return err
+		}
+		require.Equal(t, loaded(book), *got)
+
+		_, err = d.LockBook(1000)
+		require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func testRecordChanges(t *testing.T, ctx context.Context, d types.DataProvider) {
+	ctx = context.WithValue(ctx, middleware.ContextKeyActor, "alice")
+	book := createBooks(t, d, 1)[0]
+
+	// a book changed before its creation was recorded gets its former state as first revision.
+	updated := book
+	updated.Title = "updated-title"
+	require.NoError(t, d.RecordChange(ctx, types.BookChange{Op: types.CHANGE_OP_UPDATE, BookID: book.ID, Before: &book, After: &updated}))
+	require.NoError(t, d.RecordChange(ctx, types.BookChange{Op: types.CHANGE_OP_DELETE, BookID: book.ID, Before: &updated}))
+	require.NoError(t, d.RecordChange(ctx, types.BookChange{Op: types.CHANGE_OP_RESTORE, BookID: book.ID, After: &book, RestoredFrom: 1}))
+
+	want := []types.BookRevision{
+		{BookID: book.ID, Rev: 4, Op: types.CHANGE_OP_RESTORE, Title: book.Title, Author: book.Author, Description: book.Description, RestoredFrom: 1, Actor: "alice"},
+		{BookID: book.ID, Rev: 3, Op: types.CHANGE_OP_DELETE, Title: updated.Title, Author: book.Author, Description: book.Description, Actor: "alice"},
+		{BookID: book.ID, Rev: 2, Op: types.CHANGE_OP_UPDATE, Title: updated.Title, Author: book.Author, Description: book.Description, Actor: "alice"},
+		{BookID: book.ID, Rev: 1, Op: types.CHANGE_OP_CREATE, Title: book.Title, Author: book.Author, Description: book.Description},
+	}
+
+	p, got, err := d.GetRevisions(book.ID, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, &db.Pagination{Limit: 10, Page: 1, Sort: "rev desc", TotalRows: 4, TotalPages: 1}, p)
+	require.Len(t, got, len(want))
+	for i, r := range got {
+		require.NotZero(t, r.CreatedAt)
+		r.ID, r.TenantID, r.CreatedAt = want[i].ID, want[i].TenantID, want[i].CreatedAt
+		require.Equal(t, want[i], r)
+	}
+
+	r, err := d.GetRevision(book.ID, 2)
+	require.NoError(t, err)
+	require.Equal(t, types.CHANGE_OP_UPDATE, r.Op)
+	require.Equal(t, updated.Title, r.Title)
+
+	_, err = d.GetRevision(book.ID, 5)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testPaginateRevisions(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 2)
+	for _, book := range books {
+		for i := 0; i < 3; i++ {
+			change := types.BookChange{Op: types.CHANGE_OP_CREATE, BookID: book.ID, After: &book}
+			require.NoError(t, d.RecordChange(ctx, change))
+		}
+	}
+
+	p, got, err := d.GetRevisions(books[1].ID, 2, 2)
+	require.NoError(t, err)
+	require.Equal(t, &db.Pagination{Limit: 2, Page: 2, Sort: "rev desc", TotalRows: 3, TotalPages: 2}, p)
+	require.Len(t, got, 1)
+	require.Equal(t, books[1].ID, got[0].BookID)
+	require.Equal(t, 1, got[0].Rev)
+	require.Equal(t, middleware.ActorAnonymous, got[0].Actor)
+}
+
+func testStreamBooks(t *testing.T, ctx context.Context, d types.DataProvider) {
+	books := createBooks(t, d, 3)
+
+	var streamed []int
+	err := d.StreamBooks(ctx, db.SORT_DESC, types.BOOK_SORT_ID, types.BookFilter{}, func(book types.Book) error {
+		streamed = append(streamed, book.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int{books[2].ID, books[1].ID, books[0].ID}, streamed)
+
+	// the stream stops at the first error.
+	errStop := errors.New("stop")
+	streamed = nil
+	err = d.StreamBooks(ctx, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{}, func(book types.Book) error {
+		streamed = append(streamed, book.ID)
+		if len(streamed) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []int{books[0].ID, books[1].ID}, streamed)
+
+	// the streamed books are the stored ones.
+	err = d.StreamBooks(ctx, db.SORT_ASC, types.BOOK_SORT_ID, types.BookFilter{}, func(book types.Book) error {
+		require.Equal(t, books[0], book)
+		return errStop
+	})
+	require.ErrorIs(t, err, errStop)
+}
@@ -0,0 +1,40 @@
+package testutil
+
+import (
+	"net/http"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// MemoryTestSuite is a books handler serving the books of an in-memory repository, for the tests
+// checking the behavior of several requests rather than their queries.
+type MemoryTestSuite struct {
+	Handler    *service.Handler
+	Service    *service.Service
+	Repository *service.MemoryRepository
+	Logger     *zap.Logger
+	// Router serves the books API in the tenant of a deployment without tenancy.
+	Router http.Handler
+}
+
+func NewMemoryTestSuite(t *testing.T) MemoryTestSuite {
+	repo := service.NewMemoryRepository(zap.NewNop())
+	log := zap.NewNop()
+	serv := service.NewService(&repo, log)
+	handler := service.NewHandler(&serv, log)
+
+	router := service.InitializeRoutes(http.NewServeMux(), handler)
+
+	return MemoryTestSuite{
+		Handler:    &handler,
+		Service:    &serv,
+		Repository: &repo,
+		Logger:     log,
+		Router:     tenant.Middleware(config.Tenancy{}, log)(router),
+	}
+}
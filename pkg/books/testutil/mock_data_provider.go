@@ -10,6 +10,7 @@
 package testutil
 
 import (
+	context "context"
 	reflect "reflect"
 
 	types "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
@@ -55,6 +56,21 @@ func (mr *MockDataProviderMockRecorder) CreateBook(book any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBook", reflect.TypeOf((*MockDataProvider)(nil).CreateBook), book)
 }
 
+// CreateBooks mocks base method.
+func (m *MockDataProvider) CreateBooks(books []types.Book) ([]types.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBooks", books)
+	ret0, _ := ret[0].([]types.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBooks indicates an expected call of CreateBooks.
+func (mr *MockDataProviderMockRecorder) CreateBooks(books any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBooks", reflect.TypeOf((*MockDataProvider)(nil).CreateBooks), books)
+}
+
 // DeleteBook mocks base method.
 func (m *MockDataProvider) DeleteBook(book *types.Book) error {
 	m.ctrl.T.Helper()
@@ -84,10 +100,25 @@ func (mr *MockDataProviderMockRecorder) GetBook(id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBook", reflect.TypeOf((*MockDataProvider)(nil).GetBook), id)
 }
 
+// GetBookByISBN mocks base method.
+func (m *MockDataProvider) GetBookByISBN(isbn13 string) (*types.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookByISBN", isbn13)
+	ret0, _ := ret[0].(*types.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookByISBN indicates an expected call of GetBookByISBN.
+func (mr *MockDataProviderMockRecorder) GetBookByISBN(isbn13 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookByISBN", reflect.TypeOf((*MockDataProvider)(nil).GetBookByISBN), isbn13)
+}
+
 // GetBooks mocks base method.
-func (m *MockDataProvider) GetBooks(page, limit int, sortType db.SortType) (*db.Pagination, []types.Book, error) {
+func (m *MockDataProvider) GetBooks(page, limit int, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter) (*db.Pagination, []types.Book, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBooks", page, limit, sortType)
+	ret := m.ctrl.Call(m, "GetBooks", page, limit, sortType, sortBy, filter)
 	ret0, _ := ret[0].(*db.Pagination)
 	ret1, _ := ret[1].([]types.Book)
 	ret2, _ := ret[2].(error)
@@ -95,9 +126,112 @@ func (m *MockDataProvider) GetBooks(page, limit int, sortType db.SortType) (*db.
 }
 
 // GetBooks indicates an expected call of GetBooks.
-func (mr *MockDataProviderMockRecorder) GetBooks(page, limit, sortType any) *gomock.Call {
+func (mr *MockDataProviderMockRecorder) GetBooks(page, limit, sortType, sortBy, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBooks", reflect.TypeOf((*MockDataProvider)(nil).GetBooks), page, limit, sortType, sortBy, filter)
+}
+
+// GetRevision mocks base method.
+func (m *MockDataProvider) GetRevision(bookID, rev int) (*types.BookRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevision", bookID, rev)
+	ret0, _ := ret[0].(*types.BookRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRevision indicates an expected call of GetRevision.
+func (mr *MockDataProviderMockRecorder) GetRevision(bookID, rev any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevision", reflect.TypeOf((*MockDataProvider)(nil).GetRevision), bookID, rev)
+}
+
+// GetRevisions mocks base method.
+func (m *MockDataProvider) GetRevisions(bookID, page, limit int) (*db.Pagination, []types.BookRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevisions", bookID, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.BookRevision)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetRevisions indicates an expected call of GetRevisions.
+func (mr *MockDataProviderMockRecorder) GetRevisions(bookID, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevisions", reflect.TypeOf((*MockDataProvider)(nil).GetRevisions), bookID, page, limit)
+}
+
+// GetTagFacets mocks base method.
+func (m *MockDataProvider) GetTagFacets(filter types.BookFilter) ([]types.TagFacet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTagFacets", filter)
+	ret0, _ := ret[0].([]types.TagFacet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTagFacets indicates an expected call of GetTagFacets.
+func (mr *MockDataProviderMockRecorder) GetTagFacets(filter any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBooks", reflect.TypeOf((*MockDataProvider)(nil).GetBooks), page, limit, sortType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagFacets", reflect.TypeOf((*MockDataProvider)(nil).GetTagFacets), filter)
+}
+
+// LockBook mocks base method.
+func (m *MockDataProvider) LockBook(id int) (*types.Book, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBook", id)
+	ret0, _ := ret[0].(*types.Book)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockBook indicates an expected call of LockBook.
+func (mr *MockDataProviderMockRecorder) LockBook(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBook", reflect.TypeOf((*MockDataProvider)(nil).LockBook), id)
+}
+
+// RecordChange mocks base method.
+func (m *MockDataProvider) RecordChange(ctx context.Context, change types.BookChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordChange indicates an expected call of RecordChange.
+func (mr *MockDataProviderMockRecorder) RecordChange(ctx, change any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordChange", reflect.TypeOf((*MockDataProvider)(nil).RecordChange), ctx, change)
+}
+
+// StreamBooks mocks base method.
+func (m *MockDataProvider) StreamBooks(ctx context.Context, sortType db.SortType, sortBy types.BookSortField, filter types.BookFilter, fn func(types.Book) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamBooks", ctx, sortType, sortBy, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamBooks indicates an expected call of StreamBooks.
+func (mr *MockDataProviderMockRecorder) StreamBooks(ctx, sortType, sortBy, filter, fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamBooks", reflect.TypeOf((*MockDataProvider)(nil).StreamBooks), ctx, sortType, sortBy, filter, fn)
+}
+
+// Transaction mocks base method.
+func (m *MockDataProvider) Transaction(fn func(types.DataProvider) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataProviderMockRecorder) Transaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataProvider)(nil).Transaction), fn)
 }
 
 // UpdateBook mocks base method.
@@ -113,3 +247,17 @@ func (mr *MockDataProviderMockRecorder) UpdateBook(book any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBook", reflect.TypeOf((*MockDataProvider)(nil).UpdateBook), book)
 }
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
 )
 
 type TestSuite struct {
@@ -20,6 +21,7 @@ func NewTestSuite(t *testing.T) TestSuite {
 	ctrl := gomock.NewController(t)
 
 	repo := NewMockDataProvider(ctrl)
+	repo.EXPECT().WithContext(gomock.Any()).Return(repo).AnyTimes()
 	log := zap.NewNop()
 	serv := service.NewService(repo, log)
 	handler := service.NewHandler(&serv, log)
@@ -31,3 +33,10 @@ func NewTestSuite(t *testing.T) TestSuite {
 		Logger:     log,
 	}
 }
+
+// ExpectTransaction expects a transaction that runs its function against the mock repository.
+func (s TestSuite) ExpectTransaction() *gomock.Call {
+	return s.Repository.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(types.DataProvider) error) error {
+		return fn(s.Repository)
+	})
+}
@@ -0,0 +1,56 @@
+package types
+
+import "gorm.io/gorm"
+
+// CopyStatus is the status of a physical copy of a book, see the inventory service.
+type CopyStatus string
+
+const (
+	COPY_STATUS_AVAILABLE CopyStatus = "available"
+	COPY_STATUS_ON_LOAN   CopyStatus = "on_loan"
+	// COPY_STATUS_ON_HOLD is a returned copy kept for the member at the head of the hold queue.
+	COPY_STATUS_ON_HOLD CopyStatus = "on_hold"
+	COPY_STATUS_LOST    CopyStatus = "lost"
+	COPY_STATUS_REPAIR  CopyStatus = "repair"
+)
+
+// BookAvailability is the number of physical copies of a book per status.
+type BookAvailability struct {
+	Total     int64 `json:"total" yaml:"total" xml:"total" example:"4"`
+	Available int64 `json:"available" yaml:"available" xml:"available" example:"2"`
+	OnLoan    int64 `json:"on_loan" yaml:"on_loan" xml:"on_loan" example:"1"`
+	OnHold    int64 `json:"on_hold" yaml:"on_hold" xml:"on_hold" example:"0"`
+	Lost      int64 `json:"lost" yaml:"lost" xml:"lost" example:"0"`
+	Repair    int64 `json:"repair" yaml:"repair" xml:"repair" example:"1"`
+}
+
+// Add counts copies of the given status.
+func (a *BookAvailability) Add(status CopyStatus, count int64) {
+	a.Total += count
+	switch status {
+	case COPY_STATUS_AVAILABLE:
+		a.Available += count
+	case COPY_STATUS_ON_LOAN:
+		a.OnLoan += count
+	case COPY_STATUS_ON_HOLD:
+		a.OnHold += count
+	case COPY_STATUS_LOST:
+		a.Lost += count
+	case COPY_STATUS_REPAIR:
+		a.Repair += count
+	}
+}
+
+// CopyCount is the number of copies of a book with a status.
+type CopyCount struct {
+	BookID int
+	Status CopyStatus
+	Count  int64
+}
+
+// BookInventory counts the copies of the books, which are managed by the inventory service. The
+// queries run on db, the database or the transaction of the books query.
+type BookInventory interface {
+	// CountCopies counts the copies of the books per status.
+	CountCopies(db *gorm.DB, bookIDs []int) ([]CopyCount, error)
+}
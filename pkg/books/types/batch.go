@@ -0,0 +1,48 @@
+package types
+
+import "encoding/json"
+
+type BatchMode string
+
+const (
+	// BATCH_MODE_ATOMIC rolls back every operation if any of them fails.
+	BATCH_MODE_ATOMIC BatchMode = "atomic"
+	// BATCH_MODE_PER_ITEM keeps the successful operations even if others fail.
+	BATCH_MODE_PER_ITEM BatchMode = "per_item"
+)
+
+type BatchOperationType string
+
+const (
+	BATCH_OP_CREATE BatchOperationType = "create"
+	BATCH_OP_UPDATE BatchOperationType = "update"
+	BATCH_OP_DELETE BatchOperationType = "delete"
+)
+
+// BatchOperation is a single create, update or delete operation of a batch. Body holds
+// an AddBookRequest for create and an UpdateBookRequest for update.
+type BatchOperation struct {
+	Op   BatchOperationType `json:"op" example:"update" enums:"create,update,delete"`
+	ID   int                `json:"id,omitempty" example:"1"`
+	Body json.RawMessage    `json:"body,omitempty" swaggertype:"object"`
+}
+
+// BatchRequest is the request for running several operations at once
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode" example:"atomic" enums:"atomic,per_item"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperationResult is the status and the body that the single-item endpoint of
+// the operation would have returned.
+type BatchOperationResult struct {
+	Status int `json:"status" example:"200"`
+	Body   any `json:"body"`
+}
+
+// BatchResponse is the response for running several operations at once
+type BatchResponse struct {
+	Mode      BatchMode              `json:"mode" example:"atomic"`
+	Committed bool                   `json:"committed" example:"true"`
+	Results   []BatchOperationResult `json:"results"`
+}
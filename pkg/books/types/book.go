@@ -1,9 +1,36 @@
 package types
 
+import "strconv"
+
 // Book is the model for a book information.
 type Book struct {
-	ID          int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
-	Title       string `json:"title" example:"example-title" validate:"required"`
-	Author      string `json:"author" example:"John Doe" validate:"required"`
-	Description string `json:"description" example:"this is an example description" validate:"required"`
+	ID          int    `json:"id" yaml:"id" xml:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID    string `json:"-" yaml:"-" xml:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_books_tenant_isbn13,where:isbn13 <> ''"`
+	Title       string `json:"title" yaml:"title" xml:"title" example:"example-title" validate:"required"`
+	Author      string `json:"author" yaml:"author" xml:"author" example:"John Doe" validate:"required"`
+	Description string `json:"description" yaml:"description" xml:"description" example:"this is an example description" validate:"required"`
+	// ISBN13 identifies the book for the suppliers; the ISBN-10 of a book is converted into it.
+	ISBN13 string `json:"isbn13,omitempty" yaml:"isbn13,omitempty" xml:"isbn13,omitempty" gorm:"column:isbn13;not null;default:'';uniqueIndex:idx_books_tenant_isbn13" example:"9780306406157"`
+	// ISBN10 is empty for an ISBN-13 without ISBN-10.
+	ISBN10       string `json:"isbn10,omitempty" yaml:"isbn10,omitempty" xml:"isbn10,omitempty" gorm:"column:isbn10;not null;default:''" example:"0306406152"`
+	BookMetadata `yaml:",inline"`
+	BookRating   `yaml:",inline"`
+	// Cover is nil for a book without a cover image; it is set by the covers service.
+	Cover *BookCover `json:"cover,omitempty" yaml:"cover,omitempty" xml:"cover,omitempty" gorm:"serializer:json"`
+	// Availability counts the copies of the book held by the inventory service. It is set on the
+	// books loaded by id, by ISBN and as a list.
+	Availability *BookAvailability `json:"availability,omitempty" yaml:"availability,omitempty" xml:"availability,omitempty" gorm:"-"`
+}
+
+// BookCSVHeader is the header row of books represented as CSV.
+var BookCSVHeader = append([]string{"id", "title", "author", "description", "isbn13", "isbn10"}, BookMetadataCSVHeader...)
+
+// CSVRecord returns the CSV record of the book; fields follow BookCSVHeader.
+func (b Book) CSVRecord() []string {
+	return append([]string{strconv.Itoa(b.ID), b.Title, b.Author, b.Description, b.ISBN13, b.ISBN10}, b.BookMetadata.CSVRecord()...)
+}
+
+// MarshalCSV returns the CSV records of the book including the header row.
+func (b Book) MarshalCSV() ([][]string, error) {
+	return [][]string{BookCSVHeader, b.CSVRecord()}, nil
 }
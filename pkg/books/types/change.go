@@ -0,0 +1,77 @@
+package types
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type ChangeOperation string
+
+const (
+	CHANGE_OP_CREATE ChangeOperation = "create"
+	CHANGE_OP_UPDATE ChangeOperation = "update"
+	CHANGE_OP_DELETE ChangeOperation = "delete"
+	// CHANGE_OP_RESTORE brings back a past revision of a book, re-creating it if it is deleted.
+	CHANGE_OP_RESTORE ChangeOperation = "restore"
+)
+
+// BookChange is a change made to a book by the service. Before is nil for a created book
+// and After is nil for a deleted one.
+type BookChange struct {
+	Op     ChangeOperation
+	BookID int
+	Before *Book
+	After  *Book
+	// RestoredFrom is the revision brought back by a restore.
+	RestoredFrom int
+}
+
+// FieldChange is the old and new value of a changed field.
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Diff returns the changed fields of an updated book keyed by their json name.
+func (c BookChange) Diff() map[string]FieldChange {
+	diff := make(map[string]FieldChange)
+	if c.Before == nil || c.After == nil {
+		return diff
+	}
+
+	if c.Before.Title != c.After.Title {
+		diff["title"] = FieldChange{Old: c.Before.Title, New: c.After.Title}
+	}
+	if c.Before.Author != c.After.Author {
+		diff["author"] = FieldChange{Old: c.Before.Author, New: c.After.Author}
+	}
+	if c.Before.Description != c.After.Description {
+		diff["description"] = FieldChange{Old: c.Before.Description, New: c.After.Description}
+	}
+	if c.Before.ISBN13 != c.After.ISBN13 {
+		diff["isbn13"] = FieldChange{Old: c.Before.ISBN13, New: c.After.ISBN13}
+	}
+	if c.Before.ISBN10 != c.After.ISBN10 {
+		diff["isbn10"] = FieldChange{Old: c.Before.ISBN10, New: c.After.ISBN10}
+	}
+	c.Before.BookMetadata.diff(c.After.BookMetadata, diff)
+	if coverChecksum(c.Before.Cover) != coverChecksum(c.After.Cover) {
+		diff["cover"] = FieldChange{Old: c.Before.Cover, New: c.After.Cover}
+	}
+
+	return diff
+}
+
+// coverChecksum returns the checksum of the cover image, empty for no cover.
+func coverChecksum(cover *BookCover) string {
+	if cover == nil {
+		return ""
+	}
+	return cover.Checksum
+}
+
+// ChangeWriter writes the records that must be kept with a book change, e.g. events,
+// using the transaction in which the change is made. ctx is the context of the request
+// making the change.
+type ChangeWriter func(ctx context.Context, tx *gorm.DB, change BookChange) error
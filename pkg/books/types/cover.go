@@ -0,0 +1,45 @@
+package types
+
+import "time"
+
+// BookCover is the cover image of a book and its thumbnails. The images are stored in the
+// blob store under keys versioned by the checksum of the uploaded image, so their URLs
+// change with the image and can be cached for good.
+type BookCover struct {
+	URL         string `json:"url" yaml:"url" xml:"url" example:"/api/v1/books/1/cover?v=9f86d081884c7d65"`
+	ContentType string `json:"content_type" yaml:"content_type" xml:"content_type" example:"image/jpeg"`
+	Width       int    `json:"width" yaml:"width" xml:"width" example:"1200"`
+	Height      int    `json:"height" yaml:"height" xml:"height" example:"1800"`
+	// Size is the size of the image in bytes.
+	Size int64 `json:"size" yaml:"size" xml:"size" example:"245310"`
+	// Checksum is the hex SHA-256 of the image.
+	Checksum   string           `json:"checksum" yaml:"checksum" xml:"checksum" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Thumbnails []CoverThumbnail `json:"thumbnails" yaml:"thumbnails" xml:"thumbnails>thumbnail"`
+	UpdatedAt  time.Time        `json:"updated_at" yaml:"updated_at" xml:"updated_at"`
+}
+
+// Version returns the version of the cover images used in their keys and URLs.
+func (c BookCover) Version() string {
+	return c.Checksum[:16]
+}
+
+// Thumbnail returns the thumbnail of the given name.
+func (c BookCover) Thumbnail(name string) (CoverThumbnail, bool) {
+	for _, t := range c.Thumbnails {
+		if t.Name == name {
+			return t, true
+		}
+	}
+
+	return CoverThumbnail{}, false
+}
+
+// CoverThumbnail is a cover image scaled down to a width.
+type CoverThumbnail struct {
+	Name        string `json:"name" yaml:"name" xml:"name" example:"small"`
+	URL         string `json:"url" yaml:"url" xml:"url" example:"/api/v1/books/1/cover/small?v=9f86d081884c7d65"`
+	ContentType string `json:"content_type" yaml:"content_type" xml:"content_type" example:"image/jpeg"`
+	Width       int    `json:"width" yaml:"width" xml:"width" example:"100"`
+	Height      int    `json:"height" yaml:"height" xml:"height" example:"150"`
+	Size        int64  `json:"size" yaml:"size" xml:"size" example:"4120"`
+}
@@ -1,13 +1,42 @@
 package types
 
-import "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
 
 // DataProvider is the interface for the data provider for a books service
 type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	// Transaction runs fn with a data provider bound to a single database transaction.
+	// The transaction is committed if fn returns nil, otherwise it is rolled back.
+	Transaction(fn func(d DataProvider) error) error
+
 	CreateBook(book Book) (Book, error)
+	CreateBooks(books []Book) ([]Book, error)
 	UpdateBook(book *Book) error
 	DeleteBook(book *Book) error
+	// RecordChange writes the revision and the other records kept with a book change within
+	// the current transaction.
+	RecordChange(ctx context.Context, change BookChange) error
 
-	GetBooks(page int, limit int, sortType db.SortType) (*db.Pagination, []Book, error)
+	// GetBooks lists the books matching the filter ordered by the sort field, then by id.
+	GetBooks(page int, limit int, sortType db.SortType, sortBy BookSortField, filter BookFilter) (*db.Pagination, []Book, error)
+	// GetTagFacets counts the books matching the filter per tag, the most used tags first.
+	GetTagFacets(filter BookFilter) ([]TagFacet, error)
 	GetBook(id int) (*Book, error)
+	// LockBook retrieves a book like GetBook and locks its row until the end of the
+	// transaction, so concurrent changes of the book are made one after the other.
+	LockBook(id int) (*Book, error)
+	GetBookByISBN(isbn13 string) (*Book, error)
+	GetRevisions(bookID int, page int, limit int) (*db.Pagination, []BookRevision, error)
+	GetRevision(bookID int, rev int) (*BookRevision, error)
+	// StreamBooks calls fn for every book matching the filter in the same order as GetBooks,
+	// without loading all of them into memory. It stops at the first error returned by fn or
+	// when ctx is done.
+	StreamBooks(ctx context.Context, sortType db.SortType, sortBy BookSortField, filter BookFilter, fn func(book Book) error) error
 }
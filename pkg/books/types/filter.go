@@ -0,0 +1,60 @@
+package types
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// BookFilter narrows down the books listed by GetBooks.
+type BookFilter struct {
+	// Tags are normalized tags a book must all have, see NormalizeTag.
+	Tags []string
+	// SubjectID is the subject a book must be classified in, either directly or via one of
+	// the descendants of the subject. 0 means any subject.
+	SubjectID int
+
+	// Publisher and SeriesName match regardless of case.
+	Publisher  string `validate:"max=255"`
+	SeriesName string `validate:"max=255"`
+	// Language matches the tag and its subtags, e.g. "en" matches "en-GB".
+	Language string     `validate:"omitempty,bcp47_language_tag"`
+	Format   BookFormat `validate:"omitempty,oneof=hardcover paperback ebook audio"`
+	// PublishedFrom and PublishedTo bound the publication date, both included.
+	PublishedFrom string `validate:"omitempty,datetime=2006-01-02"`
+	PublishedTo   string `validate:"omitempty,datetime=2006-01-02"`
+	// MinPrice and MaxPrice bound the price in the minor unit of the currency, both included.
+	Currency string `validate:"required_with=MinPrice MaxPrice,omitempty,iso4217"`
+	MinPrice int64  `validate:"min=0"`
+	MaxPrice int64  `validate:"min=0"`
+}
+
+// NormalizeTag returns the tag in lower case with its words separated by a single space, so
+// "Science  Fiction" and "science fiction" are the same tag.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// TagFacet is the number of listed books having a tag.
+type TagFacet struct {
+	Tag   string `json:"tag" yaml:"tag" xml:"tag" example:"fantasy"`
+	Count int64  `json:"count" yaml:"count" xml:"count" example:"3"`
+}
+
+// BookFacets are the counts of the listed books per classification.
+type BookFacets struct {
+	Tags []TagFacet `json:"tags" yaml:"tags" xml:"tags>tag"`
+}
+
+// BookClassifier finds the books by their tags and subjects, which are managed by the
+// classification service. The queries are built on db, the database or the transaction of the
+// books query they are part of.
+type BookClassifier interface {
+	// TaggedBooks returns the subquery of the ids of the books having the normalized tag.
+	TaggedBooks(db *gorm.DB, tag string) *gorm.DB
+	// ClassifiedBooks returns the subquery of the ids of the books classified in the subject,
+	// either directly or via one of its descendants.
+	ClassifiedBooks(db *gorm.DB, subjectID int) *gorm.DB
+	// CountTags counts the books of the subquery of book ids per tag, the most used tags first.
+	CountTags(db *gorm.DB, bookIDs *gorm.DB) ([]TagFacet, error)
+}
@@ -0,0 +1,51 @@
+package types
+
+type ImportMode string
+
+const (
+	// IMPORT_MODE_ATOMIC writes nothing if any row is invalid.
+	IMPORT_MODE_ATOMIC ImportMode = "atomic"
+	// IMPORT_MODE_PARTIAL writes the valid rows and skips the invalid ones.
+	IMPORT_MODE_PARTIAL ImportMode = "partial"
+)
+
+// ImportBookRow is a single decoded row of a bulk import.
+type ImportBookRow struct {
+	// Line is the line number of the row in the imported stream.
+	Line int
+	Book AddBookRequest
+	// Err is the error found while decoding the row, if any.
+	Err error
+}
+
+// ImportBookReader reads rows of a bulk import one by one. Read returns io.EOF
+// when there are no more rows.
+type ImportBookReader interface {
+	Read() (ImportBookRow, error)
+}
+
+// ImportBooksOptions is the options for importing books
+type ImportBooksOptions struct {
+	Mode   ImportMode
+	DryRun bool
+}
+
+// ImportBookRowResult is the result of importing a single row
+type ImportBookRowResult struct {
+	Line   int               `json:"line" example:"2"`
+	ID     int               `json:"id,omitempty" example:"1"`
+	Error  string            `json:"error,omitempty" example:"invalid json"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// ImportBooksResponse is the validation report of a bulk import
+type ImportBooksResponse struct {
+	Mode      ImportMode            `json:"mode" example:"atomic"`
+	DryRun    bool                  `json:"dry_run" example:"false"`
+	Committed bool                  `json:"committed" example:"true"`
+	Total     int                   `json:"total" example:"2"`
+	Valid     int                   `json:"valid" example:"1"`
+	Created   int                   `json:"created" example:"1"`
+	Failed    int                   `json:"failed" example:"1"`
+	Rows      []ImportBookRowResult `json:"rows"`
+}
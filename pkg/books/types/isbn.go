@@ -0,0 +1,113 @@
+package types
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ISBN13Prefix is the prefix of the ISBN-13 converted from an ISBN-10. ISBN-13 with the 979
+// prefix have no ISBN-10.
+const ISBN13Prefix = "978"
+
+// NormalizeISBN removes the hyphens and spaces of an ISBN and upper-cases the X check digit.
+func NormalizeISBN(isbn string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(isbn))
+}
+
+// ValidISBN10 checks the normalized ISBN-10 has 9 digits followed by a check digit (or X for 10)
+// such that the digits weighted from 10 down to 1 sum to a multiple of 11.
+func ValidISBN10(isbn string) bool {
+	if len(isbn) != 10 || !isDigits(isbn[:9]) {
+		return false
+	}
+
+	check := isbn10CheckDigit(isbn[:9])
+	return isbn[9] == check
+}
+
+// ValidISBN13 checks the normalized ISBN-13 has 13 digits such that the digits weighted
+// alternately by 1 and 3 sum to a multiple of 10.
+func ValidISBN13(isbn string) bool {
+	if len(isbn) != 13 || !isDigits(isbn) {
+		return false
+	}
+
+	return isbn[12] == isbn13CheckDigit(isbn[:12])
+}
+
+// ISBN10To13 converts a valid normalized ISBN-10 into its ISBN-13.
+func ISBN10To13(isbn10 string) string {
+	base := ISBN13Prefix + isbn10[:9]
+	return base + string(isbn13CheckDigit(base))
+}
+
+// ISBN13To10 converts a valid normalized ISBN-13 into its ISBN-10. It returns false if the
+// ISBN-13 has no ISBN-10.
+func ISBN13To10(isbn13 string) (string, bool) {
+	if !strings.HasPrefix(isbn13, ISBN13Prefix) {
+		return "", false
+	}
+
+	base := isbn13[3:12]
+	return base + string(isbn10CheckDigit(base)), true
+}
+
+// RegisterISBNValidation registers the isbn_checksum tag, validating the checksum of an ISBN
+// field after normalization; its parameter is the ISBN length, e.g. `validate:"isbn_checksum=13"`.
+func RegisterISBNValidation(v *validator.Validate) {
+	_ = v.RegisterValidation("isbn_checksum", func(fl validator.FieldLevel) bool {
+		isbn := NormalizeISBN(fl.Field().String())
+		switch fl.Param() {
+		case "10":
+			return ValidISBN10(isbn)
+		case "13":
+			return ValidISBN13(isbn)
+		default:
+			return false
+		}
+	})
+}
+
+// NewValidator creates a validator for the book requests.
+func NewValidator() *validator.Validate {
+	v := validator.New()
+	RegisterISBNValidation(v)
+	return v
+}
+
+func isbn10CheckDigit(digits string) byte {
+	sum := 0
+	for i, d := range digits {
+		sum += (10 - i) * int(d-'0')
+	}
+
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return strconv.Itoa(check)[0]
+}
+
+func isbn13CheckDigit(digits string) byte {
+	sum := 0
+	for i, d := range digits {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += weight * int(d-'0')
+	}
+
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"strconv"
+
+	"golang.org/x/text/language"
+)
+
+type BookFormat string
+
+const (
+	BOOK_FORMAT_HARDCOVER BookFormat = "hardcover"
+	BOOK_FORMAT_PAPERBACK BookFormat = "paperback"
+	BOOK_FORMAT_EBOOK     BookFormat = "ebook"
+	BOOK_FORMAT_AUDIO     BookFormat = "audio"
+)
+
+// BookMetadata is the bibliographic information of a book, shared by the book, its revisions
+// and the requests changing it. A zero value means the information is unknown.
+type BookMetadata struct {
+	Publisher string `json:"publisher,omitempty" yaml:"publisher,omitempty" xml:"publisher,omitempty" gorm:"not null;default:''" example:"Bloomsbury" validate:"max=255"`
+	// PublishedDate is the publication date as YYYY-MM-DD.
+	PublishedDate string `json:"published_date,omitempty" yaml:"published_date,omitempty" xml:"published_date,omitempty" gorm:"type:varchar(10);not null;default:''" example:"1997-06-26" validate:"omitempty,datetime=2006-01-02"`
+	Edition       string `json:"edition,omitempty" yaml:"edition,omitempty" xml:"edition,omitempty" gorm:"not null;default:''" example:"1st" validate:"max=64"`
+	// Language is a BCP 47 language tag in its canonical form, e.g. "en-GB".
+	Language   string     `json:"language,omitempty" yaml:"language,omitempty" xml:"language,omitempty" gorm:"not null;default:''" example:"en-GB" validate:"omitempty,bcp47_language_tag"`
+	PageCount  int        `json:"page_count,omitempty" yaml:"page_count,omitempty" xml:"page_count,omitempty" gorm:"not null;default:0" example:"223" validate:"omitempty,min=1"`
+	Format     BookFormat `json:"format,omitempty" yaml:"format,omitempty" xml:"format,omitempty" gorm:"not null;default:''" example:"hardcover" validate:"omitempty,oneof=hardcover paperback ebook audio"`
+	SeriesName string     `json:"series_name,omitempty" yaml:"series_name,omitempty" xml:"series_name,omitempty" gorm:"not null;default:''" example:"Harry Potter" validate:"required_with=SeriesPosition,max=255"`
+	// SeriesPosition is the position of the book in the series from 1.
+	SeriesPosition int `json:"series_position,omitempty" yaml:"series_position,omitempty" xml:"series_position,omitempty" gorm:"not null;default:0" example:"1" validate:"omitempty,min=1"`
+	// Price is in the minor unit of the currency, e.g. 1299 for 12.99 GBP.
+	Price    int64  `json:"price,omitempty" yaml:"price,omitempty" xml:"price,omitempty" gorm:"not null;default:0" example:"1299" validate:"omitempty,min=0"`
+	Currency string `json:"currency,omitempty" yaml:"currency,omitempty" xml:"currency,omitempty" gorm:"type:varchar(3);not null;default:''" example:"GBP" validate:"required_with=Price,omitempty,iso4217"`
+}
+
+// BookMetadataCSVHeader is the header of the metadata columns of books represented as CSV.
+var BookMetadataCSVHeader = []string{
+	"publisher", "published_date", "edition", "language", "page_count",
+	"format", "series_name", "series_position", "price", "currency",
+}
+
+// CSVRecord returns the metadata columns of a CSV record; fields follow BookMetadataCSVHeader.
+func (m BookMetadata) CSVRecord() []string {
+	return []string{
+		m.Publisher, m.PublishedDate, m.Edition, m.Language, formatCount(int64(m.PageCount)),
+		string(m.Format), m.SeriesName, formatCount(int64(m.SeriesPosition)), formatCount(m.Price), m.Currency,
+	}
+}
+
+// Normalize returns the validated metadata with the language tag in its canonical form.
+func (m BookMetadata) Normalize() BookMetadata {
+	if tag, err := language.Parse(m.Language); err == nil {
+		m.Language = tag.String()
+	}
+
+	return m
+}
+
+// Merge returns the metadata with the known fields of the other metadata replacing its own.
+func (m BookMetadata) Merge(other BookMetadata) BookMetadata {
+	if other.Publisher != "" {
+		m.Publisher = other.Publisher
+	}
+	if other.PublishedDate != "" {
+		m.PublishedDate = other.PublishedDate
+	}
+	if other.Edition != "" {
+		m.Edition = other.Edition
+	}
+	if other.Language != "" {
+		m.Language = other.Language
+	}
+	if other.PageCount != 0 {
+		m.PageCount = other.PageCount
+	}
+	if other.Format != "" {
+		m.Format = other.Format
+	}
+	if other.SeriesName != "" {
+		m.SeriesName = other.SeriesName
+	}
+	if other.SeriesPosition != 0 {
+		m.SeriesPosition = other.SeriesPosition
+	}
+	// a new price comes with its currency, but the currency of the price may be changed alone.
+	if other.Price != 0 {
+		m.Price = other.Price
+	}
+	if other.Currency != "" {
+		m.Currency = other.Currency
+	}
+
+	return m
+}
+
+// diff adds the changed fields of the metadata to diff, keyed by their json name.
+func (m BookMetadata) diff(other BookMetadata, diff map[string]FieldChange) {
+	fields := []struct {
+		name     string
+		old, new any
+	}{
+		{"publisher", m.Publisher, other.Publisher},
+		{"published_date", m.PublishedDate, other.PublishedDate},
+		{"edition", m.Edition, other.Edition},
+		{"language", m.Language, other.Language},
+		{"page_count", m.PageCount, other.PageCount},
+		{"format", m.Format, other.Format},
+		{"series_name", m.SeriesName, other.SeriesName},
+		{"series_position", m.SeriesPosition, other.SeriesPosition},
+		{"price", m.Price, other.Price},
+		{"currency", m.Currency, other.Currency},
+	}
+
+	for _, f := range fields {
+		if f.old != f.new {
+			diff[f.name] = FieldChange{Old: f.old, New: f.new}
+		}
+	}
+}
+
+// formatCount formats a count for CSV, leaving an unknown (zero) count empty.
+func formatCount(n int64) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
@@ -0,0 +1,34 @@
+package types
+
+// BookRating is the average rating of the approved reviews of a book and their number, omitted
+// for a book without any. It is maintained incrementally by the reviews service; the books
+// service never writes it.
+type BookRating struct {
+	RatingAverage float64 `json:"rating_average,omitempty" yaml:"rating_average,omitempty" xml:"rating_average,omitempty" gorm:"<-:false;not null;default:0;index" example:"4.5"`
+	RatingCount   int     `json:"rating_count,omitempty" yaml:"rating_count,omitempty" xml:"rating_count,omitempty" gorm:"<-:false;not null;default:0;index" example:"2"`
+	// RatingSum is the sum of the ratings the average is computed from.
+	RatingSum int `json:"-" yaml:"-" xml:"-" gorm:"<-:false;not null;default:0"`
+}
+
+// BookSortField is the field the books are listed by.
+type BookSortField string
+
+const (
+	BOOK_SORT_ID           BookSortField = "id"
+	BOOK_SORT_RATING       BookSortField = "rating"
+	BOOK_SORT_RATING_COUNT BookSortField = "rating_count"
+)
+
+// Column returns the column of the sort field, empty for an unknown field.
+func (f BookSortField) Column() string {
+	switch f {
+	case BOOK_SORT_ID:
+		return "id"
+	case BOOK_SORT_RATING:
+		return "rating_average"
+	case BOOK_SORT_RATING_COUNT:
+		return "rating_count"
+	}
+
+	return ""
+}
@@ -7,17 +7,33 @@ type AddBookRequest struct {
 	Title       string `json:"title" example:"example-title" validate:"required"`
 	Author      string `json:"author" example:"John Doe" validate:"required"`
 	Description string `json:"description" example:"this is an example description" validate:"required"`
+	ISBN13      string `json:"isbn13" example:"978-0-306-40615-7" validate:"omitempty,isbn_checksum=13"`
+	ISBN10      string `json:"isbn10" example:"0-306-40615-2" validate:"omitempty,isbn_checksum=10"`
+	BookMetadata
 }
 
 // AddBookResponse is the response for adding a new book
 type AddBookResponse struct {
-	ID int `json:"id" example:"1"`
+	ID int `json:"id" yaml:"id" xml:"id" example:"1"`
 }
 
 // GetBooksResponse is the response for getting a list of books
 type GetBooksResponse struct {
-	Books      []Book         `json:"books"`
-	Pagination *db.Pagination `json:"pagination"`
+	Books      []Book         `json:"books" yaml:"books" xml:"books>book"`
+	Pagination *db.Pagination `json:"pagination" yaml:"pagination" xml:"pagination"`
+	// Facets are only given when requested.
+	Facets *BookFacets `json:"facets,omitempty" yaml:"facets,omitempty" xml:"facets,omitempty"`
+}
+
+// MarshalCSV returns the CSV records of the books including the header row.
+func (r GetBooksResponse) MarshalCSV() ([][]string, error) {
+	records := make([][]string, 0, len(r.Books)+1)
+	records = append(records, BookCSVHeader)
+	for _, b := range r.Books {
+		records = append(records, b.CSVRecord())
+	}
+
+	return records, nil
 }
 
 // DeleteBookResponse is the response for deleting a book
@@ -28,4 +44,7 @@ type UpdateBookRequest struct {
 	Title       string `json:"title" example:"example-title"`
 	Author      string `json:"author" example:"John Doe"`
 	Description string `json:"description" example:"this is an example description"`
+	ISBN13      string `json:"isbn13" example:"978-0-306-40615-7" validate:"omitempty,isbn_checksum=13"`
+	ISBN10      string `json:"isbn10" example:"0-306-40615-2" validate:"omitempty,isbn_checksum=10"`
+	BookMetadata
 }
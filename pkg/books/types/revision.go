@@ -0,0 +1,59 @@
+package types
+
+import (
+	"time"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// BookRevision is the model for a version of a book, recorded on every change. Rev counts
+// the versions of a book from 1; a revision of a deletion holds the last state of the book.
+type BookRevision struct {
+	ID          int             `json:"-" gorm:"primaryKey;autoIncrement"`
+	TenantID    string          `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	BookID      int             `json:"book_id" gorm:"not null;uniqueIndex:idx_book_revisions_book_rev" example:"1"`
+	Rev         int             `json:"rev" gorm:"not null;uniqueIndex:idx_book_revisions_book_rev" example:"2"`
+	Op          ChangeOperation `json:"op" gorm:"not null" example:"update"`
+	Title       string          `json:"title" example:"example-title"`
+	Author      string          `json:"author" example:"John Doe"`
+	Description string          `json:"description" example:"this is an example description"`
+	ISBN13      string          `json:"isbn13,omitempty" gorm:"column:isbn13" example:"9780306406157"`
+	ISBN10      string          `json:"isbn10,omitempty" gorm:"column:isbn10" example:"0306406152"`
+	BookMetadata
+	// RestoredFrom is the revision brought back by a restore.
+	RestoredFrom int       `json:"restored_from,omitempty" example:"1"`
+	Actor        string    `json:"actor" example:"alice"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (BookRevision) TableName() string {
+	return "book_revisions"
+}
+
+// Book returns the book as of the revision.
+func (r BookRevision) Book() Book {
+	return Book{
+		ID:           r.BookID,
+		Title:        r.Title,
+		Author:       r.Author,
+		Description:  r.Description,
+		ISBN13:       r.ISBN13,
+		ISBN10:       r.ISBN10,
+		BookMetadata: r.BookMetadata,
+	}
+}
+
+// GetRevisionsResponse is the response for getting the revisions of a book
+type GetRevisionsResponse struct {
+	Revisions  []BookRevision `json:"revisions"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// RevisionDiffResponse is the response for comparing two revisions of a book
+type RevisionDiffResponse struct {
+	BookID  int                    `json:"book_id" example:"1"`
+	From    int                    `json:"from" example:"1"`
+	To      int                    `json:"to" example:"3"`
+	Changes map[string]FieldChange `json:"changes"`
+}
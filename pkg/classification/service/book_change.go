@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+)
+
+var (
+	_ booktypes.ChangeWriter = WriteBookChange
+)
+
+// WriteBookChange is the change writer that removes the tags and the subjects of a deleted book.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	if change.After != nil {
+		return nil
+	}
+
+	if err := tx.Where("book_id = ?", change.BookID).Delete(&types.BookTag{}).Error; err != nil {
+		return err
+	}
+	return tx.Where("book_id = ?", change.BookID).Delete(&types.BookSubject{}).Error
+}
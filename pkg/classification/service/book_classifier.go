@@ -0,0 +1,48 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+var (
+	_ booktypes.BookClassifier = BookClassifier{}
+)
+
+// BookClassifier is the book classifier finding the books of the books service by the tags and
+// the subjects they are classified in.
+type BookClassifier struct{}
+
+// TaggedBooks returns the subquery of the ids of the books having the tag
+func (BookClassifier) TaggedBooks(db *gorm.DB, tag string) *gorm.DB {
+	return db.Table("book_tags").
+		Select("book_tags.book_id").
+		Joins("JOIN tags ON tags.id = book_tags.tag_id").
+		Where("tags.name = ?", tag)
+}
+
+// ClassifiedBooks returns the subquery of the ids of the books classified in the subject or in
+// its descendants
+func (BookClassifier) ClassifiedBooks(db *gorm.DB, subjectID int) *gorm.DB {
+	// the path of a subject is prefixed by the paths of its ancestors.
+	subjectPath := db.Table("subjects").Select("path || '%'").Where("id = ?", subjectID)
+	return db.Table("book_subjects").
+		Select("book_subjects.book_id").
+		Joins("JOIN subjects ON subjects.id = book_subjects.subject_id").
+		Where("subjects.path LIKE (?)", subjectPath)
+}
+
+// CountTags counts the books of the subquery per tag, the most used tags first
+func (BookClassifier) CountTags(db *gorm.DB, bookIDs *gorm.DB) ([]booktypes.TagFacet, error) {
+	var facets []booktypes.TagFacet
+	err := db.Table("book_tags").
+		Select("tags.name AS tag, COUNT(*) AS count").
+		Joins("JOIN tags ON tags.id = book_tags.tag_id").
+		Where("book_tags.book_id IN (?)", bookIDs).
+		Group("tags.name").
+		Order("count DESC, tag ASC").
+		Scan(&facets).Error
+
+	return facets, err
+}
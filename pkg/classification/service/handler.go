@@ -0,0 +1,376 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the classification service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the classification service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/tags", h.GetTags)
+	mux.HandleFunc("GET /api/v1/books/{id}/tags", h.GetBookTags)
+	mux.HandleFunc("PUT /api/v1/books/{id}/tags", h.SetBookTags)
+	mux.HandleFunc("GET /api/v1/subjects", h.GetSubjects)
+	mux.HandleFunc("GET /api/v1/subjects/{id}", h.GetSubject)
+	mux.HandleFunc("POST /api/v1/subjects", h.AddSubject)
+	mux.HandleFunc("PUT /api/v1/subjects/{id}", h.UpdateSubject)
+	mux.HandleFunc("DELETE /api/v1/subjects/{id}", h.DeleteSubject)
+	mux.HandleFunc("GET /api/v1/books/{id}/subjects", h.GetBookSubjects)
+	mux.HandleFunc("PUT /api/v1/books/{id}/subjects", h.SetBookSubjects)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// @Summary get list of tags with their number of books
+// @ID get-tags
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param q query string false "part of the tag"
+// @Produce json
+// @Success 200 {object} types.GetTagsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /tags [get]
+func (h Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetTags(r.URL.Query().Get("q"), page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get tags of a book
+// @ID get-book-tags
+// @Param id path int true "Book ID"
+// @Produce json
+// @Success 200 {object} types.GetBookTagsResponse
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/tags [get]
+func (h Handler) GetBookTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetBookTags(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary replace tags of a book
+// @Description Tags are compared in lower case with single spaces; missing tags are created.
+// @ID set-book-tags
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param Body body types.SetBookTagsRequest true "Tags of the book"
+// @Success 200 {object} types.GetBookTagsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/tags [put]
+func (h Handler) SetBookTags(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.SetBookTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).SetBookTags(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get list of subjects, ordered along the tree
+// @ID get-subjects
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param q query string false "part of the subject name or code"
+// @Param scheme query string false "classification scheme of the subject code" enums(dewey,bisac)
+// @Param under query int false "Subject ID whose subtree is listed, itself included"
+// @Produce json
+// @Success 200 {object} types.GetSubjectsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /subjects [get]
+func (h Handler) GetSubjects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	filter := types.SubjectFilter{
+		Query:  r.URL.Query().Get("q"),
+		Scheme: types.SubjectScheme(r.URL.Query().Get("scheme")),
+	}
+	if under := r.URL.Query().Get("under"); under != "" {
+		if filter.Under, err = parseIntParam("under", under); err != nil {
+			response.WriteError(ctx, w, err, h.log)
+			return
+		}
+	}
+
+	result, err := h.serv.WithContext(ctx).GetSubjects(filter, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get subject information from given id
+// @ID get-subject
+// @Param id path int true "Subject ID"
+// @Produce json
+// @Success 200 {object} types.Subject
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /subjects/{id} [get]
+func (h Handler) GetSubject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetSubject(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary add a new subject into the tree
+// @ID add-subject
+// @Produce json
+// @Param Body body types.AddSubjectRequest true "Subject information that needs to be added"
+// @Success 201 {object} types.AddSubjectResponse
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /subjects [post]
+func (h Handler) AddSubject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read to request body
+	var req types.AddSubjectRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).AddSubject(req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary update subject information with the given id
+// @Description A `parent_id` moves the subject with its subtree, `0` moving it to the root.
+// @ID update-subject
+// @Produce json
+// @Param id path int true "Subject ID"
+// @Param Body body types.UpdateSubjectRequest true "Subject information that needs to be updated"
+// @Success 200 {object} types.Subject
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /subjects/{id} [put]
+func (h Handler) UpdateSubject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateSubjectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateSubject(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary delete subject id from the tree
+// @Description Only a subject without children and books can be deleted.
+// @ID delete-subject
+// @Param id path int true "Subject ID"
+// @Produce json
+// @Success 200 {object} types.DeleteSubjectResponse
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /subjects/{id} [delete]
+func (h Handler) DeleteSubject(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteSubject(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get subjects of a book
+// @ID get-book-subjects
+// @Param id path int true "Book ID"
+// @Produce json
+// @Success 200 {object} types.GetBookSubjectsResponse
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/subjects [get]
+func (h Handler) GetBookSubjects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetBookSubjects(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary replace subjects of a book
+// @ID set-book-subjects
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param Body body types.SetBookSubjectsRequest true "Subjects the book is classified in"
+// @Success 200 {object} types.GetBookSubjectsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/subjects [put]
+func (h Handler) SetBookSubjects(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.SetBookSubjectsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).SetBookSubjects(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
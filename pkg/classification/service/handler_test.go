@@ -0,0 +1,382 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestSetBookTags(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.GetBookTagsResponse
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.SetBookTagsRequest
+		output     output
+	}{
+		{
+			name:  "blank tag",
+			input: types.SetBookTagsRequest{Tags: []string{"fantasy", "  "}},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "tag must not be blank",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+			},
+		},
+		{
+			name:  "book not found",
+			input: types.SetBookTagsRequest{Tags: []string{"fantasy"}},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "book not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(false, nil)
+			},
+		},
+		{
+			name:  "success with spellings of the same tag",
+			input: types.SetBookTagsRequest{Tags: []string{"Science  Fiction", "science fiction", "Classic"}},
+			output: output{
+				code: http.StatusOK,
+				body: types.GetBookTagsResponse{Tags: []string{"classic", "science fiction"}},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil).Times(2)
+				s.Repository.EXPECT().SetBookTags(1, []string{"science fiction", "classic"}).Return(nil)
+				s.Repository.EXPECT().GetBookTags(1).Return([]string{"classic", "science fiction"}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1/tags", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.GetBookTagsResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestAddSubject(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.AddSubjectResponse
+		errMsg string
+		errID  int
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.AddSubjectRequest
+		output     output
+	}{
+		{
+			name:  "code without scheme",
+			input: types.AddSubjectRequest{Name: "Fiction", Code: "823"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "{\"Code\":\"It is invalid\"}",
+			},
+		},
+		{
+			name:  "invalid code",
+			input: types.AddSubjectRequest{Name: "Fantasy", Scheme: types.SUBJECT_SCHEME_BISAC, Code: "FIC9"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid bisac code: FIC9",
+			},
+		},
+		{
+			name:  "duplicated code",
+			input: types.AddSubjectRequest{Name: "Fantasy", Scheme: types.SUBJECT_SCHEME_BISAC, Code: "FIC009000"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "subject already exists: 3",
+				errID:  3,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubjectByCode(types.SUBJECT_SCHEME_BISAC, "FIC009000").Return(&types.Subject{ID: 3}, nil)
+			},
+		},
+		{
+			name:  "code added concurrently",
+			input: types.AddSubjectRequest{Name: "Fantasy", Scheme: types.SUBJECT_SCHEME_BISAC, Code: "FIC009000"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "subject already exists: 4",
+				errID:  4,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				gomock.InOrder(
+					s.Repository.EXPECT().GetSubjectByCode(types.SUBJECT_SCHEME_BISAC, "FIC009000").Return(nil, gorm.ErrRecordNotFound),
+					s.Repository.EXPECT().CreateSubject(gomock.Any(), nil).Return(types.Subject{}, gorm.ErrDuplicatedKey),
+					s.Repository.EXPECT().GetSubjectByCode(types.SUBJECT_SCHEME_BISAC, "FIC009000").Return(&types.Subject{ID: 4}, nil),
+				)
+			},
+		},
+		{
+			name:  "parent not found",
+			input: types.AddSubjectRequest{Name: "Fantasy", ParentID: 2},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "parent subject not found: 2",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(2).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "success",
+			input: types.AddSubjectRequest{Name: "English fiction", ParentID: 2, Scheme: types.SUBJECT_SCHEME_DEWEY, Code: "823.914"},
+			output: output{
+				code: http.StatusCreated,
+				body: types.AddSubjectResponse{ID: 5},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				parent := &types.Subject{ID: 2, Path: "1/2/"}
+				s.Repository.EXPECT().GetSubjectByCode(types.SUBJECT_SCHEME_DEWEY, "823.914").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().GetSubject(2).Return(parent, nil)
+				s.Repository.EXPECT().CreateSubject(types.Subject{
+					Name:   "English fiction",
+					Scheme: types.SUBJECT_SCHEME_DEWEY,
+					Code:   "823.914",
+				}, parent).Return(types.Subject{ID: 5}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/subjects", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.AddSubjectResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+				require.Equal(t, tc.output.errID, res.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateSubject(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.Subject
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.UpdateSubjectRequest
+		output     output
+	}{
+		{
+			name:  "move into own subtree",
+			input: types.UpdateSubjectRequest{ParentID: intPtr(4)},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "subject cannot be moved into its own subtree",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(2).Return(&types.Subject{ID: 2, ParentID: intPtr(1), Path: "1/2/"}, nil)
+				s.Repository.EXPECT().GetSubject(4).Return(&types.Subject{ID: 4, ParentID: intPtr(2), Path: "1/2/4/"}, nil)
+			},
+		},
+		{
+			name:  "move to another parent",
+			input: types.UpdateSubjectRequest{ParentID: intPtr(3)},
+			output: output{
+				code: http.StatusOK,
+				body: types.Subject{ID: 2, ParentID: intPtr(3), Path: "3/2/"},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(2).Return(&types.Subject{ID: 2, ParentID: intPtr(1), Path: "1/2/"}, nil)
+				s.Repository.EXPECT().GetSubject(3).Return(&types.Subject{ID: 3, Path: "3/"}, nil)
+				s.Repository.EXPECT().UpdateSubject(&types.Subject{ID: 2, ParentID: intPtr(3), Path: "3/2/"}, "1/2/").Return(nil)
+			},
+		},
+		{
+			name:  "move to the root",
+			input: types.UpdateSubjectRequest{ParentID: intPtr(0)},
+			output: output{
+				code: http.StatusOK,
+				body: types.Subject{ID: 2, Path: "2/"},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(2).Return(&types.Subject{ID: 2, ParentID: intPtr(1), Path: "1/2/"}, nil)
+				s.Repository.EXPECT().UpdateSubject(&types.Subject{ID: 2, Path: "2/"}, "1/2/").Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/subjects/2", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Subject
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body.ParentID, res.ParentID)
+				require.Equal(t, tc.output.body.Path, res.Path)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestDeleteSubject(t *testing.T) {
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+	}{
+		{
+			name: "subject with children",
+			code: http.StatusConflict,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(1).Return(&types.Subject{ID: 1}, nil)
+				s.Repository.EXPECT().CountSubjectChildren(1).Return(int64(2), nil)
+			},
+		},
+		{
+			name: "subject with books",
+			code: http.StatusConflict,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(1).Return(&types.Subject{ID: 1}, nil)
+				s.Repository.EXPECT().CountSubjectChildren(1).Return(int64(0), nil)
+				s.Repository.EXPECT().CountSubjectBooks(1).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "success",
+			code: http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubject(1).Return(&types.Subject{ID: 1}, nil)
+				s.Repository.EXPECT().CountSubjectChildren(1).Return(int64(0), nil)
+				s.Repository.EXPECT().CountSubjectBooks(1).Return(int64(0), nil)
+				s.Repository.EXPECT().DeleteSubject(&types.Subject{ID: 1}).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+			tc.preProcess(&s)
+
+			req, err := http.NewRequest(http.MethodDelete, "/api/v1/subjects/1", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+		})
+	}
+}
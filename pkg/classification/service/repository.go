@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in a classification service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new classification-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// GetTags retrieves a list of tags with their number of books from the database
+func (r *Repository) GetTags(query string, page int, limit int) (*db.Pagination, []types.Tag, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "name asc",
+	}
+
+	tx := r.db.Model(&types.Tag{})
+	if query != "" {
+		tx = tx.Where("LOWER(name) LIKE LOWER(?)", "%"+query+"%")
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var tags []types.Tag
+	err := tx.Scopes(db.Paginate(&tags, &p, tx)).
+		Select("tags.*, (SELECT COUNT(*) FROM book_tags WHERE book_tags.tag_id = tags.id) AS book_count").
+		Find(&tags).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &p, tags, nil
+}
+
+// GetBookTags retrieves the tag names of a book from the database
+func (r *Repository) GetBookTags(bookID int) ([]string, error) {
+	names := []string{}
+	err := r.db.Model(&types.Tag{}).
+		Joins("JOIN book_tags ON book_tags.tag_id = tags.id").
+		Where("book_tags.book_id = ?", bookID).
+		Order("tags.name").
+		Pluck("tags.name", &names).Error
+
+	return names, err
+}
+
+// SetBookTags replaces the tags of a book in the database
+func (r *Repository) SetBookTags(bookID int, names []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&types.BookTag{}).Error; err != nil {
+			return err
+		}
+
+		if len(names) == 0 {
+			return nil
+		}
+
+		tags := make([]types.Tag, 0, len(names))
+		for _, name := range names {
+			tags = append(tags, types.Tag{Name: name})
+		}
+		err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "tenant_id"}, {Name: "name"}}, DoNothing: true}).
+			Create(&tags).Error
+		if err != nil {
+			return err
+		}
+
+		// the tags existing before are not returned by the insert.
+		var ids []int
+		if err := tx.Model(&types.Tag{}).Where("name IN ?", names).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+
+		links := make([]types.BookTag, 0, len(ids))
+		for _, id := range ids {
+			links = append(links, types.BookTag{BookID: bookID, TagID: id})
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// CreateSubject creates a new subject in the database
+func (r *Repository) CreateSubject(subject types.Subject, parent *types.Subject) (types.Subject, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if parent != nil {
+			subject.ParentID = &parent.ID
+		}
+		if err := tx.Create(&subject).Error; err != nil {
+			return err
+		}
+
+		// the path ends with the id given by the insert.
+		if parent != nil {
+			subject.Path = parent.ChildPath(subject.ID)
+		} else {
+			subject.Path = types.Subject{}.ChildPath(subject.ID)
+		}
+		return tx.Model(&subject).Update("path", subject.Path).Error
+	})
+
+	return subject, err
+}
+
+// UpdateSubject updates a subject in the database, moving its subtree if its path changed
+func (r *Repository) UpdateSubject(subject *types.Subject, oldPath string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(subject).Error; err != nil {
+			return err
+		}
+
+		if subject.Path == oldPath {
+			return nil
+		}
+		return tx.Model(&types.Subject{}).
+			Where("path LIKE ?", oldPath+"%").
+			Update("path", gorm.Expr("? || SUBSTR(path, ?)", subject.Path, len(oldPath)+1)).Error
+	})
+}
+
+// DeleteSubject deletes a subject from the database
+func (r *Repository) DeleteSubject(subject *types.Subject) error {
+	return r.db.Delete(subject).Error
+}
+
+// GetSubjects retrieves a list of subjects from the database, ordered along the tree
+func (r *Repository) GetSubjects(filter types.SubjectFilter, page int, limit int) (*db.Pagination, []types.Subject, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "path asc",
+	}
+
+	tx := r.db
+	if filter.Query != "" {
+		tx = tx.Where("LOWER(name) LIKE LOWER(?) OR LOWER(code) LIKE LOWER(?)", "%"+filter.Query+"%", "%"+filter.Query+"%")
+	}
+	if filter.Scheme != types.SUBJECT_SCHEME_NONE {
+		tx = tx.Where("scheme = ?", filter.Scheme)
+	}
+	if filter.Under != 0 {
+		tx = tx.Where("path LIKE (?)", r.db.Model(&types.Subject{}).Select("path || '%'").Where("id = ?", filter.Under))
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var subjects []types.Subject
+	if result := tx.Scopes(db.Paginate(&subjects, &p, tx)).Find(&subjects); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, subjects, nil
+}
+
+// GetSubject retrieves a subject from the database
+func (r *Repository) GetSubject(id int) (*types.Subject, error) {
+	var subject types.Subject
+	if result := r.db.First(&subject, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &subject, nil
+}
+
+// GetSubjectByCode retrieves a subject from the database by its code in a scheme
+func (r *Repository) GetSubjectByCode(scheme types.SubjectScheme, code string) (*types.Subject, error) {
+	var subject types.Subject
+	if result := r.db.Where("scheme = ? AND code = ?", scheme, code).First(&subject); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &subject, nil
+}
+
+// CountSubjectChildren counts the children of a subject in the database
+func (r *Repository) CountSubjectChildren(id int) (int64, error) {
+	var count int64
+	err := r.db.Model(&types.Subject{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// CountSubjectBooks counts the books classified directly in a subject in the database
+func (r *Repository) CountSubjectBooks(id int) (int64, error) {
+	var count int64
+	err := r.db.Model(&types.BookSubject{}).Where("subject_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// BookExists checks if the book exists in the database
+func (r *Repository) BookExists(bookID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&booktypes.Book{}).Where("id = ?", bookID).Count(&count).Error
+	return count > 0, err
+}
+
+// GetBookSubjects retrieves the subjects of a book from the database
+func (r *Repository) GetBookSubjects(bookID int) ([]types.Subject, error) {
+	subjects := []types.Subject{}
+	err := r.db.Joins("JOIN book_subjects ON book_subjects.subject_id = subjects.id").
+		Where("book_subjects.book_id = ?", bookID).
+		Order("subjects.path").
+		Find(&subjects).Error
+
+	return subjects, err
+}
+
+// SetBookSubjects replaces the subjects of a book in the database
+func (r *Repository) SetBookSubjects(bookID int, subjectIDs []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("book_id = ?", bookID).Delete(&types.BookSubject{}).Error; err != nil {
+			return err
+		}
+
+		if len(subjectIDs) == 0 {
+			return nil
+		}
+
+		links := make([]types.BookSubject, 0, len(subjectIDs))
+		for _, id := range subjectIDs {
+			links = append(links, types.BookSubject{BookID: bookID, SubjectID: id})
+		}
+		return tx.Create(&links).Error
+	})
+}
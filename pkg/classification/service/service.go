@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// Service is the service layer for tags and subjects
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new classification service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// GetTags returns a list of tags containing the query with their number of books
+func (s *Service) GetTags(query string, page int, limit int) (types.GetTagsResponse, error) {
+	pagination, tags, err := s.dataProvider.GetTags(booktypes.NormalizeTag(query), page, limit)
+	if err != nil {
+		return types.GetTagsResponse{}, err
+	}
+
+	return types.GetTagsResponse{
+		Tags:       tags,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetBookTags returns the tags of a book
+func (s *Service) GetBookTags(bookID int) (types.GetBookTagsResponse, error) {
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetBookTagsResponse{}, err
+	}
+
+	tags, err := s.dataProvider.GetBookTags(bookID)
+	if err != nil {
+		return types.GetBookTagsResponse{}, err
+	}
+
+	return types.GetBookTagsResponse{
+		Tags: tags,
+	}, nil
+}
+
+// SetBookTags replaces the tags of a book. The tags are normalized, so spellings of the same
+// tag are set once.
+func (s *Service) SetBookTags(bookID int, req types.SetBookTagsRequest) (types.GetBookTagsResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.GetBookTagsResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetBookTagsResponse{}, err
+	}
+
+	names := make([]string, 0, len(req.Tags))
+	seen := make(map[string]bool)
+	for _, tag := range req.Tags {
+		name := booktypes.NormalizeTag(tag)
+		if name == "" {
+			return types.GetBookTagsResponse{}, apierror.ErrInvalidInput.WithMessage("tag must not be blank")
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if err := s.dataProvider.SetBookTags(bookID, names); err != nil {
+		return types.GetBookTagsResponse{}, err
+	}
+
+	return s.GetBookTags(bookID)
+}
+
+// AddSubject adds a new subject into the tree
+func (s *Service) AddSubject(req types.AddSubjectRequest) (types.AddSubjectResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.AddSubjectResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	subject := types.Subject{
+		Name:   req.Name,
+		Scheme: req.Scheme,
+		Code:   req.Code,
+	}
+	if err := s.checkCodeAvailable(subject); err != nil {
+		return types.AddSubjectResponse{}, err
+	}
+
+	var parent *types.Subject
+	if req.ParentID != 0 {
+		var err error
+		if parent, err = s.getParent(req.ParentID); err != nil {
+			return types.AddSubjectResponse{}, err
+		}
+	}
+
+	created, err := s.dataProvider.CreateSubject(subject, parent)
+	if err != nil {
+		return types.AddSubjectResponse{}, s.codeConflict(err, subject)
+	}
+
+	return types.AddSubjectResponse{
+		ID: created.ID,
+	}, nil
+}
+
+// UpdateSubject updates a subject, moving it with its subtree if a parent is given
+func (s *Service) UpdateSubject(id int, req types.UpdateSubjectRequest) (types.Subject, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Subject{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	subject, err := s.GetSubject(id)
+	if err != nil {
+		return types.Subject{}, err
+	}
+	oldPath := subject.Path
+
+	if req.Name != "" {
+		subject.Name = req.Name
+	}
+	if req.Scheme != types.SUBJECT_SCHEME_NONE {
+		subject.Scheme = req.Scheme
+		subject.Code = req.Code
+		if err := s.checkCodeAvailable(*subject); err != nil {
+			return types.Subject{}, err
+		}
+	}
+
+	if req.ParentID != nil && *req.ParentID == 0 {
+		subject.ParentID = nil
+		subject.Path = types.Subject{}.ChildPath(subject.ID)
+	} else if req.ParentID != nil {
+		parent, err := s.getParent(*req.ParentID)
+		if err != nil {
+			return types.Subject{}, err
+		} else if subject.IsAncestorOf(*parent) {
+			return types.Subject{}, apierror.ErrInvalidInput.WithMessage("subject cannot be moved into its own subtree")
+		}
+
+		subject.ParentID = &parent.ID
+		subject.Path = parent.ChildPath(subject.ID)
+	}
+
+	if err := s.dataProvider.UpdateSubject(subject, oldPath); err != nil {
+		return types.Subject{}, s.codeConflict(err, *subject)
+	}
+
+	return *subject, nil
+}
+
+// DeleteSubject deletes a subject without children and books from the tree
+func (s *Service) DeleteSubject(id int) (types.DeleteSubjectResponse, error) {
+	subject, err := s.GetSubject(id)
+	if err != nil {
+		return types.DeleteSubjectResponse{}, err
+	}
+
+	children, err := s.dataProvider.CountSubjectChildren(id)
+	if err != nil {
+		return types.DeleteSubjectResponse{}, err
+	} else if children > 0 {
+		return types.DeleteSubjectResponse{}, apierror.ErrConflict.WithMessage(fmt.Sprintf("subject has %d children", children))
+	}
+
+	books, err := s.dataProvider.CountSubjectBooks(id)
+	if err != nil {
+		return types.DeleteSubjectResponse{}, err
+	} else if books > 0 {
+		return types.DeleteSubjectResponse{}, apierror.ErrConflict.WithMessage(fmt.Sprintf("subject has %d books", books))
+	}
+
+	return types.DeleteSubjectResponse{}, s.dataProvider.DeleteSubject(subject)
+}
+
+// GetSubjects returns a list of subjects matching the filter, ordered along the tree
+func (s *Service) GetSubjects(filter types.SubjectFilter, page int, limit int) (types.GetSubjectsResponse, error) {
+	validate := validator.New()
+	if err := validate.Var(filter.Scheme, "omitempty,oneof=dewey bisac"); err != nil {
+		return types.GetSubjectsResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid scheme: %s", filter.Scheme))
+	}
+
+	pagination, subjects, err := s.dataProvider.GetSubjects(filter, page, limit)
+	if err != nil {
+		return types.GetSubjectsResponse{}, err
+	}
+
+	return types.GetSubjectsResponse{
+		Subjects:   subjects,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetSubject returns a subject from the given id
+func (s *Service) GetSubject(id int) (*types.Subject, error) {
+	subject, err := s.dataProvider.GetSubject(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("subject not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return subject, nil
+}
+
+// GetBookSubjects returns the subjects a book is classified in
+func (s *Service) GetBookSubjects(bookID int) (types.GetBookSubjectsResponse, error) {
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetBookSubjectsResponse{}, err
+	}
+
+	subjects, err := s.dataProvider.GetBookSubjects(bookID)
+	if err != nil {
+		return types.GetBookSubjectsResponse{}, err
+	}
+
+	return types.GetBookSubjectsResponse{
+		Subjects: subjects,
+	}, nil
+}
+
+// SetBookSubjects replaces the subjects a book is classified in
+func (s *Service) SetBookSubjects(bookID int, req types.SetBookSubjectsRequest) (types.GetBookSubjectsResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.GetBookSubjectsResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetBookSubjectsResponse{}, err
+	}
+
+	seen := make(map[int]bool)
+	for _, id := range req.SubjectIDs {
+		if seen[id] {
+			return types.GetBookSubjectsResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("subject %d is given twice", id))
+		}
+		seen[id] = true
+
+		if _, err := s.dataProvider.GetSubject(id); errors.Is(err, gorm.ErrRecordNotFound) {
+			return types.GetBookSubjectsResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("subject not found: %d", id))
+		} else if err != nil {
+			return types.GetBookSubjectsResponse{}, err
+		}
+	}
+
+	if err := s.dataProvider.SetBookSubjects(bookID, req.SubjectIDs); err != nil {
+		return types.GetBookSubjectsResponse{}, err
+	}
+
+	return s.GetBookSubjects(bookID)
+}
+
+// getParent returns the subject a subject is added or moved under
+func (s *Service) getParent(id int) (*types.Subject, error) {
+	parent, err := s.dataProvider.GetSubject(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("parent subject not found: %d", id))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return parent, nil
+}
+
+// checkCodeAvailable checks that the code of the subject is valid in its scheme and no other
+// subject has it
+func (s *Service) checkCodeAvailable(subject types.Subject) error {
+	if subject.Scheme == types.SUBJECT_SCHEME_NONE {
+		return nil
+	} else if !subject.Scheme.ValidCode(subject.Code) {
+		return apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s code: %s", subject.Scheme, subject.Code))
+	}
+
+	existing, err := s.dataProvider.GetSubjectByCode(subject.Scheme, subject.Code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.ID != subject.ID {
+		return apierror.NewConflictError(fmt.Sprintf("subject already exists: %d", existing.ID), existing.ID)
+	}
+	return nil
+}
+
+// codeConflict returns the conflict with the subject holding the code of the subject if err is a
+// duplicated key, as when another subject with the code is added after the code was checked.
+// Otherwise it returns err.
+func (s *Service) codeConflict(err error, subject types.Subject) error {
+	if subject.Scheme == types.SUBJECT_SCHEME_NONE || !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	if conflict := s.checkCodeAvailable(subject); conflict != nil {
+		return conflict
+	}
+	// the other subject is gone already.
+	return apierror.ErrConflict.WithMessage(fmt.Sprintf("%s code already exists: %s", subject.Scheme, subject.Code))
+}
+
+func (s *Service) checkBookExists(bookID int) error {
+	exists, err := s.dataProvider.BookExists(bookID)
+	if err != nil {
+		return err
+	} else if !exists {
+		return apierror.NewNotFoundError("book not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,279 @@
+package testutil
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// RunDataProviderTests checks that a data provider behaves as the classification service
+// expects. newDataProvider returns an empty data provider for every test, along with the books
+// data provider of the same database, creating the classified books. ctx is the context of the
+// requests given to the data providers.
+func RunDataProviderTests(t *testing.T, ctx context.Context, newDataProvider func(t *testing.T) (types.DataProvider, booktypes.DataProvider)) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider)
+	}{
+		{"set book tags", testSetBookTags},
+		{"list tags", testGetTags},
+		{"subject tree", testSubjectTree},
+		{"move subject", testMoveSubject},
+		{"duplicate subject code", testDuplicateSubjectCode},
+		{"filter subjects", testFilterSubjects},
+		{"set book subjects", testSetBookSubjects},
+		{"tenant isolation", testTenantIsolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, books := newDataProvider(t)
+			tt.test(t, ctx, d, books)
+		})
+	}
+}
+
+// createBook creates a book to classify.
+func createBook(t *testing.T, books booktypes.DataProvider) booktypes.Book {
+	book, err := books.CreateBook(booktypes.Book{Title: "title", Author: "author", Description: "description"})
+	require.NoError(t, err)
+
+	return book
+}
+
+// createSubject creates a subject under the parent, or a root subject if parent is nil.
+func createSubject(t *testing.T, d types.DataProvider, name string, code string, parent *types.Subject) types.Subject {
+	subject := types.Subject{Name: name}
+	if code != "" {
+		subject.Scheme, subject.Code = types.SUBJECT_SCHEME_DEWEY, code
+	}
+
+	created, err := d.CreateSubject(subject, parent)
+	require.NoError(t, err)
+
+	return created
+}
+
+// subjectNames returns the names of the subjects in their order.
+func subjectNames(subjects []types.Subject) []string {
+	names := []string{}
+	for _, subject := range subjects {
+		names = append(names, subject.Name)
+	}
+
+	return names
+}
+
+func testSetBookTags(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	other := createBook(t, books)
+
+	require.NoError(t, d.SetBookTags(book.ID, []string{"fantasy", "classic"}))
+	// the existing tags are reused.
+	require.NoError(t, d.SetBookTags(other.ID, []string{"fantasy", "humour"}))
+
+	tags, err := d.GetBookTags(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"classic", "fantasy"}, tags)
+
+	require.NoError(t, d.SetBookTags(book.ID, []string{"humour"}))
+	tags, err = d.GetBookTags(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"humour"}, tags)
+
+	require.NoError(t, d.SetBookTags(book.ID, nil))
+	tags, err = d.GetBookTags(book.ID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	tags, err = d.GetBookTags(other.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fantasy", "humour"}, tags)
+}
+
+func testGetTags(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	other := createBook(t, books)
+	require.NoError(t, d.SetBookTags(book.ID, []string{"fantasy", "classic"}))
+	require.NoError(t, d.SetBookTags(other.ID, []string{"fantasy"}))
+
+	p, tags, err := d.GetTags("", 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), p.TotalRows)
+	require.Len(t, tags, 2)
+	require.Equal(t, "classic", tags[0].Name)
+	require.Equal(t, int64(1), tags[0].BookCount)
+	require.Equal(t, "fantasy", tags[1].Name)
+	require.Equal(t, int64(2), tags[1].BookCount)
+
+	p, tags, err = d.GetTags("FANT", 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), p.TotalRows)
+	require.Equal(t, "fantasy", tags[0].Name)
+}
+
+func testSubjectTree(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	literature := createSubject(t, d, "Literature", "800", nil)
+	english := createSubject(t, d, "English literature", "820", &literature)
+	fiction := createSubject(t, d, "English fiction", "823", &english)
+
+	require.Nil(t, literature.ParentID)
+	require.Equal(t, literature.ChildPath(english.ID), english.Path)
+	require.Equal(t, english.ChildPath(fiction.ID), fiction.Path)
+
+	got, err := d.GetSubject(fiction.ID)
+	require.NoError(t, err)
+	require.Equal(t, fiction.Path, got.Path)
+	require.Equal(t, english.ID, *got.ParentID)
+
+	got, err = d.GetSubjectByCode(types.SUBJECT_SCHEME_DEWEY, "820")
+	require.NoError(t, err)
+	require.Equal(t, english.ID, got.ID)
+	_, err = d.GetSubjectByCode(types.SUBJECT_SCHEME_BISAC, "820")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	count, err := d.CountSubjectChildren(literature.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+	count, err = d.CountSubjectChildren(fiction.ID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	require.NoError(t, d.DeleteSubject(&fiction))
+	_, err = d.GetSubject(fiction.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testMoveSubject(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	literature := createSubject(t, d, "Literature", "", nil)
+	english := createSubject(t, d, "English literature", "", &literature)
+	fiction := createSubject(t, d, "English fiction", "", &english)
+	arts := createSubject(t, d, "Arts", "", nil)
+
+	// the subtree of a moved subject is moved along.
+	oldPath := english.Path
+	english.ParentID = &arts.ID
+	english.Path = arts.ChildPath(english.ID)
+	require.NoError(t, d.UpdateSubject(&english, oldPath))
+
+	got, err := d.GetSubject(fiction.ID)
+	require.NoError(t, err)
+	require.Equal(t, english.ChildPath(fiction.ID), got.Path)
+
+	_, subjects, err := d.GetSubjects(types.SubjectFilter{Under: arts.ID}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Arts", "English literature", "English fiction"}, subjectNames(subjects))
+
+	_, subjects, err = d.GetSubjects(types.SubjectFilter{Under: literature.ID}, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Literature"}, subjectNames(subjects))
+}
+
+func testDuplicateSubjectCode(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	createSubject(t, d, "Literature", "800", nil)
+
+	_, err := d.CreateSubject(types.Subject{Name: "Other", Scheme: types.SUBJECT_SCHEME_DEWEY, Code: "800"}, nil)
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+
+	// subjects without code don't conflict.
+	createSubject(t, d, "Uncoded", "", nil)
+	createSubject(t, d, "Uncoded", "", nil)
+}
+
+func testFilterSubjects(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	literature := createSubject(t, d, "Literature", "800", nil)
+	createSubject(t, d, "English literature", "820", &literature)
+	_, err := d.CreateSubject(types.Subject{Name: "Fantasy", Scheme: types.SUBJECT_SCHEME_BISAC, Code: "FIC009000"}, nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		filter types.SubjectFilter
+		names  []string
+	}{
+		{
+			name:  "whole tree in order",
+			names: []string{"Literature", "English literature", "Fantasy"},
+		},
+		{
+			name:   "name regardless of case",
+			filter: types.SubjectFilter{Query: "LITERATURE"},
+			names:  []string{"Literature", "English literature"},
+		},
+		{
+			name:   "code",
+			filter: types.SubjectFilter{Query: "fic009"},
+			names:  []string{"Fantasy"},
+		},
+		{
+			name:   "scheme",
+			filter: types.SubjectFilter{Scheme: types.SUBJECT_SCHEME_BISAC},
+			names:  []string{"Fantasy"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, subjects, err := d.GetSubjects(tc.filter, 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.names)), p.TotalRows)
+			require.Equal(t, tc.names, subjectNames(subjects))
+		})
+	}
+}
+
+func testSetBookSubjects(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	literature := createSubject(t, d, "Literature", "800", nil)
+	english := createSubject(t, d, "English literature", "820", &literature)
+
+	exists, err := d.BookExists(book.ID)
+	require.NoError(t, err)
+	require.True(t, exists)
+	exists, err = d.BookExists(book.ID + 1)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	require.NoError(t, d.SetBookSubjects(book.ID, []int{english.ID, literature.ID}))
+	subjects, err := d.GetBookSubjects(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Literature", "English literature"}, subjectNames(subjects))
+
+	count, err := d.CountSubjectBooks(english.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	require.NoError(t, d.SetBookSubjects(book.ID, nil))
+	subjects, err = d.GetBookSubjects(book.ID)
+	require.NoError(t, err)
+	require.Empty(t, subjects)
+}
+
+func testTenantIsolation(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	require.NoError(t, d.SetBookTags(book.ID, []string{"fantasy"}))
+	subject := createSubject(t, d, "Literature", "800", nil)
+
+	other := d.WithContext(tenant.NewContext(context.Background(), "other-tenant"))
+	_, err := other.GetSubject(subject.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	p, _, err := other.GetTags("", 1, 10)
+	require.NoError(t, err)
+	require.Zero(t, p.TotalRows)
+
+	// a tag and a code are used once per tenant.
+	otherBook := createBook(t, books.WithContext(tenant.NewContext(context.Background(), "other-tenant")))
+	require.NoError(t, other.SetBookTags(otherBook.ID, []string{"fantasy"}))
+	createSubject(t, other, "Literature", "800", nil)
+
+	tags, err := d.GetBookTags(book.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fantasy"}, tags)
+}
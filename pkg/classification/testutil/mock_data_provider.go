@@ -0,0 +1,264 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/classification/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/classification/testutil/mock_data_provider.go --source ./pkg/classification/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/classification/types"
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// BookExists mocks base method.
+func (m *MockDataProvider) BookExists(bookID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookExists", bookID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookExists indicates an expected call of BookExists.
+func (mr *MockDataProviderMockRecorder) BookExists(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookExists", reflect.TypeOf((*MockDataProvider)(nil).BookExists), bookID)
+}
+
+// CountSubjectBooks mocks base method.
+func (m *MockDataProvider) CountSubjectBooks(id int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSubjectBooks", id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSubjectBooks indicates an expected call of CountSubjectBooks.
+func (mr *MockDataProviderMockRecorder) CountSubjectBooks(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSubjectBooks", reflect.TypeOf((*MockDataProvider)(nil).CountSubjectBooks), id)
+}
+
+// CountSubjectChildren mocks base method.
+func (m *MockDataProvider) CountSubjectChildren(id int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountSubjectChildren", id)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountSubjectChildren indicates an expected call of CountSubjectChildren.
+func (mr *MockDataProviderMockRecorder) CountSubjectChildren(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountSubjectChildren", reflect.TypeOf((*MockDataProvider)(nil).CountSubjectChildren), id)
+}
+
+// CreateSubject mocks base method.
+func (m *MockDataProvider) CreateSubject(subject types.Subject, parent *types.Subject) (types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubject", subject, parent)
+	ret0, _ := ret[0].(types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSubject indicates an expected call of CreateSubject.
+func (mr *MockDataProviderMockRecorder) CreateSubject(subject, parent any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubject", reflect.TypeOf((*MockDataProvider)(nil).CreateSubject), subject, parent)
+}
+
+// DeleteSubject mocks base method.
+func (m *MockDataProvider) DeleteSubject(subject *types.Subject) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteSubject", subject)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteSubject indicates an expected call of DeleteSubject.
+func (mr *MockDataProviderMockRecorder) DeleteSubject(subject any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteSubject", reflect.TypeOf((*MockDataProvider)(nil).DeleteSubject), subject)
+}
+
+// GetBookSubjects mocks base method.
+func (m *MockDataProvider) GetBookSubjects(bookID int) ([]types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookSubjects", bookID)
+	ret0, _ := ret[0].([]types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookSubjects indicates an expected call of GetBookSubjects.
+func (mr *MockDataProviderMockRecorder) GetBookSubjects(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookSubjects", reflect.TypeOf((*MockDataProvider)(nil).GetBookSubjects), bookID)
+}
+
+// GetBookTags mocks base method.
+func (m *MockDataProvider) GetBookTags(bookID int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBookTags", bookID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookTags indicates an expected call of GetBookTags.
+func (mr *MockDataProviderMockRecorder) GetBookTags(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookTags", reflect.TypeOf((*MockDataProvider)(nil).GetBookTags), bookID)
+}
+
+// GetSubject mocks base method.
+func (m *MockDataProvider) GetSubject(id int) (*types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubject", id)
+	ret0, _ := ret[0].(*types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubject indicates an expected call of GetSubject.
+func (mr *MockDataProviderMockRecorder) GetSubject(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubject", reflect.TypeOf((*MockDataProvider)(nil).GetSubject), id)
+}
+
+// GetSubjectByCode mocks base method.
+func (m *MockDataProvider) GetSubjectByCode(scheme types.SubjectScheme, code string) (*types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjectByCode", scheme, code)
+	ret0, _ := ret[0].(*types.Subject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubjectByCode indicates an expected call of GetSubjectByCode.
+func (mr *MockDataProviderMockRecorder) GetSubjectByCode(scheme, code any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjectByCode", reflect.TypeOf((*MockDataProvider)(nil).GetSubjectByCode), scheme, code)
+}
+
+// GetSubjects mocks base method.
+func (m *MockDataProvider) GetSubjects(filter types.SubjectFilter, page, limit int) (*db.Pagination, []types.Subject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubjects", filter, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.Subject)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSubjects indicates an expected call of GetSubjects.
+func (mr *MockDataProviderMockRecorder) GetSubjects(filter, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubjects", reflect.TypeOf((*MockDataProvider)(nil).GetSubjects), filter, page, limit)
+}
+
+// GetTags mocks base method.
+func (m *MockDataProvider) GetTags(query string, page, limit int) (*db.Pagination, []types.Tag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTags", query, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.Tag)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTags indicates an expected call of GetTags.
+func (mr *MockDataProviderMockRecorder) GetTags(query, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTags", reflect.TypeOf((*MockDataProvider)(nil).GetTags), query, page, limit)
+}
+
+// SetBookSubjects mocks base method.
+func (m *MockDataProvider) SetBookSubjects(bookID int, subjectIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBookSubjects", bookID, subjectIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBookSubjects indicates an expected call of SetBookSubjects.
+func (mr *MockDataProviderMockRecorder) SetBookSubjects(bookID, subjectIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBookSubjects", reflect.TypeOf((*MockDataProvider)(nil).SetBookSubjects), bookID, subjectIDs)
+}
+
+// SetBookTags mocks base method.
+func (m *MockDataProvider) SetBookTags(bookID int, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetBookTags", bookID, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetBookTags indicates an expected call of SetBookTags.
+func (mr *MockDataProviderMockRecorder) SetBookTags(bookID, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetBookTags", reflect.TypeOf((*MockDataProvider)(nil).SetBookTags), bookID, names)
+}
+
+// UpdateSubject mocks base method.
+func (m *MockDataProvider) UpdateSubject(subject *types.Subject, oldPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubject", subject, oldPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubject indicates an expected call of UpdateSubject.
+func (mr *MockDataProviderMockRecorder) UpdateSubject(subject, oldPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubject", reflect.TypeOf((*MockDataProvider)(nil).UpdateSubject), subject, oldPath)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tag is the model for a free-form label of books. Names are normalized, see
+// booktypes.NormalizeTag.
+type Tag struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_tags_tenant_name"`
+	Name     string `json:"name" gorm:"not null;uniqueIndex:idx_tags_tenant_name" example:"fantasy"`
+	// BookCount is the number of books having the tag, only read when listing the tags.
+	BookCount int64     `json:"book_count" gorm:"->;-:migration" example:"3"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BookTag is the model for the relation of a book and a tag.
+type BookTag struct {
+	BookID   int    `json:"book_id" gorm:"primaryKey" example:"1"`
+	TagID    int    `json:"tag_id" gorm:"primaryKey;index" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';index"`
+}
+
+// TableName overrides the table name used by gorm.
+func (BookTag) TableName() string {
+	return "book_tags"
+}
+
+type SubjectScheme string
+
+const (
+	// SUBJECT_SCHEME_NONE is a subject without a code in a standard classification.
+	SUBJECT_SCHEME_NONE  SubjectScheme = ""
+	SUBJECT_SCHEME_DEWEY SubjectScheme = "dewey"
+	SUBJECT_SCHEME_BISAC SubjectScheme = "bisac"
+)
+
+// subjectCodeFormats are the formats of the codes of the subject schemes, e.g. "823.914" in
+// the Dewey Decimal Classification and "FIC009000" in BISAC.
+var subjectCodeFormats = map[SubjectScheme]*regexp.Regexp{
+	SUBJECT_SCHEME_DEWEY: regexp.MustCompile(`^\d{3}(\.\d+)?$`),
+	SUBJECT_SCHEME_BISAC: regexp.MustCompile(`^[A-Z]{3}\d{6}$`),
+}
+
+// ValidCode checks the code has the format of the scheme.
+func (s SubjectScheme) ValidCode(code string) bool {
+	format, ok := subjectCodeFormats[s]
+	return ok && format.MatchString(code)
+}
+
+// Subject is the model for a node of the subject tree. A subject may hold the code of a
+// standard classification, unique within its scheme.
+type Subject struct {
+	ID       int           `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string        `json:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_subjects_tenant_code,where:code <> ''"`
+	ParentID *int          `json:"parent_id" gorm:"index" example:"1"`
+	Name     string        `json:"name" gorm:"not null" example:"Fantasy"`
+	Scheme   SubjectScheme `json:"scheme,omitempty" gorm:"uniqueIndex:idx_subjects_tenant_code" example:"bisac"`
+	Code     string        `json:"code,omitempty" gorm:"uniqueIndex:idx_subjects_tenant_code" example:"FIC009000"`
+	// Path is the ids of the ancestors of the subject and its own id, each followed by a
+	// slash, e.g. "1/4/9/". The subtree of a subject is the subjects whose path starts with
+	// its path.
+	Path      string    `json:"path" gorm:"not null;index" example:"1/4/9/"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChildPath returns the path of a child of the subject with the given id.
+func (s Subject) ChildPath(childID int) string {
+	return s.Path + strconv.Itoa(childID) + "/"
+}
+
+// IsAncestorOf checks if the other subject is in the subtree of the subject, itself included.
+func (s Subject) IsAncestorOf(other Subject) bool {
+	return strings.HasPrefix(other.Path, s.Path)
+}
+
+// BookSubject is the model for the classification of a book in a subject.
+type BookSubject struct {
+	BookID    int    `json:"book_id" gorm:"primaryKey" example:"1"`
+	SubjectID int    `json:"subject_id" gorm:"primaryKey;index" example:"1"`
+	TenantID  string `json:"-" gorm:"<-:create;not null;default:'default';index"`
+}
+
+// TableName overrides the table name used by gorm.
+func (BookSubject) TableName() string {
+	return "book_subjects"
+}
+
+// SubjectFilter narrows down the subjects listed by GetSubjects.
+type SubjectFilter struct {
+	// Query is a part of the name or the code of the subjects.
+	Query  string
+	Scheme SubjectScheme
+	// Under is the subject whose subtree is listed, itself included. 0 means the whole tree.
+	Under int
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for a classification service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	// GetTags lists the tags containing the query with their number of books.
+	GetTags(query string, page int, limit int) (*db.Pagination, []Tag, error)
+	GetBookTags(bookID int) ([]string, error)
+	// SetBookTags replaces the tags of a book, creating the missing ones.
+	SetBookTags(bookID int, names []string) error
+
+	// CreateSubject creates a subject under the parent, or a root subject if parent is nil.
+	CreateSubject(subject Subject, parent *Subject) (Subject, error)
+	// UpdateSubject saves the subject. If its path changed from oldPath, the paths of its
+	// descendants are moved along.
+	UpdateSubject(subject *Subject, oldPath string) error
+	DeleteSubject(subject *Subject) error
+	GetSubjects(filter SubjectFilter, page int, limit int) (*db.Pagination, []Subject, error)
+	GetSubject(id int) (*Subject, error)
+	GetSubjectByCode(scheme SubjectScheme, code string) (*Subject, error)
+	CountSubjectChildren(id int) (int64, error)
+	CountSubjectBooks(id int) (int64, error)
+
+	BookExists(bookID int) (bool, error)
+	GetBookSubjects(bookID int) ([]Subject, error)
+	SetBookSubjects(bookID int, subjectIDs []int) error
+}
@@ -0,0 +1,60 @@
+package types
+
+import "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+
+// GetTagsResponse is the response for getting a list of tags
+type GetTagsResponse struct {
+	Tags       []Tag          `json:"tags"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// GetBookTagsResponse is the response for getting the tags of a book
+type GetBookTagsResponse struct {
+	Tags []string `json:"tags" example:"fantasy"`
+}
+
+// SetBookTagsRequest is the request for replacing the tags of a book; missing tags are created
+type SetBookTagsRequest struct {
+	Tags []string `json:"tags" example:"fantasy" validate:"dive,required,max=64"`
+}
+
+// AddSubjectRequest is the request for adding a new subject
+type AddSubjectRequest struct {
+	ParentID int           `json:"parent_id" example:"1"`
+	Name     string        `json:"name" example:"Fantasy" validate:"required,max=255"`
+	Scheme   SubjectScheme `json:"scheme" example:"bisac" validate:"omitempty,oneof=dewey bisac"`
+	Code     string        `json:"code" example:"FIC009000" validate:"required_with=Scheme,excluded_without=Scheme"`
+}
+
+// AddSubjectResponse is the response for adding a new subject
+type AddSubjectResponse struct {
+	ID int `json:"id" example:"1"`
+}
+
+// UpdateSubjectRequest is the request for updating a subject. A parent id moves the subject
+// with its subtree, 0 moving it to the root; the scheme and the code are changed together.
+type UpdateSubjectRequest struct {
+	ParentID *int          `json:"parent_id" example:"1"`
+	Name     string        `json:"name" example:"Fantasy" validate:"max=255"`
+	Scheme   SubjectScheme `json:"scheme" example:"bisac" validate:"omitempty,oneof=dewey bisac"`
+	Code     string        `json:"code" example:"FIC009000" validate:"required_with=Scheme,excluded_without=Scheme"`
+}
+
+// GetSubjectsResponse is the response for getting a list of subjects
+type GetSubjectsResponse struct {
+	Subjects   []Subject      `json:"subjects"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// DeleteSubjectResponse is the response for deleting a subject
+type DeleteSubjectResponse struct{}
+
+// GetBookSubjectsResponse is the response for getting the subjects of a book
+type GetBookSubjectsResponse struct {
+	Subjects []Subject `json:"subjects"`
+}
+
+// SetBookSubjectsRequest is the request for replacing the subjects of a book
+type SetBookSubjectsRequest struct {
+	SubjectIDs []int `json:"subject_ids" example:"1" validate:"dive,required"`
+}
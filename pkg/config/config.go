@@ -2,28 +2,196 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // DBConn represents the database connection configuration.
 type DBConn struct {
+	// Driver is the database used, postgres, sqlite or memory, see db.Init.
+	Driver string `yaml:"driver" mapstructure:"driver"`
+	// Path is the file of the sqlite database.
+	Path     string `yaml:"path" mapstructure:"path"`
 	Host     string `yaml:"host" mapstructure:"host"`
 	Port     string `yaml:"port" mapstructure:"port"`
 	Username string `yaml:"username" mapstructure:"username"`
 	Password string `yaml:"password" mapstructure:"password"`
 	DBName   string `yaml:"db_name" mapstructure:"database_name"`
+	// SSLMode is the sslmode of the Postgres connection, e.g. disable, require or verify-full;
+	// the certificates are the paths of PEM files.
+	SSLMode     string `yaml:"sslmode" mapstructure:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert" mapstructure:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert" mapstructure:"sslcert"`
+	SSLKey      string `yaml:"sslkey" mapstructure:"sslkey"`
+	// StatementTimeout aborts the statements running longer, 0 for no limit.
+	StatementTimeout time.Duration `yaml:"statement_timeout" mapstructure:"statement_timeout"`
+	ApplicationName  string        `yaml:"application_name" mapstructure:"application_name"`
+	Pool             DBPool        `yaml:"pool" mapstructure:"pool"`
+	Retry            DBRetry       `yaml:"retry" mapstructure:"retry"`
+}
+
+// DBPool represents the pool of connections to the Postgres database. 0 means no limit.
+type DBPool struct {
+	MaxOpenConns    int           `yaml:"max_open_conns" mapstructure:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" mapstructure:"conn_max_idle_time"`
+}
+
+// DBRetry represents how long the database is waited for when the server starts.
+type DBRetry struct {
+	Timeout        time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
 }
 
 type App struct {
 	Port string `yaml:"port" mapstructure:"port"`
 }
 
+// Idempotency represents the configuration of requests sent with an idempotency key.
+type Idempotency struct {
+	TTL           time.Duration `yaml:"ttl" mapstructure:"ttl"`
+	Lease         time.Duration `yaml:"lease" mapstructure:"lease"`
+	MaxBodySize   int64         `yaml:"max_body_size" mapstructure:"max_body_size"`
+	PurgeInterval time.Duration `yaml:"purge_interval" mapstructure:"purge_interval"`
+}
+
+// OutboxSinks represents the destinations of the events published from the outbox.
+type OutboxSinks struct {
+	Log            bool          `yaml:"log" mapstructure:"log"`
+	File           string        `yaml:"file" mapstructure:"file"`
+	WebhookURL     string        `yaml:"webhook_url" mapstructure:"webhook_url"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout" mapstructure:"webhook_timeout"`
+}
+
+// Outbox represents the configuration of the relay publishing the events from the outbox.
+type Outbox struct {
+	PollInterval time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size" mapstructure:"batch_size"`
+	// Lease is how long a relay has to publish the batch it claimed before the other relays
+	// claim it again.
+	Lease          time.Duration `yaml:"lease" mapstructure:"lease"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+	Sinks          OutboxSinks   `yaml:"sinks" mapstructure:"sinks"`
+}
+
+// Webhooks represents the configuration of the dispatcher sending the webhook deliveries.
+type Webhooks struct {
+	PollInterval   time.Duration `yaml:"poll_interval" mapstructure:"poll_interval"`
+	BatchSize      int           `yaml:"batch_size" mapstructure:"batch_size"`
+	Timeout        time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	MaxAttempts    int           `yaml:"max_attempts" mapstructure:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff" mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff" mapstructure:"max_backoff"`
+}
+
+// Feed represents the configuration of the server-sent events feed of the book changes.
+type Feed struct {
+	Backend    string        `yaml:"backend" mapstructure:"backend"`
+	Channel    string        `yaml:"channel" mapstructure:"channel"`
+	BufferSize int           `yaml:"buffer_size" mapstructure:"buffer_size"`
+	Heartbeat  time.Duration `yaml:"heartbeat" mapstructure:"heartbeat"`
+}
+
+// S3 represents an S3-compatible object storage.
+type S3 struct {
+	Endpoint        string        `yaml:"endpoint" mapstructure:"endpoint"`
+	Region          string        `yaml:"region" mapstructure:"region"`
+	Bucket          string        `yaml:"bucket" mapstructure:"bucket"`
+	AccessKeyID     string        `yaml:"access_key_id" mapstructure:"access_key_id"`
+	SecretAccessKey string        `yaml:"secret_access_key" mapstructure:"secret_access_key"`
+	PathStyle       bool          `yaml:"path_style" mapstructure:"path_style"`
+	Timeout         time.Duration `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// Blob represents the store of the uploaded files.
+type Blob struct {
+	Backend string `yaml:"backend" mapstructure:"backend"`
+	Dir     string `yaml:"dir" mapstructure:"dir"`
+	S3      S3     `yaml:"s3" mapstructure:"s3"`
+}
+
+// Covers represents the limits of the uploaded cover images.
+type Covers struct {
+	MaxSize   int64 `yaml:"max_size" mapstructure:"max_size"`
+	MaxPixels int   `yaml:"max_pixels" mapstructure:"max_pixels"`
+}
+
+// Tier represents the limits of a membership tier.
+type Tier struct {
+	MaxLoans int `yaml:"max_loans" mapstructure:"max_loans"`
+}
+
+// Fines represents the schedule of the fines charged for late returns. The amounts are in the
+// minor unit of the currency.
+type Fines struct {
+	Currency    string        `yaml:"currency" mapstructure:"currency"`
+	DailyRate   int64         `yaml:"daily_rate" mapstructure:"daily_rate"`
+	GracePeriod time.Duration `yaml:"grace_period" mapstructure:"grace_period"`
+	MaxPerLoan  int64         `yaml:"max_per_loan" mapstructure:"max_per_loan"`
+	Limit       int64         `yaml:"limit" mapstructure:"limit"`
+}
+
+// Loans represents the lending rules and the schedule of the overdue and hold checks.
+type Loans struct {
+	Period               time.Duration   `yaml:"period" mapstructure:"period"`
+	MaxRenewals          int             `yaml:"max_renewals" mapstructure:"max_renewals"`
+	OverdueCheckInterval time.Duration   `yaml:"overdue_check_interval" mapstructure:"overdue_check_interval"`
+	HoldPickupPeriod     time.Duration   `yaml:"hold_pickup_period" mapstructure:"hold_pickup_period"`
+	HoldCheckInterval    time.Duration   `yaml:"hold_check_interval" mapstructure:"hold_check_interval"`
+	DefaultTier          string          `yaml:"default_tier" mapstructure:"default_tier"`
+	Tiers                map[string]Tier `yaml:"tiers" mapstructure:"tiers"`
+	Fines                Fines           `yaml:"fines" mapstructure:"fines"`
+
+	// Tenants are the lending rules of the tenants overriding some of these ones, read from
+	// tenancy.tenants.<id>.loans.
+	Tenants map[string]Loans `yaml:"-" mapstructure:"-"`
+}
+
+// For returns the lending rules of the tenant.
+func (l Loans) For(tenant string) Loans {
+	if loans, ok := l.Tenants[tenant]; ok {
+		return loans
+	}
+
+	return l
+}
+
+// Tenant represents an organization whose catalog is hosted on the deployment.
+type Tenant struct {
+	Name string `yaml:"name" mapstructure:"name"`
+}
+
+// Tenancy represents how the tenant of a request is found. A request is in the tenant named by
+// the claim of its bearer token, its subdomain of Domain or its tenant header, which must be one
+// of the configured tenants.
+type Tenancy struct {
+	Enabled     bool              `yaml:"enabled" mapstructure:"enabled"`
+	Header      string            `yaml:"header" mapstructure:"header"`
+	Domain      string            `yaml:"domain" mapstructure:"domain"`
+	Claim       string            `yaml:"claim" mapstructure:"claim"`
+	TokenSecret string            `yaml:"token_secret" mapstructure:"token_secret"`
+	Tenants     map[string]Tenant `yaml:"tenants" mapstructure:"tenants"`
+}
+
 // Config represents the configuration of the application.
 type Config struct {
-	Conn DBConn `yaml:"database_connection" mapstructure:"database_connection"`
-	App  App    `yaml:"app" mapstructure:"app"`
+	Conn        DBConn      `yaml:"database_connection" mapstructure:"database_connection"`
+	App         App         `yaml:"app" mapstructure:"app"`
+	Idempotency Idempotency `yaml:"idempotency" mapstructure:"idempotency"`
+	Outbox      Outbox      `yaml:"outbox" mapstructure:"outbox"`
+	Webhooks    Webhooks    `yaml:"webhooks" mapstructure:"webhooks"`
+	Feed        Feed        `yaml:"feed" mapstructure:"feed"`
+	Blob        Blob        `yaml:"blob" mapstructure:"blob"`
+	Covers      Covers      `yaml:"covers" mapstructure:"covers"`
+	Loans       Loans       `yaml:"loans" mapstructure:"loans"`
+	Tenancy     Tenancy     `yaml:"tenancy" mapstructure:"tenancy"`
 }
 
 // splitFilename splits the filename into name and extension.
@@ -51,6 +219,63 @@ func ReadConfig(filename string) (Config, error) {
 	viper.AddConfigPath(".")
 	viper.AutomaticEnv()
 
+	viper.SetDefault("database_connection.driver", "postgres")
+	viper.SetDefault("database_connection.path", "data/example.db")
+	viper.SetDefault("database_connection.sslmode", "prefer")
+	viper.SetDefault("database_connection.application_name", "go-http-gorm-example")
+	viper.SetDefault("database_connection.pool.max_open_conns", 25)
+	viper.SetDefault("database_connection.pool.max_idle_conns", 5)
+	viper.SetDefault("database_connection.pool.conn_max_lifetime", "30m")
+	viper.SetDefault("database_connection.pool.conn_max_idle_time", "5m")
+	viper.SetDefault("database_connection.retry.timeout", "30s")
+	viper.SetDefault("database_connection.retry.initial_backoff", "500ms")
+	viper.SetDefault("database_connection.retry.max_backoff", "5s")
+	viper.SetDefault("idempotency.ttl", "24h")
+	viper.SetDefault("idempotency.lease", "1m")
+	viper.SetDefault("idempotency.max_body_size", 10<<20)
+	viper.SetDefault("idempotency.purge_interval", "1h")
+	viper.SetDefault("outbox.poll_interval", "1s")
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.lease", "5m")
+	viper.SetDefault("outbox.max_attempts", 10)
+	viper.SetDefault("outbox.initial_backoff", "1s")
+	viper.SetDefault("outbox.max_backoff", "10m")
+	viper.SetDefault("outbox.sinks.log", true)
+	viper.SetDefault("outbox.sinks.webhook_timeout", "5s")
+	viper.SetDefault("webhooks.poll_interval", "1s")
+	viper.SetDefault("webhooks.batch_size", 50)
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.max_attempts", 8)
+	viper.SetDefault("webhooks.initial_backoff", "30s")
+	viper.SetDefault("webhooks.max_backoff", "6h")
+	viper.SetDefault("feed.backend", "postgres")
+	viper.SetDefault("feed.channel", "book_events")
+	viper.SetDefault("feed.buffer_size", 1000)
+	viper.SetDefault("feed.heartbeat", "15s")
+	viper.SetDefault("blob.backend", "fs")
+	viper.SetDefault("blob.dir", "data/blobs")
+	viper.SetDefault("blob.s3.region", "us-east-1")
+	viper.SetDefault("blob.s3.timeout", "30s")
+	viper.SetDefault("covers.max_size", 5<<20)
+	viper.SetDefault("covers.max_pixels", 40_000_000)
+	viper.SetDefault("loans.period", "336h")
+	viper.SetDefault("loans.max_renewals", 2)
+	viper.SetDefault("loans.overdue_check_interval", "24h")
+	viper.SetDefault("loans.hold_pickup_period", "72h")
+	viper.SetDefault("loans.hold_check_interval", "1h")
+	viper.SetDefault("loans.default_tier", "standard")
+	viper.SetDefault("loans.tiers", map[string]interface{}{
+		"standard": map[string]interface{}{"max_loans": 5},
+		"premium":  map[string]interface{}{"max_loans": 10},
+	})
+	viper.SetDefault("loans.fines.currency", "USD")
+	viper.SetDefault("loans.fines.daily_rate", 25)
+	viper.SetDefault("loans.fines.max_per_loan", 1000)
+	viper.SetDefault("loans.fines.limit", 1000)
+	viper.SetDefault("tenancy.enabled", false)
+	viper.SetDefault("tenancy.header", "X-Tenant")
+	viper.SetDefault("tenancy.claim", "tenant")
+
 	if err := viper.ReadInConfig(); err != nil {
 		return Config{}, err
 	}
@@ -59,6 +284,63 @@ func ReadConfig(filename string) (Config, error) {
 	if err := viper.Unmarshal(&config); err != nil {
 		return Config{}, err
 	}
+	if config.Loans.Tenants, err = readTenantLoans(config.Tenancy); err != nil {
+		return Config{}, err
+	}
+	if err := validateIntervals(config); err != nil {
+		return Config{}, err
+	}
 
 	return config, nil
 }
+
+// validateIntervals checks the intervals of the background jobs are positive, since they are the
+// periods of their tickers.
+func validateIntervals(conf Config) error {
+	intervals := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"idempotency.purge_interval", conf.Idempotency.PurgeInterval},
+		{"outbox.poll_interval", conf.Outbox.PollInterval},
+		{"webhooks.poll_interval", conf.Webhooks.PollInterval},
+		{"feed.heartbeat", conf.Feed.Heartbeat},
+		{"loans.overdue_check_interval", conf.Loans.OverdueCheckInterval},
+		{"loans.hold_check_interval", conf.Loans.HoldCheckInterval},
+	}
+	for _, interval := range intervals {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", interval.key, interval.value)
+		}
+	}
+
+	return nil
+}
+
+// readTenantLoans reads the lending rules of the tenants overriding some of the rules of the
+// deployment.
+func readTenantLoans(conf Tenancy) (map[string]Loans, error) {
+	tenants := make(map[string]Loans)
+	for id := range conf.Tenants {
+		overrides := viper.GetStringMap("tenancy.tenants." + id + ".loans")
+		if len(overrides) == 0 {
+			continue
+		}
+
+		v := viper.New()
+		if err := v.MergeConfigMap(map[string]any{"loans": viper.AllSettings()["loans"]}); err != nil {
+			return nil, err
+		}
+		if err := v.MergeConfigMap(map[string]any{"loans": overrides}); err != nil {
+			return nil, err
+		}
+
+		var loans Loans
+		if err := v.UnmarshalKey("loans", &loans); err != nil {
+			return nil, err
+		}
+		tenants[id] = loans
+	}
+
+	return tenants, nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateIntervals(t *testing.T) {
+	valid := Config{
+		Idempotency: Idempotency{PurgeInterval: time.Hour},
+		Outbox:      Outbox{PollInterval: time.Second},
+		Webhooks:    Webhooks{PollInterval: time.Second},
+		Feed:        Feed{Heartbeat: 15 * time.Second},
+		Loans:       Loans{OverdueCheckInterval: 24 * time.Hour, HoldCheckInterval: time.Hour},
+	}
+	require.NoError(t, validateIntervals(valid))
+
+	testCases := []struct {
+		name   string
+		modify func(conf *Config)
+		err    string
+	}{
+		{
+			name:   "zero purge interval",
+			modify: func(conf *Config) { conf.Idempotency.PurgeInterval = 0 },
+			err:    "idempotency.purge_interval must be positive, got 0s",
+		},
+		{
+			name:   "negative purge interval",
+			modify: func(conf *Config) { conf.Idempotency.PurgeInterval = -time.Hour },
+			err:    "idempotency.purge_interval must be positive, got -1h0m0s",
+		},
+		{
+			name:   "negative outbox poll interval",
+			modify: func(conf *Config) { conf.Outbox.PollInterval = -time.Second },
+			err:    "outbox.poll_interval must be positive, got -1s",
+		},
+		{
+			name:   "zero webhooks poll interval",
+			modify: func(conf *Config) { conf.Webhooks.PollInterval = 0 },
+			err:    "webhooks.poll_interval must be positive, got 0s",
+		},
+		{
+			name:   "zero feed heartbeat",
+			modify: func(conf *Config) { conf.Feed.Heartbeat = 0 },
+			err:    "feed.heartbeat must be positive, got 0s",
+		},
+		{
+			name:   "zero overdue check interval",
+			modify: func(conf *Config) { conf.Loans.OverdueCheckInterval = 0 },
+			err:    "loans.overdue_check_interval must be positive, got 0s",
+		},
+		{
+			name:   "zero hold check interval",
+			modify: func(conf *Config) { conf.Loans.HoldCheckInterval = 0 },
+			err:    "loans.hold_check_interval must be positive, got 0s",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := valid
+			tc.modify(&conf)
+			require.EqualError(t, validateIntervals(conf), tc.err)
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+// coverFormField is the name of the form field holding the image of a multipart upload.
+const coverFormField = "cover"
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the covers service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the covers service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/books/{id}/cover", h.GetCover)
+	mux.HandleFunc("GET /api/v1/books/{id}/cover/{size}", h.GetThumbnail)
+	mux.HandleFunc("PUT /api/v1/books/{id}/cover", h.SetCover)
+	mux.HandleFunc("DELETE /api/v1/books/{id}/cover", h.DeleteCover)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// @Summary upload the cover image of a book
+// @Description The image is either the request body or the "cover" field of a multipart form. Its type is
+// @Description found from its content, whatever the Content-Type given. JPEG and PNG covers get thumbnails.
+// @ID set-book-cover
+// @Accept image/jpeg,image/png,image/webp,multipart/form-data
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} types.SetCoverResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 413 {object} errors.Error
+// @Failure 415 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/cover [put]
+func (h Handler) SetCover(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	data, err := readCover(r, h.serv.MaxSize())
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).SetCover(ctx, bookID, data)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// readCover reads the uploaded image from the request, up to one byte more than the maximum
+// size so a too large image is told apart from one of the maximum size.
+func readCover(r *http.Request, maxSize int64) ([]byte, error) {
+	body := io.Reader(r.Body)
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		mr, err := r.MultipartReader()
+		if err != nil {
+			return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid multipart body: %v", err))
+		}
+
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("%s field is missing", coverFormField))
+			} else if err != nil {
+				return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid multipart body: %v", err))
+			}
+
+			if part.FormName() == coverFormField {
+				body = part
+				break
+			}
+		}
+	}
+
+	return io.ReadAll(io.LimitReader(body, maxSize+1))
+}
+
+// @Summary get the cover image of a book
+// @Description A request with the version of the cover in the v parameter, as in the URL of the book cover,
+// @Description can be cached for good. Any other request is revalidated with the ETag.
+// @ID get-book-cover
+// @Produce image/jpeg,image/png,image/webp
+// @Param id path int true "Book ID"
+// @Param v query string false "version of the cover"
+// @Success 200 {file} file
+// @Success 304
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/cover [get]
+func (h Handler) GetCover(w http.ResponseWriter, r *http.Request) {
+	h.serveCover(w, r, types.ORIGINAL)
+}
+
+// @Summary get a thumbnail of the cover image of a book
+// @ID get-book-cover-thumbnail
+// @Produce image/jpeg,image/png
+// @Param id path int true "Book ID"
+// @Param size path string true "thumbnail size" enums(small,medium,large)
+// @Param v query string false "version of the cover"
+// @Success 200 {file} file
+// @Success 304
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/cover/{size} [get]
+func (h Handler) GetThumbnail(w http.ResponseWriter, r *http.Request) {
+	h.serveCover(w, r, r.PathValue("size"))
+}
+
+func (h Handler) serveCover(w http.ResponseWriter, r *http.Request, name string) {
+	ctx := r.Context()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	file, err := h.serv.WithContext(ctx).StatCover(bookID, name)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%s-%s"`, file.Version, name)
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		setCacheHeaders(w, r, file, etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, err := h.serv.WithContext(ctx).OpenCover(ctx, file)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+	defer body.Close()
+
+	setCacheHeaders(w, r, file, etag)
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		h.log.Error(fmt.Sprintf("failed to write cover image %s: %v", file.Key, err))
+	}
+}
+
+// setCacheHeaders lets a cover image requested with its current version be cached for good,
+// since a new cover gets a new version. Any other request must be revalidated.
+func setCacheHeaders(w http.ResponseWriter, r *http.Request, file types.CoverFile, etag string) {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Last-Modified", file.UpdatedAt.UTC().Format(http.TimeFormat))
+	if r.URL.Query().Get("v") == file.Version {
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, no-cache")
+	}
+}
+
+// etagMatches reports whether the If-None-Match header matches the entity tag.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// @Summary delete the cover image of a book
+// @ID delete-book-cover
+// @Produce json
+// @Param id path int true "Book ID"
+// @Success 200 {object} types.DeleteCoverResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/cover [delete]
+func (h Handler) DeleteCover(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteCover(ctx, bookID)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
@@ -0,0 +1,418 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/blob"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/types"
+)
+
+func pngImage(t *testing.T, width int, height int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func jpegImage(t *testing.T, width int, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+// webpImage returns a WebP image of the testdata directory, taken from golang.org/x/image.
+func webpImage(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return data
+}
+
+func readBlob(t *testing.T, store blob.Store, key string) []byte {
+	r, err := store.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return data
+}
+
+func TestSetCover(t *testing.T) {
+	book := booktypes.Book{ID: 1, Title: "test-title", Author: "test-author", Description: "test-desc"}
+
+	testCases := []struct {
+		name        string
+		body        []byte
+		contentType string
+		preProcess  func(s *testutil.TestSuite)
+		code        int
+		message     string
+		check       func(t *testing.T, s *testutil.TestSuite, cover booktypes.BookCover)
+	}{
+		{
+			name:    "empty cover",
+			code:    http.StatusBadRequest,
+			message: "cover is empty",
+		},
+		{
+			name:        "type sniffed from content",
+			body:        []byte("not an image"),
+			contentType: "image/png",
+			code:        http.StatusUnsupportedMediaType,
+			message:     "unsupported cover type: text/plain; charset=utf-8",
+		},
+		{
+			name:    "too large cover",
+			body:    append(pngImage(t, 10, 10), make([]byte, testutil.TestConfig.MaxSize)...),
+			code:    http.StatusRequestEntityTooLarge,
+			message: "cover is larger than 1048576 bytes",
+		},
+		{
+			name:    "too many pixels",
+			body:    pngImage(t, 2000, 1000),
+			code:    http.StatusRequestEntityTooLarge,
+			message: "cover is larger than 1000000 pixels: 2000x1000",
+		},
+		{
+			name:    "truncated image",
+			body:    pngImage(t, 10, 10)[:60],
+			code:    http.StatusBadRequest,
+			message: "invalid cover image: png: invalid format: unexpected EOF",
+		},
+		{
+			name: "book not found",
+			body: pngImage(t, 10, 10),
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBook(1).Return(nil, gorm.ErrRecordNotFound)
+			},
+			code:    http.StatusNotFound,
+			message: "book not found",
+		},
+		{
+			name: "png cover with thumbnails",
+			body: pngImage(t, 400, 200),
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBook(1).Return(&book, nil).Times(2)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().UpdateBook(gomock.Any()).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, change booktypes.BookChange) error {
+					require.Contains(t, change.Diff(), "cover")
+					return nil
+				})
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, s *testutil.TestSuite, cover booktypes.BookCover) {
+				require.Equal(t, types.CONTENT_TYPE_PNG, cover.ContentType)
+				require.Equal(t, 400, cover.Width)
+				require.Equal(t, 200, cover.Height)
+				require.Equal(t, "/api/v1/books/1/cover?v="+cover.Version(), cover.URL)
+				require.Len(t, cover.Thumbnails, 2)
+				require.Equal(t, "/api/v1/books/1/cover/small?v="+cover.Version(), cover.Thumbnails[0].URL)
+
+				small, err := png.Decode(bytes.NewReader(readBlob(t, s.Store, types.BlobKey(1, cover.Version(), "small"))))
+				require.NoError(t, err)
+				require.Equal(t, image.Rect(0, 0, 100, 50), small.Bounds())
+
+				medium, ok := cover.Thumbnail("medium")
+				require.True(t, ok)
+				require.Equal(t, 300, medium.Width)
+				require.Equal(t, 150, medium.Height)
+				require.Len(t, readBlob(t, s.Store, types.BlobKey(1, cover.Version(), "medium")), int(medium.Size))
+			},
+		},
+		{
+			name:        "multipart jpeg cover",
+			body:        jpegImage(t, 120, 180),
+			contentType: "multipart/form-data",
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBook(1).Return(&book, nil).Times(2)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().UpdateBook(gomock.Any()).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, s *testutil.TestSuite, cover booktypes.BookCover) {
+				require.Equal(t, types.CONTENT_TYPE_JPEG, cover.ContentType)
+				require.Len(t, cover.Thumbnails, 1)
+				require.Equal(t, types.CONTENT_TYPE_JPEG, cover.Thumbnails[0].ContentType)
+				require.Equal(t, 150, cover.Thumbnails[0].Height)
+			},
+		},
+		{
+			name: "webp cover with thumbnails",
+			body: webpImage(t, "blue-purple-pink.lossy.webp"),
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBook(1).Return(&book, nil).Times(2)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().UpdateBook(gomock.Any()).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, s *testutil.TestSuite, cover booktypes.BookCover) {
+				require.Equal(t, types.CONTENT_TYPE_WEBP, cover.ContentType)
+				require.Equal(t, 150, cover.Width)
+				require.Equal(t, 100, cover.Height)
+				require.Len(t, cover.Thumbnails, 1)
+				require.Equal(t, types.CONTENT_TYPE_JPEG, cover.Thumbnails[0].ContentType)
+
+				small, err := jpeg.Decode(bytes.NewReader(readBlob(t, s.Store, types.BlobKey(1, cover.Version(), "small"))))
+				require.NoError(t, err)
+				require.Equal(t, image.Rect(0, 0, 100, 67), small.Bounds())
+			},
+		},
+		{
+			name: "transparent webp cover with png thumbnails",
+			body: webpImage(t, "yellow_rose.lossy-with-alpha.webp"),
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetBook(1).Return(&book, nil).Times(2)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().UpdateBook(gomock.Any()).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, s *testutil.TestSuite, cover booktypes.BookCover) {
+				require.Equal(t, types.CONTENT_TYPE_WEBP, cover.ContentType)
+				require.Equal(t, 400, cover.Width)
+				require.Equal(t, 301, cover.Height)
+				require.Len(t, cover.Thumbnails, 2)
+				for _, thumbnail := range cover.Thumbnails {
+					require.Equal(t, types.CONTENT_TYPE_PNG, thumbnail.ContentType)
+				}
+
+				medium, err := png.Decode(bytes.NewReader(readBlob(t, s.Store, types.BlobKey(1, cover.Version(), "medium"))))
+				require.NoError(t, err)
+				require.Equal(t, image.Rect(0, 0, 300, 226), medium.Bounds())
+			},
+		},
+		{
+			name:    "truncated webp",
+			body:    webpImage(t, "blue-purple-pink.lossy.webp")[:200],
+			code:    http.StatusBadRequest,
+			message: "invalid cover image: unexpected EOF",
+		},
+		{
+			name: "former cover deleted",
+			body: pngImage(t, 50, 50),
+			preProcess: func(s *testutil.TestSuite) {
+				former := &booktypes.BookCover{Checksum: strings.Repeat("ab", 32), ContentType: types.CONTENT_TYPE_PNG}
+				require.NoError(t, s.Store.Put(context.Background(), types.BlobKey(1, former.Version(), types.ORIGINAL), strings.NewReader("old"), 3, types.CONTENT_TYPE_PNG))
+
+				withCover := book
+				withCover.Cover = former
+				s.Repository.EXPECT().GetBook(1).DoAndReturn(func(id int) (*booktypes.Book, error) {
+					b := withCover
+					return &b, nil
+				}).Times(2)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().UpdateBook(gomock.Any()).Return(nil)
+				s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			code: http.StatusOK,
+			check: func(t *testing.T, s *testutil.TestSuite, cover booktypes.BookCover) {
+				_, err := s.Store.Get(context.Background(), types.BlobKey(1, strings.Repeat("ab", 8), types.ORIGINAL))
+				require.ErrorIs(t, err, blob.ErrNotFound)
+				require.NotEmpty(t, readBlob(t, s.Store, types.BlobKey(1, cover.Version(), types.ORIGINAL)))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			body := bytes.NewBuffer(tc.body)
+			contentType := tc.contentType
+			if contentType == "multipart/form-data" {
+				body = &bytes.Buffer{}
+				mw := multipart.NewWriter(body)
+				require.NoError(t, mw.WriteField("title", "ignored"))
+				fw, err := mw.CreateFormFile("cover", "cover.jpg")
+				require.NoError(t, err)
+				_, err = fw.Write(tc.body)
+				require.NoError(t, err)
+				require.NoError(t, mw.Close())
+				contentType = mw.FormDataContentType()
+			}
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1/cover", body)
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", contentType)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+			if tc.message != "" {
+				require.JSONEq(t, `{"message": `+mustJSON(t, tc.message)+`}`, resp.Body.String())
+			}
+			if tc.check != nil {
+				var result types.SetCoverResponse
+				require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+				tc.check(t, &s, result.Cover)
+			}
+		})
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestGetCover(t *testing.T) {
+	cover := &booktypes.BookCover{
+		ContentType: types.CONTENT_TYPE_PNG,
+		Size:        5,
+		Checksum:    strings.Repeat("0123456789abcdef", 4),
+		Thumbnails:  []booktypes.CoverThumbnail{{Name: "small", ContentType: types.CONTENT_TYPE_JPEG, Size: 3}},
+		UpdatedAt:   time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name    string
+		path    string
+		header  http.Header
+		noCover bool
+		code    int
+		headers map[string]string
+		body    string
+	}{
+		{
+			name: "versioned cover",
+			path: "/api/v1/books/1/cover?v=0123456789abcdef",
+			code: http.StatusOK,
+			headers: map[string]string{
+				"Content-Type":   "image/png",
+				"Content-Length": "5",
+				"ETag":           `"0123456789abcdef-original"`,
+				"Cache-Control":  "public, max-age=31536000, immutable",
+				"Last-Modified":  "Wed, 01 May 2024 10:00:00 GMT",
+			},
+			body: "cover",
+		},
+		{
+			name: "unversioned thumbnail",
+			path: "/api/v1/books/1/cover/small",
+			code: http.StatusOK,
+			headers: map[string]string{
+				"Content-Type":  "image/jpeg",
+				"ETag":          `"0123456789abcdef-small"`,
+				"Cache-Control": "public, no-cache",
+			},
+			body: "thm",
+		},
+		{
+			name:   "not modified",
+			path:   "/api/v1/books/1/cover",
+			header: http.Header{"If-None-Match": []string{`"other", W/"0123456789abcdef-original"`}},
+			code:   http.StatusNotModified,
+			headers: map[string]string{
+				"ETag": `"0123456789abcdef-original"`,
+			},
+		},
+		{
+			name: "unknown thumbnail",
+			path: "/api/v1/books/1/cover/large",
+			code: http.StatusNotFound,
+			body: `{"message":"cover thumbnail not found: large"}` + "\n",
+		},
+		{
+			name:    "no cover",
+			path:    "/api/v1/books/1/cover",
+			noCover: true,
+			code:    http.StatusNotFound,
+			body:    `{"message":"book has no cover"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			book := booktypes.Book{ID: 1, Title: "test-title", Cover: cover}
+			if tc.noCover {
+				book.Cover = nil
+			}
+			s.Repository.EXPECT().GetBook(1).Return(&book, nil)
+			require.NoError(t, s.Store.Put(context.Background(), types.BlobKey(1, cover.Version(), types.ORIGINAL), strings.NewReader("cover"), 5, types.CONTENT_TYPE_PNG))
+			require.NoError(t, s.Store.Put(context.Background(), types.BlobKey(1, cover.Version(), "small"), strings.NewReader("thm"), 3, types.CONTENT_TYPE_JPEG))
+
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			for name, values := range tc.header {
+				req.Header[name] = values
+			}
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+			for name, value := range tc.headers {
+				require.Equal(t, value, resp.Header().Get(name), name)
+			}
+			require.Equal(t, tc.body, resp.Body.String())
+		})
+	}
+}
+
+func TestDeleteCover(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+
+	cover := &booktypes.BookCover{Checksum: strings.Repeat("ab", 32), ContentType: types.CONTENT_TYPE_PNG}
+	key := types.BlobKey(1, cover.Version(), types.ORIGINAL)
+	require.NoError(t, s.Store.Put(context.Background(), key, strings.NewReader("cover"), 5, types.CONTENT_TYPE_PNG))
+
+	s.ExpectTransaction()
+	s.Repository.EXPECT().GetBook(1).Return(&booktypes.Book{ID: 1, Cover: cover}, nil)
+	s.Repository.EXPECT().UpdateBook(&booktypes.Book{ID: 1}).Return(nil)
+	s.Repository.EXPECT().RecordChange(gomock.Any(), gomock.Any()).Return(nil)
+
+	req, err := http.NewRequest(http.MethodDelete, "/api/v1/books/1/cover", nil)
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router := http.NewServeMux()
+	router = service.InitializeRoutes(router, *s.Handler)
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+	_, err = s.Store.Get(context.Background(), key)
+	require.ErrorIs(t, err, blob.ErrNotFound)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	// the WebP decoder is registered for image.Decode.
+	_ "golang.org/x/image/webp"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// thumbnailJPEGQuality is the quality of the thumbnails encoded as JPEG.
+const thumbnailJPEGQuality = 85
+
+// coverImage is an uploaded cover image checked to be an image of a supported type.
+type coverImage struct {
+	contentType string
+	width       int
+	height      int
+	img         image.Image
+}
+
+// decodeCover checks the type of the image by sniffing its content, regardless of the type
+// given by the client, and decodes it. The dimensions are checked before decoding, so a small
+// file cannot make the decoder allocate a huge image.
+func decodeCover(data []byte, maxPixels int) (coverImage, error) {
+	contentType := http.DetectContentType(data)
+
+	switch contentType {
+	case types.CONTENT_TYPE_JPEG, types.CONTENT_TYPE_PNG, types.CONTENT_TYPE_WEBP:
+	default:
+		return coverImage{}, apierror.ErrUnsupportedMedia.WithMessage(fmt.Sprintf("unsupported cover type: %s", contentType))
+	}
+
+	conf, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return coverImage{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid cover image: %v", err))
+	}
+
+	if conf.Width*conf.Height > maxPixels {
+		msg := fmt.Sprintf("cover is larger than %d pixels: %dx%d", maxPixels, conf.Width, conf.Height)
+		return coverImage{}, apierror.ErrTooLarge.WithMessage(msg)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return coverImage{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid cover image: %v", err))
+	}
+
+	return coverImage{contentType: contentType, width: conf.Width, height: conf.Height, img: img}, nil
+}
+
+// thumbnail is a generated thumbnail encoded in its content type.
+type thumbnail struct {
+	size        types.ThumbnailSize
+	contentType string
+	width       int
+	height      int
+	data        []byte
+}
+
+// thumbnails generates the thumbnails of the cover narrower than the cover. A PNG cover, or a
+// WebP cover with transparency, keeps its transparency in PNG thumbnails; the other covers get
+// JPEG thumbnails.
+func thumbnails(cover coverImage) ([]thumbnail, error) {
+	transparent := cover.contentType == types.CONTENT_TYPE_PNG ||
+		cover.contentType == types.CONTENT_TYPE_WEBP && !opaque(cover.img)
+
+	var src *image.RGBA
+	var result []thumbnail
+	for _, size := range types.ThumbnailSizes {
+		if size.Width >= cover.width {
+			break
+		}
+		if src == nil {
+			src = image.NewRGBA(image.Rect(0, 0, cover.width, cover.height))
+			draw.Draw(src, src.Bounds(), cover.img, cover.img.Bounds().Min, draw.Src)
+		}
+
+		height := max(1, (cover.height*size.Width+cover.width/2)/cover.width)
+		img := scaleDown(src, size.Width, height)
+
+		t := thumbnail{size: size, width: size.Width, height: height}
+		var buf bytes.Buffer
+		var err error
+		if transparent {
+			t.contentType = types.CONTENT_TYPE_PNG
+			err = png.Encode(&buf, img)
+		} else {
+			t.contentType = types.CONTENT_TYPE_JPEG
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: thumbnailJPEGQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+		t.data = buf.Bytes()
+
+		result = append(result, t)
+	}
+
+	return result, nil
+}
+
+// opaque reports whether every pixel of the image is opaque.
+func opaque(img image.Image) bool {
+	o, ok := img.(interface{ Opaque() bool })
+	return ok && o.Opaque()
+}
+
+// scaleDown scales the image down to the given size, each pixel being the average of the
+// pixels of the area it covers in the image.
+func scaleDown(src *image.RGBA, width int, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride+x0*4 : sy*src.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+
+			n := (y1 - y0) * (x1 - x0)
+			i := dst.PixOffset(x, y)
+			for c := range sum {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+
+	return dst
+}
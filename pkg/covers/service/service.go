@@ -0,0 +1,256 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/blob"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+)
+
+// Service is the service layer for the cover images of books. The cover of a book is kept
+// in the book itself, so setting it is a change of the book.
+type Service struct {
+	dataProvider booktypes.DataProvider
+	store        blob.Store
+	conf         config.Covers
+	log          *zap.Logger
+}
+
+// NewService creates a new covers service storing the images in the blob store
+func NewService(d booktypes.DataProvider, store blob.Store, conf config.Covers, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		store:        store,
+		conf:         conf,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// MaxSize returns the maximum size in bytes of an uploaded cover.
+func (s *Service) MaxSize() int64 {
+	return s.conf.MaxSize
+}
+
+// SetCover replaces the cover of a book with the uploaded image and generates its thumbnails.
+// The images are stored before the book is changed and the images of the former cover are
+// removed after, so the cover of the book always refers to stored images.
+func (s *Service) SetCover(ctx context.Context, bookID int, data []byte) (types.SetCoverResponse, error) {
+	if len(data) == 0 {
+		return types.SetCoverResponse{}, apierror.ErrInvalidInput.WithMessage("cover is empty")
+	}
+	if int64(len(data)) > s.conf.MaxSize {
+		return types.SetCoverResponse{}, apierror.ErrTooLarge.WithMessage(fmt.Sprintf("cover is larger than %d bytes", s.conf.MaxSize))
+	}
+
+	img, err := decodeCover(data, s.conf.MaxPixels)
+	if err != nil {
+		return types.SetCoverResponse{}, err
+	}
+
+	book, err := s.getBook(s.dataProvider, bookID)
+	if err != nil {
+		return types.SetCoverResponse{}, err
+	}
+
+	checksum := sha256.Sum256(data)
+	cover := booktypes.BookCover{
+		ContentType: img.contentType,
+		Width:       img.width,
+		Height:      img.height,
+		Size:        int64(len(data)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+		Thumbnails:  []booktypes.CoverThumbnail{},
+		UpdatedAt:   time.Now().UTC(),
+	}
+	// the same image is uploaded again.
+	if book.Cover != nil && book.Cover.Checksum == cover.Checksum {
+		return types.SetCoverResponse{Cover: *book.Cover}, nil
+	}
+
+	thumbs, err := thumbnails(img)
+	if err != nil {
+		return types.SetCoverResponse{}, err
+	}
+
+	version := cover.Version()
+	cover.URL = types.URL(bookID, version, types.ORIGINAL)
+	if err := s.put(ctx, types.BlobKey(bookID, version, types.ORIGINAL), data, img.contentType); err != nil {
+		return types.SetCoverResponse{}, err
+	}
+	for _, t := range thumbs {
+		if err := s.put(ctx, types.BlobKey(bookID, version, t.size.Name), t.data, t.contentType); err != nil {
+			s.deleteBlobs(ctx, bookID, &cover)
+			return types.SetCoverResponse{}, err
+		}
+
+		cover.Thumbnails = append(cover.Thumbnails, booktypes.CoverThumbnail{
+			Name:        t.size.Name,
+			URL:         types.URL(bookID, version, t.size.Name),
+			ContentType: t.contentType,
+			Width:       t.width,
+			Height:      t.height,
+			Size:        int64(len(t.data)),
+		})
+	}
+
+	var former *booktypes.BookCover
+	err = s.dataProvider.Transaction(func(d booktypes.DataProvider) error {
+		book, err := s.getBook(d, bookID)
+		if err != nil {
+			return err
+		}
+		before := *book
+		// a concurrent upload of the same image already set the cover.
+		if book.Cover == nil || book.Cover.Checksum != cover.Checksum {
+			former = book.Cover
+		}
+
+		book.Cover = &cover
+		if err := d.UpdateBook(book); err != nil {
+			return err
+		}
+
+		return d.RecordChange(ctx, booktypes.BookChange{
+			Op:     booktypes.CHANGE_OP_UPDATE,
+			BookID: bookID,
+			Before: &before,
+			After:  book,
+		})
+	})
+	if err != nil {
+		s.deleteBlobs(ctx, bookID, &cover)
+		return types.SetCoverResponse{}, err
+	}
+
+	s.deleteBlobs(ctx, bookID, former)
+	return types.SetCoverResponse{Cover: cover}, nil
+}
+
+// DeleteCover removes the cover of a book
+func (s *Service) DeleteCover(ctx context.Context, bookID int) (types.DeleteCoverResponse, error) {
+	var former *booktypes.BookCover
+	err := s.dataProvider.Transaction(func(d booktypes.DataProvider) error {
+		book, err := s.getBook(d, bookID)
+		if err != nil {
+			return err
+		}
+		if book.Cover == nil {
+			return apierror.NewNotFoundError("book has no cover")
+		}
+		before := *book
+		former = book.Cover
+
+		book.Cover = nil
+		if err := d.UpdateBook(book); err != nil {
+			return err
+		}
+
+		return d.RecordChange(ctx, booktypes.BookChange{
+			Op:     booktypes.CHANGE_OP_UPDATE,
+			BookID: bookID,
+			Before: &before,
+			After:  book,
+		})
+	})
+	if err != nil {
+		return types.DeleteCoverResponse{}, err
+	}
+
+	s.deleteBlobs(ctx, bookID, former)
+	return types.DeleteCoverResponse{}, nil
+}
+
+// StatCover returns the cover image of a book or one of its thumbnails by name
+func (s *Service) StatCover(bookID int, name string) (types.CoverFile, error) {
+	book, err := s.getBook(s.dataProvider, bookID)
+	if err != nil {
+		return types.CoverFile{}, err
+	}
+	if book.Cover == nil {
+		return types.CoverFile{}, apierror.NewNotFoundError("book has no cover")
+	}
+
+	file := types.CoverFile{
+		Key:         types.BlobKey(bookID, book.Cover.Version(), name),
+		ContentType: book.Cover.ContentType,
+		Size:        book.Cover.Size,
+		Version:     book.Cover.Version(),
+		UpdatedAt:   book.Cover.UpdatedAt,
+	}
+	if name != types.ORIGINAL {
+		thumb, ok := book.Cover.Thumbnail(name)
+		if !ok {
+			return types.CoverFile{}, apierror.NewNotFoundError(fmt.Sprintf("cover thumbnail not found: %s", name))
+		}
+		file.ContentType, file.Size = thumb.ContentType, thumb.Size
+	}
+
+	return file, nil
+}
+
+// OpenCover opens a cover image returned by StatCover
+func (s *Service) OpenCover(ctx context.Context, file types.CoverFile) (io.ReadCloser, error) {
+	body, err := s.store.Get(ctx, file.Key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, apierror.NewNotFoundError(fmt.Sprintf("cover image not found: %s", file.Key))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func (s *Service) getBook(d booktypes.DataProvider, id int) (*booktypes.Book, error) {
+	book, err := d.GetBook(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("book not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+func (s *Service) put(ctx context.Context, key string, data []byte, contentType string) error {
+	return s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType)
+}
+
+// deleteBlobs removes the images of a cover no longer referred to. A failure only leaves
+// unused images in the store, so it is logged rather than returned.
+func (s *Service) deleteBlobs(ctx context.Context, bookID int, cover *booktypes.BookCover) {
+	if cover == nil {
+		return
+	}
+
+	names := []string{types.ORIGINAL}
+	for _, t := range cover.Thumbnails {
+		names = append(names, t.Name)
+	}
+	for _, name := range names {
+		key := types.BlobKey(bookID, cover.Version(), name)
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.log.Error(fmt.Sprintf("failed to delete cover image %s: %v", key, err))
+		}
+	}
+}
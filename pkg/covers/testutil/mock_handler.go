@@ -0,0 +1,55 @@
+package testutil
+
+import (
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/blob"
+	booktestutil "github.com/nkitlabs/go-http-gorm-example/pkg/books/testutil"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/covers/service"
+)
+
+// TestConfig is the configuration of the covers service of the test suite.
+var TestConfig = config.Covers{MaxSize: 1 << 20, MaxPixels: 1_000_000}
+
+type TestSuite struct {
+	Handler    *service.Handler
+	Service    *service.Service
+	Repository *booktestutil.MockDataProvider
+	Store      *blob.FSStore
+	Logger     *zap.Logger
+}
+
+// NewTestSuite creates a test suite storing the covers in a temporary directory.
+func NewTestSuite(t *testing.T) TestSuite {
+	ctrl := gomock.NewController(t)
+
+	repo := booktestutil.NewMockDataProvider(ctrl)
+	repo.EXPECT().WithContext(gomock.Any()).Return(repo).AnyTimes()
+	store, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zap.NewNop()
+	serv := service.NewService(repo, store, TestConfig, log)
+	handler := service.NewHandler(&serv, log)
+
+	return TestSuite{
+		Handler:    &handler,
+		Service:    &serv,
+		Repository: repo,
+		Store:      store,
+		Logger:     log,
+	}
+}
+
+// ExpectTransaction expects a transaction that runs its function against the mock repository.
+func (s TestSuite) ExpectTransaction() *gomock.Call {
+	return s.Repository.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(booktypes.DataProvider) error) error {
+		return fn(s.Repository)
+	})
+}
@@ -0,0 +1,39 @@
+package types
+
+import "fmt"
+
+const (
+	CONTENT_TYPE_JPEG = "image/jpeg"
+	CONTENT_TYPE_PNG  = "image/png"
+	CONTENT_TYPE_WEBP = "image/webp"
+)
+
+// ORIGINAL is the name of the uploaded cover image among its thumbnails.
+const ORIGINAL = "original"
+
+// ThumbnailSize is the name and the width of a thumbnail generated for every cover.
+type ThumbnailSize struct {
+	Name  string
+	Width int
+}
+
+// ThumbnailSizes are the thumbnails generated for every cover, smallest first. A cover is
+// never scaled up, so a cover narrower than a size has no thumbnail of that size.
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", Width: 100},
+	{Name: "medium", Width: 300},
+	{Name: "large", Width: 600},
+}
+
+// BlobKey returns the key of a cover image of a book in the blob store.
+func BlobKey(bookID int, version string, name string) string {
+	return fmt.Sprintf("covers/%d/%s/%s", bookID, version, name)
+}
+
+// URL returns the URL of a cover image of a book, versioned so it can be cached for good.
+func URL(bookID int, version string, name string) string {
+	if name == ORIGINAL {
+		return fmt.Sprintf("/api/v1/books/%d/cover?v=%s", bookID, version)
+	}
+	return fmt.Sprintf("/api/v1/books/%d/cover/%s?v=%s", bookID, name, version)
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"time"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+// SetCoverResponse is the response for uploading the cover of a book
+type SetCoverResponse struct {
+	Cover booktypes.BookCover `json:"cover" yaml:"cover" xml:"cover"`
+}
+
+// DeleteCoverResponse is the response for deleting the cover of a book
+type DeleteCoverResponse struct{}
+
+// CoverFile is a stored cover image to be served.
+type CoverFile struct {
+	// Key is the key of the image in the blob store.
+	Key         string
+	ContentType string
+	Size        int64
+	// Version is the version of the cover the image belongs to, see BookCover.Version.
+	Version   string
+	UpdatedAt time.Time
+}
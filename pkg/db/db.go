@@ -2,28 +2,158 @@ package db
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/glebarez/sqlite"
+	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
 )
 
-// Init initializes the database connection.
-func Init(conf config.DBConn) (*gorm.DB, error) {
-	dbURL := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s",
-		conf.Username,
-		conf.Password,
-		conf.Host,
-		conf.Port,
-		conf.DBName,
-	)
-
-	db, err := gorm.Open(postgres.Open(dbURL), &gorm.Config{})
+const (
+	// DRIVER_POSTGRES connects to the Postgres server of the connection configuration.
+	DRIVER_POSTGRES = "postgres"
+	// DRIVER_SQLITE opens the sqlite database file at the path of the connection configuration.
+	DRIVER_SQLITE = "sqlite"
+	// DRIVER_MEMORY opens an empty sqlite database kept in memory, lost when the process exits.
+	DRIVER_MEMORY = "memory"
+)
+
+// sqliteOptions are the connection parameters of the sqlite databases: the foreign keys are
+// enforced, the transactions take the write lock when they begin and a locked database is
+// waited for rather than failing right away.
+const sqliteOptions = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+// Init initializes the database connection. The database is waited for until conf.Retry.Timeout,
+// so a server started along with its database connects once the database is up.
+func Init(conf config.DBConn, log *zap.Logger) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch conf.Driver {
+	case DRIVER_POSTGRES, "":
+		dialector = postgres.Open(PostgresURL(conf))
+	case DRIVER_SQLITE:
+		if err := os.MkdirAll(filepath.Dir(conf.Path), 0o755); err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(fmt.Sprintf("file:%s?%s", conf.Path, sqliteOptions))
+	case DRIVER_MEMORY:
+		dialector = sqlite.Open(fmt.Sprintf("file::memory:?%s", sqliteOptions))
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", conf.Driver)
+	}
+
+	db, err := open(dialector, conf.Retry, log)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
+	if IsPostgres(db) {
+		sqlDB.SetMaxOpenConns(conf.Pool.MaxOpenConns)
+		sqlDB.SetMaxIdleConns(conf.Pool.MaxIdleConns)
+		sqlDB.SetConnMaxLifetime(conf.Pool.ConnMaxLifetime)
+		sqlDB.SetConnMaxIdleTime(conf.Pool.ConnMaxIdleTime)
+	} else {
+		// sqlite has a single writer, so the queries share one connection instead of waiting for
+		// the lock of the database. It also keeps an in-memory database alive, which is dropped
+		// with its last connection.
+		sqlDB.SetMaxOpenConns(1)
+	}
 
 	return db, nil
 }
+
+// open connects to the database, retrying with an exponential backoff until the timeout of retry.
+// The last attempt is made at the timeout.
+func open(dialector gorm.Dialector, retry config.DBRetry, log *zap.Logger) (*gorm.DB, error) {
+	deadline := time.Now().Add(retry.Timeout)
+	backoff := retry.InitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		// the errors of the drivers are translated, so a unique violation is gorm.ErrDuplicatedKey.
+		db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+		if err == nil {
+			return db, nil
+		}
+		// the pool of a failed connection is opened anyway.
+		if db != nil {
+			if sqlDB, dbErr := db.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+
+		wait := min(backoff, time.Until(deadline))
+		if backoff <= 0 || wait <= 0 {
+			return nil, fmt.Errorf("failed to connect to the database (%d attempts): %w", attempt, err)
+		}
+		log.Warn(fmt.Sprintf("failed to connect to the database, retrying in %s: %v", wait, err))
+
+		time.Sleep(wait)
+		backoff = min(backoff*2, max(retry.MaxBackoff, retry.InitialBackoff))
+	}
+}
+
+// PostgresURL returns the URL of the Postgres database of the connection configuration, with its
+// credentials escaped.
+func PostgresURL(conf config.DBConn) string {
+	params := url.Values{}
+	options := []struct {
+		name  string
+		value string
+	}{
+		{"sslmode", conf.SSLMode},
+		{"sslrootcert", conf.SSLRootCert},
+		{"sslcert", conf.SSLCert},
+		{"sslkey", conf.SSLKey},
+		{"application_name", conf.ApplicationName},
+	}
+	for _, option := range options {
+		if option.value != "" {
+			params.Set(option.name, option.value)
+		}
+	}
+	// the statement timeout is a setting of the session, in milliseconds.
+	if conf.StatementTimeout > 0 {
+		params.Set("statement_timeout", strconv.FormatInt(conf.StatementTimeout.Milliseconds(), 10))
+	}
+
+	host := conf.Host
+	if conf.Port != "" {
+		host = net.JoinHostPort(conf.Host, conf.Port)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(conf.Username, conf.Password),
+		Host:     host,
+		Path:     "/" + conf.DBName,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// IsPostgres reports whether db is connected to Postgres. The other databases are used by a
+// single replica, so they don't need the locks taken by the replicas sharing a Postgres database.
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == DRIVER_POSTGRES
+}
+
+// LockTransaction takes the advisory lock of the key until the end of the transaction tx. It
+// does nothing outside Postgres.
+func LockTransaction(tx *gorm.DB, key int64) error {
+	if !IsPostgres(tx) {
+		return nil
+	}
+
+	return tx.Exec("SELECT pg_advisory_xact_lock(?)", key).Error
+}
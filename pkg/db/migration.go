@@ -0,0 +1,62 @@
+package db
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockKey is the key of the advisory lock serializing the migrations of the replicas.
+const migrationLockKey = 0x6d696772 // "migr"
+
+// Migration is a one-off change of the data, applied once and recorded in schema_migrations.
+// The tables are created by gorm's AutoMigrate before the migrations run.
+type Migration struct {
+	ID      string
+	Migrate func(tx *gorm.DB) error
+}
+
+// SchemaMigration is the model for an applied migration.
+type SchemaMigration struct {
+	ID        string    `gorm:"primaryKey"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName overrides the table name used by gorm.
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrate applies the migrations not applied yet in the given order, each one in its own transaction.
+func Migrate(db *gorm.DB, migrations ...Migration) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := LockTransaction(tx, migrationLockKey); err != nil {
+				return err
+			}
+
+			var applied int64
+			if err := tx.Model(&SchemaMigration{}).Where("id = ?", m.ID).Count(&applied).Error; err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+
+			if err := m.Migrate(tx); err != nil {
+				return err
+			}
+
+			return tx.Create(&SchemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
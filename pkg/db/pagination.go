@@ -22,11 +22,11 @@ func ToSortType(sortTypeStr string) SortType {
 
 // Pagination is the model for pagination information.
 type Pagination struct {
-	Limit      int    `json:"limit,omitempty" example:"10"`
-	Page       int    `json:"page,omitempty" example:"1"`
-	Sort       string `json:"sort,omitempty" example:"Id desc"`
-	TotalRows  int64  `json:"total_rows" example:"100"`
-	TotalPages int    `json:"total_pages" example:"10"`
+	Limit      int    `json:"limit,omitempty" yaml:"limit,omitempty" xml:"limit,omitempty" example:"10"`
+	Page       int    `json:"page,omitempty" yaml:"page,omitempty" xml:"page,omitempty" example:"1"`
+	Sort       string `json:"sort,omitempty" yaml:"sort,omitempty" xml:"sort,omitempty" example:"Id desc"`
+	TotalRows  int64  `json:"total_rows" yaml:"total_rows" xml:"total_rows" example:"100"`
+	TotalPages int    `json:"total_pages" yaml:"total_pages" xml:"total_pages" example:"10"`
 }
 
 func (p *Pagination) GetOffset() int {
@@ -54,13 +54,25 @@ func (p *Pagination) GetSort() string {
 	return p.Sort
 }
 
+// SetTotalRows sets the number of rows to paginate and the number of pages they fill.
+func (p *Pagination) SetTotalRows(totalRows int64) {
+	p.TotalRows = totalRows
+	p.TotalPages = int(math.Ceil(float64(totalRows) / float64(p.Limit)))
+}
+
+// Bounds returns the range of the rows of the page among the given number of rows, for the rows
+// paginated in memory rather than by the database.
+func (p *Pagination) Bounds(totalRows int) (int, int) {
+	start := min(max(p.GetOffset(), 0), totalRows)
+	end := min(start+p.GetLimit(), totalRows)
+	return start, end
+}
+
 func Paginate(value interface{}, pagination *Pagination, db *gorm.DB) func(db *gorm.DB) *gorm.DB {
 	var totalRows int64
 	db.Model(value).Count(&totalRows)
 
-	pagination.TotalRows = totalRows
-	totalPages := int(math.Ceil(float64(totalRows) / float64(pagination.Limit)))
-	pagination.TotalPages = totalPages
+	pagination.SetTotalRows(totalRows)
 
 	return func(db *gorm.DB) *gorm.DB {
 		return db.Offset(pagination.GetOffset()).Limit(pagination.GetLimit()).Order(pagination.GetSort())
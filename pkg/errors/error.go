@@ -5,14 +5,25 @@ import (
 )
 
 var (
-	ErrInternal     = NewError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-	ErrInvalidInput = NewError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
-	ErrNotFound     = NewError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	ErrInternal         = NewError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	ErrInvalidInput     = NewError(http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+	ErrUnauthorized     = NewError(http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+	ErrPaymentRequired  = NewError(http.StatusPaymentRequired, http.StatusText(http.StatusPaymentRequired))
+	ErrForbidden        = NewError(http.StatusForbidden, http.StatusText(http.StatusForbidden))
+	ErrNotFound         = NewError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	ErrNotAcceptable    = NewError(http.StatusNotAcceptable, http.StatusText(http.StatusNotAcceptable))
+	ErrConflict         = NewError(http.StatusConflict, http.StatusText(http.StatusConflict))
+	ErrTooLarge         = NewError(http.StatusRequestEntityTooLarge, http.StatusText(http.StatusRequestEntityTooLarge))
+	ErrUnsupportedMedia = NewError(http.StatusUnsupportedMediaType, http.StatusText(http.StatusUnsupportedMediaType))
+	ErrUnprocessable    = NewError(http.StatusUnprocessableEntity, http.StatusText(http.StatusUnprocessableEntity))
+	ErrFailedDependency = NewError(http.StatusFailedDependency, http.StatusText(http.StatusFailedDependency))
 )
 
 type Error struct {
 	Message string `json:"message"`
-	Code    int    `json:"-"`
+	// ID is the id of the existing resource that a conflict is with.
+	ID   int `json:"id,omitempty"`
+	Code int `json:"-"`
 }
 
 func NewError(code int, message string) *Error {
@@ -23,11 +34,16 @@ func NewNotFoundError(message string) *Error {
 	return ErrNotFound.WithMessage(message)
 }
 
+// NewConflictError returns the conflict with the existing resource of the given id.
+func NewConflictError(message string, id int) *Error {
+	newErr := ErrConflict.WithMessage(message)
+	newErr.ID = id
+	return newErr
+}
+
 func (e *Error) WithMessage(message string) *Error {
-	newErr := Error{
-		Message: message,
-		Code:    e.Code,
-	}
+	newErr := *e
+	newErr.Message = message
 	return &newErr
 }
 
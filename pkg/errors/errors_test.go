@@ -45,6 +45,11 @@ func TestToError(t *testing.T) {
 			in:   errors.New("test"),
 			out:  apierror.ErrInternal,
 		},
+		{
+			name: "testConflictError",
+			in:   errors.Wrap(apierror.NewConflictError("book already exists: 7", 7), "test"),
+			out:  &apierror.Error{Message: "book already exists: 7", ID: 7, Code: 409},
+		},
 	}
 
 	for _, tc := range tcs {
@@ -11,16 +11,11 @@ func ConvertValidatorErrorsToError(err error) *Error {
 		return nil
 	}
 
-	validatorErrs, ok := err.(validator.ValidationErrors)
+	errMaps, ok := ValidatorErrorsToMap(err)
 	if !ok {
 		return ErrInternal.WithMessage(err.Error())
 	}
 
-	errMaps := make(map[string]string)
-	for _, e := range validatorErrs {
-		errMaps[e.Field()] = validatorTagToMsg(e.Tag())
-	}
-
 	jsonString, err := json.Marshal(errMaps)
 	if err != nil {
 		return ErrInternal.WithMessage(err.Error()) // unlikely to get this error.
@@ -29,12 +24,30 @@ func ConvertValidatorErrorsToError(err error) *Error {
 	return ErrInvalidInput.WithMessage(string(jsonString))
 }
 
+// ValidatorErrorsToMap converts validator errors to a map of field names to error messages.
+// It returns false if the given error is not a validator error.
+func ValidatorErrorsToMap(err error) (map[string]string, bool) {
+	validatorErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, false
+	}
+
+	errMaps := make(map[string]string)
+	for _, e := range validatorErrs {
+		errMaps[e.Field()] = validatorTagToMsg(e.Tag())
+	}
+
+	return errMaps, true
+}
+
 func validatorTagToMsg(tag string) string {
 	switch tag {
 	case "required":
 		return "It is required"
 	case "email":
 		return "It is not a valid email address"
+	case "isbn_checksum":
+		return "It is not a valid ISBN"
 	default:
 		return "It is invalid"
 	}
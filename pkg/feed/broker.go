@@ -0,0 +1,117 @@
+package feed
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+)
+
+// subscriberBuffer is the number of events a subscriber can lag behind before it is dropped.
+const subscriberBuffer = 64
+
+// Subscription receives the events published into the broker after it subscribed. C is closed
+// when the subscriber is dropped for lagging behind or unsubscribed.
+type Subscription struct {
+	C  <-chan outbox.Event
+	ch chan outbox.Event
+}
+
+// Broker fans out the published events to the subscribers in process and keeps the latest
+// events in a bounded log, so a subscriber can resume from the last event it received.
+//
+// The events are logged in the order they are published, which is not the order of their ids:
+// the ids are taken when the changes are written, but the transactions of the changes commit
+// in any order.
+type Broker struct {
+	mu     sync.Mutex
+	size   int
+	events []outbox.Event
+	// logged holds the ids of the events in the log to ignore their redeliveries.
+	logged map[uint64]struct{}
+	// evictedID is the id of the last event evicted from the log, 0 while none is evicted.
+	evictedID uint64
+	subs      map[*Subscription]struct{}
+}
+
+// NewBroker creates a new broker keeping up to size events in its log
+func NewBroker(size int) *Broker {
+	return &Broker{
+		size:   size,
+		logged: make(map[uint64]struct{}),
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish appends the event into the log and sends it to the subscribers. An event still in the
+// log is a redelivery and is ignored.
+func (b *Broker) Publish(event outbox.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.logged[event.ID]; ok {
+		return
+	}
+	b.logged[event.ID] = struct{}{}
+
+	b.events = append(b.events, event)
+	if len(b.events) > b.size {
+		b.evictedID = b.events[0].ID
+		delete(b.logged, b.evictedID)
+		b.events = b.events[1:]
+	}
+
+	for sub := range b.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			// a slow subscriber would hold back the others; it resumes from the log once it reconnects.
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns the logged events published after the event
+// lastID for it to replay first. A lastID of 0 replays nothing. The returned flag is false if
+// some events after lastID are already evicted from the log, so the subscriber has missed them.
+func (b *Broker) Subscribe(lastID uint64) (*Subscription, []outbox.Event, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan outbox.Event, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch}
+	b.subs[sub] = struct{}{}
+
+	if lastID == 0 {
+		return sub, nil, true
+	}
+
+	if lastID == b.evictedID {
+		return sub, slices.Clone(b.events), true
+	}
+	if i := slices.IndexFunc(b.events, func(event outbox.Event) bool { return event.ID == lastID }); i >= 0 {
+		return sub, slices.Clone(b.events[i+1:]), true
+	}
+
+	// the position of an unknown event is lost, so the newer events of the log are replayed.
+	var replay []outbox.Event
+	for _, event := range b.events {
+		if event.ID > lastID {
+			replay = append(replay, event)
+		}
+	}
+
+	return sub, replay, b.evictedID == 0
+}
+
+// Unsubscribe removes the subscriber from the broker and closes its channel.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
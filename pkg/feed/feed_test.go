@@ -0,0 +1,239 @@
+package feed_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/feed"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+)
+
+func newEvent(id uint64) outbox.Event {
+	return outbox.Event{
+		ID:            id,
+		Type:          outbox.EVENT_BOOK_CREATED,
+		AggregateType: outbox.AGGREGATE_BOOK,
+		Payload:       json.RawMessage(`{"book": {"id": 1}}`),
+	}
+}
+
+func TestBrokerSubscribe(t *testing.T) {
+	broker := feed.NewBroker(3)
+	for i := uint64(1); i <= 5; i++ {
+		broker.Publish(newEvent(i))
+	}
+	// a redelivered event is ignored.
+	broker.Publish(newEvent(4))
+
+	testCases := []struct {
+		name     string
+		lastID   uint64
+		replay   []uint64
+		complete bool
+	}{
+		{
+			name:     "no last id",
+			lastID:   0,
+			complete: true,
+		},
+		{
+			name:     "resume within the log",
+			lastID:   3,
+			replay:   []uint64{4, 5},
+			complete: true,
+		},
+		{
+			name:     "resume from the oldest evicted event",
+			lastID:   2,
+			replay:   []uint64{3, 4, 5},
+			complete: true,
+		},
+		{
+			name:     "resume after evicted events",
+			lastID:   1,
+			replay:   []uint64{3, 4, 5},
+			complete: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, replay, complete := broker.Subscribe(tc.lastID)
+			defer broker.Unsubscribe(sub)
+
+			var ids []uint64
+			for _, event := range replay {
+				ids = append(ids, event.ID)
+			}
+			require.Equal(t, tc.replay, ids)
+			require.Equal(t, tc.complete, complete)
+		})
+	}
+}
+
+func TestBrokerOutOfOrder(t *testing.T) {
+	broker := feed.NewBroker(3)
+	sub, _, _ := broker.Subscribe(0)
+	defer broker.Unsubscribe(sub)
+
+	// the transaction of event 10 commits after the one of event 11.
+	broker.Publish(newEvent(11))
+	broker.Publish(newEvent(10))
+	// a redelivered event is ignored.
+	broker.Publish(newEvent(11))
+	require.Equal(t, uint64(11), (<-sub.C).ID)
+	require.Equal(t, uint64(10), (<-sub.C).ID)
+	require.Empty(t, sub.C)
+
+	testCases := []struct {
+		name     string
+		lastID   uint64
+		replay   []uint64
+		complete bool
+	}{
+		{
+			name:     "resume before the late event",
+			lastID:   11,
+			replay:   []uint64{10},
+			complete: true,
+		},
+		{
+			name:     "resume after the late event",
+			lastID:   10,
+			complete: true,
+		},
+		{
+			name:     "resume from an unknown event",
+			lastID:   9,
+			replay:   []uint64{11, 10},
+			complete: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sub, replay, complete := broker.Subscribe(tc.lastID)
+			defer broker.Unsubscribe(sub)
+
+			var ids []uint64
+			for _, event := range replay {
+				ids = append(ids, event.ID)
+			}
+			require.Equal(t, tc.replay, ids)
+			require.Equal(t, tc.complete, complete)
+		})
+	}
+
+	// the late event is evicted in the order it was published.
+	for i := uint64(12); i <= 13; i++ {
+		broker.Publish(newEvent(i))
+	}
+	sub, replay, complete := broker.Subscribe(11)
+	defer broker.Unsubscribe(sub)
+	require.Equal(t, []uint64{10, 12, 13}, []uint64{replay[0].ID, replay[1].ID, replay[2].ID})
+	require.True(t, complete)
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	broker := feed.NewBroker(1000)
+	sub, _, _ := broker.Subscribe(0)
+
+	for i := uint64(1); i <= 100; i++ {
+		broker.Publish(newEvent(i))
+	}
+
+	received := 0
+	for range sub.C {
+		received++
+	}
+	require.Less(t, received, 100)
+}
+
+// readEvent reads the lines of the next event or comment of the stream.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestStreamBooks(t *testing.T) {
+	broker := feed.NewBroker(2)
+	for i := uint64(1); i <= 3; i++ {
+		broker.Publish(newEvent(i))
+	}
+
+	router := feed.InitializeRoutes(http.NewServeMux(), feed.NewHandler(broker, 50*time.Millisecond, zap.NewNop()))
+	server := httptest.NewServer(middleware.Wraps(router, zap.NewNop()))
+	defer server.Close()
+
+	t.Run("invalid last event id", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, server.URL+"/api/v1/books/stream", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", "not-an-int")
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("resume, live events and heartbeat", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/books/stream", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", "2")
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		r := bufio.NewReader(resp.Body)
+		require.Equal(t, []string{"id: 3", "event: book.created", `data: {"book":{"id":1}}`}, readEvent(t, r))
+
+		broker.Publish(newEvent(4))
+		require.Equal(t, []string{"id: 4", "event: book.created", `data: {"book":{"id":1}}`}, readEvent(t, r))
+
+		require.Equal(t, []string{": heartbeat"}, readEvent(t, r))
+	})
+
+	t.Run("reset after evicted events", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/v1/books/stream?last_event_id=1", nil)
+		require.NoError(t, err)
+
+		resp, err := server.Client().Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		r := bufio.NewReader(resp.Body)
+		require.Equal(t, []string{"event: reset", "data: {}"}, readEvent(t, r))
+		require.Equal(t, "id: 3", readEvent(t, r)[0])
+		require.Equal(t, "id: 4", readEvent(t, r)[0])
+	})
+}
@@ -0,0 +1,137 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// EVENT_RESET is sent when the events after the Last-Event-ID are no longer in the log, so the
+// client should reload the catalog instead of relying on the feed.
+const EVENT_RESET = "reset"
+
+type Handler struct {
+	broker    *Broker
+	heartbeat time.Duration
+	log       *zap.Logger
+}
+
+// NewHandler creates a new handler for the change feed
+func NewHandler(broker *Broker, heartbeat time.Duration, log *zap.Logger) Handler {
+	return Handler{broker, heartbeat, log}
+}
+
+// InitializeRoutes initializes the routes for the change feed
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/books/stream", h.StreamBooks)
+	return mux
+}
+
+// @Summary stream book changes as server-sent events
+// @Description Every book created, updated or deleted is sent as an event with the outbox event id.
+// @Description Reconnecting clients resume after the Last-Event-ID header; a `reset` event tells
+// @Description them that some events were missed. A comment is sent as heartbeat.
+// @ID stream-books
+// @Param Last-Event-ID header int false "id of the last received event"
+// @Param last_event_id query int false "id of the last received event, for clients that cannot set headers"
+// @Produce text/event-stream
+// @Success 200 {string} string "stream of book events"
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/stream [get]
+func (h Handler) StreamBooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	lastIDStr := r.Header.Get("Last-Event-ID")
+	if lastIDStr == "" {
+		lastIDStr = r.URL.Query().Get("last_event_id")
+	}
+
+	var lastID uint64
+	if lastIDStr != "" {
+		id, err := strconv.ParseUint(lastIDStr, 10, 64)
+		if err != nil {
+			newErr := apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid last event id: %s", lastIDStr))
+			response.WriteError(ctx, w, newErr, h.log)
+			return
+		}
+		lastID = id
+	}
+
+	// the broker holds the events of every tenant, the client only receives the ones of its tenant.
+	tenantID, _ := tenant.FromContext(ctx)
+
+	sub, replay, complete := h.broker.Subscribe(lastID)
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if !complete {
+		fmt.Fprintf(w, "event: %s\ndata: {}\n\n", EVENT_RESET)
+	}
+	for _, event := range replay {
+		if event.TenantID == tenantID {
+			writeEvent(w, event)
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		h.log.Error(fmt.Sprintf("failed to stream book events: %v", err))
+		return
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event, ok := <-sub.C:
+			if !ok {
+				// the client lagged behind and is dropped; it resumes from the log after reconnecting.
+				return
+			}
+			if event.TenantID != tenantID {
+				continue
+			}
+			writeEvent(w, event)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent writes a book event in the server-sent events format, skipping other events.
+func writeEvent(w http.ResponseWriter, event outbox.Event) {
+	if event.AggregateType != outbox.AGGREGATE_BOOK {
+		return
+	}
+
+	// a data line cannot contain a line break, so the payload is compacted.
+	var data bytes.Buffer
+	if err := json.Compact(&data, event.Payload); err != nil {
+		data.Reset()
+		data.WriteString("{}")
+	}
+
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data.Bytes())
+}
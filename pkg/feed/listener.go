@@ -0,0 +1,122 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// reconnectDelay is the delay before listening again after the connection is lost.
+const reconnectDelay = time.Second
+
+// Listener publishes the events notified on a Postgres channel into the broker, so the broker
+// of every replica receives the events published by the relay of any replica.
+type Listener struct {
+	db      *gorm.DB
+	channel string
+	broker  *Broker
+	log     *zap.Logger
+}
+
+// NewListener creates a new listener of the Postgres channel
+func NewListener(db *gorm.DB, channel string, broker *Broker, log *zap.Logger) *Listener {
+	return &Listener{
+		db:      db,
+		channel: channel,
+		broker:  broker,
+		log:     log,
+	}
+}
+
+// Run listens to the channel until ctx is done, reconnecting if the connection is lost.
+func (l *Listener) Run(ctx context.Context) {
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		l.log.Error(fmt.Sprintf("failed to listen to channel %s: %v", l.channel, err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// listen holds a dedicated connection listening to the channel and publishes every notified event.
+func (l *Listener) listen(ctx context.Context) error {
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return err
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn any) error {
+		pgConn := driverConn.(*stdlib.Conn).Conn()
+		if _, err := pgConn.Exec(ctx, "LISTEN "+pgx.Identifier{l.channel}.Sanitize()); err != nil {
+			return err
+		}
+
+		// the events notified while not listening are caught up from the outbox.
+		if err := l.catchUp(ctx); err != nil {
+			return err
+		}
+
+		for {
+			n, err := pgConn.WaitForNotification(ctx)
+			if err != nil {
+				return err
+			}
+
+			id, err := strconv.ParseUint(n.Payload, 10, 64)
+			if err != nil {
+				l.log.Error(fmt.Sprintf("invalid notification on channel %s: %q", l.channel, n.Payload))
+				continue
+			}
+
+			var event outbox.Event
+			if err := l.db.WithContext(tenant.AllTenants(ctx)).First(&event, id).Error; err != nil {
+				return err
+			}
+			l.broker.Publish(event)
+		}
+	})
+}
+
+// catchUp publishes the latest published events again, up to the size of the log of the broker,
+// in the order they were published. The broker ignores the ones it already holds.
+func (l *Listener) catchUp(ctx context.Context) error {
+	var events []outbox.Event
+	err := l.db.WithContext(tenant.AllTenants(ctx)).
+		Where("published_at IS NOT NULL").
+		Order("published_at desc, id desc").
+		Limit(l.broker.size).
+		Find(&events).Error
+	if err != nil {
+		return err
+	}
+
+	slices.Reverse(events)
+	for _, event := range events {
+		l.broker.Publish(event)
+	}
+
+	return nil
+}
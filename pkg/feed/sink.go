@@ -0,0 +1,57 @@
+package feed
+
+import (
+	"context"
+	"strconv"
+
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+)
+
+const (
+	// BACKEND_MEMORY publishes the events straight into the broker of the replica running the relay.
+	BACKEND_MEMORY = "memory"
+	// BACKEND_POSTGRES notifies every replica of the events via Postgres LISTEN/NOTIFY.
+	BACKEND_POSTGRES = "postgres"
+)
+
+var (
+	_ outbox.Sink = &BrokerSink{}
+	_ outbox.Sink = &NotifySink{}
+)
+
+// BrokerSink publishes the events into an in-process broker.
+type BrokerSink struct {
+	broker *Broker
+}
+
+// NewBrokerSink creates a new sink that publishes the events into the broker
+func NewBrokerSink(broker *Broker) *BrokerSink {
+	return &BrokerSink{broker}
+}
+
+func (s *BrokerSink) Name() string { return "feed" }
+
+func (s *BrokerSink) Publish(ctx context.Context, event outbox.Event) error {
+	s.broker.Publish(event)
+	return nil
+}
+
+// NotifySink notifies the listeners of the channel of the published events. Only the event id
+// is sent, as a notification payload is limited to 8000 bytes; listeners load the event itself.
+type NotifySink struct {
+	db      *gorm.DB
+	channel string
+}
+
+// NewNotifySink creates a new sink that notifies the events on the Postgres channel
+func NewNotifySink(db *gorm.DB, channel string) *NotifySink {
+	return &NotifySink{db, channel}
+}
+
+func (s *NotifySink) Name() string { return "feed" }
+
+func (s *NotifySink) Publish(ctx context.Context, event outbox.Event) error {
+	return s.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", s.channel, strconv.FormatUint(event.ID, 10)).Error
+}
@@ -0,0 +1,186 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+const (
+	// HeaderKey is the request header carrying the idempotency key.
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is the response header set when the response is replayed from the store.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// maxKeyLength is the maximum length of an idempotency key.
+	maxKeyLength = 255
+)
+
+// recorder captures the response written by the handler while passing it through.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) WriteHeader(code int) {
+	rec.status = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// Middleware makes POST requests sent with an Idempotency-Key header safe to retry. The first
+// response of a key is stored for the configured ttl and replayed to later requests with the same
+// key. A request sent while the first one is in progress gets 409, and a request reusing the key
+// with a different method, path or body gets 422. Server errors are not stored, so they can be
+// retried. The key of a request in progress is reserved for the configured lease only, so a
+// request whose replica stopped does not hold its key until the ttl. The body is read into memory
+// to fingerprint the request, so it must not be larger than the configured size.
+func Middleware(store Store, conf config.Idempotency, log *zap.Logger) func(http.Handler) http.HandlerFunc {
+	return func(next http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			if len(key) > maxKeyLength {
+				msg := fmt.Sprintf("%s must not be longer than %d characters", HeaderKey, maxKeyLength)
+				response.WriteError(ctx, w, apierror.ErrInvalidInput.WithMessage(msg), log)
+				return
+			}
+			// the keys are chosen by the clients, so the same key may be used in several tenants.
+			if id, ok := tenant.FromContext(ctx); ok {
+				key = id + "/" + key
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, conf.MaxBodySize))
+			r.Body.Close()
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				msg := fmt.Sprintf("body of a request with an %s is larger than %d bytes", HeaderKey, conf.MaxBodySize)
+				response.WriteError(ctx, w, apierror.ErrTooLarge.WithMessage(msg), log)
+				return
+			} else if err != nil {
+				response.WriteError(ctx, w, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("failed to read body: %v", err)), log)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			hash := requestHash(r, body)
+			existing, reserved, err := store.Begin(key, hash, conf.Lease)
+			if err != nil {
+				response.WriteError(ctx, w, err, log)
+				return
+			}
+
+			if !reserved {
+				replay(ctx, w, existing, hash, log)
+				return
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			completed := false
+			defer func() {
+				// free the key if the handler panics, so the request can be retried.
+				if !completed {
+					release(ctx, store, key, log)
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+
+			completed = true
+			if rec.status >= http.StatusInternalServerError {
+				release(ctx, store, key, log)
+				return
+			}
+
+			if err := store.Complete(key, rec.status, rec.Header().Get("Content-Type"), rec.body.Bytes(), conf.TTL); err != nil {
+				log.Error(
+					fmt.Sprintf("failed to store response of idempotency key %s: %v", key, err),
+					zap.String(middleware.LogKeyID, middleware.GetRequestID(ctx)),
+				)
+				release(ctx, store, key, log)
+			}
+		}
+	}
+}
+
+// replay writes the stored response of the existing record, or an error if it cannot be replayed.
+func replay(ctx context.Context, w http.ResponseWriter, existing *Record, hash string, log *zap.Logger) {
+	switch {
+	case existing != nil && existing.RequestHash != hash:
+		msg := fmt.Sprintf("%s was already used with a different request", HeaderKey)
+		response.WriteError(ctx, w, apierror.ErrUnprocessable.WithMessage(msg), log)
+	case existing == nil || existing.State != STATE_COMPLETED:
+		msg := fmt.Sprintf("a request with the same %s is in progress", HeaderKey)
+		response.WriteError(ctx, w, apierror.ErrConflict.WithMessage(msg), log)
+	default:
+		if existing.ContentType != "" {
+			w.Header().Set("Content-Type", existing.ContentType)
+		}
+		w.Header().Set(HeaderReplayed, "true")
+		w.WriteHeader(existing.StatusCode)
+		if _, err := w.Write(existing.Body); err != nil {
+			log.Error(fmt.Sprintf("failed to write replayed response: %v", err), zap.String(middleware.LogKeyID, middleware.GetRequestID(ctx)))
+		}
+	}
+}
+
+func release(ctx context.Context, store Store, key string, log *zap.Logger) {
+	if err := store.Release(key); err != nil {
+		log.Error(
+			fmt.Sprintf("failed to release idempotency key %s: %v", key, err),
+			zap.String(middleware.LogKeyID, middleware.GetRequestID(ctx)),
+		)
+	}
+}
+
+// requestHash returns the fingerprint of the request used to detect a reused key.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// RunPurger deletes the expired records periodically until ctx is done.
+func RunPurger(ctx context.Context, store Store, interval time.Duration, log *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			n, err := store.DeleteExpired(now)
+			if err != nil {
+				log.Error(fmt.Sprintf("failed to delete expired idempotency keys: %v", err))
+			} else if n > 0 {
+				log.Info(fmt.Sprintf("deleted %d expired idempotency keys", n))
+			}
+		}
+	}
+}
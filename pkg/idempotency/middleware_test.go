@@ -0,0 +1,290 @@
+package idempotency_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/idempotency"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// memoryStore is an in-memory idempotency store for tests.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]idempotency.Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: make(map[string]idempotency.Record)}
+}
+
+func (m *memoryStore) Begin(key string, requestHash string, lease time.Duration) (*idempotency.Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if existing, ok := m.records[key]; ok && existing.ExpiresAt.After(time.Now()) {
+		return &existing, false, nil
+	}
+
+	m.records[key] = idempotency.Record{
+		Key:         key,
+		RequestHash: requestHash,
+		State:       idempotency.STATE_IN_PROGRESS,
+		ExpiresAt:   time.Now().Add(lease),
+	}
+	return nil, true, nil
+}
+
+func (m *memoryStore) Complete(key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	record := m.records[key]
+	record.State = idempotency.STATE_COMPLETED
+	record.StatusCode = statusCode
+	record.ContentType = contentType
+	record.Body = body
+	record.ExpiresAt = time.Now().Add(ttl)
+	m.records[key] = record
+	return nil
+}
+
+func (m *memoryStore) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.records[key].State == idempotency.STATE_IN_PROGRESS {
+		delete(m.records, key)
+	}
+	return nil
+}
+
+func (m *memoryStore) DeleteExpired(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// conf is the configuration of the middleware in tests.
+var conf = config.Idempotency{TTL: time.Hour, Lease: time.Minute, MaxBodySize: 1 << 10}
+
+func TestMiddleware(t *testing.T) {
+	type request struct {
+		key    string
+		tenant string
+		body   string
+	}
+	type output struct {
+		code     int
+		body     string
+		replayed bool
+		errMsg   string
+	}
+	testCases := []struct {
+		name     string
+		status   int
+		requests []request
+		outputs  []output
+		calls    int
+	}{
+		{
+			name:   "without key",
+			status: http.StatusCreated,
+			requests: []request{
+				{body: `{"title":"a"}`},
+				{body: `{"title":"a"}`},
+			},
+			outputs: []output{
+				{code: http.StatusCreated, body: "{\"id\":1}\n"},
+				{code: http.StatusCreated, body: "{\"id\":2}\n"},
+			},
+			calls: 2,
+		},
+		{
+			name:   "replay",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", body: `{"title":"a"}`},
+				{key: "key-1", body: `{"title":"a"}`},
+			},
+			outputs: []output{
+				{code: http.StatusCreated, body: "{\"id\":1}\n"},
+				{code: http.StatusCreated, body: "{\"id\":1}\n", replayed: true},
+			},
+			calls: 1,
+		},
+		{
+			name:   "different body",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", body: `{"title":"a"}`},
+				{key: "key-1", body: `{"title":"b"}`},
+			},
+			outputs: []output{
+				{code: http.StatusCreated, body: "{\"id\":1}\n"},
+				{code: http.StatusUnprocessableEntity, errMsg: "Idempotency-Key was already used with a different request"},
+			},
+			calls: 1,
+		},
+		{
+			name:   "same key in another tenant",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", tenant: "school-a", body: `{"title":"a"}`},
+				{key: "key-1", tenant: "school-b", body: `{"title":"b"}`},
+				{key: "key-1", tenant: "school-a", body: `{"title":"a"}`},
+			},
+			outputs: []output{
+				{code: http.StatusCreated, body: "{\"id\":1}\n"},
+				{code: http.StatusCreated, body: "{\"id\":2}\n"},
+				{code: http.StatusCreated, body: "{\"id\":1}\n", replayed: true},
+			},
+			calls: 2,
+		},
+		{
+			name:   "body too large",
+			status: http.StatusCreated,
+			requests: []request{
+				{key: "key-1", body: `{"title":"` + strings.Repeat("a", 1<<10) + `"}`},
+			},
+			outputs: []output{
+				{code: http.StatusRequestEntityTooLarge, errMsg: "body of a request with an Idempotency-Key is larger than 1024 bytes"},
+			},
+			calls: 0,
+		},
+		{
+			name:   "server error is not stored",
+			status: http.StatusInternalServerError,
+			requests: []request{
+				{key: "key-1", body: `{"title":"a"}`},
+				{key: "key-1", body: `{"title":"a"}`},
+			},
+			outputs: []output{
+				{code: http.StatusInternalServerError, body: "{\"id\":1}\n"},
+				{code: http.StatusInternalServerError, body: "{\"id\":2}\n"},
+			},
+			calls: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newMemoryStore()
+
+			calls := 0
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				require.NoError(t, json.NewEncoder(w).Encode(map[string]int{"id": calls}))
+			})
+			handler := idempotency.Middleware(store, conf, zap.NewNop())(next)
+
+			for i, r := range tc.requests {
+				req, err := http.NewRequest(http.MethodPost, "/api/v1/books", strings.NewReader(r.body))
+				require.NoError(t, err)
+				if r.key != "" {
+					req.Header.Set(idempotency.HeaderKey, r.key)
+				}
+				if r.tenant != "" {
+					req = req.WithContext(tenant.NewContext(req.Context(), r.tenant))
+				}
+
+				resp := httptest.NewRecorder()
+				handler.ServeHTTP(resp, req)
+
+				out := tc.outputs[i]
+				require.Equal(t, out.code, resp.Code)
+
+				if out.errMsg == "" {
+					require.Equal(t, out.body, resp.Body.String())
+					require.Equal(t, "application/json", resp.Header().Get("Content-Type"))
+					require.Equal(t, out.replayed, resp.Header().Get(idempotency.HeaderReplayed) == "true")
+				} else {
+					var res apierrors.Error
+					err := json.NewDecoder(resp.Body).Decode(&res)
+					require.NoError(t, err)
+
+					require.Equal(t, out.errMsg, res.Message)
+				}
+			}
+
+			require.Equal(t, tc.calls, calls)
+		})
+	}
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	started := make(chan struct{})
+	done := make(chan struct{})
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-done
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := idempotency.Middleware(newMemoryStore(), conf, zap.NewNop())(next)
+
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest(http.MethodPost, "/api/v1/books", strings.NewReader(`{"title":"a"}`))
+		require.NoError(t, err)
+		req.Header.Set(idempotency.HeaderKey, "key-1")
+		return req
+	}
+
+	first := httptest.NewRecorder()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(first, newRequest())
+	}()
+	<-started
+
+	second := httptest.NewRecorder()
+	handler.ServeHTTP(second, newRequest())
+
+	close(done)
+	wg.Wait()
+
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Equal(t, http.StatusConflict, second.Code)
+
+	var res apierrors.Error
+	err := json.NewDecoder(second.Body).Decode(&res)
+	require.NoError(t, err)
+	require.Equal(t, "a request with the same Idempotency-Key is in progress", res.Message)
+}
+
+func TestMiddlewareExpiredLease(t *testing.T) {
+	store := newMemoryStore()
+	// the replica serving the first request stopped before completing it.
+	_, reserved, err := store.Begin("key-1", "hash", -time.Second)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := idempotency.Middleware(store, conf, zap.NewNop())(next)
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/books", strings.NewReader(`{"title":"a"}`))
+	require.NoError(t, err)
+	req.Header.Set(idempotency.HeaderKey, "key-1")
+
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusCreated, resp.Code)
+	require.Equal(t, 1, calls)
+	require.True(t, store.records["key-1"].ExpiresAt.After(time.Now().Add(conf.Lease)))
+}
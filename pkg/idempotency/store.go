@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type State string
+
+const (
+	STATE_IN_PROGRESS State = "in_progress"
+	STATE_COMPLETED   State = "completed"
+)
+
+// Record is the model for a request sent with an idempotency key and its stored response.
+type Record struct {
+	Key         string `gorm:"primaryKey;column:idempotency_key"`
+	RequestHash string `gorm:"not null"`
+	State       State  `gorm:"not null"`
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time `gorm:"index"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// Store is the interface for storing requests sent with an idempotency key
+type Store interface {
+	// Begin reserves the key for a new request until the lease expires. If the key is already
+	// taken by an unexpired record, the existing record is returned and the key is not reserved.
+	// The existing record is nil if the key is released by its request in the meantime.
+	Begin(key string, requestHash string, lease time.Duration) (existing *Record, reserved bool, err error)
+	// Complete stores the response of the request reserving the key until the ttl expires.
+	Complete(key string, statusCode int, contentType string, body []byte, ttl time.Duration) error
+	// Release frees a reserved key whose request did not complete, so it can be retried.
+	Release(key string) error
+	// DeleteExpired deletes the records expired before the given time.
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+var (
+	_ Store = &Repository{}
+)
+
+// Repository is the store that keeps the idempotency records in the database.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new idempotency repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// Begin inserts an in-progress record for the key unless an unexpired one already exists
+func (r *Repository) Begin(key string, requestHash string, lease time.Duration) (*Record, bool, error) {
+	now := time.Now()
+	record := Record{
+		Key:         key,
+		RequestHash: requestHash,
+		State:       STATE_IN_PROGRESS,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(lease),
+	}
+
+	// the key may still be held by an expired record that is not purged yet, such as the record
+	// of a request whose replica stopped before completing it.
+	if err := r.db.Where("idempotency_key = ? AND expires_at < ?", key, now).Delete(&Record{}).Error; err != nil {
+		return nil, false, err
+	}
+
+	tx := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record)
+	if tx.Error != nil {
+		return nil, false, tx.Error
+	}
+	if tx.RowsAffected == 1 {
+		return nil, true, nil
+	}
+
+	var existing Record
+	if err := r.db.Where("idempotency_key = ?", key).First(&existing).Error; errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	return &existing, false, nil
+}
+
+// Complete stores the response of the request
+func (r *Repository) Complete(key string, statusCode int, contentType string, body []byte, ttl time.Duration) error {
+	return r.db.Model(&Record{}).Where("idempotency_key = ?", key).Updates(map[string]any{
+		"state":        STATE_COMPLETED,
+		"status_code":  statusCode,
+		"content_type": contentType,
+		"body":         body,
+		"expires_at":   time.Now().Add(ttl),
+	}).Error
+}
+
+// Release deletes the in-progress record of the key
+func (r *Repository) Release(key string) error {
+	return r.db.Where("idempotency_key = ? AND state = ?", key, STATE_IN_PROGRESS).Delete(&Record{}).Error
+}
+
+// DeleteExpired deletes the expired records
+func (r *Repository) DeleteExpired(now time.Time) (int64, error) {
+	tx := r.db.Where("expires_at < ?", now).Delete(&Record{})
+	return tx.RowsAffected, tx.Error
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+)
+
+var (
+	_ booktypes.ChangeWriter = WriteBookChange
+)
+
+// WriteBookChange is the change writer that keeps a book with copies from being deleted, so no
+// copy is left without its book. The copies must be deleted first.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	if change.After != nil {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&types.Copy{}).Where("book_id = ?", change.BookID).Count(&count).Error; err != nil {
+		return err
+	} else if count > 0 {
+		return apierror.ErrConflict.WithMessage(fmt.Sprintf("book has %d copies", count))
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package service
+
+import (
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+var (
+	_ booktypes.BookInventory = BookInventory{}
+)
+
+// BookInventory is the book inventory counting the copies of the books of the books service.
+type BookInventory struct{}
+
+// CountCopies counts the copies of the books per status
+func (BookInventory) CountCopies(db *gorm.DB, bookIDs []int) ([]booktypes.CopyCount, error) {
+	var counts []booktypes.CopyCount
+	err := db.Table("book_copies").
+		Select("book_id, status, COUNT(*) AS count").
+		Where("book_id IN ?", bookIDs).
+		Group("book_id, status").
+		Scan(&counts).Error
+
+	return counts, err
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the inventory service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the inventory service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/books/{id}/copies", h.GetCopies)
+	mux.HandleFunc("POST /api/v1/books/{id}/copies", h.AddCopy)
+	mux.HandleFunc("GET /api/v1/books/{id}/copies/{copy_id}", h.GetCopy)
+	mux.HandleFunc("PUT /api/v1/books/{id}/copies/{copy_id}", h.UpdateCopy)
+	mux.HandleFunc("DELETE /api/v1/books/{id}/copies/{copy_id}", h.DeleteCopy)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// parseCopyPath parses the book id and the copy id of the path
+func parseCopyPath(r *http.Request) (bookID int, id int, err error) {
+	if bookID, err = parseIntParam("id", r.PathValue("id")); err != nil {
+		return 0, 0, err
+	}
+	if id, err = parseIntParam("copy_id", r.PathValue("copy_id")); err != nil {
+		return 0, 0, err
+	}
+
+	return bookID, id, nil
+}
+
+// @Summary get the copies of a book
+// @ID get-book-copies
+// @Param id path int true "Book ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param branch query string false "branch holding the copies"
+// @Param status query string false "status of the copies" enums(available,on_loan,on_hold,lost,repair)
+// @Produce json
+// @Success 200 {object} types.GetCopiesResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/copies [get]
+func (h Handler) GetCopies(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	filter := types.CopyFilter{
+		Branch: r.URL.Query().Get("branch"),
+		Status: booktypes.CopyStatus(r.URL.Query().Get("status")),
+	}
+
+	result, err := h.serv.WithContext(ctx).GetCopies(bookID, filter, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a copy of a book
+// @ID get-book-copy
+// @Param id path int true "Book ID"
+// @Param copy_id path int true "Copy ID"
+// @Produce json
+// @Success 200 {object} types.Copy
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/copies/{copy_id} [get]
+func (h Handler) GetCopy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseCopyPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetCopy(bookID, id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary add a new copy of a book
+// @Description The barcode is unique across all branches.
+// @ID add-book-copy
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param Body body types.AddCopyRequest true "Copy information"
+// @Success 201 {object} types.AddCopyResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/copies [post]
+func (h Handler) AddCopy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.AddCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).AddCopy(bookID, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary update a copy of a book
+// @ID update-book-copy
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param copy_id path int true "Copy ID"
+// @Param Body body types.UpdateCopyRequest true "Copy information that needs to be updated"
+// @Success 200 {object} types.Copy
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/copies/{copy_id} [put]
+func (h Handler) UpdateCopy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseCopyPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateCopyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateCopy(bookID, id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary delete a copy of a book
+// @ID delete-book-copy
+// @Param id path int true "Book ID"
+// @Param copy_id path int true "Copy ID"
+// @Produce json
+// @Success 200 {object} types.DeleteCopyResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/copies/{copy_id} [delete]
+func (h Handler) DeleteCopy(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseCopyPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteCopy(bookID, id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
@@ -0,0 +1,335 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	require.Equal(t, "LIB000123", types.NormalizeBarcode(" lib 000123 "))
+}
+
+func TestBookAvailability(t *testing.T) {
+	var a booktypes.BookAvailability
+	a.Add(booktypes.COPY_STATUS_AVAILABLE, 2)
+	a.Add(booktypes.COPY_STATUS_ON_LOAN, 1)
+	a.Add(booktypes.COPY_STATUS_REPAIR, 1)
+
+	require.Equal(t, booktypes.BookAvailability{Total: 4, Available: 2, OnLoan: 1, Repair: 1}, a)
+}
+
+func TestAddCopy(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.AddCopyResponse
+		errMsg string
+		errID  int
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.AddCopyRequest
+		output     output
+	}{
+		{
+			name:  "invalid status",
+			input: types.AddCopyRequest{Barcode: "LIB000123", Branch: "Central", Status: "borrowed"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Status":"It is invalid"}`,
+			},
+		},
+		{
+			name:  "book not found",
+			input: types.AddCopyRequest{Barcode: "LIB000123", Branch: "Central"},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "book not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(false, nil)
+			},
+		},
+		{
+			name:  "blank barcode",
+			input: types.AddCopyRequest{Barcode: "   ", Branch: "Central"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "barcode must not be blank",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+			},
+		},
+		{
+			name:  "barcode used in another branch",
+			input: types.AddCopyRequest{Barcode: "lib000123", Branch: "Central"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "copy already exists: 7",
+				errID:  7,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().GetCopyByBarcode("LIB000123").Return(&types.Copy{ID: 7, BookID: 2, Branch: "North"}, nil)
+			},
+		},
+		{
+			name:  "barcode added concurrently",
+			input: types.AddCopyRequest{Barcode: "lib000123", Branch: "Central"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "copy already exists: 8",
+				errID:  8,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				gomock.InOrder(
+					s.Repository.EXPECT().GetCopyByBarcode("LIB000123").Return(nil, gorm.ErrRecordNotFound),
+					s.Repository.EXPECT().CreateCopy(gomock.Any()).Return(types.Copy{}, gorm.ErrDuplicatedKey),
+					s.Repository.EXPECT().GetCopyByBarcode("LIB000123").Return(&types.Copy{ID: 8, BookID: 1, Branch: "North"}, nil),
+				)
+			},
+		},
+		{
+			name:  "success with defaults",
+			input: types.AddCopyRequest{Barcode: "lib 000123", Branch: "Central", Location: "Shelf 4B"},
+			output: output{
+				code: http.StatusCreated,
+				body: types.AddCopyResponse{ID: 1},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				c := types.Copy{
+					BookID:    1,
+					Barcode:   "LIB000123",
+					Branch:    "Central",
+					Location:  "Shelf 4B",
+					Condition: types.COPY_CONDITION_GOOD,
+					Status:    booktypes.COPY_STATUS_AVAILABLE,
+				}
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().GetCopyByBarcode("LIB000123").Return(nil, gorm.ErrRecordNotFound)
+				created := c
+				created.ID = 1
+				s.Repository.EXPECT().CreateCopy(c).Return(created, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/books/1/copies", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.AddCopyResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+				require.Equal(t, tc.output.errID, res.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateCopy(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.Copy
+		errMsg string
+	}
+	existing := types.Copy{
+		ID:        3,
+		BookID:    1,
+		Barcode:   "LIB000123",
+		Branch:    "Central",
+		Condition: types.COPY_CONDITION_GOOD,
+		Status:    booktypes.COPY_STATUS_AVAILABLE,
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.UpdateCopyRequest
+		output     output
+	}{
+		{
+			name:  "copy of another book",
+			input: types.UpdateCopyRequest{Status: booktypes.COPY_STATUS_REPAIR},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "copy not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetCopy(1, 3).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "status set to on loan",
+			input: types.UpdateCopyRequest{Status: booktypes.COPY_STATUS_ON_LOAN},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Status":"It is invalid"}`,
+			},
+		},
+		{
+			name:  "status of a copy on loan",
+			input: types.UpdateCopyRequest{Status: booktypes.COPY_STATUS_AVAILABLE},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "copy is on loan",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				c := existing
+				c.Status = booktypes.COPY_STATUS_ON_LOAN
+				s.Repository.EXPECT().GetCopy(1, 3).Return(&c, nil)
+			},
+		},
+		{
+			name:  "same barcode kept",
+			input: types.UpdateCopyRequest{Barcode: "lib000123", Status: booktypes.COPY_STATUS_REPAIR, Condition: types.COPY_CONDITION_FAIR},
+			output: output{
+				code: http.StatusOK,
+				body: types.Copy{
+					ID:        3,
+					BookID:    1,
+					Barcode:   "LIB000123",
+					Branch:    "Central",
+					Condition: types.COPY_CONDITION_FAIR,
+					Status:    booktypes.COPY_STATUS_REPAIR,
+				},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				c := existing
+				s.Repository.EXPECT().GetCopy(1, 3).Return(&c, nil)
+				s.Repository.EXPECT().GetCopyByBarcode("LIB000123").Return(&existing, nil)
+				s.Repository.EXPECT().UpdateCopy(&types.Copy{
+					ID:        3,
+					BookID:    1,
+					Barcode:   "LIB000123",
+					Branch:    "Central",
+					Condition: types.COPY_CONDITION_FAIR,
+					Status:    booktypes.COPY_STATUS_REPAIR,
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, "/api/v1/books/1/copies/3", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Copy
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestGetCopies(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+	}{
+		{
+			name:  "invalid status",
+			query: "page=1&limit=10&status=borrowed",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "filtered by branch and status",
+			query: "page=1&limit=10&branch=Central&status=available",
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().GetCopies(1, types.CopyFilter{Branch: "Central", Status: booktypes.COPY_STATUS_AVAILABLE}, 1, 10).Return(nil, []types.Copy{}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/books/1/copies?"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+		})
+	}
+}
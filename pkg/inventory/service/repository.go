@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in an inventory service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new inventory-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// BookExists checks if the book exists in the database
+func (r *Repository) BookExists(bookID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&booktypes.Book{}).Where("id = ?", bookID).Count(&count).Error
+	return count > 0, err
+}
+
+// CreateCopy creates a new copy in the database
+func (r *Repository) CreateCopy(c types.Copy) (types.Copy, error) {
+	tx := r.db.Create(&c)
+	return c, tx.Error
+}
+
+// UpdateCopy updates a copy in the database
+func (r *Repository) UpdateCopy(c *types.Copy) error {
+	return r.db.Save(c).Error
+}
+
+// DeleteCopy deletes a copy from the database
+func (r *Repository) DeleteCopy(c *types.Copy) error {
+	return r.db.Delete(c).Error
+}
+
+// GetCopies retrieves the copies of a book matching the filter from the database
+func (r *Repository) GetCopies(bookID int, filter types.CopyFilter, page int, limit int) (*db.Pagination, []types.Copy, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id asc",
+	}
+
+	tx := r.db.Where("book_id = ?", bookID)
+	if filter.Branch != "" {
+		tx = tx.Where("branch = ?", filter.Branch)
+	}
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var copies []types.Copy
+	if result := tx.Scopes(db.Paginate(&copies, &p, tx)).Find(&copies); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, copies, nil
+}
+
+// GetCopy retrieves a copy of a book from the database
+func (r *Repository) GetCopy(bookID int, id int) (*types.Copy, error) {
+	var c types.Copy
+	if result := r.db.Where("book_id = ?", bookID).First(&c, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &c, nil
+}
+
+// GetCopyByBarcode retrieves a copy from the database by its normalized barcode
+func (r *Repository) GetCopyByBarcode(barcode string) (*types.Copy, error) {
+	var c types.Copy
+	if result := r.db.Where("barcode = ?", barcode).First(&c); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+)
+
+// Service is the service layer for the physical copies of books
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new inventory service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// AddCopy adds a new copy of a book
+func (s *Service) AddCopy(bookID int, req types.AddCopyRequest) (types.AddCopyResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.AddCopyResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.AddCopyResponse{}, err
+	}
+
+	c := types.Copy{
+		BookID:    bookID,
+		Barcode:   types.NormalizeBarcode(req.Barcode),
+		Branch:    req.Branch,
+		Location:  req.Location,
+		Condition: req.Condition,
+		Status:    req.Status,
+	}
+	if c.Condition == "" {
+		c.Condition = types.COPY_CONDITION_GOOD
+	}
+	if c.Status == "" {
+		c.Status = booktypes.COPY_STATUS_AVAILABLE
+	}
+	if err := s.checkBarcodeAvailable(c); err != nil {
+		return types.AddCopyResponse{}, err
+	}
+
+	created, err := s.dataProvider.CreateCopy(c)
+	if err != nil {
+		return types.AddCopyResponse{}, s.barcodeConflict(err, c)
+	}
+
+	return types.AddCopyResponse{
+		ID: created.ID,
+	}, nil
+}
+
+// UpdateCopy updates a copy of a book
+func (s *Service) UpdateCopy(bookID int, id int, req types.UpdateCopyRequest) (types.Copy, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Copy{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	c, err := s.GetCopy(bookID, id)
+	if err != nil {
+		return types.Copy{}, err
+	}
+
+	if req.Barcode != "" {
+		c.Barcode = types.NormalizeBarcode(req.Barcode)
+		if err := s.checkBarcodeAvailable(*c); err != nil {
+			return types.Copy{}, err
+		}
+	}
+	if req.Branch != "" {
+		c.Branch = req.Branch
+	}
+	if req.Location != "" {
+		c.Location = req.Location
+	}
+	if req.Condition != "" {
+		c.Condition = req.Condition
+	}
+	if req.Status != "" {
+		if err := checkNotInCirculation(c); err != nil {
+			return types.Copy{}, err
+		}
+		c.Status = req.Status
+	}
+
+	if err := s.dataProvider.UpdateCopy(c); err != nil {
+		return types.Copy{}, s.barcodeConflict(err, *c)
+	}
+
+	return *c, nil
+}
+
+// DeleteCopy deletes a copy of a book
+func (s *Service) DeleteCopy(bookID int, id int) (types.DeleteCopyResponse, error) {
+	c, err := s.GetCopy(bookID, id)
+	if err != nil {
+		return types.DeleteCopyResponse{}, err
+	}
+	if err := checkNotInCirculation(c); err != nil {
+		return types.DeleteCopyResponse{}, err
+	}
+
+	return types.DeleteCopyResponse{}, s.dataProvider.DeleteCopy(c)
+}
+
+// GetCopies returns the copies of a book matching the filter
+func (s *Service) GetCopies(bookID int, filter types.CopyFilter, page int, limit int) (types.GetCopiesResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		return types.GetCopiesResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid status: %s", filter.Status))
+	}
+
+	if err := s.checkBookExists(bookID); err != nil {
+		return types.GetCopiesResponse{}, err
+	}
+
+	pagination, copies, err := s.dataProvider.GetCopies(bookID, filter, page, limit)
+	if err != nil {
+		return types.GetCopiesResponse{}, err
+	}
+
+	return types.GetCopiesResponse{
+		Copies:     copies,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetCopy returns a copy of a book from the given id
+func (s *Service) GetCopy(bookID int, id int) (*types.Copy, error) {
+	c, err := s.dataProvider.GetCopy(bookID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("copy not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// checkBarcodeAvailable checks that the barcode is not blank and no other copy has it, in any
+// branch
+func (s *Service) checkBarcodeAvailable(c types.Copy) error {
+	if c.Barcode == "" {
+		return apierror.ErrInvalidInput.WithMessage("barcode must not be blank")
+	}
+
+	existing, err := s.dataProvider.GetCopyByBarcode(c.Barcode)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.ID != c.ID {
+		return apierror.NewConflictError(fmt.Sprintf("copy already exists: %d", existing.ID), existing.ID)
+	}
+	return nil
+}
+
+// barcodeConflict returns the conflict with the copy holding the barcode of the copy if err is a
+// duplicated key, as when another copy with the barcode is added after the barcode was checked.
+// Otherwise it returns err.
+func (s *Service) barcodeConflict(err error, c types.Copy) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	if conflict := s.checkBarcodeAvailable(c); conflict != nil {
+		return conflict
+	}
+	// the other copy is gone already.
+	return apierror.ErrConflict.WithMessage(fmt.Sprintf("barcode already exists: %s", c.Barcode))
+}
+
+// checkNotInCirculation checks that the copy is neither on loan nor on hold, as only the loans
+// service changes the status of such a copy
+func checkNotInCirculation(c *types.Copy) error {
+	switch c.Status {
+	case booktypes.COPY_STATUS_ON_LOAN:
+		return apierror.ErrConflict.WithMessage("copy is on loan")
+	case booktypes.COPY_STATUS_ON_HOLD:
+		return apierror.ErrConflict.WithMessage("copy is on hold")
+	}
+
+	return nil
+}
+
+func (s *Service) checkBookExists(bookID int) error {
+	exists, err := s.dataProvider.BookExists(bookID)
+	if err != nil {
+		return err
+	} else if !exists {
+		return apierror.NewNotFoundError("book not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// RunDataProviderTests checks that a data provider behaves as the inventory service expects.
+// newDataProvider returns an empty data provider for every test, along with the books data
+// provider of the same database, creating the books the copies belong to. ctx is the context
+// of the requests given to the data providers.
+func RunDataProviderTests(t *testing.T, ctx context.Context, newDataProvider func(t *testing.T) (types.DataProvider, booktypes.DataProvider)) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider)
+	}{
+		{"book exists", testBookExists},
+		{"create and get copy", testCreateAndGetCopy},
+		{"duplicate barcode", testDuplicateBarcode},
+		{"update and delete copy", testUpdateAndDeleteCopy},
+		{"filter and paginate copies", testFilterCopies},
+		{"tenant isolation", testTenantIsolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, books := newDataProvider(t)
+			tt.test(t, ctx, d, books)
+		})
+	}
+}
+
+// createBook creates a book for the copies.
+func createBook(t *testing.T, books booktypes.DataProvider) booktypes.Book {
+	book, err := books.CreateBook(booktypes.Book{Title: "title", Author: "author", Description: "description"})
+	require.NoError(t, err)
+
+	return book
+}
+
+func newCopy(bookID int, n int) types.Copy {
+	return types.Copy{
+		BookID:    bookID,
+		Barcode:   fmt.Sprintf("LIB%06d", n),
+		Branch:    "Central",
+		Condition: types.COPY_CONDITION_GOOD,
+		Status:    booktypes.COPY_STATUS_AVAILABLE,
+	}
+}
+
+// copyIDs returns the ids of the copies in their order.
+func copyIDs(copies []types.Copy) []int {
+	ids := []int{}
+	for _, c := range copies {
+		ids = append(ids, c.ID)
+	}
+
+	return ids
+}
+
+func testBookExists(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+
+	exists, err := d.BookExists(book.ID)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	exists, err = d.BookExists(book.ID + 1)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func testCreateAndGetCopy(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+
+	created, err := d.CreateCopy(newCopy(book.ID, 1))
+	require.NoError(t, err)
+	require.NotZero(t, created.ID)
+
+	got, err := d.GetCopy(book.ID, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "LIB000001", got.Barcode)
+	require.Equal(t, booktypes.COPY_STATUS_AVAILABLE, got.Status)
+
+	got, err = d.GetCopyByBarcode("LIB000001")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, got.ID)
+
+	// a copy of another book is not found.
+	_, err = d.GetCopy(book.ID+1, created.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = d.GetCopyByBarcode("LIB999999")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testDuplicateBarcode(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+
+	_, err := d.CreateCopy(newCopy(book.ID, 1))
+	require.NoError(t, err)
+
+	_, err = d.CreateCopy(newCopy(book.ID, 1))
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func testUpdateAndDeleteCopy(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	created, err := d.CreateCopy(newCopy(book.ID, 1))
+	require.NoError(t, err)
+
+	created.Branch = "North"
+	created.Status = booktypes.COPY_STATUS_REPAIR
+	require.NoError(t, d.UpdateCopy(&created))
+
+	got, err := d.GetCopy(book.ID, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "North", got.Branch)
+	require.Equal(t, booktypes.COPY_STATUS_REPAIR, got.Status)
+
+	require.NoError(t, d.DeleteCopy(&created))
+	_, err = d.GetCopy(book.ID, created.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testFilterCopies(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	other := createBook(t, books)
+
+	var created []types.Copy
+	for i := 1; i <= 4; i++ {
+		c := newCopy(book.ID, i)
+		if i%2 == 0 {
+			c.Branch = "North"
+			c.Status = booktypes.COPY_STATUS_ON_LOAN
+		}
+		c, err := d.CreateCopy(c)
+		require.NoError(t, err)
+		created = append(created, c)
+	}
+	_, err := d.CreateCopy(newCopy(other.ID, 5))
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		filter types.CopyFilter
+		ids    []int
+	}{
+		{
+			name: "no filter",
+			ids:  copyIDs(created),
+		},
+		{
+			name:   "branch",
+			filter: types.CopyFilter{Branch: "North"},
+			ids:    []int{created[1].ID, created[3].ID},
+		},
+		{
+			name:   "status",
+			filter: types.CopyFilter{Status: booktypes.COPY_STATUS_AVAILABLE},
+			ids:    []int{created[0].ID, created[2].ID},
+		},
+		{
+			name:   "branch and status",
+			filter: types.CopyFilter{Branch: "North", Status: booktypes.COPY_STATUS_AVAILABLE},
+			ids:    []int{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, copies, err := d.GetCopies(book.ID, tc.filter, 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.ids)), p.TotalRows)
+			require.Equal(t, tc.ids, copyIDs(copies))
+		})
+	}
+
+	p, copies, err := d.GetCopies(book.ID, types.CopyFilter{}, 2, 3)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), p.TotalRows)
+	require.Equal(t, 2, p.TotalPages)
+	require.Equal(t, []int{created[3].ID}, copyIDs(copies))
+}
+
+func testTenantIsolation(t *testing.T, ctx context.Context, d types.DataProvider, books booktypes.DataProvider) {
+	book := createBook(t, books)
+	created, err := d.CreateCopy(newCopy(book.ID, 1))
+	require.NoError(t, err)
+
+	otherCtx := tenant.NewContext(context.Background(), "other-tenant")
+	other := d.WithContext(otherCtx)
+
+	_, err = other.GetCopy(book.ID, created.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	exists, err := other.BookExists(book.ID)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// a barcode is used once per tenant.
+	_, err = other.CreateCopy(newCopy(book.ID, 1))
+	require.NoError(t, err)
+
+	got, err := d.GetCopyByBarcode("LIB000001")
+	require.NoError(t, err)
+	require.Equal(t, created.ID, got.ID)
+}
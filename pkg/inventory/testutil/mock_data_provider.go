@@ -0,0 +1,160 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/inventory/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/inventory/testutil/mock_data_provider.go --source ./pkg/inventory/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// BookExists mocks base method.
+func (m *MockDataProvider) BookExists(bookID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookExists", bookID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookExists indicates an expected call of BookExists.
+func (mr *MockDataProviderMockRecorder) BookExists(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookExists", reflect.TypeOf((*MockDataProvider)(nil).BookExists), bookID)
+}
+
+// CreateCopy mocks base method.
+func (m *MockDataProvider) CreateCopy(c types.Copy) (types.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCopy", c)
+	ret0, _ := ret[0].(types.Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCopy indicates an expected call of CreateCopy.
+func (mr *MockDataProviderMockRecorder) CreateCopy(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCopy", reflect.TypeOf((*MockDataProvider)(nil).CreateCopy), c)
+}
+
+// DeleteCopy mocks base method.
+func (m *MockDataProvider) DeleteCopy(c *types.Copy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCopy", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCopy indicates an expected call of DeleteCopy.
+func (mr *MockDataProviderMockRecorder) DeleteCopy(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCopy", reflect.TypeOf((*MockDataProvider)(nil).DeleteCopy), c)
+}
+
+// GetCopies mocks base method.
+func (m *MockDataProvider) GetCopies(bookID int, filter types.CopyFilter, page, limit int) (*db.Pagination, []types.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopies", bookID, filter, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.Copy)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetCopies indicates an expected call of GetCopies.
+func (mr *MockDataProviderMockRecorder) GetCopies(bookID, filter, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopies", reflect.TypeOf((*MockDataProvider)(nil).GetCopies), bookID, filter, page, limit)
+}
+
+// GetCopy mocks base method.
+func (m *MockDataProvider) GetCopy(bookID, id int) (*types.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopy", bookID, id)
+	ret0, _ := ret[0].(*types.Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCopy indicates an expected call of GetCopy.
+func (mr *MockDataProviderMockRecorder) GetCopy(bookID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopy", reflect.TypeOf((*MockDataProvider)(nil).GetCopy), bookID, id)
+}
+
+// GetCopyByBarcode mocks base method.
+func (m *MockDataProvider) GetCopyByBarcode(barcode string) (*types.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCopyByBarcode", barcode)
+	ret0, _ := ret[0].(*types.Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCopyByBarcode indicates an expected call of GetCopyByBarcode.
+func (mr *MockDataProviderMockRecorder) GetCopyByBarcode(barcode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCopyByBarcode", reflect.TypeOf((*MockDataProvider)(nil).GetCopyByBarcode), barcode)
+}
+
+// UpdateCopy mocks base method.
+func (m *MockDataProvider) UpdateCopy(c *types.Copy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCopy", c)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCopy indicates an expected call of UpdateCopy.
+func (mr *MockDataProviderMockRecorder) UpdateCopy(c any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCopy", reflect.TypeOf((*MockDataProvider)(nil).UpdateCopy), c)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
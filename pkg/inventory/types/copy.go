@@ -0,0 +1,51 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+type CopyCondition string
+
+const (
+	COPY_CONDITION_NEW     CopyCondition = "new"
+	COPY_CONDITION_GOOD    CopyCondition = "good"
+	COPY_CONDITION_FAIR    CopyCondition = "fair"
+	COPY_CONDITION_POOR    CopyCondition = "poor"
+	COPY_CONDITION_DAMAGED CopyCondition = "damaged"
+)
+
+// Copy is the model for a physical copy of a book held by a library branch.
+type Copy struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_book_copies_tenant_barcode"`
+	BookID   int    `json:"book_id" gorm:"not null;index" example:"1"`
+	// Barcode identifies the copy across all branches, see NormalizeBarcode.
+	Barcode string `json:"barcode" gorm:"not null;uniqueIndex:idx_book_copies_tenant_barcode" example:"LIB000123"`
+	Branch  string `json:"branch" gorm:"not null" example:"Central"`
+	// Location is the place of the copy within its branch, e.g. a shelf.
+	Location  string               `json:"location" gorm:"not null;default:''" example:"Shelf 4B"`
+	Condition CopyCondition        `json:"condition" gorm:"not null" example:"good"`
+	Status    booktypes.CopyStatus `json:"status" gorm:"not null" example:"available"`
+	CreatedAt time.Time            `json:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Copy) TableName() string {
+	return "book_copies"
+}
+
+// NormalizeBarcode returns the barcode in upper case without spaces, so a barcode typed by hand
+// matches the scanned one.
+func NormalizeBarcode(barcode string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(barcode), ""))
+}
+
+// CopyFilter narrows down the copies of a book listed by GetCopies.
+type CopyFilter struct {
+	Branch string
+	Status booktypes.CopyStatus `validate:"omitempty,oneof=available on_loan on_hold lost repair"`
+}
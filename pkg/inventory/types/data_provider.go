@@ -0,0 +1,24 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for an inventory service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	BookExists(bookID int) (bool, error)
+
+	CreateCopy(c Copy) (Copy, error)
+	UpdateCopy(c *Copy) error
+	DeleteCopy(c *Copy) error
+	GetCopies(bookID int, filter CopyFilter, page int, limit int) (*db.Pagination, []Copy, error)
+	// GetCopy retrieves a copy of the book; a copy of another book is not found.
+	GetCopy(bookID int, id int) (*Copy, error)
+	GetCopyByBarcode(barcode string) (*Copy, error)
+}
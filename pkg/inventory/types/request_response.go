@@ -0,0 +1,39 @@
+package types
+
+import (
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// AddCopyRequest is the request for adding a new copy of a book. A copy is in good condition
+// and available unless told otherwise.
+type AddCopyRequest struct {
+	Barcode   string               `json:"barcode" example:"LIB000123" validate:"required,max=64"`
+	Branch    string               `json:"branch" example:"Central" validate:"required,max=255"`
+	Location  string               `json:"location" example:"Shelf 4B" validate:"max=255"`
+	Condition CopyCondition        `json:"condition" example:"good" validate:"omitempty,oneof=new good fair poor damaged"`
+	Status    booktypes.CopyStatus `json:"status" example:"available" validate:"omitempty,oneof=available lost repair"`
+}
+
+// AddCopyResponse is the response for adding a new copy of a book
+type AddCopyResponse struct {
+	ID int `json:"id" example:"1"`
+}
+
+// UpdateCopyRequest is the request for updating a copy of a book; empty fields are kept
+type UpdateCopyRequest struct {
+	Barcode   string               `json:"barcode" example:"LIB000123" validate:"max=64"`
+	Branch    string               `json:"branch" example:"Central" validate:"max=255"`
+	Location  string               `json:"location" example:"Shelf 4B" validate:"max=255"`
+	Condition CopyCondition        `json:"condition" example:"good" validate:"omitempty,oneof=new good fair poor damaged"`
+	Status    booktypes.CopyStatus `json:"status" example:"repair" validate:"omitempty,oneof=available lost repair"`
+}
+
+// GetCopiesResponse is the response for getting the copies of a book
+type GetCopiesResponse struct {
+	Copies     []Copy         `json:"copies"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// DeleteCopyResponse is the response for deleting a copy of a book
+type DeleteCopyResponse struct{}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+)
+
+var (
+	_ booktypes.ChangeWriter = WriteBookChange
+)
+
+// WriteBookChange is the change writer that removes a deleted book from the lists, closing the
+// gap it leaves in each of them.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	if change.After != nil {
+		return nil
+	}
+
+	var listIDs []int
+	if err := tx.Model(&types.ListItem{}).Where("book_id = ?", change.BookID).Pluck("list_id", &listIDs).Error; err != nil {
+		return err
+	}
+	for _, listID := range listIDs {
+		// the list is locked as when its items are changed by its owner, before the position of
+		// the book is read.
+		var list types.List
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&list, listID).Error; err != nil {
+			return err
+		}
+
+		var item types.ListItem
+		if err := tx.Where("list_id = ? AND book_id = ?", listID, change.BookID).First(&item).Error; err != nil {
+			return err
+		}
+		if err := removeItem(tx, item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the lists service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the lists service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/lists", h.GetLists)
+	mux.HandleFunc("POST /api/v1/lists", h.CreateList)
+	mux.HandleFunc("GET /api/v1/lists/{id}", h.GetList)
+	mux.HandleFunc("PUT /api/v1/lists/{id}", h.UpdateList)
+	mux.HandleFunc("DELETE /api/v1/lists/{id}", h.DeleteList)
+	mux.HandleFunc("POST /api/v1/lists/{id}/items", h.AddListItem)
+	mux.HandleFunc("PUT /api/v1/lists/{id}/items", h.ReorderListItems)
+	mux.HandleFunc("DELETE /api/v1/lists/{id}/items/{book_id}", h.RemoveListItem)
+	mux.HandleFunc("GET /api/v1/shared-lists/{slug}", h.GetSharedList)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// @Summary get the lists of the caller
+// @ID get-lists
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param X-Actor header string true "Owner"
+// @Produce json
+// @Success 200 {object} types.GetListsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists [get]
+func (h Handler) GetLists(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetLists(ctx, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a list with its books
+// @Description A private list is only found by its owner.
+// @ID get-list
+// @Param id path int true "List ID"
+// @Param X-Actor header string false "Caller"
+// @Produce json
+// @Success 200 {object} types.List
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists/{id} [get]
+func (h Handler) GetList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetList(ctx, id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a public list with its books by its slug
+// @ID get-shared-list
+// @Param slug path string true "List slug"
+// @Produce json
+// @Success 200 {object} types.List
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /shared-lists/{slug} [get]
+func (h Handler) GetSharedList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result, err := h.serv.WithContext(ctx).GetSharedList(r.PathValue("slug"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary create a list
+// @Description The owner is the caller given by the X-Actor header. The slug is made from the owner and the name unless given, and is unique.
+// @ID create-list
+// @Produce json
+// @Param X-Actor header string true "Owner"
+// @Param Body body types.CreateListRequest true "List information"
+// @Success 201 {object} types.CreateListResponse
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists [post]
+func (h Handler) CreateList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.CreateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).CreateList(ctx, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary update a list
+// @ID update-list
+// @Produce json
+// @Param id path int true "List ID"
+// @Param X-Actor header string true "Owner"
+// @Param Body body types.UpdateListRequest true "List information that needs to be updated"
+// @Success 200 {object} types.List
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists/{id} [put]
+func (h Handler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateList(ctx, id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary delete a list
+// @ID delete-list
+// @Produce json
+// @Param id path int true "List ID"
+// @Param X-Actor header string true "Owner"
+// @Success 200 {object} types.DeleteListResponse
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists/{id} [delete]
+func (h Handler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteList(ctx, id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary add a book into a list
+// @Description The book is added at the end of the list unless a position is given, moving the books from there down by one.
+// @ID add-list-item
+// @Produce json
+// @Param id path int true "List ID"
+// @Param X-Actor header string true "Owner"
+// @Param Body body types.AddListItemRequest true "Book to add"
+// @Success 200 {object} types.List
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists/{id}/items [post]
+func (h Handler) AddListItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.AddListItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).AddListItem(ctx, id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary reorder the books of a list
+// @ID reorder-list-items
+// @Produce json
+// @Param id path int true "List ID"
+// @Param X-Actor header string true "Owner"
+// @Param Body body types.ReorderListItemsRequest true "Every book of the list in the new order"
+// @Success 200 {object} types.List
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists/{id}/items [put]
+func (h Handler) ReorderListItems(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.ReorderListItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).ReorderListItems(ctx, id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary remove a book from a list
+// @ID remove-list-item
+// @Produce json
+// @Param id path int true "List ID"
+// @Param book_id path int true "Book ID"
+// @Param X-Actor header string true "Owner"
+// @Success 200 {object} types.List
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /lists/{id}/items/{book_id} [delete]
+func (h Handler) RemoveListItem(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+	bookID, err := parseIntParam("book_id", r.PathValue("book_id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).RemoveListItem(ctx, id, bookID)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
@@ -0,0 +1,449 @@
+package service_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware/middlewaretest"
+)
+
+// serve serves a request through the routes of the lists service on behalf of the actor,
+// anonymously if empty.
+func serve(t *testing.T, s testutil.TestSuite, method string, url string, actor string, input any) *httptest.ResponseRecorder {
+	router := service.InitializeRoutes(http.NewServeMux(), *s.Handler)
+	return middlewaretest.Serve(t, router, method, url, actor, input)
+}
+
+func TestCreateList(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.CreateListResponse
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		actor      string
+		input      types.CreateListRequest
+		output     output
+	}{
+		{
+			name:  "anonymous owner",
+			input: types.CreateListRequest{Name: "To read"},
+			output: output{
+				code:   http.StatusUnauthorized,
+				errMsg: "owner is required: set the X-Actor header",
+			},
+		},
+		{
+			name:  "invalid visibility",
+			actor: "alice",
+			input: types.CreateListRequest{Name: "To read", Visibility: "friends"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Visibility":"It is invalid"}`,
+			},
+		},
+		{
+			name:  "invalid slug",
+			actor: "alice",
+			input: types.CreateListRequest{Name: "To read", Slug: "--"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid slug: --",
+			},
+		},
+		{
+			name:  "slug of another list",
+			actor: "alice",
+			input: types.CreateListRequest{Name: "To read"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "slug already exists: alice-to-read",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetListBySlug("alice-to-read").Return(&types.List{ID: 2}, nil)
+			},
+		},
+		{
+			name:  "slug added concurrently",
+			actor: "alice",
+			input: types.CreateListRequest{Name: "To read"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "slug already exists: alice-to-read",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetListBySlug("alice-to-read").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateList(gomock.Any()).Return(types.List{}, gorm.ErrDuplicatedKey)
+			},
+		},
+		{
+			name:  "success with the slug made from the owner and the name",
+			actor: "alice",
+			input: types.CreateListRequest{Name: "To Read!"},
+			output: output{
+				code: http.StatusCreated,
+				body: types.CreateListResponse{ID: 1, Slug: "alice-to-read"},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetListBySlug("alice-to-read").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateList(types.List{Owner: "alice", Name: "To Read!", Slug: "alice-to-read", Visibility: types.LIST_VISIBILITY_PRIVATE}).
+					Return(types.List{ID: 1, Slug: "alice-to-read"}, nil)
+			},
+		},
+		{
+			name:  "success with a given slug",
+			actor: "alice",
+			input: types.CreateListRequest{Name: "Course syllabus", Slug: "CS 101", Visibility: types.LIST_VISIBILITY_PUBLIC},
+			output: output{
+				code: http.StatusCreated,
+				body: types.CreateListResponse{ID: 1, Slug: "cs-101"},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetListBySlug("cs-101").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateList(types.List{Owner: "alice", Name: "Course syllabus", Slug: "cs-101", Visibility: types.LIST_VISIBILITY_PUBLIC}).
+					Return(types.List{ID: 1, Slug: "cs-101"}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodPost, "/api/v1/lists", tc.actor, tc.input)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.CreateListResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestGetList(t *testing.T) {
+	private := types.List{ID: 1, Owner: "alice", Name: "To read", Slug: "alice-to-read", Visibility: types.LIST_VISIBILITY_PRIVATE}
+	public := types.List{ID: 1, Owner: "alice", Name: "To read", Slug: "alice-to-read", Visibility: types.LIST_VISIBILITY_PUBLIC}
+	items := []types.ListItem{{BookID: 3, Position: 1, Title: "The Hobbit"}}
+
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		url        string
+		actor      string
+		code       int
+		errMsg     string
+	}{
+		{
+			name:   "private list of another user",
+			url:    "/api/v1/lists/1",
+			actor:  "bob",
+			code:   http.StatusNotFound,
+			errMsg: "list not found",
+			preProcess: func(s *testutil.TestSuite) {
+				list := private
+				s.Repository.EXPECT().GetList(1).Return(&list, nil)
+			},
+		},
+		{
+			name:  "private list of the owner",
+			url:   "/api/v1/lists/1",
+			actor: "alice",
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				list := private
+				s.Repository.EXPECT().GetList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+		{
+			name:   "private list shared by its slug",
+			url:    "/api/v1/shared-lists/alice-to-read",
+			code:   http.StatusNotFound,
+			errMsg: "list not found",
+			preProcess: func(s *testutil.TestSuite) {
+				list := private
+				s.Repository.EXPECT().GetListBySlug("alice-to-read").Return(&list, nil)
+			},
+		},
+		{
+			name: "public list shared by its slug",
+			url:  "/api/v1/shared-lists/alice-to-read",
+			code: http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				list := public
+				s.Repository.EXPECT().GetListBySlug("alice-to-read").Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodGet, tc.url, tc.actor, nil)
+
+			require.Equal(t, tc.code, resp.Code)
+
+			if tc.code == http.StatusOK {
+				var res types.List
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, items, res.Items)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestAddListItem(t *testing.T) {
+	list := types.List{ID: 1, Owner: "alice", Visibility: types.LIST_VISIBILITY_PUBLIC}
+	items := []types.ListItem{{ListID: 1, BookID: 3, Position: 1}, {ListID: 1, BookID: 4, Position: 2}}
+
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		actor      string
+		input      types.AddListItemRequest
+		code       int
+		errMsg     string
+	}{
+		{
+			name:   "book not found",
+			actor:  "alice",
+			input:  types.AddListItemRequest{BookID: 9},
+			code:   http.StatusBadRequest,
+			errMsg: "book not found: 9",
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(9).Return(gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:   "list of another user",
+			actor:  "bob",
+			input:  types.AddListItemRequest{BookID: 5},
+			code:   http.StatusForbidden,
+			errMsg: "list belongs to another user",
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(5).Return(nil)
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+			},
+		},
+		{
+			name:   "book already in list",
+			actor:  "alice",
+			input:  types.AddListItemRequest{BookID: 4},
+			code:   http.StatusConflict,
+			errMsg: "book already in list: 4",
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(4).Return(nil)
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+		{
+			name:   "position out of range",
+			actor:  "alice",
+			input:  types.AddListItemRequest{BookID: 5, Position: 4},
+			code:   http.StatusBadRequest,
+			errMsg: "position out of range: 4",
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(5).Return(nil)
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+		{
+			name:  "appended to the end of the list",
+			actor: "alice",
+			input: types.AddListItemRequest{BookID: 5},
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(5).Return(nil)
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+				s.Repository.EXPECT().InsertListItem(types.ListItem{ListID: 1, BookID: 5, Position: 3}).Return(nil)
+				s.Repository.EXPECT().GetList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+		{
+			name:  "inserted at a position",
+			actor: "alice",
+			input: types.AddListItemRequest{BookID: 5, Position: 1},
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(5).Return(nil)
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+				s.Repository.EXPECT().InsertListItem(types.ListItem{ListID: 1, BookID: 5, Position: 1}).Return(nil)
+				s.Repository.EXPECT().GetList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodPost, "/api/v1/lists/1/items", tc.actor, tc.input)
+
+			require.Equal(t, tc.code, resp.Code)
+
+			if tc.code != http.StatusOK {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestReorderListItems(t *testing.T) {
+	list := types.List{ID: 1, Owner: "alice"}
+	items := []types.ListItem{{ListID: 1, BookID: 3, Position: 1}, {ListID: 1, BookID: 4, Position: 2}}
+
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.ReorderListItemsRequest
+		code       int
+		errMsg     string
+	}{
+		{
+			name:   "missing book",
+			input:  types.ReorderListItemsRequest{BookIDs: []int{4}},
+			code:   http.StatusBadRequest,
+			errMsg: "book_ids must hold every book of the list once",
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+		{
+			name:   "repeated book",
+			input:  types.ReorderListItemsRequest{BookIDs: []int{4, 4}},
+			code:   http.StatusBadRequest,
+			errMsg: "book_ids must hold every book of the list once",
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+		{
+			name:  "success",
+			input: types.ReorderListItemsRequest{BookIDs: []int{4, 3}},
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				list := list
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+				s.Repository.EXPECT().SetListItemPositions(1, []int{4, 3}).Return(nil)
+				s.Repository.EXPECT().GetList(1).Return(&list, nil)
+				s.Repository.EXPECT().GetListItems(1).Return(items, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodPut, "/api/v1/lists/1/items", "alice", tc.input)
+
+			require.Equal(t, tc.code, resp.Code)
+
+			if tc.code != http.StatusOK {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestRemoveListItem(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+
+	list := types.List{ID: 1, Owner: "alice"}
+	s.ExpectTransaction()
+	s.Repository.EXPECT().LockList(1).Return(&list, nil)
+	s.Repository.EXPECT().GetListItems(1).Return([]types.ListItem{}, nil)
+	s.Repository.EXPECT().RemoveListItem(1, 3).Return(gorm.ErrRecordNotFound)
+
+	resp := serve(t, s, http.MethodDelete, "/api/v1/lists/1/items/3", "alice", nil)
+
+	require.Equal(t, http.StatusNotFound, resp.Code)
+
+	var res apierrors.Error
+	err := json.NewDecoder(resp.Body).Decode(&res)
+	require.NoError(t, err)
+
+	require.Equal(t, "book not in list: 3", res.Message)
+}
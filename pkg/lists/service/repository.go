@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in a lists service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new lists-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *Repository) Transaction(fn func(d types.DataProvider) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx, r.log)
+		return fn(&repo)
+	})
+}
+
+// LockBook locks the row of a book in the database against deletion until the end of the
+// transaction
+func (r *Repository) LockBook(bookID int) error {
+	var book booktypes.Book
+	return r.db.Clauses(clause.Locking{Strength: "SHARE"}).Select("id").First(&book, bookID).Error
+}
+
+// CreateList creates a new list in the database
+func (r *Repository) CreateList(list types.List) (types.List, error) {
+	tx := r.db.Create(&list)
+	return list, tx.Error
+}
+
+// UpdateList updates a list in the database
+func (r *Repository) UpdateList(list *types.List) error {
+	return r.db.Save(list).Error
+}
+
+// DeleteList deletes a list and its items from the database
+func (r *Repository) DeleteList(list *types.List) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("list_id = ?", list.ID).Delete(&types.ListItem{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(list).Error
+	})
+}
+
+// GetList retrieves a list from the database
+func (r *Repository) GetList(id int) (*types.List, error) {
+	var list types.List
+	if result := r.db.First(&list, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &list, nil
+}
+
+// LockList retrieves a list from the database and locks its row until the end of the transaction
+func (r *Repository) LockList(id int) (*types.List, error) {
+	var list types.List
+	if result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&list, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &list, nil
+}
+
+// GetListBySlug retrieves a list from the database by its slug
+func (r *Repository) GetListBySlug(slug string) (*types.List, error) {
+	var list types.List
+	if result := r.db.Where("slug = ?", slug).First(&list); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &list, nil
+}
+
+// GetLists retrieves the lists of an owner from the database
+func (r *Repository) GetLists(owner string, page int, limit int) (*db.Pagination, []types.List, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id asc",
+	}
+
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx := r.db.Where("owner = ?", owner).Session(&gorm.Session{})
+
+	var lists []types.List
+	if result := tx.Scopes(db.Paginate(&lists, &p, tx)).Find(&lists); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, lists, nil
+}
+
+// GetListItems retrieves the items of a list with the title and the author of their books from
+// the database, in order
+func (r *Repository) GetListItems(listID int) ([]types.ListItem, error) {
+	var items []types.ListItem
+	err := r.db.Model(&types.ListItem{}).
+		Select("reading_list_items.*, books.title, books.author").
+		Joins("JOIN books ON books.id = reading_list_items.book_id").
+		Where("reading_list_items.list_id = ?", listID).
+		Order("reading_list_items.position").
+		Find(&items).Error
+	return items, err
+}
+
+// InsertListItem inserts an item into a list in the database at its position
+func (r *Repository) InsertListItem(item types.ListItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&types.ListItem{}).
+			Where("list_id = ? AND position >= ?", item.ListID, item.Position).
+			UpdateColumn("position", gorm.Expr("position + 1")).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&item).Error
+	})
+}
+
+// RemoveListItem removes the item of a book from a list in the database
+func (r *Repository) RemoveListItem(listID int, bookID int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var item types.ListItem
+		if err := tx.Where("list_id = ? AND book_id = ?", listID, bookID).First(&item).Error; err != nil {
+			return err
+		}
+
+		return removeItem(tx, item)
+	})
+}
+
+// SetListItemPositions sets the positions of the items of a list in the database to the order
+// of the books
+func (r *Repository) SetListItemPositions(listID int, bookIDs []int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, bookID := range bookIDs {
+			if err := tx.Model(&types.ListItem{}).
+				Where("list_id = ? AND book_id = ?", listID, bookID).
+				UpdateColumn("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// removeItem deletes an item and moves the items after it in its list up by one
+func removeItem(tx *gorm.DB, item types.ListItem) error {
+	if err := tx.Where("list_id = ? AND book_id = ?", item.ListID, item.BookID).Delete(&types.ListItem{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&types.ListItem{}).
+		Where("list_id = ? AND position > ?", item.ListID, item.Position).
+		UpdateColumn("position", gorm.Expr("position - 1")).Error
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+)
+
+// Service is the service layer for the reading lists
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new lists service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// CreateList creates a list owned by the caller
+func (s *Service) CreateList(ctx context.Context, req types.CreateListRequest) (types.CreateListResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.CreateListResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return types.CreateListResponse{}, err
+	}
+
+	slug := req.Slug
+	if slug == "" {
+		slug = owner + " " + req.Name
+	}
+	list := types.List{
+		Owner:      owner,
+		Name:       req.Name,
+		Visibility: req.Visibility,
+	}
+	if list.Visibility == "" {
+		list.Visibility = types.LIST_VISIBILITY_PRIVATE
+	}
+	if list.Slug, err = s.checkSlug(slug, 0); err != nil {
+		return types.CreateListResponse{}, err
+	}
+
+	created, err := s.dataProvider.CreateList(list)
+	if err != nil {
+		return types.CreateListResponse{}, slugConflict(err, list.Slug)
+	}
+
+	return types.CreateListResponse{
+		ID:   created.ID,
+		Slug: created.Slug,
+	}, nil
+}
+
+// UpdateList updates a list of the caller
+func (s *Service) UpdateList(ctx context.Context, id int, req types.UpdateListRequest) (*types.List, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return nil, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.dataProvider.GetList(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := checkOwner(list, owner); err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" {
+		list.Name = req.Name
+	}
+	if req.Slug != "" {
+		if list.Slug, err = s.checkSlug(req.Slug, list.ID); err != nil {
+			return nil, err
+		}
+	}
+	if req.Visibility != "" {
+		list.Visibility = req.Visibility
+	}
+	if err := s.dataProvider.UpdateList(list); err != nil {
+		return nil, slugConflict(err, list.Slug)
+	}
+
+	return list, nil
+}
+
+// DeleteList deletes a list of the caller
+func (s *Service) DeleteList(ctx context.Context, id int) (types.DeleteListResponse, error) {
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return types.DeleteListResponse{}, err
+	}
+
+	list, err := s.dataProvider.GetList(id)
+	if err != nil {
+		return types.DeleteListResponse{}, notFound(err)
+	}
+	if err := checkOwner(list, owner); err != nil {
+		return types.DeleteListResponse{}, err
+	}
+
+	if err := s.dataProvider.DeleteList(list); err != nil {
+		return types.DeleteListResponse{}, err
+	}
+
+	return types.DeleteListResponse{}, nil
+}
+
+// GetLists returns the lists of the caller
+func (s *Service) GetLists(ctx context.Context, page int, limit int) (types.GetListsResponse, error) {
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return types.GetListsResponse{}, err
+	}
+
+	pagination, lists, err := s.dataProvider.GetLists(owner, page, limit)
+	if err != nil {
+		return types.GetListsResponse{}, err
+	}
+
+	return types.GetListsResponse{
+		Lists:      lists,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetList returns a public list or a list of the caller with its items
+func (s *Service) GetList(ctx context.Context, id int) (*types.List, error) {
+	list, err := s.dataProvider.GetList(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if !list.IsVisibleTo(middleware.GetActor(ctx)) {
+		return nil, apierror.NewNotFoundError("list not found")
+	}
+
+	return s.withItems(s.dataProvider, list)
+}
+
+// GetSharedList returns a public list from its slug with its items
+func (s *Service) GetSharedList(slug string) (*types.List, error) {
+	list, err := s.dataProvider.GetListBySlug(slug)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if list.Visibility != types.LIST_VISIBILITY_PUBLIC {
+		return nil, apierror.NewNotFoundError("list not found")
+	}
+
+	return s.withItems(s.dataProvider, list)
+}
+
+// AddListItem adds a book into a list of the caller
+func (s *Service) AddListItem(ctx context.Context, id int, req types.AddListItemRequest) (*types.List, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return nil, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list *types.List
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		// the book is locked before the list, as when it is deleted.
+		if err := d.LockBook(req.BookID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("book not found: %d", req.BookID))
+		} else if err != nil {
+			return err
+		}
+
+		items, err := lockItems(d, id, owner)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if item.BookID == req.BookID {
+				return apierror.ErrConflict.WithMessage(fmt.Sprintf("book already in list: %d", req.BookID))
+			}
+		}
+
+		position := req.Position
+		if position == 0 {
+			position = len(items) + 1
+		} else if position > len(items)+1 {
+			return apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("position out of range: %d", position))
+		}
+		if err := d.InsertListItem(types.ListItem{ListID: id, BookID: req.BookID, Position: position}); err != nil {
+			return err
+		}
+
+		list, err = s.getListWithItems(d, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// RemoveListItem removes a book from a list of the caller
+func (s *Service) RemoveListItem(ctx context.Context, id int, bookID int) (*types.List, error) {
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list *types.List
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		if _, err := lockItems(d, id, owner); err != nil {
+			return err
+		}
+
+		if err := d.RemoveListItem(id, bookID); errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NewNotFoundError(fmt.Sprintf("book not in list: %d", bookID))
+		} else if err != nil {
+			return err
+		}
+
+		list, err = s.getListWithItems(d, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ReorderListItems puts the books of a list of the caller in a new order
+func (s *Service) ReorderListItems(ctx context.Context, id int, req types.ReorderListItemsRequest) (*types.List, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return nil, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	owner, err := getOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var list *types.List
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		items, err := lockItems(d, id, owner)
+		if err != nil {
+			return err
+		}
+		if !isPermutation(items, req.BookIDs) {
+			return apierror.ErrInvalidInput.WithMessage("book_ids must hold every book of the list once")
+		}
+
+		if err := d.SetListItemPositions(id, req.BookIDs); err != nil {
+			return err
+		}
+
+		list, err = s.getListWithItems(d, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// checkSlug returns the slug made from the text, which must not be the slug of another list
+// than the given one
+func (s *Service) checkSlug(text string, id int) (string, error) {
+	slug := types.Slugify(text)
+	if slug == "" {
+		return "", apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid slug: %s", text))
+	}
+
+	existing, err := s.dataProvider.GetListBySlug(slug)
+	if err == nil && existing.ID != id {
+		return "", apierror.ErrConflict.WithMessage(fmt.Sprintf("slug already exists: %s", slug))
+	} else if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	return slug, nil
+}
+
+// slugConflict returns the conflict on the slug if err is a duplicated key, as when another list
+// with the slug is added after the slug was checked. Otherwise it returns err.
+func slugConflict(err error, slug string) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return apierror.ErrConflict.WithMessage(fmt.Sprintf("slug already exists: %s", slug))
+	}
+	return err
+}
+
+// getListWithItems returns a list with its items
+func (s *Service) getListWithItems(d types.DataProvider, id int) (*types.List, error) {
+	list, err := d.GetList(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.withItems(d, list)
+}
+
+// withItems loads the items of a list
+func (s *Service) withItems(d types.DataProvider, list *types.List) (*types.List, error) {
+	items, err := d.GetListItems(list.ID)
+	if err != nil {
+		return nil, err
+	}
+	list.Items = items
+
+	return list, nil
+}
+
+// getOwner returns the identity of the caller, who must not be anonymous
+func getOwner(ctx context.Context) (string, error) {
+	owner := middleware.GetActor(ctx)
+	if owner == middleware.ActorAnonymous {
+		return "", apierror.ErrUnauthorized.WithMessage(fmt.Sprintf("owner is required: set the %s header", middleware.HeaderActor))
+	}
+
+	return owner, nil
+}
+
+// checkOwner checks that the list is owned by the caller. A private list of another user is not
+// found rather than forbidden, so its existence is not disclosed.
+func checkOwner(list *types.List, owner string) error {
+	if !list.IsVisibleTo(owner) {
+		return apierror.NewNotFoundError("list not found")
+	} else if list.Owner != owner {
+		return apierror.ErrForbidden.WithMessage("list belongs to another user")
+	}
+
+	return nil
+}
+
+// lockItems locks a list of the caller and returns its items
+func lockItems(d types.DataProvider, id int, owner string) ([]types.ListItem, error) {
+	list, err := d.LockList(id)
+	if err != nil {
+		return nil, notFound(err)
+	}
+	if err := checkOwner(list, owner); err != nil {
+		return nil, err
+	}
+
+	return d.GetListItems(id)
+}
+
+// notFound converts a missing list into a not found error
+func notFound(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apierror.NewNotFoundError("list not found")
+	}
+
+	return err
+}
+
+// isPermutation reports whether the books hold every book of the items once
+func isPermutation(items []types.ListItem, bookIDs []int) bool {
+	if len(items) != len(bookIDs) {
+		return false
+	}
+
+	remaining := make(map[int]bool, len(items))
+	for _, item := range items {
+		remaining[item.BookID] = true
+	}
+	for _, bookID := range bookIDs {
+		if !remaining[bookID] {
+			return false
+		}
+		delete(remaining, bookID)
+	}
+
+	return true
+}
@@ -0,0 +1,245 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/lists/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/lists/testutil/mock_data_provider.go --source ./pkg/lists/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// CreateList mocks base method.
+func (m *MockDataProvider) CreateList(list types.List) (types.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateList", list)
+	ret0, _ := ret[0].(types.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateList indicates an expected call of CreateList.
+func (mr *MockDataProviderMockRecorder) CreateList(list any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateList", reflect.TypeOf((*MockDataProvider)(nil).CreateList), list)
+}
+
+// DeleteList mocks base method.
+func (m *MockDataProvider) DeleteList(list *types.List) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteList", list)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteList indicates an expected call of DeleteList.
+func (mr *MockDataProviderMockRecorder) DeleteList(list any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteList", reflect.TypeOf((*MockDataProvider)(nil).DeleteList), list)
+}
+
+// GetList mocks base method.
+func (m *MockDataProvider) GetList(id int) (*types.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetList", id)
+	ret0, _ := ret[0].(*types.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetList indicates an expected call of GetList.
+func (mr *MockDataProviderMockRecorder) GetList(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetList", reflect.TypeOf((*MockDataProvider)(nil).GetList), id)
+}
+
+// GetListBySlug mocks base method.
+func (m *MockDataProvider) GetListBySlug(slug string) (*types.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListBySlug", slug)
+	ret0, _ := ret[0].(*types.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListBySlug indicates an expected call of GetListBySlug.
+func (mr *MockDataProviderMockRecorder) GetListBySlug(slug any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListBySlug", reflect.TypeOf((*MockDataProvider)(nil).GetListBySlug), slug)
+}
+
+// GetListItems mocks base method.
+func (m *MockDataProvider) GetListItems(listID int) ([]types.ListItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetListItems", listID)
+	ret0, _ := ret[0].([]types.ListItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetListItems indicates an expected call of GetListItems.
+func (mr *MockDataProviderMockRecorder) GetListItems(listID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetListItems", reflect.TypeOf((*MockDataProvider)(nil).GetListItems), listID)
+}
+
+// GetLists mocks base method.
+func (m *MockDataProvider) GetLists(owner string, page, limit int) (*db.Pagination, []types.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLists", owner, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.List)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLists indicates an expected call of GetLists.
+func (mr *MockDataProviderMockRecorder) GetLists(owner, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLists", reflect.TypeOf((*MockDataProvider)(nil).GetLists), owner, page, limit)
+}
+
+// InsertListItem mocks base method.
+func (m *MockDataProvider) InsertListItem(item types.ListItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertListItem", item)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertListItem indicates an expected call of InsertListItem.
+func (mr *MockDataProviderMockRecorder) InsertListItem(item any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertListItem", reflect.TypeOf((*MockDataProvider)(nil).InsertListItem), item)
+}
+
+// LockBook mocks base method.
+func (m *MockDataProvider) LockBook(bookID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBook", bookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockBook indicates an expected call of LockBook.
+func (mr *MockDataProviderMockRecorder) LockBook(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBook", reflect.TypeOf((*MockDataProvider)(nil).LockBook), bookID)
+}
+
+// LockList mocks base method.
+func (m *MockDataProvider) LockList(id int) (*types.List, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockList", id)
+	ret0, _ := ret[0].(*types.List)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockList indicates an expected call of LockList.
+func (mr *MockDataProviderMockRecorder) LockList(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockList", reflect.TypeOf((*MockDataProvider)(nil).LockList), id)
+}
+
+// RemoveListItem mocks base method.
+func (m *MockDataProvider) RemoveListItem(listID, bookID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveListItem", listID, bookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveListItem indicates an expected call of RemoveListItem.
+func (mr *MockDataProviderMockRecorder) RemoveListItem(listID, bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveListItem", reflect.TypeOf((*MockDataProvider)(nil).RemoveListItem), listID, bookID)
+}
+
+// SetListItemPositions mocks base method.
+func (m *MockDataProvider) SetListItemPositions(listID int, bookIDs []int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetListItemPositions", listID, bookIDs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetListItemPositions indicates an expected call of SetListItemPositions.
+func (mr *MockDataProviderMockRecorder) SetListItemPositions(listID, bookIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetListItemPositions", reflect.TypeOf((*MockDataProvider)(nil).SetListItemPositions), listID, bookIDs)
+}
+
+// Transaction mocks base method.
+func (m *MockDataProvider) Transaction(fn func(types.DataProvider) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataProviderMockRecorder) Transaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataProvider)(nil).Transaction), fn)
+}
+
+// UpdateList mocks base method.
+func (m *MockDataProvider) UpdateList(list *types.List) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateList", list)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateList indicates an expected call of UpdateList.
+func (mr *MockDataProviderMockRecorder) UpdateList(list any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateList", reflect.TypeOf((*MockDataProvider)(nil).UpdateList), list)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
@@ -0,0 +1,42 @@
+package testutil
+
+import (
+	"testing"
+
+	gomock "go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/lists/types"
+)
+
+type TestSuite struct {
+	Handler    *service.Handler
+	Service    *service.Service
+	Repository *MockDataProvider
+	Logger     *zap.Logger
+}
+
+func NewTestSuite(t *testing.T) TestSuite {
+	ctrl := gomock.NewController(t)
+
+	repo := NewMockDataProvider(ctrl)
+	repo.EXPECT().WithContext(gomock.Any()).Return(repo).AnyTimes()
+	log := zap.NewNop()
+	serv := service.NewService(repo, log)
+	handler := service.NewHandler(&serv, log)
+
+	return TestSuite{
+		Handler:    &handler,
+		Service:    &serv,
+		Repository: repo,
+		Logger:     log,
+	}
+}
+
+// ExpectTransaction expects a transaction that runs its function against the mock repository.
+func (s TestSuite) ExpectTransaction() *gomock.Call {
+	return s.Repository.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(types.DataProvider) error) error {
+		return fn(s.Repository)
+	})
+}
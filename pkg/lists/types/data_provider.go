@@ -0,0 +1,44 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for a lists service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	// Transaction runs fn with a data provider bound to a single database transaction.
+	// The transaction is committed if fn returns nil, otherwise it is rolled back.
+	Transaction(fn func(d DataProvider) error) error
+
+	// LockBook locks the row of a book against deletion until the end of the transaction, so a
+	// book is not added into a list while it is deleted. It returns gorm.ErrRecordNotFound if
+	// the book does not exist.
+	LockBook(bookID int) error
+
+	CreateList(list List) (List, error)
+	UpdateList(list *List) error
+	// DeleteList deletes a list and its items.
+	DeleteList(list *List) error
+	GetList(id int) (*List, error)
+	// LockList retrieves a list and locks its row until the end of the transaction, so its
+	// items are changed by one request at a time.
+	LockList(id int) (*List, error)
+	GetListBySlug(slug string) (*List, error)
+	GetLists(owner string, page int, limit int) (*db.Pagination, []List, error)
+
+	// GetListItems lists the items of a list in order.
+	GetListItems(listID int) ([]ListItem, error)
+	// InsertListItem inserts an item at its position, moving the items from there down by one.
+	InsertListItem(item ListItem) error
+	// RemoveListItem removes the item of a book, moving the items after it up by one. It
+	// returns gorm.ErrRecordNotFound if the book is not in the list.
+	RemoveListItem(listID int, bookID int) error
+	// SetListItemPositions sets the positions of the items to the order of the books.
+	SetListItemPositions(listID int, bookIDs []int) error
+}
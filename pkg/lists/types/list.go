@@ -0,0 +1,77 @@
+package types
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Visibility string
+
+const (
+	// LIST_VISIBILITY_PRIVATE is a list seen by its owner only.
+	LIST_VISIBILITY_PRIVATE Visibility = "private"
+	// LIST_VISIBILITY_PUBLIC is a list seen by anyone, including by its slug.
+	LIST_VISIBILITY_PUBLIC Visibility = "public"
+)
+
+// maxSlugLength is the maximum number of characters of a slug.
+const maxSlugLength = 100
+
+// List is the model for a named, ordered list of books kept by a user, e.g. "To read".
+type List struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_reading_lists_tenant_slug"`
+	// Owner is the identity of the caller who created the list.
+	Owner      string     `json:"owner" gorm:"not null;index" example:"alice"`
+	Name       string     `json:"name" gorm:"not null" example:"To read"`
+	Slug       string     `json:"slug" gorm:"not null;uniqueIndex:idx_reading_lists_tenant_slug" example:"alice-to-read"`
+	Visibility Visibility `json:"visibility" gorm:"not null;default:private" example:"public"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Items are the books of the list in order; they are only loaded for a single list.
+	Items []ListItem `json:"items,omitempty" gorm:"-"`
+}
+
+// TableName overrides the table name used by gorm.
+func (List) TableName() string {
+	return "reading_lists"
+}
+
+// IsVisibleTo reports whether the list can be seen by the caller.
+func (l List) IsVisibleTo(caller string) bool {
+	return l.Visibility == LIST_VISIBILITY_PUBLIC || l.Owner == caller
+}
+
+// ListItem is a book in a list at its position, starting from 1.
+type ListItem struct {
+	ListID   int    `json:"-" gorm:"primaryKey"`
+	BookID   int    `json:"book_id" gorm:"primaryKey;index" example:"1"`
+	Position int    `json:"position" gorm:"not null" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	// Title and Author are read from the book.
+	Title   string    `json:"title" gorm:"->;-:migration" example:"The Hobbit"`
+	Author  string    `json:"author" gorm:"->;-:migration" example:"J. R. R. Tolkien"`
+	AddedAt time.Time `json:"added_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the table name used by gorm.
+func (ListItem) TableName() string {
+	return "reading_list_items"
+}
+
+// Slugify returns the text in lower case with its letters and digits only, words separated by
+// a single hyphen, so it can be used in a URL.
+func Slugify(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	slug := []rune(strings.Join(words, "-"))
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+
+	return strings.Trim(string(slug), "-")
+}
@@ -0,0 +1,46 @@
+package types
+
+import "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+
+// CreateListRequest is the request for creating a list
+type CreateListRequest struct {
+	Name string `json:"name" example:"To read" validate:"required,max=255"`
+	// Slug is made from the owner and the name of the list unless given.
+	Slug       string     `json:"slug" example:"alice-to-read" validate:"max=255"`
+	Visibility Visibility `json:"visibility" example:"public" validate:"omitempty,oneof=private public"`
+}
+
+// CreateListResponse is the response for creating a list
+type CreateListResponse struct {
+	ID   int    `json:"id" example:"1"`
+	Slug string `json:"slug" example:"alice-to-read"`
+}
+
+// UpdateListRequest is the request for updating a list
+type UpdateListRequest struct {
+	Name       string     `json:"name" example:"Course syllabus" validate:"max=255"`
+	Slug       string     `json:"slug" example:"alice-course-syllabus" validate:"max=255"`
+	Visibility Visibility `json:"visibility" example:"private" validate:"omitempty,oneof=private public"`
+}
+
+// AddListItemRequest is the request for adding a book into a list
+type AddListItemRequest struct {
+	BookID int `json:"book_id" example:"1" validate:"required"`
+	// Position is the position of the book in the list, the end of the list unless given.
+	Position int `json:"position" example:"1" validate:"omitempty,min=1"`
+}
+
+// ReorderListItemsRequest is the request for reordering the books of a list
+type ReorderListItemsRequest struct {
+	// BookIDs are every book of the list in their new order.
+	BookIDs []int `json:"book_ids" example:"3,1,2" validate:"required"`
+}
+
+// GetListsResponse is the response for getting the lists of a user
+type GetListsResponse struct {
+	Lists      []List         `json:"lists"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// DeleteListResponse is the response for deleting a list
+type DeleteListResponse struct{}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+)
+
+// GetAccount returns the balance of the fines of a member with its entries, the latest first
+func (s *Service) GetAccount(id int, page int, limit int) (types.GetAccountResponse, error) {
+	if _, err := s.GetMember(id); err != nil {
+		return types.GetAccountResponse{}, err
+	}
+
+	account, err := s.account(s.dataProvider, id)
+	if err != nil {
+		return types.GetAccountResponse{}, err
+	}
+
+	pagination, entries, err := s.dataProvider.GetAccountEntries(id, page, limit)
+	if err != nil {
+		return types.GetAccountResponse{}, err
+	}
+
+	return types.GetAccountResponse{
+		Account:    account,
+		Entries:    entries,
+		Pagination: pagination,
+	}, nil
+}
+
+// Pay records a payment of a member, up to their balance, and returns their account
+func (s *Service) Pay(id int, req types.PayRequest) (types.Account, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Account{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	var account types.Account
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		// the member is locked so concurrent payments cannot pay more than the balance.
+		_, err := d.LockMember(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NewNotFoundError("member not found")
+		} else if err != nil {
+			return err
+		}
+
+		balance, _, err := s.balance(d, id, time.Now())
+		if err != nil {
+			return err
+		}
+		if req.Amount > balance {
+			return apierror.ErrConflict.WithMessage(fmt.Sprintf("payment exceeds balance: %d", balance))
+		}
+
+		if _, err := d.CreateAccountEntry(types.AccountEntry{
+			MemberID: id,
+			Kind:     types.ENTRY_KIND_PAYMENT,
+			Amount:   -req.Amount,
+		}); err != nil {
+			return err
+		}
+
+		account, err = s.account(d, id)
+		return err
+	})
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	return account, nil
+}
+
+// account returns the account of a member
+func (s *Service) account(d types.DataProvider, id int) (types.Account, error) {
+	balance, accrued, err := s.balance(d, id, time.Now())
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	return types.Account{
+		MemberID: id,
+		Balance:  balance,
+		Accrued:  accrued,
+		Currency: s.conf.Fines.Currency,
+		Limit:    s.conf.Fines.Limit,
+		Blocked:  balance > s.conf.Fines.Limit,
+	}, nil
+}
+
+// balance returns the balance of a member at the given time with the part of it accrued by their
+// loans past due: the fines of these loans are only charged when their copies are returned, but
+// they count as soon as they accrue.
+func (s *Service) balance(d types.DataProvider, id int, now time.Time) (int64, int64, error) {
+	balance, err := d.GetBalance(id)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	loans, err := d.GetDueLoans(id, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	var accrued int64
+	for _, loan := range loans {
+		accrued += s.lateFine(loan.DueAt, now)
+	}
+
+	return balance + accrued, accrued, nil
+}
+
+// lateFine returns the fine of a copy returned after its due date: the daily rate for every day,
+// or part of a day, late past the grace period, up to the maximum per loan.
+func (s *Service) lateFine(dueAt time.Time, returnedAt time.Time) int64 {
+	late := returnedAt.Sub(dueAt) - s.conf.Fines.GracePeriod
+	if late <= 0 {
+		return 0
+	}
+
+	days := int64((late + 24*time.Hour - 1) / (24 * time.Hour))
+	fine := days * s.conf.Fines.DailyRate
+	if max := s.conf.Fines.MaxPerLoan; max > 0 && fine > max {
+		fine = max
+	}
+	return fine
+}
@@ -0,0 +1,639 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the loans service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the loans service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/members", h.GetMembers)
+	mux.HandleFunc("POST /api/v1/members", h.AddMember)
+	mux.HandleFunc("GET /api/v1/members/{id}", h.GetMember)
+	mux.HandleFunc("PUT /api/v1/members/{id}", h.UpdateMember)
+	mux.HandleFunc("GET /api/v1/members/{id}/loans", h.GetMemberLoans)
+	mux.HandleFunc("GET /api/v1/members/{id}/holds", h.GetMemberHolds)
+	mux.HandleFunc("GET /api/v1/members/{id}/account", h.GetAccount)
+	mux.HandleFunc("POST /api/v1/members/{id}/payments", h.Pay)
+	mux.HandleFunc("GET /api/v1/loans", h.GetLoans)
+	mux.HandleFunc("POST /api/v1/loans", h.Checkout)
+	mux.HandleFunc("GET /api/v1/loans/{id}", h.GetLoan)
+	mux.HandleFunc("POST /api/v1/loans/{id}/return", h.Return)
+	mux.HandleFunc("POST /api/v1/loans/{id}/renew", h.Renew)
+	mux.HandleFunc("GET /api/v1/holds", h.GetHolds)
+	mux.HandleFunc("POST /api/v1/holds", h.PlaceHold)
+	mux.HandleFunc("GET /api/v1/holds/{id}", h.GetHold)
+	mux.HandleFunc("POST /api/v1/holds/{id}/cancel", h.CancelHold)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// parsePageParams parses the page and limit query parameters
+func parsePageParams(r *http.Request) (page int, limit int, err error) {
+	if page, err = parseIntParam("page", r.URL.Query().Get("page")); err != nil {
+		return 0, 0, err
+	}
+	if limit, err = parseIntParam("limit", r.URL.Query().Get("limit")); err != nil {
+		return 0, 0, err
+	}
+
+	return page, limit, nil
+}
+
+// @Summary get a list of members
+// @ID get-members
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param q query string false "part of the member name or email"
+// @Produce json
+// @Success 200 {object} types.GetMembersResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members [get]
+func (h Handler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetMembers(r.URL.Query().Get("q"), page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a member
+// @ID get-member
+// @Param id path int true "Member ID"
+// @Produce json
+// @Success 200 {object} types.Member
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members/{id} [get]
+func (h Handler) GetMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetMember(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary add a new member
+// @Description The email is unique and stored in lower case. The tier is one of the configured tiers, the default one unless given.
+// @ID add-member
+// @Produce json
+// @Param Body body types.AddMemberRequest true "Member information"
+// @Success 201 {object} types.AddMemberResponse
+// @Failure 400 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members [post]
+func (h Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).AddMember(req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary update a member
+// @Description Members are never deleted; a suspended member cannot borrow copies or place holds.
+// @ID update-member
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param Body body types.UpdateMemberRequest true "Member information that needs to be updated"
+// @Success 200 {object} types.Member
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members/{id} [put]
+func (h Handler) UpdateMember(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateMember(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get the loans of a member
+// @ID get-member-loans
+// @Param id path int true "Member ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param status query string false "status of the loans" enums(active,overdue,returned)
+// @Produce json
+// @Success 200 {object} types.GetLoansResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members/{id}/loans [get]
+func (h Handler) GetMemberLoans(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	status := types.LoanStatus(r.URL.Query().Get("status"))
+	result, err := h.serv.WithContext(ctx).GetMemberLoans(id, status, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a list of loans
+// @ID get-loans
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param status query string false "status of the loans" enums(active,overdue,returned)
+// @Param member_id query int false "member who borrowed the copies"
+// @Param book_id query int false "book of the borrowed copies"
+// @Produce json
+// @Success 200 {object} types.GetLoansResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /loans [get]
+func (h Handler) GetLoans(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	filter := types.LoanFilter{
+		Status: types.LoanStatus(r.URL.Query().Get("status")),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"member_id", &filter.MemberID},
+		{"book_id", &filter.BookID},
+	} {
+		if value := r.URL.Query().Get(p.name); value != "" {
+			if *p.dst, err = parseIntParam(p.name, value); err != nil {
+				response.WriteError(ctx, w, err, h.log)
+				return
+			}
+		}
+	}
+
+	result, err := h.serv.WithContext(ctx).GetLoans(filter, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a loan
+// @ID get-loan
+// @Param id path int true "Loan ID"
+// @Produce json
+// @Success 200 {object} types.Loan
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /loans/{id} [get]
+func (h Handler) GetLoan(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetLoan(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary check out a copy of a book
+// @Description The copy is given by its id or its barcode and must be available, or on hold for the member. The loan is due at the end of the loan period.
+// @ID checkout
+// @Produce json
+// @Param Body body types.CheckoutRequest true "Member and copy"
+// @Success 201 {object} types.Loan
+// @Failure 400 {object} errors.Error
+// @Failure 402 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /loans [post]
+func (h Handler) Checkout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.CheckoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).Checkout(req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary return a borrowed copy
+// @ID return-loan
+// @Param id path int true "Loan ID"
+// @Produce json
+// @Success 200 {object} types.Loan
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /loans/{id}/return [post]
+func (h Handler) Return(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).Return(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary renew a loan
+// @Description The loan is due at the end of a new loan period from now. Overdue loans cannot be renewed.
+// @ID renew-loan
+// @Param id path int true "Loan ID"
+// @Produce json
+// @Success 200 {object} types.Loan
+// @Failure 400 {object} errors.Error
+// @Failure 402 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /loans/{id}/renew [post]
+func (h Handler) Renew(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).Renew(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get the holds of a member
+// @ID get-member-holds
+// @Param id path int true "Member ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param status query string false "status of the holds" enums(waiting,ready,fulfilled,cancelled,expired)
+// @Produce json
+// @Success 200 {object} types.GetHoldsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members/{id}/holds [get]
+func (h Handler) GetMemberHolds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	status := types.HoldStatus(r.URL.Query().Get("status"))
+	result, err := h.serv.WithContext(ctx).GetMemberHolds(id, status, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a list of holds
+// @Description The holds are listed in the order they were placed; waiting holds hold their position in the queue of their book.
+// @ID get-holds
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param status query string false "status of the holds" enums(waiting,ready,fulfilled,cancelled,expired)
+// @Param member_id query int false "member who placed the holds"
+// @Param book_id query int false "book held"
+// @Produce json
+// @Success 200 {object} types.GetHoldsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /holds [get]
+func (h Handler) GetHolds(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	filter := types.HoldFilter{
+		Status: types.HoldStatus(r.URL.Query().Get("status")),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"member_id", &filter.MemberID},
+		{"book_id", &filter.BookID},
+	} {
+		if value := r.URL.Query().Get(p.name); value != "" {
+			if *p.dst, err = parseIntParam(p.name, value); err != nil {
+				response.WriteError(ctx, w, err, h.log)
+				return
+			}
+		}
+	}
+
+	result, err := h.serv.WithContext(ctx).GetHolds(filter, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a hold
+// @ID get-hold
+// @Param id path int true "Hold ID"
+// @Produce json
+// @Success 200 {object} types.Hold
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /holds/{id} [get]
+func (h Handler) GetHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetHold(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary place a hold on a book
+// @Description A hold is placed on a book without available copies and waits in its queue. A returned copy is kept for the first waiting hold until the end of the pickup period.
+// @ID place-hold
+// @Produce json
+// @Param Body body types.PlaceHoldRequest true "Member and book"
+// @Success 201 {object} types.Hold
+// @Failure 400 {object} errors.Error
+// @Failure 402 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /holds [post]
+func (h Handler) PlaceHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.PlaceHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).PlaceHold(req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary cancel a hold
+// @Description The copy kept for a ready hold is passed on to the next hold of the book.
+// @ID cancel-hold
+// @Param id path int true "Hold ID"
+// @Produce json
+// @Success 200 {object} types.Hold
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /holds/{id}/cancel [post]
+func (h Handler) CancelHold(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).CancelHold(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get the account of a member
+// @Description The balance is the sum of the fines of late returns less the payments. A member whose balance is over the limit is blocked from borrowing.
+// @ID get-member-account
+// @Param id path int true "Member ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Produce json
+// @Success 200 {object} types.GetAccountResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members/{id}/account [get]
+func (h Handler) GetAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, limit, err := parsePageParams(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetAccount(id, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary pay fines of a member
+// @Description The amount is in the minor unit of the currency of the fines and must not exceed the balance.
+// @ID pay-member-fines
+// @Produce json
+// @Param id path int true "Member ID"
+// @Param Body body types.PayRequest true "Payment"
+// @Success 201 {object} types.Account
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /members/{id}/payments [post]
+func (h Handler) Pay(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.PayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).Pay(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
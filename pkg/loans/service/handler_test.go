@@ -0,0 +1,1045 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+)
+
+func TestAddMember(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.AddMemberResponse
+		errMsg string
+		errID  int
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.AddMemberRequest
+		output     output
+	}{
+		{
+			name:  "invalid email",
+			input: types.AddMemberRequest{Name: "Jane Doe", Email: "jane"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Email":"It is not a valid email address"}`,
+			},
+		},
+		{
+			name:  "unknown tier",
+			input: types.AddMemberRequest{Name: "Jane Doe", Email: "jane@example.com", Tier: "gold"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "unknown tier: gold",
+			},
+		},
+		{
+			name:  "email of another member",
+			input: types.AddMemberRequest{Name: "Jane Doe", Email: "Jane@Example.com"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "member already exists: 4",
+				errID:  4,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetMemberByEmail("jane@example.com").Return(&types.Member{ID: 4}, nil)
+			},
+		},
+		{
+			name:  "email added concurrently",
+			input: types.AddMemberRequest{Name: "Jane Doe", Email: "Jane@Example.com"},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "member already exists: 5",
+				errID:  5,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				gomock.InOrder(
+					s.Repository.EXPECT().GetMemberByEmail("jane@example.com").Return(nil, gorm.ErrRecordNotFound),
+					s.Repository.EXPECT().CreateMember(gomock.Any()).Return(types.Member{}, gorm.ErrDuplicatedKey),
+					s.Repository.EXPECT().GetMemberByEmail("jane@example.com").Return(&types.Member{ID: 5}, nil),
+				)
+			},
+		},
+		{
+			name:  "success",
+			input: types.AddMemberRequest{Name: "Jane Doe", Email: " Jane@Example.com"},
+			output: output{
+				code: http.StatusCreated,
+				body: types.AddMemberResponse{ID: 1},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetMemberByEmail("jane@example.com").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateMember(types.Member{Name: "Jane Doe", Email: "jane@example.com", Tier: "standard", Status: types.MEMBER_STATUS_ACTIVE}).
+					Return(types.Member{ID: 1}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/members", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.AddMemberResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+				require.Equal(t, tc.output.errID, res.ID)
+			}
+		})
+	}
+}
+
+func TestCheckout(t *testing.T) {
+	member := types.Member{ID: 2, Name: "Jane Doe", Email: "jane@example.com", Tier: "standard", Status: types.MEMBER_STATUS_ACTIVE}
+	available := inventorytypes.Copy{ID: 3, BookID: 1, Barcode: "LIB000123", Status: booktypes.COPY_STATUS_AVAILABLE}
+
+	type output struct {
+		code   int
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.CheckoutRequest
+		output     output
+	}{
+		{
+			name:  "no copy",
+			input: types.CheckoutRequest{MemberID: 2},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Barcode":"It is invalid","CopyID":"It is invalid"}`,
+			},
+		},
+		{
+			name:  "member not found",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "member not found: 2",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "inactive member",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "member is not active: 2",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				m := member
+				m.Status = types.MEMBER_STATUS_SUSPENDED
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&m, nil)
+			},
+		},
+		{
+			name:  "member over the fine limit",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusPaymentRequired,
+				errMsg: "member is blocked by unpaid fines: balance 1025 is over the limit of 1000 USD",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(1025), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:  "member over the fine limit with the fines accrued by a loan past due",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusPaymentRequired,
+				errMsg: "member is blocked by unpaid fines: balance 1040 is over the limit of 1000 USD",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(990), nil)
+				// 36 hours late past the grace period of a day are 2 days of fines.
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return([]types.Loan{
+					{ID: 5, MemberID: 2, Status: types.LOAN_STATUS_OVERDUE, DueAt: time.Now().Add(-60 * time.Hour)},
+				}, nil)
+			},
+		},
+		{
+			name:  "loan limit of the tier",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "loan limit reached: 2",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(1000), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CountOpenLoans(2).Return(int64(2), nil)
+			},
+		},
+		{
+			name:  "copy not found",
+			input: types.CheckoutRequest{MemberID: 2, Barcode: "lib 000999"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "copy not found: LIB000999",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CountOpenLoans(2).Return(int64(1), nil)
+				s.Repository.EXPECT().LockCopyByBarcode("LIB000999").Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "copy already on loan",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "copy is not available: on_loan",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				c := available
+				c.Status = booktypes.COPY_STATUS_ON_LOAN
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CountOpenLoans(2).Return(int64(1), nil)
+				s.Repository.EXPECT().LockCopy(3).Return(&c, nil)
+				s.Repository.EXPECT().GetOpenHold(1, 2).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "copy on hold for another member",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "copy is on hold",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				c := available
+				c.Status = booktypes.COPY_STATUS_ON_HOLD
+				hold := types.Hold{ID: 8, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_WAITING}
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CountOpenLoans(2).Return(int64(1), nil)
+				s.Repository.EXPECT().LockCopy(3).Return(&c, nil)
+				s.Repository.EXPECT().GetOpenHold(1, 2).Return(&hold, nil)
+				s.Repository.EXPECT().LockHold(8).Return(&hold, nil)
+			},
+		},
+		{
+			name:  "copy on hold for the member",
+			input: types.CheckoutRequest{MemberID: 2, CopyID: 3},
+			output: output{
+				code: http.StatusCreated,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				c := available
+				c.Status = booktypes.COPY_STATUS_ON_HOLD
+				copyID := 3
+				hold := types.Hold{ID: 8, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_READY, CopyID: &copyID}
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CountOpenLoans(2).Return(int64(1), nil)
+				s.Repository.EXPECT().LockCopy(3).Return(&c, nil)
+				s.Repository.EXPECT().GetOpenHold(1, 2).Return(&hold, nil)
+				s.Repository.EXPECT().LockHold(8).Return(&hold, nil)
+				s.Repository.EXPECT().CreateLoan(gomock.Any()).DoAndReturn(func(l types.Loan) (types.Loan, error) {
+					l.ID = 5
+					return l, nil
+				})
+				s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_ON_LOAN).Return(nil)
+				s.Repository.EXPECT().UpdateHold(gomock.Any()).DoAndReturn(func(h *types.Hold) error {
+					require.Equal(t, types.HOLD_STATUS_FULFILLED, h.Status)
+					require.NotNil(t, h.ClosedAt)
+					return nil
+				})
+			},
+		},
+		{
+			name:  "success",
+			input: types.CheckoutRequest{MemberID: 2, Barcode: "lib000123"},
+			output: output{
+				code: http.StatusCreated,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CountOpenLoans(2).Return(int64(1), nil)
+				s.Repository.EXPECT().LockCopyByBarcode("LIB000123").Return(&available, nil)
+				s.Repository.EXPECT().GetOpenHold(1, 2).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateLoan(gomock.Any()).DoAndReturn(func(l types.Loan) (types.Loan, error) {
+					require.Equal(t, 3, l.CopyID)
+					require.Equal(t, 1, l.BookID)
+					require.Equal(t, 2, l.MemberID)
+					require.Equal(t, types.LOAN_STATUS_ACTIVE, l.Status)
+					require.Equal(t, testutil.TestConfig.Period, l.DueAt.Sub(l.CheckedOutAt))
+					l.ID = 5
+					return l, nil
+				})
+				s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_ON_LOAN).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/loans", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.Loan
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, 5, res.ID)
+				require.Equal(t, types.LOAN_STATUS_ACTIVE, res.Status)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestReturn(t *testing.T) {
+	loan := types.Loan{ID: 5, CopyID: 3, BookID: 1, MemberID: 2, Status: types.LOAN_STATUS_ACTIVE, DueAt: time.Now().Add(time.Hour)}
+	// three days late once the grace period of a day is deducted, the last one started
+	late := loan
+	late.Status = types.LOAN_STATUS_OVERDUE
+	late.DueAt = time.Now().Add(-3*24*time.Hour - time.Hour)
+
+	type output struct {
+		code   int
+		fine   int64
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		output     output
+	}{
+		{
+			name: "loan not found",
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "loan not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetLoan(5).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name: "returned by a concurrent request",
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "loan is already returned",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				l := loan
+				returned := loan
+				returned.Status = types.LOAN_STATUS_RETURNED
+				s.Repository.EXPECT().GetLoan(5).Return(&l, nil)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockCopy(3).Return(&inventorytypes.Copy{ID: 3}, nil)
+				s.Repository.EXPECT().LockLoan(5).Return(&returned, nil)
+			},
+		},
+		{
+			name: "success",
+			output: output{
+				code: http.StatusOK,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				l, locked := loan, loan
+				s.Repository.EXPECT().GetLoan(5).Return(&l, nil)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockCopy(3).Return(&inventorytypes.Copy{ID: 3}, nil)
+				s.Repository.EXPECT().LockLoan(5).Return(&locked, nil)
+				s.Repository.EXPECT().UpdateLoan(&locked).Return(nil)
+				s.Repository.EXPECT().LockNextHold(1).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_AVAILABLE).Return(nil)
+			},
+		},
+		{
+			name: "late return",
+			output: output{
+				code: http.StatusOK,
+				fine: 75,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				l, locked := late, late
+				s.Repository.EXPECT().GetLoan(5).Return(&l, nil)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockCopy(3).Return(&inventorytypes.Copy{ID: 3}, nil)
+				s.Repository.EXPECT().LockLoan(5).Return(&locked, nil)
+				s.Repository.EXPECT().UpdateLoan(&locked).Return(nil)
+				s.Repository.EXPECT().CreateAccountEntry(gomock.Any()).DoAndReturn(func(e types.AccountEntry) (types.AccountEntry, error) {
+					require.Equal(t, types.AccountEntry{MemberID: 2, LoanID: &locked.ID, Kind: types.ENTRY_KIND_FINE, Amount: 75}, e)
+					return e, nil
+				})
+				s.Repository.EXPECT().LockNextHold(1).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_AVAILABLE).Return(nil)
+			},
+		},
+		{
+			name: "copy passed on to the next hold",
+			output: output{
+				code: http.StatusOK,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				l, locked := loan, loan
+				next := types.Hold{ID: 8, BookID: 1, MemberID: 4, Status: types.HOLD_STATUS_WAITING}
+				s.Repository.EXPECT().GetLoan(5).Return(&l, nil)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockCopy(3).Return(&inventorytypes.Copy{ID: 3}, nil)
+				s.Repository.EXPECT().LockLoan(5).Return(&locked, nil)
+				s.Repository.EXPECT().UpdateLoan(&locked).Return(nil)
+				s.Repository.EXPECT().LockNextHold(1).Return(&next, nil)
+				s.Repository.EXPECT().UpdateHold(&next).DoAndReturn(func(h *types.Hold) error {
+					require.Equal(t, types.HOLD_STATUS_READY, h.Status)
+					require.Equal(t, 3, *h.CopyID)
+					require.Equal(t, testutil.TestConfig.HoldPickupPeriod, h.ExpiresAt.Sub(*h.ReadyAt))
+					return nil
+				})
+				s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_ON_HOLD).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/loans/5/return", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Loan
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, types.LOAN_STATUS_RETURNED, res.Status)
+				require.NotNil(t, res.ReturnedAt)
+				require.Equal(t, tc.output.fine, res.Fine)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestRenew(t *testing.T) {
+	dueAt := time.Now().Add(24 * time.Hour).UTC()
+	loan := types.Loan{ID: 5, CopyID: 3, BookID: 1, MemberID: 2, Status: types.LOAN_STATUS_ACTIVE, DueAt: dueAt, Renewals: 1}
+
+	type output struct {
+		code   int
+		errMsg string
+	}
+	testCases := []struct {
+		name    string
+		current func() types.Loan
+		output  output
+	}{
+		{
+			name: "overdue",
+			current: func() types.Loan {
+				l := loan
+				l.Status = types.LOAN_STATUS_OVERDUE
+				return l
+			},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "loan is overdue",
+			},
+		},
+		{
+			name: "past due date before the overdue check",
+			current: func() types.Loan {
+				l := loan
+				l.DueAt = time.Now().Add(-time.Hour).UTC()
+				return l
+			},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "loan is overdue",
+			},
+		},
+		{
+			name: "renewal limit",
+			current: func() types.Loan {
+				l := loan
+				l.Renewals = 2
+				return l
+			},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "renewal limit reached: 2",
+			},
+		},
+		{
+			name:    "success",
+			current: func() types.Loan { return loan },
+			output: output{
+				code: http.StatusOK,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			l := tc.current()
+			s.ExpectTransaction()
+			s.Repository.EXPECT().LockLoan(5).Return(&l, nil)
+			if tc.output.code == http.StatusOK {
+				s.Repository.EXPECT().LockMember(2).Return(&types.Member{ID: 2, Status: types.MEMBER_STATUS_ACTIVE}, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().UpdateLoan(&l).Return(nil)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/loans/5/renew", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Loan
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, 2, res.Renewals)
+				require.True(t, res.DueAt.After(dueAt))
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestGetLoans(t *testing.T) {
+	testCases := []struct {
+		name       string
+		query      string
+		preProcess func(s *testutil.TestSuite)
+		code       int
+	}{
+		{
+			name:  "invalid status",
+			query: "?page=1&limit=10&status=lost",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "invalid member",
+			query: "?page=1&limit=10&member_id=jane",
+			code:  http.StatusBadRequest,
+		},
+		{
+			name:  "filters",
+			query: "?page=1&limit=10&status=overdue&member_id=2&book_id=1",
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetLoans(types.LoanFilter{MemberID: 2, BookID: 1, Status: types.LOAN_STATUS_OVERDUE}, 1, 10).Return(nil, []types.Loan{}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/loans"+tc.query, nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+		})
+	}
+}
+
+func TestPlaceHold(t *testing.T) {
+	member := types.Member{ID: 2, Name: "Jane Doe", Email: "jane@example.com", Tier: "standard", Status: types.MEMBER_STATUS_ACTIVE}
+
+	type output struct {
+		code   int
+		body   types.Hold
+		errMsg string
+		errID  int
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		output     output
+	}{
+		{
+			name: "book not found",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "book not found: 1",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().BookExists(1).Return(false, nil)
+			},
+		},
+		{
+			name: "available copies",
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "book has available copies",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().LockBookCopies(1).Return([]inventorytypes.Copy{
+					{ID: 3, BookID: 1, Status: booktypes.COPY_STATUS_ON_LOAN},
+					{ID: 4, BookID: 1, Status: booktypes.COPY_STATUS_AVAILABLE},
+				}, nil)
+			},
+		},
+		{
+			name: "hold already placed",
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "hold already exists: 8",
+				errID:  8,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().LockBookCopies(1).Return([]inventorytypes.Copy{{ID: 3, BookID: 1, Status: booktypes.COPY_STATUS_ON_LOAN}}, nil)
+				s.Repository.EXPECT().GetOpenHold(1, 2).Return(&types.Hold{ID: 8}, nil)
+			},
+		},
+		{
+			name: "success",
+			output: output{
+				code: http.StatusCreated,
+				body: types.Hold{ID: 9, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_WAITING, Position: 3},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&member, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().LockBookCopies(1).Return([]inventorytypes.Copy{{ID: 3, BookID: 1, Status: booktypes.COPY_STATUS_ON_HOLD}}, nil)
+				s.Repository.EXPECT().GetOpenHold(1, 2).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateHold(types.Hold{BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_WAITING}).
+					Return(types.Hold{ID: 9, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_WAITING}, nil)
+				s.Repository.EXPECT().GetHoldPosition(gomock.Any()).Return(3, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(types.PlaceHoldRequest{MemberID: 2, BookID: 1})
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/holds", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.Hold
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+				require.Equal(t, tc.output.errID, res.ID)
+			}
+		})
+	}
+}
+
+func TestCancelHold(t *testing.T) {
+	copyID := 3
+	ready := types.Hold{ID: 8, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_READY, CopyID: &copyID}
+
+	type output struct {
+		code   int
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		output     output
+	}{
+		{
+			name: "already expired",
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "hold is already expired",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				h := ready
+				h.Status = types.HOLD_STATUS_EXPIRED
+				s.Repository.EXPECT().GetHold(8).Return(&h, nil)
+			},
+		},
+		{
+			name: "ready hold",
+			output: output{
+				code: http.StatusOK,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				h, locked := ready, ready
+				s.Repository.EXPECT().GetHold(8).Return(&h, nil)
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockCopy(3).Return(&inventorytypes.Copy{ID: 3}, nil)
+				s.Repository.EXPECT().LockHold(8).Return(&locked, nil)
+				s.Repository.EXPECT().UpdateHold(&locked).Return(nil)
+				s.Repository.EXPECT().LockNextHold(1).Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_AVAILABLE).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/holds/8/cancel", nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Hold
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, types.HOLD_STATUS_CANCELLED, res.Status)
+				require.NotNil(t, res.ClosedAt)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestExpireHolds(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+
+	now := time.Now()
+	expiresAt := now.Add(-time.Minute)
+	copyID := 3
+	expired := types.Hold{ID: 8, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_READY, CopyID: &copyID, ExpiresAt: &expiresAt}
+	collected := types.Hold{ID: 9, BookID: 2, MemberID: 2, Status: types.HOLD_STATUS_FULFILLED, CopyID: &copyID, ExpiresAt: &expiresAt}
+	next := types.Hold{ID: 10, BookID: 1, MemberID: 4, Status: types.HOLD_STATUS_WAITING}
+
+	s.Repository.EXPECT().GetExpiredHolds(now).Return([]types.Hold{expired, {ID: 9, CopyID: &copyID}}, nil)
+	s.ExpectTransaction().Times(2)
+	s.Repository.EXPECT().LockCopy(3).Return(&inventorytypes.Copy{ID: 3}, nil).Times(2)
+	s.Repository.EXPECT().LockHold(8).Return(&expired, nil)
+	s.Repository.EXPECT().UpdateHold(&expired).Return(nil)
+	s.Repository.EXPECT().LockNextHold(1).Return(&next, nil)
+	s.Repository.EXPECT().UpdateHold(&next).Return(nil)
+	s.Repository.EXPECT().UpdateCopyStatus(3, booktypes.COPY_STATUS_ON_HOLD).Return(nil)
+	s.Repository.EXPECT().LockHold(9).Return(&collected, nil)
+
+	n, err := s.Service.ExpireHolds(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+	require.Equal(t, types.HOLD_STATUS_EXPIRED, expired.Status)
+	require.Equal(t, types.HOLD_STATUS_READY, next.Status)
+	require.Equal(t, now.Add(testutil.TestConfig.HoldPickupPeriod), *next.ExpiresAt)
+}
+
+func TestGetMemberHolds(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+
+	copyID := 3
+	s.Repository.EXPECT().GetMember(2).Return(&types.Member{ID: 2}, nil)
+	s.Repository.EXPECT().GetHolds(types.HoldFilter{MemberID: 2}, 1, 10).Return(nil, []types.Hold{
+		{ID: 8, BookID: 1, MemberID: 2, Status: types.HOLD_STATUS_READY, CopyID: &copyID},
+		{ID: 9, BookID: 5, MemberID: 2, Status: types.HOLD_STATUS_WAITING},
+	}, nil)
+	s.Repository.EXPECT().GetHoldPosition(types.Hold{ID: 9, BookID: 5, MemberID: 2, Status: types.HOLD_STATUS_WAITING}).Return(2, nil)
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/members/2/holds?page=1&limit=10", nil)
+	require.NoError(t, err)
+
+	resp := httptest.NewRecorder()
+	router := http.NewServeMux()
+	router = service.InitializeRoutes(router, *s.Handler)
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+
+	var res types.GetHoldsResponse
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	require.NoError(t, err)
+
+	require.Len(t, res.Holds, 2)
+	require.Equal(t, 0, res.Holds[0].Position)
+	require.Equal(t, 2, res.Holds[1].Position)
+}
+
+func TestPay(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.Account
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.PayRequest
+		output     output
+	}{
+		{
+			name:  "no amount",
+			input: types.PayRequest{},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Amount":"It is required"}`,
+			},
+		},
+		{
+			name:  "more than the balance",
+			input: types.PayRequest{Amount: 1200},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "payment exceeds balance: 1100",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&types.Member{ID: 2}, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(1100), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+			},
+		},
+		{
+			name:  "fines accrued by a loan past due",
+			input: types.PayRequest{Amount: 150},
+			output: output{
+				code: http.StatusCreated,
+				body: types.Account{MemberID: 2, Balance: 50, Accrued: 50, Currency: "USD", Limit: 1000},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				overdue := []types.Loan{{ID: 5, MemberID: 2, Status: types.LOAN_STATUS_OVERDUE, DueAt: time.Now().Add(-60 * time.Hour)}}
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&types.Member{ID: 2}, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(100), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(overdue, nil)
+				s.Repository.EXPECT().CreateAccountEntry(types.AccountEntry{MemberID: 2, Kind: types.ENTRY_KIND_PAYMENT, Amount: -150}).
+					Return(types.AccountEntry{ID: 4}, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(0), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(overdue, nil)
+			},
+		},
+		{
+			name:  "success",
+			input: types.PayRequest{Amount: 200},
+			output: output{
+				code: http.StatusCreated,
+				body: types.Account{MemberID: 2, Balance: 900, Currency: "USD", Limit: 1000},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockMember(2).Return(&types.Member{ID: 2}, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(1100), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+				s.Repository.EXPECT().CreateAccountEntry(types.AccountEntry{MemberID: 2, Kind: types.ENTRY_KIND_PAYMENT, Amount: -200}).
+					Return(types.AccountEntry{ID: 4}, nil)
+				s.Repository.EXPECT().GetBalance(2).Return(int64(900), nil)
+				s.Repository.EXPECT().GetDueLoans(2, gomock.Any()).Return(nil, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/members/2/payments", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.Account
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+)
+
+// PlaceHold queues a member for a book that has no available copy. The copies of the book are
+// locked while the hold is placed, so a copy returned meanwhile is passed on to the new hold.
+func (s *Service) PlaceHold(req types.PlaceHoldRequest) (types.Hold, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Hold{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	var hold types.Hold
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		m, err := s.lockEligibleMember(d, req.MemberID)
+		if err != nil {
+			return err
+		}
+
+		exists, err := d.BookExists(req.BookID)
+		if err != nil {
+			return err
+		} else if !exists {
+			return apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("book not found: %d", req.BookID))
+		}
+
+		copies, err := d.LockBookCopies(req.BookID)
+		if err != nil {
+			return err
+		}
+		for _, c := range copies {
+			if c.Status == booktypes.COPY_STATUS_AVAILABLE {
+				return apierror.ErrConflict.WithMessage("book has available copies")
+			}
+		}
+
+		existing, err := d.GetOpenHold(req.BookID, m.ID)
+		if err == nil {
+			return apierror.NewConflictError(fmt.Sprintf("hold already exists: %d", existing.ID), existing.ID)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if hold, err = d.CreateHold(types.Hold{
+			BookID:   req.BookID,
+			MemberID: m.ID,
+			Status:   types.HOLD_STATUS_WAITING,
+		}); err != nil {
+			return err
+		}
+
+		hold.Position, err = d.GetHoldPosition(hold)
+		return err
+	})
+	if err != nil {
+		return types.Hold{}, err
+	}
+
+	return hold, nil
+}
+
+// CancelHold closes a waiting or ready hold. The copy kept for a ready hold is passed on to the
+// next hold of the book, if any, or made available again.
+func (s *Service) CancelHold(id int) (types.Hold, error) {
+	hold, err := s.GetHold(id)
+	if err != nil {
+		return types.Hold{}, err
+	}
+	if !hold.IsOpen() {
+		return types.Hold{}, apierror.ErrConflict.WithMessage(fmt.Sprintf("hold is already %s", hold.Status))
+	}
+
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		// the kept copy is locked before the hold, in the same order as a checkout.
+		locked := hold.CopyID
+		if locked != nil {
+			if _, err := d.LockCopy(*locked); err != nil {
+				return err
+			}
+		}
+		if hold, err = d.LockHold(id); err != nil {
+			return err
+		}
+		if !hold.IsOpen() {
+			return apierror.ErrConflict.WithMessage(fmt.Sprintf("hold is already %s", hold.Status))
+		}
+		// the hold became ready after it was read.
+		if hold.CopyID != nil && (locked == nil || *locked != *hold.CopyID) {
+			if _, err := d.LockCopy(*hold.CopyID); err != nil {
+				return err
+			}
+		}
+
+		return s.closeHold(d, hold, types.HOLD_STATUS_CANCELLED, time.Now())
+	})
+	if err != nil {
+		return types.Hold{}, err
+	}
+
+	return *hold, nil
+}
+
+// ExpireHolds closes the ready holds not collected before now and passes their copies on. It
+// returns the number of expired holds.
+func (s *Service) ExpireHolds(now time.Time) (int, error) {
+	holds, err := s.dataProvider.GetExpiredHolds(now)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, h := range holds {
+		err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+			if _, err := d.LockCopy(*h.CopyID); err != nil {
+				return err
+			}
+			hold, err := d.LockHold(h.ID)
+			if err != nil {
+				return err
+			}
+			// the hold may have been collected or cancelled since it was listed.
+			if hold.Status != types.HOLD_STATUS_READY || !hold.ExpiresAt.Before(now) {
+				return nil
+			}
+
+			expired++
+			return s.closeHold(d, hold, types.HOLD_STATUS_EXPIRED, now)
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// GetHolds returns the holds matching the filter in the order they were placed, with the queue
+// positions of the waiting ones
+func (s *Service) GetHolds(filter types.HoldFilter, page int, limit int) (types.GetHoldsResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		return types.GetHoldsResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid status: %s", filter.Status))
+	}
+
+	pagination, holds, err := s.dataProvider.GetHolds(filter, page, limit)
+	if err != nil {
+		return types.GetHoldsResponse{}, err
+	}
+	for i := range holds {
+		if err := s.setPosition(&holds[i]); err != nil {
+			return types.GetHoldsResponse{}, err
+		}
+	}
+
+	return types.GetHoldsResponse{
+		Holds:      holds,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetMemberHolds returns the holds of a member in the order they were placed
+func (s *Service) GetMemberHolds(id int, status types.HoldStatus, page int, limit int) (types.GetHoldsResponse, error) {
+	if _, err := s.GetMember(id); err != nil {
+		return types.GetHoldsResponse{}, err
+	}
+
+	return s.GetHolds(types.HoldFilter{MemberID: id, Status: status}, page, limit)
+}
+
+// GetHold returns a hold from the given id
+func (s *Service) GetHold(id int) (*types.Hold, error) {
+	hold, err := s.dataProvider.GetHold(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("hold not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := s.setPosition(hold); err != nil {
+		return nil, err
+	}
+	return hold, nil
+}
+
+// setPosition sets the queue position of a waiting hold
+func (s *Service) setPosition(hold *types.Hold) error {
+	if hold.Status != types.HOLD_STATUS_WAITING {
+		return nil
+	}
+
+	var err error
+	hold.Position, err = s.dataProvider.GetHoldPosition(*hold)
+	return err
+}
+
+// closeHold closes an open hold and passes its kept copy on. The copy must be locked.
+func (s *Service) closeHold(d types.DataProvider, hold *types.Hold, status types.HoldStatus, now time.Time) error {
+	wasReady := hold.Status == types.HOLD_STATUS_READY
+	hold.Status = status
+	hold.Position = 0
+	hold.ClosedAt = &now
+	if err := d.UpdateHold(hold); err != nil {
+		return err
+	}
+
+	if !wasReady {
+		return nil
+	}
+	return s.passCopy(d, hold.BookID, *hold.CopyID, now)
+}
+
+// passCopy keeps a locked copy for the next waiting hold of its book until the end of the pickup
+// period, or makes it available when nobody is waiting
+func (s *Service) passCopy(d types.DataProvider, bookID int, copyID int, now time.Time) error {
+	next, err := d.LockNextHold(bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return d.UpdateCopyStatus(copyID, booktypes.COPY_STATUS_AVAILABLE)
+	} else if err != nil {
+		return err
+	}
+
+	// the hold is in the tenant of the copy, which may not be the one of the service when the
+	// holds of every tenant are expired.
+	expiresAt := now.Add(s.conf.For(next.TenantID).HoldPickupPeriod)
+	next.Status = types.HOLD_STATUS_READY
+	next.CopyID = &copyID
+	next.ReadyAt = &now
+	next.ExpiresAt = &expiresAt
+	if err := d.UpdateHold(next); err != nil {
+		return err
+	}
+
+	return d.UpdateCopyStatus(copyID, booktypes.COPY_STATUS_ON_HOLD)
+}
+
+// lockOpenHold locks the open hold of a member for a book, if any
+func lockOpenHold(d types.DataProvider, bookID int, memberID int) (*types.Hold, error) {
+	hold, err := d.GetOpenHold(bookID, memberID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if hold, err = d.LockHold(hold.ID); err != nil {
+		return nil, err
+	}
+	if !hold.IsOpen() {
+		return nil, nil
+	}
+	return hold, nil
+}
+
+// isReadyWith reports whether the hold is ready with the given copy kept for pickup
+func isReadyWith(hold *types.Hold, copyID int) bool {
+	return hold != nil && hold.Status == types.HOLD_STATUS_READY && hold.CopyID != nil && *hold.CopyID == copyID
+}
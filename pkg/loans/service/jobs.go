@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// RunOverdueCheck flags the overdue loans of every tenant once at start and then at every
+// interval until the context is done.
+func RunOverdueCheck(ctx context.Context, serv *Service, interval time.Duration, log *zap.Logger) {
+	serv = serv.WithContext(tenant.AllTenants(ctx))
+	runEvery(ctx, interval, func(now time.Time) {
+		n, err := serv.FlagOverdueLoans(now)
+		if err != nil {
+			log.Error(fmt.Sprintf("failed to flag overdue loans: %v", err))
+		} else if n > 0 {
+			log.Info(fmt.Sprintf("flagged %d overdue loans", n))
+		}
+	})
+}
+
+// RunHoldExpiry expires the holds of every tenant not collected in time once at start and then
+// at every interval until the context is done.
+func RunHoldExpiry(ctx context.Context, serv *Service, interval time.Duration, log *zap.Logger) {
+	serv = serv.WithContext(tenant.AllTenants(ctx))
+	runEvery(ctx, interval, func(now time.Time) {
+		n, err := serv.ExpireHolds(now)
+		if err != nil {
+			log.Error(fmt.Sprintf("failed to expire holds: %v", err))
+		}
+		if n > 0 {
+			log.Info(fmt.Sprintf("expired %d holds", n))
+		}
+	})
+}
+
+// runEvery calls fn with the current time once and then at every interval until the context is done.
+func runEvery(ctx context.Context, interval time.Duration, fn func(now time.Time)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	fn(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			fn(now)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in a loans service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new loans-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *Repository) Transaction(fn func(d types.DataProvider) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx, r.log)
+		return fn(&repo)
+	})
+}
+
+// CreateMember creates a new member in the database
+func (r *Repository) CreateMember(m types.Member) (types.Member, error) {
+	tx := r.db.Create(&m)
+	return m, tx.Error
+}
+
+// UpdateMember updates a member in the database
+func (r *Repository) UpdateMember(m *types.Member) error {
+	return r.db.Save(m).Error
+}
+
+// GetMembers retrieves the members whose name or email contains the query from the database
+func (r *Repository) GetMembers(query string, page int, limit int) (*db.Pagination, []types.Member, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id asc",
+	}
+
+	tx := r.db
+	if query != "" {
+		tx = tx.Where("LOWER(name) LIKE LOWER(?) OR LOWER(email) LIKE LOWER(?)", "%"+query+"%", "%"+query+"%")
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var members []types.Member
+	if result := tx.Scopes(db.Paginate(&members, &p, tx)).Find(&members); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, members, nil
+}
+
+// GetMember retrieves a member from the database
+func (r *Repository) GetMember(id int) (*types.Member, error) {
+	var m types.Member
+	if result := r.db.First(&m, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &m, nil
+}
+
+// GetMemberByEmail retrieves a member from the database by its normalized email
+func (r *Repository) GetMemberByEmail(email string) (*types.Member, error) {
+	var m types.Member
+	if result := r.db.Where("email = ?", email).First(&m); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &m, nil
+}
+
+// LockMember retrieves a member from the database with a row lock held until the end of the transaction
+func (r *Repository) LockMember(id int) (*types.Member, error) {
+	var m types.Member
+	if result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&m, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &m, nil
+}
+
+// CountOpenLoans counts the loans of a member that are not returned in the database
+func (r *Repository) CountOpenLoans(memberID int) (int64, error) {
+	var count int64
+	err := r.db.Model(&types.Loan{}).Where("member_id = ? AND returned_at IS NULL", memberID).Count(&count).Error
+	return count, err
+}
+
+// GetBalance sums the amounts of the account entries of a member in the database
+func (r *Repository) GetBalance(memberID int) (int64, error) {
+	var balance int64
+	err := r.db.Model(&types.AccountEntry{}).
+		Where("member_id = ?", memberID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetDueLoans retrieves the loans of a member not returned and due before the given time from
+// the database
+func (r *Repository) GetDueLoans(memberID int, before time.Time) ([]types.Loan, error) {
+	var loans []types.Loan
+	err := r.db.Where("member_id = ? AND returned_at IS NULL AND due_at < ?", memberID, before).Order("id asc").Find(&loans).Error
+	return loans, err
+}
+
+// CreateAccountEntry creates a new account entry in the database
+func (r *Repository) CreateAccountEntry(e types.AccountEntry) (types.AccountEntry, error) {
+	tx := r.db.Create(&e)
+	return e, tx.Error
+}
+
+// GetAccountEntries retrieves the account entries of a member from the database, the latest first
+func (r *Repository) GetAccountEntries(memberID int, page int, limit int) (*db.Pagination, []types.AccountEntry, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id desc",
+	}
+
+	tx := r.db.Where("member_id = ?", memberID).Session(&gorm.Session{})
+
+	var entries []types.AccountEntry
+	if result := tx.Scopes(db.Paginate(&entries, &p, tx)).Find(&entries); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, entries, nil
+}
+
+// BookExists checks if the book exists in the database
+func (r *Repository) BookExists(bookID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&booktypes.Book{}).Where("id = ?", bookID).Count(&count).Error
+	return count > 0, err
+}
+
+// LockBookCopies retrieves the copies of a book from the database with row locks held until the
+// end of the transaction
+func (r *Repository) LockBookCopies(bookID int) ([]inventorytypes.Copy, error) {
+	var copies []inventorytypes.Copy
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("book_id = ?", bookID).Order("id asc").Find(&copies).Error
+	return copies, err
+}
+
+// LockCopy retrieves a copy from the database with a row lock held until the end of the transaction
+func (r *Repository) LockCopy(id int) (*inventorytypes.Copy, error) {
+	var c inventorytypes.Copy
+	if result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&c, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &c, nil
+}
+
+// LockCopyByBarcode retrieves a copy from the database by its normalized barcode with a row lock
+// held until the end of the transaction
+func (r *Repository) LockCopyByBarcode(barcode string) (*inventorytypes.Copy, error) {
+	var c inventorytypes.Copy
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("barcode = ?", barcode).First(&c)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &c, nil
+}
+
+// UpdateCopyStatus updates the status of a copy in the database
+func (r *Repository) UpdateCopyStatus(id int, status booktypes.CopyStatus) error {
+	return r.db.Model(&inventorytypes.Copy{}).Where("id = ?", id).Update("status", status).Error
+}
+
+// CreateLoan creates a new loan in the database
+func (r *Repository) CreateLoan(l types.Loan) (types.Loan, error) {
+	tx := r.db.Create(&l)
+	return l, tx.Error
+}
+
+// UpdateLoan updates a loan in the database
+func (r *Repository) UpdateLoan(l *types.Loan) error {
+	return r.db.Save(l).Error
+}
+
+// LockLoan retrieves a loan from the database with a row lock held until the end of the transaction
+func (r *Repository) LockLoan(id int) (*types.Loan, error) {
+	var l types.Loan
+	if result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&l, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &l, nil
+}
+
+// GetLoan retrieves a loan from the database
+func (r *Repository) GetLoan(id int) (*types.Loan, error) {
+	var l types.Loan
+	if result := r.db.First(&l, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &l, nil
+}
+
+// GetLoans retrieves the loans matching the filter from the database, the latest first
+func (r *Repository) GetLoans(filter types.LoanFilter, page int, limit int) (*db.Pagination, []types.Loan, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id desc",
+	}
+
+	tx := r.db
+	if filter.MemberID != 0 {
+		tx = tx.Where("member_id = ?", filter.MemberID)
+	}
+	if filter.BookID != 0 {
+		tx = tx.Where("book_id = ?", filter.BookID)
+	}
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var loans []types.Loan
+	if result := tx.Scopes(db.Paginate(&loans, &p, tx)).Find(&loans); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, loans, nil
+}
+
+// CreateHold creates a new hold in the database
+func (r *Repository) CreateHold(h types.Hold) (types.Hold, error) {
+	tx := r.db.Create(&h)
+	return h, tx.Error
+}
+
+// UpdateHold updates a hold in the database
+func (r *Repository) UpdateHold(h *types.Hold) error {
+	return r.db.Save(h).Error
+}
+
+// LockHold retrieves a hold from the database with a row lock held until the end of the transaction
+func (r *Repository) LockHold(id int) (*types.Hold, error) {
+	var h types.Hold
+	if result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&h, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &h, nil
+}
+
+// GetHold retrieves a hold from the database
+func (r *Repository) GetHold(id int) (*types.Hold, error) {
+	var h types.Hold
+	if result := r.db.First(&h, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &h, nil
+}
+
+// GetOpenHold retrieves the waiting or ready hold of a member for a book from the database
+func (r *Repository) GetOpenHold(bookID int, memberID int) (*types.Hold, error) {
+	var h types.Hold
+	result := r.db.Where("book_id = ? AND member_id = ? AND closed_at IS NULL", bookID, memberID).First(&h)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &h, nil
+}
+
+// LockNextHold retrieves the first waiting hold of a book from the database with a row lock held
+// until the end of the transaction
+func (r *Repository) LockNextHold(bookID int) (*types.Hold, error) {
+	var h types.Hold
+	result := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, types.HOLD_STATUS_WAITING).
+		Order("id asc").
+		First(&h)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &h, nil
+}
+
+// GetHolds retrieves the holds matching the filter from the database in the order they were placed
+func (r *Repository) GetHolds(filter types.HoldFilter, page int, limit int) (*db.Pagination, []types.Hold, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id asc",
+	}
+
+	tx := r.db
+	if filter.MemberID != 0 {
+		tx = tx.Where("member_id = ?", filter.MemberID)
+	}
+	if filter.BookID != 0 {
+		tx = tx.Where("book_id = ?", filter.BookID)
+	}
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var holds []types.Hold
+	if result := tx.Scopes(db.Paginate(&holds, &p, tx)).Find(&holds); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, holds, nil
+}
+
+// GetHoldPosition counts the waiting holds of the book placed up to the given one in the database
+func (r *Repository) GetHoldPosition(h types.Hold) (int, error) {
+	var count int64
+	err := r.db.Model(&types.Hold{}).
+		Where("book_id = ? AND status = ? AND id <= ?", h.BookID, types.HOLD_STATUS_WAITING, h.ID).
+		Count(&count).Error
+	return int(count), err
+}
+
+// GetExpiredHolds retrieves the ready holds that expired before now from the database
+func (r *Repository) GetExpiredHolds(now time.Time) ([]types.Hold, error) {
+	var holds []types.Hold
+	err := r.db.Where("status = ? AND expires_at < ?", types.HOLD_STATUS_READY, now).Order("id asc").Find(&holds).Error
+	return holds, err
+}
+
+// FlagOverdueLoans sets the status of the active loans due before now to overdue in the database.
+// The condition on the status makes it safe to run concurrently with returns and renewals.
+func (r *Repository) FlagOverdueLoans(now time.Time) (int64, error) {
+	result := r.db.Model(&types.Loan{}).
+		Where("status = ? AND due_at < ?", types.LOAN_STATUS_ACTIVE, now).
+		Updates(map[string]interface{}{"status": types.LOAN_STATUS_OVERDUE, "updated_at": now})
+	return result.RowsAffected, result.Error
+}
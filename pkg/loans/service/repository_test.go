@@ -0,0 +1,35 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+
+	bookservice "github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	classificationservice "github.com/nkitlabs/go-http-gorm-example/pkg/classification/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db/dbtest"
+	inventoryservice "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/service"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+func TestRepository(t *testing.T) {
+	ctx := tenant.NewContext(context.Background(), "school-a")
+	for _, driver := range dbtest.Drivers() {
+		t.Run(driver, func(t *testing.T) {
+			testutil.RunDataProviderTests(t, ctx, func(t *testing.T) (types.DataProvider, testutil.Fixtures) {
+				gdb := dbtest.Open(t, driver, &booktypes.Book{}, &inventorytypes.Copy{}, &types.Member{}, &types.Loan{}, &types.Hold{}, &types.AccountEntry{})
+
+				repo := service.NewRepository(gdb, zap.NewNop())
+				books := bookservice.NewRepository(gdb, zap.NewNop(), classificationservice.BookClassifier{}, inventoryservice.BookInventory{})
+				copies := inventoryservice.NewRepository(gdb, zap.NewNop())
+				return repo.WithContext(ctx), testutil.Fixtures{Books: books.WithContext(ctx), Copies: copies.WithContext(ctx)}
+			})
+		})
+	}
+}
@@ -0,0 +1,458 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// Service is the service layer for the members and their loans
+type Service struct {
+	dataProvider types.DataProvider
+	conf         config.Loans
+	log          *zap.Logger
+}
+
+// NewService creates a new loans service
+func NewService(d types.DataProvider, conf config.Loans, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		conf:         conf,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request, and following the loan rules of the tenant.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	if id, ok := tenant.FromContext(ctx); ok {
+		serv.conf = s.conf.For(id)
+	}
+	return &serv
+}
+
+// AddMember adds a new member into the system
+func (s *Service) AddMember(req types.AddMemberRequest) (types.AddMemberResponse, error) {
+	req.Email = types.NormalizeEmail(req.Email)
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.AddMemberResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	m := types.Member{
+		Name:   req.Name,
+		Email:  req.Email,
+		Tier:   req.Tier,
+		Status: types.MEMBER_STATUS_ACTIVE,
+	}
+	if m.Tier == "" {
+		m.Tier = s.conf.DefaultTier
+	}
+	if err := s.checkTier(m.Tier); err != nil {
+		return types.AddMemberResponse{}, err
+	}
+	if err := s.checkEmailAvailable(m); err != nil {
+		return types.AddMemberResponse{}, err
+	}
+
+	created, err := s.dataProvider.CreateMember(m)
+	if err != nil {
+		return types.AddMemberResponse{}, s.emailConflict(err, m)
+	}
+
+	return types.AddMemberResponse{
+		ID: created.ID,
+	}, nil
+}
+
+// UpdateMember updates a member in the system
+func (s *Service) UpdateMember(id int, req types.UpdateMemberRequest) (types.Member, error) {
+	req.Email = types.NormalizeEmail(req.Email)
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Member{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	m, err := s.GetMember(id)
+	if err != nil {
+		return types.Member{}, err
+	}
+
+	if req.Name != "" {
+		m.Name = req.Name
+	}
+	if req.Email != "" {
+		m.Email = req.Email
+		if err := s.checkEmailAvailable(*m); err != nil {
+			return types.Member{}, err
+		}
+	}
+	if req.Tier != "" {
+		if err := s.checkTier(req.Tier); err != nil {
+			return types.Member{}, err
+		}
+		m.Tier = req.Tier
+	}
+	if req.Status != "" {
+		m.Status = req.Status
+	}
+
+	if err := s.dataProvider.UpdateMember(m); err != nil {
+		return types.Member{}, s.emailConflict(err, *m)
+	}
+
+	return *m, nil
+}
+
+// GetMembers returns the members whose name or email contains the query
+func (s *Service) GetMembers(query string, page int, limit int) (types.GetMembersResponse, error) {
+	pagination, members, err := s.dataProvider.GetMembers(query, page, limit)
+	if err != nil {
+		return types.GetMembersResponse{}, err
+	}
+
+	return types.GetMembersResponse{
+		Members:    members,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetMember returns a member from the given id
+func (s *Service) GetMember(id int) (*types.Member, error) {
+	m, err := s.dataProvider.GetMember(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("member not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMemberLoans returns the loans of a member, the latest first
+func (s *Service) GetMemberLoans(id int, status types.LoanStatus, page int, limit int) (types.GetLoansResponse, error) {
+	if _, err := s.GetMember(id); err != nil {
+		return types.GetLoansResponse{}, err
+	}
+
+	return s.GetLoans(types.LoanFilter{MemberID: id, Status: status}, page, limit)
+}
+
+// Checkout lends a copy to a member until the end of the loan period. The copy must be available,
+// or on hold for the member. The copy is locked for the duration of the transaction, so two
+// checkouts of the same copy cannot both succeed.
+func (s *Service) Checkout(req types.CheckoutRequest) (types.Loan, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Loan{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	var loan types.Loan
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		m, err := s.lockEligibleMember(d, req.MemberID)
+		if err != nil {
+			return err
+		}
+		if err := s.checkLoanLimit(d, m); err != nil {
+			return err
+		}
+
+		c, err := lockCopy(d, req)
+		if err != nil {
+			return err
+		}
+		// the hold is locked after the copy, in the same order as a return passing the copy on.
+		hold, err := lockOpenHold(d, c.BookID, m.ID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case c.Status == booktypes.COPY_STATUS_ON_HOLD && isReadyWith(hold, c.ID):
+		case c.Status == booktypes.COPY_STATUS_ON_HOLD:
+			return apierror.ErrConflict.WithMessage("copy is on hold")
+		case c.Status != booktypes.COPY_STATUS_AVAILABLE:
+			return apierror.ErrConflict.WithMessage(fmt.Sprintf("copy is not available: %s", c.Status))
+		}
+
+		now := time.Now()
+		loan, err = d.CreateLoan(types.Loan{
+			CopyID:       c.ID,
+			BookID:       c.BookID,
+			MemberID:     m.ID,
+			Status:       types.LOAN_STATUS_ACTIVE,
+			CheckedOutAt: now,
+			DueAt:        now.Add(s.conf.Period),
+		})
+		if err != nil {
+			return err
+		}
+		if err := d.UpdateCopyStatus(c.ID, booktypes.COPY_STATUS_ON_LOAN); err != nil {
+			return err
+		}
+
+		// the hold of the member is fulfilled by the copy kept for them or by any other copy
+		// while they are still waiting.
+		if hold != nil && (hold.Status == types.HOLD_STATUS_WAITING || isReadyWith(hold, c.ID)) {
+			hold.Status = types.HOLD_STATUS_FULFILLED
+			hold.ClosedAt = &now
+			return d.UpdateHold(hold)
+		}
+		return nil
+	})
+	if err != nil {
+		return types.Loan{}, err
+	}
+
+	return loan, nil
+}
+
+// Return closes a loan, charges the member a fine if the copy is late, and passes the copy on to
+// the next hold of the book, if any, or makes it available again
+func (s *Service) Return(id int) (types.Loan, error) {
+	loan, err := s.GetLoan(id)
+	if err != nil {
+		return types.Loan{}, err
+	}
+
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		// the copy is locked before the loan, in the same order as a checkout.
+		if _, err := d.LockCopy(loan.CopyID); err != nil {
+			return err
+		}
+		if loan, err = d.LockLoan(id); err != nil {
+			return err
+		}
+		if loan.Status == types.LOAN_STATUS_RETURNED {
+			return apierror.ErrConflict.WithMessage("loan is already returned")
+		}
+
+		now := time.Now()
+		loan.Status = types.LOAN_STATUS_RETURNED
+		loan.ReturnedAt = &now
+		loan.Fine = s.lateFine(loan.DueAt, now)
+		if err := d.UpdateLoan(loan); err != nil {
+			return err
+		}
+		if loan.Fine > 0 {
+			if _, err := d.CreateAccountEntry(types.AccountEntry{
+				MemberID: loan.MemberID,
+				LoanID:   &loan.ID,
+				Kind:     types.ENTRY_KIND_FINE,
+				Amount:   loan.Fine,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return s.passCopy(d, loan.BookID, loan.CopyID, now)
+	})
+	if err != nil {
+		return types.Loan{}, err
+	}
+
+	return *loan, nil
+}
+
+// Renew extends an active loan by a loan period from now, up to the maximum number of renewals,
+// if the member may still borrow. Overdue loans must be returned instead, including the ones past
+// their due date not marked yet by the overdue check.
+func (s *Service) Renew(id int) (types.Loan, error) {
+	now := time.Now()
+	var loan *types.Loan
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		var err error
+		loan, err = d.LockLoan(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NewNotFoundError("loan not found")
+		} else if err != nil {
+			return err
+		}
+
+		switch loan.Status {
+		case types.LOAN_STATUS_RETURNED:
+			return apierror.ErrConflict.WithMessage("loan is already returned")
+		case types.LOAN_STATUS_OVERDUE:
+			return apierror.ErrConflict.WithMessage("loan is overdue")
+		}
+		if loan.DueAt.Before(now) {
+			return apierror.ErrConflict.WithMessage("loan is overdue")
+		}
+		if loan.Renewals >= s.conf.MaxRenewals {
+			return apierror.ErrConflict.WithMessage(fmt.Sprintf("renewal limit reached: %d", s.conf.MaxRenewals))
+		}
+		if _, err := s.lockEligibleMember(d, loan.MemberID); err != nil {
+			return err
+		}
+
+		loan.Renewals++
+		loan.DueAt = now.Add(s.conf.Period)
+		return d.UpdateLoan(loan)
+	})
+	if err != nil {
+		return types.Loan{}, err
+	}
+
+	return *loan, nil
+}
+
+// GetLoans returns the loans matching the filter, the latest first
+func (s *Service) GetLoans(filter types.LoanFilter, page int, limit int) (types.GetLoansResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		return types.GetLoansResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid status: %s", filter.Status))
+	}
+
+	pagination, loans, err := s.dataProvider.GetLoans(filter, page, limit)
+	if err != nil {
+		return types.GetLoansResponse{}, err
+	}
+
+	return types.GetLoansResponse{
+		Loans:      loans,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetLoan returns a loan from the given id
+func (s *Service) GetLoan(id int) (*types.Loan, error) {
+	loan, err := s.dataProvider.GetLoan(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("loan not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return loan, nil
+}
+
+// FlagOverdueLoans flags the active loans due before now as overdue and returns their number
+func (s *Service) FlagOverdueLoans(now time.Time) (int64, error) {
+	return s.dataProvider.FlagOverdueLoans(now)
+}
+
+// checkEmailAvailable checks that no other member has the email of the member
+func (s *Service) checkEmailAvailable(m types.Member) error {
+	existing, err := s.dataProvider.GetMemberByEmail(m.Email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if existing.ID != m.ID {
+		return apierror.NewConflictError(fmt.Sprintf("member already exists: %d", existing.ID), existing.ID)
+	}
+	return nil
+}
+
+// emailConflict returns the conflict with the member holding the email of the member if err is a
+// duplicated key, as when another member with the email is added after the email was checked.
+// Otherwise it returns err.
+func (s *Service) emailConflict(err error, m types.Member) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	if conflict := s.checkEmailAvailable(m); conflict != nil {
+		return conflict
+	}
+	// the other member is gone already.
+	return apierror.ErrConflict.WithMessage(fmt.Sprintf("email already exists: %s", m.Email))
+}
+
+// lockEligibleMember locks a member who may borrow copies: an active member whose balance is
+// not over the fine limit
+func (s *Service) lockEligibleMember(d types.DataProvider, id int) (*types.Member, error) {
+	m, err := d.LockMember(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("member not found: %d", id))
+	} else if err != nil {
+		return nil, err
+	}
+	if m.Status != types.MEMBER_STATUS_ACTIVE {
+		return nil, apierror.ErrConflict.WithMessage(fmt.Sprintf("member is not active: %d", m.ID))
+	}
+
+	balance, _, err := s.balance(d, m.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if balance > s.conf.Fines.Limit {
+		return nil, apierror.ErrPaymentRequired.WithMessage(fmt.Sprintf(
+			"member is blocked by unpaid fines: balance %d is over the limit of %d %s",
+			balance, s.conf.Fines.Limit, s.conf.Fines.Currency,
+		))
+	}
+
+	return m, nil
+}
+
+// checkLoanLimit checks that a locked member has fewer loans than their tier allows
+func (s *Service) checkLoanLimit(d types.DataProvider, m *types.Member) error {
+	tier, ok := s.conf.Tiers[m.Tier]
+	if !ok {
+		// the tier of the member is no longer configured.
+		tier = s.conf.Tiers[s.conf.DefaultTier]
+	}
+	if tier.MaxLoans <= 0 {
+		return nil
+	}
+
+	count, err := d.CountOpenLoans(m.ID)
+	if err != nil {
+		return err
+	}
+	if count >= int64(tier.MaxLoans) {
+		return apierror.ErrConflict.WithMessage(fmt.Sprintf("loan limit reached: %d", tier.MaxLoans))
+	}
+
+	return nil
+}
+
+// checkTier checks that the membership tier is configured
+func (s *Service) checkTier(tier string) error {
+	if _, ok := s.conf.Tiers[tier]; !ok {
+		return apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("unknown tier: %s", tier))
+	}
+
+	return nil
+}
+
+// lockCopy locks the copy of a checkout request, given by id or else by barcode
+func lockCopy(d types.DataProvider, req types.CheckoutRequest) (*inventorytypes.Copy, error) {
+	var (
+		c   *inventorytypes.Copy
+		err error
+	)
+	if req.CopyID != 0 {
+		c, err = d.LockCopy(req.CopyID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("copy not found: %d", req.CopyID))
+		}
+	} else {
+		barcode := inventorytypes.NormalizeBarcode(req.Barcode)
+		c, err = d.LockCopyByBarcode(barcode)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("copy not found: %s", barcode))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
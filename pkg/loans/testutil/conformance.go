@@ -0,0 +1,571 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// Fixtures creates the books and the copies the loans and the holds are for, in the database of
+// the data provider under test.
+type Fixtures struct {
+	Books  booktypes.DataProvider
+	Copies inventorytypes.DataProvider
+}
+
+// RunDataProviderTests checks that a data provider behaves as the loans service expects.
+// newDataProvider returns an empty data provider for every test, along with the fixtures of the
+// same database. ctx is the context of the requests given to the data providers.
+func RunDataProviderTests(t *testing.T, ctx context.Context, newDataProvider func(t *testing.T) (types.DataProvider, Fixtures)) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures)
+	}{
+		{"create and get member", testCreateAndGetMember},
+		{"duplicate email", testDuplicateEmail},
+		{"search members", testSearchMembers},
+		{"account entries", testAccountEntries},
+		{"lock copies", testLockCopies},
+		{"loans", testLoans},
+		{"one open loan per copy", testOpenLoanPerCopy},
+		{"flag overdue loans", testFlagOverdueLoans},
+		{"due loans", testDueLoans},
+		{"hold queue", testHoldQueue},
+		{"one open hold per member", testOpenHoldPerMember},
+		{"expired holds", testExpiredHolds},
+		{"rollback transaction", testRollbackTransaction},
+		{"tenant isolation", testTenantIsolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, f := newDataProvider(t)
+			tt.test(t, ctx, d, f)
+		})
+	}
+}
+
+// createBook creates a book with a copy of every barcode.
+func (f Fixtures) createBook(t *testing.T, barcodes ...string) (booktypes.Book, []inventorytypes.Copy) {
+	book, err := f.Books.CreateBook(booktypes.Book{Title: "title", Author: "author", Description: "description"})
+	require.NoError(t, err)
+
+	copies := make([]inventorytypes.Copy, 0, len(barcodes))
+	for _, barcode := range barcodes {
+		c, err := f.Copies.CreateCopy(inventorytypes.Copy{
+			BookID:    book.ID,
+			Barcode:   barcode,
+			Branch:    "Central",
+			Condition: inventorytypes.COPY_CONDITION_GOOD,
+			Status:    booktypes.COPY_STATUS_AVAILABLE,
+		})
+		require.NoError(t, err)
+		copies = append(copies, c)
+	}
+
+	return book, copies
+}
+
+// createMembers creates the members numbered from 1 to n.
+func createMembers(t *testing.T, d types.DataProvider, n int) []types.Member {
+	members := make([]types.Member, 0, n)
+	for i := 1; i <= n; i++ {
+		member, err := d.CreateMember(types.Member{
+			Name:   fmt.Sprintf("member-%d", i),
+			Email:  fmt.Sprintf("member-%d@example.com", i),
+			Tier:   "standard",
+			Status: types.MEMBER_STATUS_ACTIVE,
+		})
+		require.NoError(t, err)
+		members = append(members, member)
+	}
+
+	return members
+}
+
+// newLoan returns an active loan of the copy due at dueAt.
+func newLoan(c inventorytypes.Copy, member types.Member, dueAt time.Time) types.Loan {
+	return types.Loan{
+		CopyID:       c.ID,
+		BookID:       c.BookID,
+		MemberID:     member.ID,
+		Status:       types.LOAN_STATUS_ACTIVE,
+		CheckedOutAt: dueAt.Add(-14 * 24 * time.Hour),
+		DueAt:        dueAt,
+	}
+}
+
+// loanIDs returns the ids of the loans in their order.
+func loanIDs(loans []types.Loan) []int {
+	ids := []int{}
+	for _, loan := range loans {
+		ids = append(ids, loan.ID)
+	}
+
+	return ids
+}
+
+// holdIDs returns the ids of the holds in their order.
+func holdIDs(holds []types.Hold) []int {
+	ids := []int{}
+	for _, hold := range holds {
+		ids = append(ids, hold.ID)
+	}
+
+	return ids
+}
+
+func testCreateAndGetMember(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 1)
+
+	got, err := d.GetMember(members[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, "member-1", got.Name)
+	require.Equal(t, types.MEMBER_STATUS_ACTIVE, got.Status)
+
+	got, err = d.GetMemberByEmail("member-1@example.com")
+	require.NoError(t, err)
+	require.Equal(t, members[0].ID, got.ID)
+
+	got.Status = types.MEMBER_STATUS_SUSPENDED
+	require.NoError(t, d.UpdateMember(got))
+
+	err = d.Transaction(func(d types.DataProvider) error {
+		locked, err := d.LockMember(members[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, types.MEMBER_STATUS_SUSPENDED, locked.Status)
+		return nil
+	})
+	require.NoError(t, err)
+
+	_, err = d.GetMember(members[0].ID + 1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = d.GetMemberByEmail("nobody@example.com")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testDuplicateEmail(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 1)
+
+	member := members[0]
+	member.ID = 0
+	_, err := d.CreateMember(member)
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+}
+
+func testSearchMembers(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 3)
+	jane, err := d.CreateMember(types.Member{Name: "Jane Doe", Email: "jane@library.org", Tier: "standard", Status: types.MEMBER_STATUS_ACTIVE})
+	require.NoError(t, err)
+
+	p, found, err := d.GetMembers("", 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), p.TotalRows)
+	require.Len(t, found, 4)
+
+	_, found, err = d.GetMembers("JANE", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, jane.ID, found[0].ID)
+
+	_, found, err = d.GetMembers("example.com", 2, 2)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, members[2].ID, found[0].ID)
+}
+
+func testAccountEntries(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 2)
+
+	balance, err := d.GetBalance(members[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, balance)
+
+	var entries []types.AccountEntry
+	for _, amount := range []int64{75, 25, -60} {
+		kind := types.ENTRY_KIND_FINE
+		if amount < 0 {
+			kind = types.ENTRY_KIND_PAYMENT
+		}
+		entry, err := d.CreateAccountEntry(types.AccountEntry{MemberID: members[0].ID, Kind: kind, Amount: amount})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	_, err = d.CreateAccountEntry(types.AccountEntry{MemberID: members[1].ID, Kind: types.ENTRY_KIND_FINE, Amount: 10})
+	require.NoError(t, err)
+
+	balance, err = d.GetBalance(members[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), balance)
+
+	// the latest entries come first.
+	p, got, err := d.GetAccountEntries(members[0].ID, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), p.TotalRows)
+	require.Len(t, got, 2)
+	require.Equal(t, entries[2].ID, got[0].ID)
+	require.Equal(t, entries[1].ID, got[1].ID)
+}
+
+func testLockCopies(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	book, copies := f.createBook(t, "LIB000001", "LIB000002")
+	f.createBook(t, "LIB000003")
+
+	exists, err := d.BookExists(book.ID)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	err = d.Transaction(func(d types.DataProvider) error {
+		locked, err := d.LockBookCopies(book.ID)
+		require.NoError(t, err)
+		require.Len(t, locked, 2)
+		require.Equal(t, copies[0].ID, locked[0].ID)
+		require.Equal(t, copies[1].ID, locked[1].ID)
+
+		c, err := d.LockCopy(copies[1].ID)
+		require.NoError(t, err)
+		require.Equal(t, "LIB000002", c.Barcode)
+
+		c, err = d.LockCopyByBarcode("LIB000001")
+		require.NoError(t, err)
+		require.Equal(t, copies[0].ID, c.ID)
+
+		return d.UpdateCopyStatus(c.ID, booktypes.COPY_STATUS_ON_LOAN)
+	})
+	require.NoError(t, err)
+
+	c, err := d.LockCopy(copies[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, booktypes.COPY_STATUS_ON_LOAN, c.Status)
+
+	_, err = d.LockCopyByBarcode("LIB999999")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testLoans(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 2)
+	book, copies := f.createBook(t, "LIB000001", "LIB000002")
+	dueAt := time.Now().Add(14 * 24 * time.Hour)
+
+	first, err := d.CreateLoan(newLoan(copies[0], members[0], dueAt))
+	require.NoError(t, err)
+	second, err := d.CreateLoan(newLoan(copies[1], members[1], dueAt))
+	require.NoError(t, err)
+
+	count, err := d.CountOpenLoans(members[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	returnedAt := time.Now()
+	first.Status = types.LOAN_STATUS_RETURNED
+	first.ReturnedAt = &returnedAt
+	require.NoError(t, d.UpdateLoan(&first))
+
+	got, err := d.GetLoan(first.ID)
+	require.NoError(t, err)
+	require.Equal(t, types.LOAN_STATUS_RETURNED, got.Status)
+	require.NotNil(t, got.ReturnedAt)
+
+	count, err = d.CountOpenLoans(members[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	err = d.Transaction(func(d types.DataProvider) error {
+		locked, err := d.LockLoan(second.ID)
+		require.NoError(t, err)
+		require.Equal(t, copies[1].ID, locked.CopyID)
+		return nil
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		filter types.LoanFilter
+		ids    []int
+	}{
+		{
+			name: "latest first",
+			ids:  []int{second.ID, first.ID},
+		},
+		{
+			name:   "member",
+			filter: types.LoanFilter{MemberID: members[0].ID},
+			ids:    []int{first.ID},
+		},
+		{
+			name:   "book and status",
+			filter: types.LoanFilter{BookID: book.ID, Status: types.LOAN_STATUS_ACTIVE},
+			ids:    []int{second.ID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, loans, err := d.GetLoans(tc.filter, 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.ids)), p.TotalRows)
+			require.Equal(t, tc.ids, loanIDs(loans))
+		})
+	}
+
+	_, err = d.GetLoan(second.ID + 1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testOpenLoanPerCopy(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 2)
+	_, copies := f.createBook(t, "LIB000001")
+	dueAt := time.Now().Add(14 * 24 * time.Hour)
+
+	loan, err := d.CreateLoan(newLoan(copies[0], members[0], dueAt))
+	require.NoError(t, err)
+
+	_, err = d.CreateLoan(newLoan(copies[0], members[1], dueAt))
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+
+	// the copy is lent again once returned.
+	returnedAt := time.Now()
+	loan.Status = types.LOAN_STATUS_RETURNED
+	loan.ReturnedAt = &returnedAt
+	require.NoError(t, d.UpdateLoan(&loan))
+
+	_, err = d.CreateLoan(newLoan(copies[0], members[1], dueAt))
+	require.NoError(t, err)
+}
+
+func testFlagOverdueLoans(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 1)
+	_, copies := f.createBook(t, "LIB000001", "LIB000002", "LIB000003")
+	now := time.Now()
+
+	late, err := d.CreateLoan(newLoan(copies[0], members[0], now.Add(-time.Hour)))
+	require.NoError(t, err)
+	onTime, err := d.CreateLoan(newLoan(copies[1], members[0], now.Add(time.Hour)))
+	require.NoError(t, err)
+	returned := newLoan(copies[2], members[0], now.Add(-time.Hour))
+	returned.Status = types.LOAN_STATUS_RETURNED
+	returned.ReturnedAt = &now
+	returned, err = d.CreateLoan(returned)
+	require.NoError(t, err)
+
+	flagged, err := d.FlagOverdueLoans(now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), flagged)
+
+	for id, status := range map[int]types.LoanStatus{
+		late.ID:     types.LOAN_STATUS_OVERDUE,
+		onTime.ID:   types.LOAN_STATUS_ACTIVE,
+		returned.ID: types.LOAN_STATUS_RETURNED,
+	} {
+		got, err := d.GetLoan(id)
+		require.NoError(t, err)
+		require.Equal(t, status, got.Status)
+	}
+
+	flagged, err = d.FlagOverdueLoans(now)
+	require.NoError(t, err)
+	require.Zero(t, flagged)
+}
+
+func testDueLoans(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 2)
+	_, copies := f.createBook(t, "LIB000001", "LIB000002", "LIB000003", "LIB000004")
+	now := time.Now()
+
+	late, err := d.CreateLoan(newLoan(copies[0], members[0], now.Add(-time.Hour)))
+	require.NoError(t, err)
+	_, err = d.CreateLoan(newLoan(copies[1], members[0], now.Add(time.Hour)))
+	require.NoError(t, err)
+	returned := newLoan(copies[2], members[0], now.Add(-time.Hour))
+	returned.Status = types.LOAN_STATUS_RETURNED
+	returned.ReturnedAt = &now
+	_, err = d.CreateLoan(returned)
+	require.NoError(t, err)
+	_, err = d.CreateLoan(newLoan(copies[3], members[1], now.Add(-time.Hour)))
+	require.NoError(t, err)
+
+	loans, err := d.GetDueLoans(members[0].ID, now)
+	require.NoError(t, err)
+	require.Len(t, loans, 1)
+	require.Equal(t, late.ID, loans[0].ID)
+}
+
+func testHoldQueue(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 3)
+	book, _ := f.createBook(t)
+	other, _ := f.createBook(t)
+
+	var holds []types.Hold
+	for _, member := range members {
+		hold, err := d.CreateHold(types.Hold{BookID: book.ID, MemberID: member.ID, Status: types.HOLD_STATUS_WAITING})
+		require.NoError(t, err)
+		holds = append(holds, hold)
+	}
+	otherHold, err := d.CreateHold(types.Hold{BookID: other.ID, MemberID: members[0].ID, Status: types.HOLD_STATUS_WAITING})
+	require.NoError(t, err)
+
+	position, err := d.GetHoldPosition(holds[2])
+	require.NoError(t, err)
+	require.Equal(t, 3, position)
+
+	got, err := d.GetOpenHold(book.ID, members[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, holds[1].ID, got.ID)
+
+	// the first waiting hold is the next one, and the holds behind it move up once it is ready.
+	err = d.Transaction(func(d types.DataProvider) error {
+		next, err := d.LockNextHold(book.ID)
+		require.NoError(t, err)
+		require.Equal(t, holds[0].ID, next.ID)
+
+		readyAt := time.Now()
+		next.Status = types.HOLD_STATUS_READY
+		next.ReadyAt = &readyAt
+		return d.UpdateHold(next)
+	})
+	require.NoError(t, err)
+
+	position, err = d.GetHoldPosition(holds[2])
+	require.NoError(t, err)
+	require.Equal(t, 2, position)
+
+	locked, err := d.LockHold(holds[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, types.HOLD_STATUS_READY, locked.Status)
+
+	testCases := []struct {
+		name   string
+		filter types.HoldFilter
+		ids    []int
+	}{
+		{
+			name: "in the order placed",
+			ids:  []int{holds[0].ID, holds[1].ID, holds[2].ID, otherHold.ID},
+		},
+		{
+			name:   "member",
+			filter: types.HoldFilter{MemberID: members[0].ID},
+			ids:    []int{holds[0].ID, otherHold.ID},
+		},
+		{
+			name:   "book and status",
+			filter: types.HoldFilter{BookID: book.ID, Status: types.HOLD_STATUS_WAITING},
+			ids:    []int{holds[1].ID, holds[2].ID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, got, err := d.GetHolds(tc.filter, 1, 10)
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tc.ids)), p.TotalRows)
+			require.Equal(t, tc.ids, holdIDs(got))
+		})
+	}
+
+	_, err = d.GetHold(otherHold.ID + 1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = d.LockNextHold(other.ID + 1)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
+
+func testOpenHoldPerMember(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 1)
+	book, _ := f.createBook(t)
+
+	hold, err := d.CreateHold(types.Hold{BookID: book.ID, MemberID: members[0].ID, Status: types.HOLD_STATUS_WAITING})
+	require.NoError(t, err)
+
+	_, err = d.CreateHold(types.Hold{BookID: book.ID, MemberID: members[0].ID, Status: types.HOLD_STATUS_WAITING})
+	require.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+
+	// the book is held again once the hold is closed.
+	closedAt := time.Now()
+	hold.Status = types.HOLD_STATUS_CANCELLED
+	hold.ClosedAt = &closedAt
+	require.NoError(t, d.UpdateHold(&hold))
+
+	_, err = d.GetOpenHold(book.ID, members[0].ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = d.CreateHold(types.Hold{BookID: book.ID, MemberID: members[0].ID, Status: types.HOLD_STATUS_WAITING})
+	require.NoError(t, err)
+}
+
+func testExpiredHolds(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 3)
+	book, _ := f.createBook(t)
+	now := time.Now()
+
+	var holds []types.Hold
+	for i, expiresAt := range []time.Time{now.Add(-time.Hour), now.Add(time.Hour), now.Add(-time.Hour)} {
+		hold := types.Hold{BookID: book.ID, MemberID: members[i].ID, Status: types.HOLD_STATUS_READY, ExpiresAt: &expiresAt}
+		if i == 2 {
+			hold.Status = types.HOLD_STATUS_FULFILLED
+			hold.ClosedAt = &now
+		}
+		hold, err := d.CreateHold(hold)
+		require.NoError(t, err)
+		holds = append(holds, hold)
+	}
+
+	expired, err := d.GetExpiredHolds(now)
+	require.NoError(t, err)
+	require.Equal(t, []int{holds[0].ID}, holdIDs(expired))
+}
+
+func testRollbackTransaction(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 1)
+	errRollback := errors.New("rollback")
+
+	err := d.Transaction(func(d types.DataProvider) error {
+		_, err := d.CreateAccountEntry(types.AccountEntry{MemberID: members[0].ID, Kind: types.ENTRY_KIND_FINE, Amount: 75})
+		require.NoError(t, err)
+
+		balance, err := d.GetBalance(members[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(75), balance)
+
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+
+	balance, err := d.GetBalance(members[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, balance)
+}
+
+func testTenantIsolation(t *testing.T, ctx context.Context, d types.DataProvider, f Fixtures) {
+	members := createMembers(t, d, 1)
+	_, copies := f.createBook(t, "LIB000001")
+	loan, err := d.CreateLoan(newLoan(copies[0], members[0], time.Now().Add(-time.Hour)))
+	require.NoError(t, err)
+
+	other := d.WithContext(tenant.NewContext(context.Background(), "other-tenant"))
+	_, err = other.GetMember(members[0].ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = other.GetLoan(loan.ID)
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = other.LockCopyByBarcode("LIB000001")
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// the overdue loans of the other tenants are not flagged.
+	flagged, err := other.FlagOverdueLoans(time.Now())
+	require.NoError(t, err)
+	require.Zero(t, flagged)
+
+	// an email is used once per tenant.
+	member := members[0]
+	member.ID = 0
+	_, err = other.CreateMember(member)
+	require.NoError(t, err)
+}
@@ -0,0 +1,538 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/loans/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/loans/testutil/mock_data_provider.go --source ./pkg/loans/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	types0 "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+	types1 "github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// BookExists mocks base method.
+func (m *MockDataProvider) BookExists(bookID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookExists", bookID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookExists indicates an expected call of BookExists.
+func (mr *MockDataProviderMockRecorder) BookExists(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookExists", reflect.TypeOf((*MockDataProvider)(nil).BookExists), bookID)
+}
+
+// CountOpenLoans mocks base method.
+func (m *MockDataProvider) CountOpenLoans(memberID int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOpenLoans", memberID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOpenLoans indicates an expected call of CountOpenLoans.
+func (mr *MockDataProviderMockRecorder) CountOpenLoans(memberID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenLoans", reflect.TypeOf((*MockDataProvider)(nil).CountOpenLoans), memberID)
+}
+
+// CreateAccountEntry mocks base method.
+func (m *MockDataProvider) CreateAccountEntry(entry types1.AccountEntry) (types1.AccountEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAccountEntry", entry)
+	ret0, _ := ret[0].(types1.AccountEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAccountEntry indicates an expected call of CreateAccountEntry.
+func (mr *MockDataProviderMockRecorder) CreateAccountEntry(entry any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAccountEntry", reflect.TypeOf((*MockDataProvider)(nil).CreateAccountEntry), entry)
+}
+
+// CreateHold mocks base method.
+func (m *MockDataProvider) CreateHold(hold types1.Hold) (types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHold", hold)
+	ret0, _ := ret[0].(types1.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockDataProviderMockRecorder) CreateHold(hold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockDataProvider)(nil).CreateHold), hold)
+}
+
+// CreateLoan mocks base method.
+func (m *MockDataProvider) CreateLoan(loan types1.Loan) (types1.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateLoan", loan)
+	ret0, _ := ret[0].(types1.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateLoan indicates an expected call of CreateLoan.
+func (mr *MockDataProviderMockRecorder) CreateLoan(loan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateLoan", reflect.TypeOf((*MockDataProvider)(nil).CreateLoan), loan)
+}
+
+// CreateMember mocks base method.
+func (m *MockDataProvider) CreateMember(member types1.Member) (types1.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateMember", member)
+	ret0, _ := ret[0].(types1.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateMember indicates an expected call of CreateMember.
+func (mr *MockDataProviderMockRecorder) CreateMember(member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMember", reflect.TypeOf((*MockDataProvider)(nil).CreateMember), member)
+}
+
+// FlagOverdueLoans mocks base method.
+func (m *MockDataProvider) FlagOverdueLoans(now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlagOverdueLoans", now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlagOverdueLoans indicates an expected call of FlagOverdueLoans.
+func (mr *MockDataProviderMockRecorder) FlagOverdueLoans(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlagOverdueLoans", reflect.TypeOf((*MockDataProvider)(nil).FlagOverdueLoans), now)
+}
+
+// GetAccountEntries mocks base method.
+func (m *MockDataProvider) GetAccountEntries(memberID, page, limit int) (*db.Pagination, []types1.AccountEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAccountEntries", memberID, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types1.AccountEntry)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAccountEntries indicates an expected call of GetAccountEntries.
+func (mr *MockDataProviderMockRecorder) GetAccountEntries(memberID, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAccountEntries", reflect.TypeOf((*MockDataProvider)(nil).GetAccountEntries), memberID, page, limit)
+}
+
+// GetBalance mocks base method.
+func (m *MockDataProvider) GetBalance(memberID int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", memberID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockDataProviderMockRecorder) GetBalance(memberID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockDataProvider)(nil).GetBalance), memberID)
+}
+
+// GetDueLoans mocks base method.
+func (m *MockDataProvider) GetDueLoans(memberID int, before time.Time) ([]types1.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDueLoans", memberID, before)
+	ret0, _ := ret[0].([]types1.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDueLoans indicates an expected call of GetDueLoans.
+func (mr *MockDataProviderMockRecorder) GetDueLoans(memberID, before any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDueLoans", reflect.TypeOf((*MockDataProvider)(nil).GetDueLoans), memberID, before)
+}
+
+// GetExpiredHolds mocks base method.
+func (m *MockDataProvider) GetExpiredHolds(now time.Time) ([]types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredHolds", now)
+	ret0, _ := ret[0].([]types1.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredHolds indicates an expected call of GetExpiredHolds.
+func (mr *MockDataProviderMockRecorder) GetExpiredHolds(now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredHolds", reflect.TypeOf((*MockDataProvider)(nil).GetExpiredHolds), now)
+}
+
+// GetHold mocks base method.
+func (m *MockDataProvider) GetHold(id int) (*types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", id)
+	ret0, _ := ret[0].(*types1.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockDataProviderMockRecorder) GetHold(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockDataProvider)(nil).GetHold), id)
+}
+
+// GetHoldPosition mocks base method.
+func (m *MockDataProvider) GetHoldPosition(hold types1.Hold) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHoldPosition", hold)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHoldPosition indicates an expected call of GetHoldPosition.
+func (mr *MockDataProviderMockRecorder) GetHoldPosition(hold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHoldPosition", reflect.TypeOf((*MockDataProvider)(nil).GetHoldPosition), hold)
+}
+
+// GetHolds mocks base method.
+func (m *MockDataProvider) GetHolds(filter types1.HoldFilter, page, limit int) (*db.Pagination, []types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHolds", filter, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types1.Hold)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetHolds indicates an expected call of GetHolds.
+func (mr *MockDataProviderMockRecorder) GetHolds(filter, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHolds", reflect.TypeOf((*MockDataProvider)(nil).GetHolds), filter, page, limit)
+}
+
+// GetLoan mocks base method.
+func (m *MockDataProvider) GetLoan(id int) (*types1.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoan", id)
+	ret0, _ := ret[0].(*types1.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoan indicates an expected call of GetLoan.
+func (mr *MockDataProviderMockRecorder) GetLoan(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoan", reflect.TypeOf((*MockDataProvider)(nil).GetLoan), id)
+}
+
+// GetLoans mocks base method.
+func (m *MockDataProvider) GetLoans(filter types1.LoanFilter, page, limit int) (*db.Pagination, []types1.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoans", filter, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types1.Loan)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetLoans indicates an expected call of GetLoans.
+func (mr *MockDataProviderMockRecorder) GetLoans(filter, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoans", reflect.TypeOf((*MockDataProvider)(nil).GetLoans), filter, page, limit)
+}
+
+// GetMember mocks base method.
+func (m *MockDataProvider) GetMember(id int) (*types1.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMember", id)
+	ret0, _ := ret[0].(*types1.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMember indicates an expected call of GetMember.
+func (mr *MockDataProviderMockRecorder) GetMember(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMember", reflect.TypeOf((*MockDataProvider)(nil).GetMember), id)
+}
+
+// GetMemberByEmail mocks base method.
+func (m *MockDataProvider) GetMemberByEmail(email string) (*types1.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMemberByEmail", email)
+	ret0, _ := ret[0].(*types1.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMemberByEmail indicates an expected call of GetMemberByEmail.
+func (mr *MockDataProviderMockRecorder) GetMemberByEmail(email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMemberByEmail", reflect.TypeOf((*MockDataProvider)(nil).GetMemberByEmail), email)
+}
+
+// GetMembers mocks base method.
+func (m *MockDataProvider) GetMembers(query string, page, limit int) (*db.Pagination, []types1.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMembers", query, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types1.Member)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetMembers indicates an expected call of GetMembers.
+func (mr *MockDataProviderMockRecorder) GetMembers(query, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMembers", reflect.TypeOf((*MockDataProvider)(nil).GetMembers), query, page, limit)
+}
+
+// GetOpenHold mocks base method.
+func (m *MockDataProvider) GetOpenHold(bookID, memberID int) (*types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOpenHold", bookID, memberID)
+	ret0, _ := ret[0].(*types1.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOpenHold indicates an expected call of GetOpenHold.
+func (mr *MockDataProviderMockRecorder) GetOpenHold(bookID, memberID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOpenHold", reflect.TypeOf((*MockDataProvider)(nil).GetOpenHold), bookID, memberID)
+}
+
+// LockBookCopies mocks base method.
+func (m *MockDataProvider) LockBookCopies(bookID int) ([]types0.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBookCopies", bookID)
+	ret0, _ := ret[0].([]types0.Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockBookCopies indicates an expected call of LockBookCopies.
+func (mr *MockDataProviderMockRecorder) LockBookCopies(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBookCopies", reflect.TypeOf((*MockDataProvider)(nil).LockBookCopies), bookID)
+}
+
+// LockCopy mocks base method.
+func (m *MockDataProvider) LockCopy(id int) (*types0.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockCopy", id)
+	ret0, _ := ret[0].(*types0.Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockCopy indicates an expected call of LockCopy.
+func (mr *MockDataProviderMockRecorder) LockCopy(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockCopy", reflect.TypeOf((*MockDataProvider)(nil).LockCopy), id)
+}
+
+// LockCopyByBarcode mocks base method.
+func (m *MockDataProvider) LockCopyByBarcode(barcode string) (*types0.Copy, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockCopyByBarcode", barcode)
+	ret0, _ := ret[0].(*types0.Copy)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockCopyByBarcode indicates an expected call of LockCopyByBarcode.
+func (mr *MockDataProviderMockRecorder) LockCopyByBarcode(barcode any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockCopyByBarcode", reflect.TypeOf((*MockDataProvider)(nil).LockCopyByBarcode), barcode)
+}
+
+// LockHold mocks base method.
+func (m *MockDataProvider) LockHold(id int) (*types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockHold", id)
+	ret0, _ := ret[0].(*types1.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockHold indicates an expected call of LockHold.
+func (mr *MockDataProviderMockRecorder) LockHold(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockHold", reflect.TypeOf((*MockDataProvider)(nil).LockHold), id)
+}
+
+// LockLoan mocks base method.
+func (m *MockDataProvider) LockLoan(id int) (*types1.Loan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockLoan", id)
+	ret0, _ := ret[0].(*types1.Loan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockLoan indicates an expected call of LockLoan.
+func (mr *MockDataProviderMockRecorder) LockLoan(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLoan", reflect.TypeOf((*MockDataProvider)(nil).LockLoan), id)
+}
+
+// LockMember mocks base method.
+func (m *MockDataProvider) LockMember(id int) (*types1.Member, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockMember", id)
+	ret0, _ := ret[0].(*types1.Member)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockMember indicates an expected call of LockMember.
+func (mr *MockDataProviderMockRecorder) LockMember(id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockMember", reflect.TypeOf((*MockDataProvider)(nil).LockMember), id)
+}
+
+// LockNextHold mocks base method.
+func (m *MockDataProvider) LockNextHold(bookID int) (*types1.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockNextHold", bookID)
+	ret0, _ := ret[0].(*types1.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockNextHold indicates an expected call of LockNextHold.
+func (mr *MockDataProviderMockRecorder) LockNextHold(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockNextHold", reflect.TypeOf((*MockDataProvider)(nil).LockNextHold), bookID)
+}
+
+// Transaction mocks base method.
+func (m *MockDataProvider) Transaction(fn func(types1.DataProvider) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataProviderMockRecorder) Transaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataProvider)(nil).Transaction), fn)
+}
+
+// UpdateCopyStatus mocks base method.
+func (m *MockDataProvider) UpdateCopyStatus(id int, status types.CopyStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCopyStatus", id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCopyStatus indicates an expected call of UpdateCopyStatus.
+func (mr *MockDataProviderMockRecorder) UpdateCopyStatus(id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCopyStatus", reflect.TypeOf((*MockDataProvider)(nil).UpdateCopyStatus), id, status)
+}
+
+// UpdateHold mocks base method.
+func (m *MockDataProvider) UpdateHold(hold *types1.Hold) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHold", hold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHold indicates an expected call of UpdateHold.
+func (mr *MockDataProviderMockRecorder) UpdateHold(hold any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHold", reflect.TypeOf((*MockDataProvider)(nil).UpdateHold), hold)
+}
+
+// UpdateLoan mocks base method.
+func (m *MockDataProvider) UpdateLoan(loan *types1.Loan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateLoan", loan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateLoan indicates an expected call of UpdateLoan.
+func (mr *MockDataProviderMockRecorder) UpdateLoan(loan any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLoan", reflect.TypeOf((*MockDataProvider)(nil).UpdateLoan), loan)
+}
+
+// UpdateMember mocks base method.
+func (m *MockDataProvider) UpdateMember(member *types1.Member) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMember", member)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMember indicates an expected call of UpdateMember.
+func (mr *MockDataProviderMockRecorder) UpdateMember(member any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMember", reflect.TypeOf((*MockDataProvider)(nil).UpdateMember), member)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types1.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types1.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
@@ -0,0 +1,56 @@
+package testutil
+
+import (
+	"testing"
+	"time"
+
+	gomock "go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/loans/types"
+)
+
+// TestConfig is the configuration of the loans service of the test suite.
+var TestConfig = config.Loans{
+	Period:               14 * 24 * time.Hour,
+	MaxRenewals:          2,
+	OverdueCheckInterval: time.Hour,
+	HoldPickupPeriod:     3 * 24 * time.Hour,
+	HoldCheckInterval:    time.Hour,
+	DefaultTier:          "standard",
+	Tiers:                map[string]config.Tier{"standard": {MaxLoans: 2}, "premium": {MaxLoans: 10}},
+	Fines:                config.Fines{Currency: "USD", DailyRate: 25, GracePeriod: 24 * time.Hour, MaxPerLoan: 500, Limit: 1000},
+}
+
+type TestSuite struct {
+	Handler    *service.Handler
+	Service    *service.Service
+	Repository *MockDataProvider
+	Logger     *zap.Logger
+}
+
+func NewTestSuite(t *testing.T) TestSuite {
+	ctrl := gomock.NewController(t)
+
+	repo := NewMockDataProvider(ctrl)
+	repo.EXPECT().WithContext(gomock.Any()).Return(repo).AnyTimes()
+	log := zap.NewNop()
+	serv := service.NewService(repo, TestConfig, log)
+	handler := service.NewHandler(&serv, log)
+
+	return TestSuite{
+		Handler:    &handler,
+		Service:    &serv,
+		Repository: repo,
+		Logger:     log,
+	}
+}
+
+// ExpectTransaction expects a transaction that runs its function against the mock repository.
+func (s TestSuite) ExpectTransaction() *gomock.Call {
+	return s.Repository.EXPECT().Transaction(gomock.Any()).DoAndReturn(func(fn func(types.DataProvider) error) error {
+		return fn(s.Repository)
+	})
+}
@@ -0,0 +1,36 @@
+package types
+
+import "time"
+
+type EntryKind string
+
+const (
+	ENTRY_KIND_FINE    EntryKind = "fine"
+	ENTRY_KIND_PAYMENT EntryKind = "payment"
+)
+
+// AccountEntry is the model for a fine charged to a member or a payment from them. The balance
+// of a member is the sum of the amounts of their entries.
+type AccountEntry struct {
+	ID       int       `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string    `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	MemberID int       `json:"member_id" gorm:"not null;index" example:"1"`
+	LoanID   *int      `json:"loan_id,omitempty" gorm:"index" example:"1"`
+	Kind     EntryKind `json:"kind" gorm:"not null" example:"fine"`
+	// Amount is positive for a fine and negative for a payment, in the minor unit of the
+	// currency of the fines.
+	Amount    int64     `json:"amount" gorm:"not null" example:"75"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Account is the balance of the fines of a member. A member whose balance is over the limit is
+// blocked from borrowing until they settle it. The balance includes the fines accrued so far by
+// the loans past their due date, which are only charged when their copies are returned.
+type Account struct {
+	MemberID int    `json:"member_id" example:"1"`
+	Balance  int64  `json:"balance" example:"75"`
+	Accrued  int64  `json:"accrued" example:"25"`
+	Currency string `json:"currency" example:"USD"`
+	Limit    int64  `json:"limit" example:"1000"`
+	Blocked  bool   `json:"blocked" example:"false"`
+}
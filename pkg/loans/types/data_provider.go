@@ -0,0 +1,77 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	inventorytypes "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/types"
+)
+
+// DataProvider is the interface for the data provider for a loans service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	// Transaction runs fn with a data provider bound to a single database transaction.
+	// The transaction is committed if fn returns nil, otherwise it is rolled back.
+	Transaction(fn func(d DataProvider) error) error
+
+	CreateMember(member Member) (Member, error)
+	UpdateMember(member *Member) error
+	// GetMembers lists the members whose name or email contains the query.
+	GetMembers(query string, page int, limit int) (*db.Pagination, []Member, error)
+	GetMember(id int) (*Member, error)
+	GetMemberByEmail(email string) (*Member, error)
+	// LockMember retrieves a member and locks its row until the end of the transaction, so the
+	// loans and the balance of a member are checked by one request at a time.
+	LockMember(id int) (*Member, error)
+	// CountOpenLoans counts the loans of a member that are not returned.
+	CountOpenLoans(memberID int) (int64, error)
+	GetBalance(memberID int) (int64, error)
+	// GetDueLoans lists the loans of a member not returned and due before the given time.
+	GetDueLoans(memberID int, before time.Time) ([]Loan, error)
+	CreateAccountEntry(entry AccountEntry) (AccountEntry, error)
+	// GetAccountEntries lists the entries of a member, the latest first.
+	GetAccountEntries(memberID int, page int, limit int) (*db.Pagination, []AccountEntry, error)
+
+	BookExists(bookID int) (bool, error)
+	// LockBookCopies retrieves the copies of a book and locks their rows until the end of the
+	// transaction, so no copy of the book is returned while a hold is placed.
+	LockBookCopies(bookID int) ([]inventorytypes.Copy, error)
+
+	// LockCopy retrieves a copy and locks its row until the end of the transaction, so the
+	// status of a copy is changed by one checkout or return at a time.
+	LockCopy(id int) (*inventorytypes.Copy, error)
+	LockCopyByBarcode(barcode string) (*inventorytypes.Copy, error)
+	UpdateCopyStatus(id int, status booktypes.CopyStatus) error
+
+	CreateLoan(loan Loan) (Loan, error)
+	UpdateLoan(loan *Loan) error
+	// LockLoan retrieves a loan and locks its row until the end of the transaction.
+	LockLoan(id int) (*Loan, error)
+	GetLoan(id int) (*Loan, error)
+	GetLoans(filter LoanFilter, page int, limit int) (*db.Pagination, []Loan, error)
+	CreateHold(hold Hold) (Hold, error)
+	UpdateHold(hold *Hold) error
+	// LockHold retrieves a hold and locks its row until the end of the transaction.
+	LockHold(id int) (*Hold, error)
+	GetHold(id int) (*Hold, error)
+	// GetOpenHold retrieves the waiting or ready hold of a member for a book.
+	GetOpenHold(bookID int, memberID int) (*Hold, error)
+	// LockNextHold retrieves the first waiting hold of a book and locks its row until the end
+	// of the transaction.
+	LockNextHold(bookID int) (*Hold, error)
+	// GetHolds lists the holds matching the filter in the order they were placed.
+	GetHolds(filter HoldFilter, page int, limit int) (*db.Pagination, []Hold, error)
+	// GetHoldPosition returns the place of a waiting hold in the queue of its book.
+	GetHoldPosition(hold Hold) (int, error)
+	// GetExpiredHolds lists the ready holds that expired before now.
+	GetExpiredHolds(now time.Time) ([]Hold, error)
+
+	// FlagOverdueLoans sets the status of the active loans due before now to overdue and
+	// returns their number.
+	FlagOverdueLoans(now time.Time) (int64, error)
+}
@@ -0,0 +1,48 @@
+package types
+
+import "time"
+
+type HoldStatus string
+
+const (
+	// HOLD_STATUS_WAITING is a hold in the queue of its book.
+	HOLD_STATUS_WAITING HoldStatus = "waiting"
+	// HOLD_STATUS_READY is a hold with a copy kept for pickup until it expires.
+	HOLD_STATUS_READY     HoldStatus = "ready"
+	HOLD_STATUS_FULFILLED HoldStatus = "fulfilled"
+	HOLD_STATUS_CANCELLED HoldStatus = "cancelled"
+	HOLD_STATUS_EXPIRED   HoldStatus = "expired"
+)
+
+// Hold is the model for a member queuing for a book. The waiting holds of a book are served
+// first in, first out; a member has at most one open hold per book, which the partial unique
+// index enforces.
+type Hold struct {
+	ID       int        `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string     `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	BookID   int        `json:"book_id" gorm:"not null;index;uniqueIndex:idx_holds_open_member,where:closed_at IS NULL" example:"1"`
+	MemberID int        `json:"member_id" gorm:"not null;index;uniqueIndex:idx_holds_open_member,where:closed_at IS NULL" example:"1"`
+	Status   HoldStatus `json:"status" gorm:"not null;index" example:"waiting"`
+	// CopyID is the copy kept for the member once the hold is ready.
+	CopyID *int `json:"copy_id,omitempty" gorm:"index" example:"1"`
+	// Position is the place of a waiting hold in the queue of its book, starting at 1.
+	Position  int        `json:"position,omitempty" gorm:"-" example:"1"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" gorm:"index"`
+	// ClosedAt is set once the hold is fulfilled, cancelled or expired.
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsOpen reports whether the hold is waiting or ready.
+func (h Hold) IsOpen() bool {
+	return h.Status == HOLD_STATUS_WAITING || h.Status == HOLD_STATUS_READY
+}
+
+// HoldFilter narrows down the holds listed by GetHolds. Zero fields match any hold.
+type HoldFilter struct {
+	MemberID int
+	BookID   int
+	Status   HoldStatus `validate:"omitempty,oneof=waiting ready fulfilled cancelled expired"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+type LoanStatus string
+
+const (
+	LOAN_STATUS_ACTIVE LoanStatus = "active"
+	// LOAN_STATUS_OVERDUE is set by the overdue check on the active loans past their due date.
+	LOAN_STATUS_OVERDUE  LoanStatus = "overdue"
+	LOAN_STATUS_RETURNED LoanStatus = "returned"
+)
+
+// Loan is the model for a copy of a book checked out by a member. A copy has at most one loan
+// that is not returned, which the partial unique index on the copy enforces.
+type Loan struct {
+	ID           int        `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID     string     `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	CopyID       int        `json:"copy_id" gorm:"not null;index;uniqueIndex:idx_loans_open_copy,where:returned_at IS NULL" example:"1"`
+	BookID       int        `json:"book_id" gorm:"not null;index" example:"1"`
+	MemberID     int        `json:"member_id" gorm:"not null;index" example:"1"`
+	Status       LoanStatus `json:"status" gorm:"not null;index:idx_loans_status_due" example:"active"`
+	CheckedOutAt time.Time  `json:"checked_out_at"`
+	DueAt        time.Time  `json:"due_at" gorm:"index:idx_loans_status_due"`
+	ReturnedAt   *time.Time `json:"returned_at,omitempty"`
+	// Renewals is the number of times the due date was pushed back.
+	Renewals int `json:"renewals" gorm:"not null;default:0" example:"0"`
+	// Fine is the amount charged when the copy was returned late.
+	Fine      int64     `json:"fine,omitempty" gorm:"not null;default:0" example:"0"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoanFilter narrows down the loans listed by GetLoans. Zero fields match any loan.
+type LoanFilter struct {
+	MemberID int
+	BookID   int
+	Status   LoanStatus `validate:"omitempty,oneof=active overdue returned"`
+}
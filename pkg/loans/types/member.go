@@ -0,0 +1,34 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+type MemberStatus string
+
+const (
+	MEMBER_STATUS_ACTIVE MemberStatus = "active"
+	// MEMBER_STATUS_SUSPENDED is a member who cannot borrow copies or place holds.
+	MEMBER_STATUS_SUSPENDED MemberStatus = "suspended"
+)
+
+// Member is the model for a person allowed to borrow copies of books.
+type Member struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';uniqueIndex:idx_members_tenant_email"`
+	Name     string `json:"name" gorm:"not null" example:"Jane Doe"`
+	// Email identifies the member, see NormalizeEmail.
+	Email string `json:"email" gorm:"not null;uniqueIndex:idx_members_tenant_email" example:"jane@example.com"`
+	// Tier is the membership tier, which caps the number of loans of the member, see the loans
+	// configuration.
+	Tier      string       `json:"tier" gorm:"not null;default:standard" example:"standard"`
+	Status    MemberStatus `json:"status" gorm:"not null;default:active;index" example:"active"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// NormalizeEmail returns the email in lower case without surrounding spaces.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,67 @@
+package types
+
+import "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+
+// AddMemberRequest is the request for adding a new member, in the default tier unless given
+type AddMemberRequest struct {
+	Name  string `json:"name" example:"Jane Doe" validate:"required,max=255"`
+	Email string `json:"email" example:"jane@example.com" validate:"required,email"`
+	Tier  string `json:"tier" example:"standard" validate:"max=32"`
+}
+
+// AddMemberResponse is the response for adding a new member
+type AddMemberResponse struct {
+	ID int `json:"id" example:"1"`
+}
+
+// UpdateMemberRequest is the request for updating a member
+type UpdateMemberRequest struct {
+	Name   string       `json:"name" example:"Jane Doe" validate:"max=255"`
+	Email  string       `json:"email" example:"jane@example.com" validate:"omitempty,email"`
+	Tier   string       `json:"tier" example:"premium" validate:"max=32"`
+	Status MemberStatus `json:"status" example:"suspended" validate:"omitempty,oneof=active suspended"`
+}
+
+// GetMembersResponse is the response for getting a list of members
+type GetMembersResponse struct {
+	Members    []Member       `json:"members"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// CheckoutRequest is the request for lending a copy, given by id or by barcode, to a member
+type CheckoutRequest struct {
+	MemberID int    `json:"member_id" example:"1" validate:"required"`
+	CopyID   int    `json:"copy_id" example:"1" validate:"required_without=Barcode"`
+	Barcode  string `json:"barcode" example:"LIB000123" validate:"required_without=CopyID,max=64"`
+}
+
+// GetLoansResponse is the response for getting a list of loans
+type GetLoansResponse struct {
+	Loans      []Loan         `json:"loans"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// PlaceHoldRequest is the request for queuing a member for a book without available copies
+type PlaceHoldRequest struct {
+	MemberID int `json:"member_id" example:"1" validate:"required"`
+	BookID   int `json:"book_id" example:"1" validate:"required"`
+}
+
+// GetHoldsResponse is the response for getting a list of holds
+type GetHoldsResponse struct {
+	Holds      []Hold         `json:"holds"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// GetAccountResponse is the response for getting the account of a member with its entries,
+// the latest first
+type GetAccountResponse struct {
+	Account    Account        `json:"account"`
+	Entries    []AccountEntry `json:"entries"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// PayRequest is the request for paying off some or all of the balance of a member
+type PayRequest struct {
+	Amount int64 `json:"amount" example:"75" validate:"required,gt=0"`
+}
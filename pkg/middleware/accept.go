@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// AcceptedFormat holds the representation preferences sent by the client.
+type AcceptedFormat struct {
+	// Format is the explicit format requested via the `format` query parameter.
+	Format string
+	// Accept is the raw value of the Accept header.
+	Accept string
+}
+
+// InjectAcceptedFormat injects the client's representation preferences into the context of the request.
+// Only the representation of a GET request is negotiated: the other requests change the resources
+// before their response is written, so they are answered in the default representation rather than
+// failing once the change is done.
+func InjectAcceptedFormat(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var accepted AcceptedFormat
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			accepted = AcceptedFormat{
+				Format: r.URL.Query().Get("format"),
+				Accept: r.Header.Get("Accept"),
+			}
+		}
+
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, ContextKeyAcceptedFormat, accepted)
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// GetAcceptedFormat retrieves the client's representation preferences from the context.
+func GetAcceptedFormat(ctx context.Context) AcceptedFormat {
+	accepted, ok := ctx.Value(ContextKeyAcceptedFormat).(AcceptedFormat)
+	if !ok {
+		return AcceptedFormat{}
+	}
+
+	return accepted
+}
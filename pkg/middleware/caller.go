@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// HeaderActor is the header carrying the identity of the caller.
+const HeaderActor = "X-Actor"
+
+// InjectCaller injects the identity and the IP address of the caller into the context of the request.
+// An actor already in the context, e.g. set by an authentication middleware, is kept over the header.
+func InjectCaller(next http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if _, ok := ctx.Value(ContextKeyActor).(string); !ok {
+			if actor := r.Header.Get(HeaderActor); actor != "" {
+				ctx = context.WithValue(ctx, ContextKeyActor, actor)
+			}
+		}
+		ctx = context.WithValue(ctx, ContextKeyRemoteIP, ReadUserIP(r))
+		r = r.WithContext(ctx)
+
+		next.ServeHTTP(w, r)
+	}
+}
+
+// GetActor retrieves the identity of the caller from the context.
+func GetActor(ctx context.Context) string {
+	actor, ok := ctx.Value(ContextKeyActor).(string)
+	if !ok {
+		return ActorAnonymous
+	}
+
+	return actor
+}
+
+// GetRemoteIP retrieves the IP address of the caller from the context.
+func GetRemoteIP(ctx context.Context) string {
+	ip, _ := ctx.Value(ContextKeyRemoteIP).(string)
+	return ip
+}
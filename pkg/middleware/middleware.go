@@ -11,6 +11,8 @@ func Wraps(router http.Handler, logger *zap.Logger) http.Handler {
 	funcs := []func(http.Handler) http.HandlerFunc{
 		LogResult(logger),
 		InjectRequestID,
+		InjectCaller,
+		InjectAcceptedFormat,
 	}
 
 	r := router
@@ -0,0 +1,35 @@
+// Package middlewaretest serves the requests of the handler tests through the middlewares.
+package middlewaretest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+)
+
+// Serve serves a request with input encoded as its JSON body, if any, through the router on
+// behalf of the actor, anonymously if empty.
+func Serve(t *testing.T, router http.Handler, method string, url string, actor string, input any) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if input != nil {
+		err := json.NewEncoder(&body).Encode(input)
+		require.NoError(t, err)
+	}
+
+	req, err := http.NewRequest(method, url, &body)
+	require.NoError(t, err)
+	if actor != "" {
+		req.Header.Set(middleware.HeaderActor, actor)
+	}
+
+	resp := httptest.NewRecorder()
+	middleware.InjectCaller(router).ServeHTTP(resp, req)
+
+	return resp
+}
@@ -3,8 +3,12 @@ package middleware
 type ContextKey string
 
 const (
-	ContextKeyRequestID = ContextKey("request_id")
-	RequestIDUnknown    = "unknown"
+	ContextKeyRequestID      = ContextKey("request_id")
+	ContextKeyAcceptedFormat = ContextKey("accepted_format")
+	ContextKeyActor          = ContextKey("actor")
+	ContextKeyRemoteIP       = ContextKey("remote_ip")
+	RequestIDUnknown         = "unknown"
+	ActorAnonymous           = "anonymous"
 
 	LogKeyStatus   = "status"
 	LogKeyLatency  = "latency"
@@ -0,0 +1,100 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+)
+
+const (
+	EVENT_BOOK_CREATED  = "book.created"
+	EVENT_BOOK_UPDATED  = "book.updated"
+	EVENT_BOOK_DELETED  = "book.deleted"
+	EVENT_BOOK_RESTORED = "book.restored"
+
+	AGGREGATE_BOOK = "book"
+)
+
+// Event is the model for a domain event kept in the outbox until it is published.
+type Event struct {
+	ID            uint64          `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID      string          `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	Type          string          `json:"type" gorm:"not null;index" example:"book.updated"`
+	AggregateType string          `json:"aggregate_type" gorm:"not null" example:"book"`
+	AggregateID   string          `json:"aggregate_id" gorm:"not null;index" example:"1"`
+	Payload       json.RawMessage `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"`
+	CreatedAt     time.Time       `json:"created_at"`
+	PublishedAt   *time.Time      `json:"-" gorm:"index"`
+	// NextAttemptAt is the time the event is due again, after a failed attempt or while it is
+	// claimed by a relay; nil for an event never attempted.
+	NextAttemptAt *time.Time `json:"-"`
+	Attempts      int        `json:"-"`
+	LastError     string     `json:"-"`
+	// DeadAt is the time the event ran out of attempts; a dead event is no longer published.
+	DeadAt *time.Time `json:"-"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Event) TableName() string {
+	return "outbox_events"
+}
+
+// BookEventPayload is the payload of the book events. Book is the book after the change,
+// or the deleted book; Changes only exists in book.updated and book.restored events.
+type BookEventPayload struct {
+	Book    booktypes.Book                   `json:"book"`
+	Changes map[string]booktypes.FieldChange `json:"changes,omitempty"`
+}
+
+// NewBookEvent converts a book change into its domain event.
+func NewBookEvent(change booktypes.BookChange) (Event, error) {
+	var eventType string
+	var payload BookEventPayload
+
+	switch change.Op {
+	case booktypes.CHANGE_OP_CREATE:
+		eventType = EVENT_BOOK_CREATED
+		payload.Book = *change.After
+	case booktypes.CHANGE_OP_UPDATE:
+		eventType = EVENT_BOOK_UPDATED
+		payload.Book = *change.After
+		payload.Changes = change.Diff()
+	case booktypes.CHANGE_OP_RESTORE:
+		eventType = EVENT_BOOK_RESTORED
+		payload.Book = *change.After
+		payload.Changes = change.Diff()
+	default:
+		eventType = EVENT_BOOK_DELETED
+		payload.Book = *change.Before
+	}
+
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		Type:          eventType,
+		AggregateType: AGGREGATE_BOOK,
+		AggregateID:   strconv.Itoa(change.BookID),
+		Payload:       b,
+	}, nil
+}
+
+// WriteBookChange is the change writer that stores the event of a book change in the outbox
+// within the transaction of the change.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	event, err := NewBookEvent(change)
+	if err != nil {
+		return err
+	}
+
+	return tx.Create(&event).Error
+}
+
+var _ booktypes.ChangeWriter = WriteBookChange
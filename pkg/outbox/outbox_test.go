@@ -0,0 +1,205 @@
+package outbox_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+)
+
+func TestNewBookEvent(t *testing.T) {
+	before := booktypes.Book{ID: 1, Title: "old-title", Author: "test-author", Description: "test-desc"}
+	after := booktypes.Book{ID: 1, Title: "new-title", Author: "test-author", Description: "test-desc"}
+
+	testCases := []struct {
+		name      string
+		in        booktypes.BookChange
+		eventType string
+		payload   string
+	}{
+		{
+			name:      "created",
+			in:        booktypes.BookChange{Op: booktypes.CHANGE_OP_CREATE, BookID: 1, After: &before},
+			eventType: outbox.EVENT_BOOK_CREATED,
+			payload:   `{"book":{"id":1,"title":"old-title","author":"test-author","description":"test-desc"}}`,
+		},
+		{
+			name:      "updated",
+			in:        booktypes.BookChange{Op: booktypes.CHANGE_OP_UPDATE, BookID: 1, Before: &before, After: &after},
+			eventType: outbox.EVENT_BOOK_UPDATED,
+			payload: `{"book":{"id":1,"title":"new-title","author":"test-author","description":"test-desc"},` +
+				`"changes":{"title":{"old":"old-title","new":"new-title"}}}`,
+		},
+		{
+			name:      "restored",
+			in:        booktypes.BookChange{Op: booktypes.CHANGE_OP_RESTORE, BookID: 1, Before: &after, After: &before, RestoredFrom: 1},
+			eventType: outbox.EVENT_BOOK_RESTORED,
+			payload: `{"book":{"id":1,"title":"old-title","author":"test-author","description":"test-desc"},` +
+				`"changes":{"title":{"old":"new-title","new":"old-title"}}}`,
+		},
+		{
+			name:      "deleted",
+			in:        booktypes.BookChange{Op: booktypes.CHANGE_OP_DELETE, BookID: 1, Before: &after},
+			eventType: outbox.EVENT_BOOK_DELETED,
+			payload:   `{"book":{"id":1,"title":"new-title","author":"test-author","description":"test-desc"}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			event, err := outbox.NewBookEvent(tc.in)
+			require.NoError(t, err)
+
+			require.Equal(t, tc.eventType, event.Type)
+			require.Equal(t, outbox.AGGREGATE_BOOK, event.AggregateType)
+			require.Equal(t, "1", event.AggregateID)
+			require.JSONEq(t, tc.payload, string(event.Payload))
+		})
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	sink := outbox.NewFileSink(path)
+
+	for i := uint64(1); i <= 2; i++ {
+		err := sink.Publish(context.Background(), outbox.Event{ID: i, Type: outbox.EVENT_BOOK_CREATED, Payload: json.RawMessage(`{}`)})
+		require.NoError(t, err)
+	}
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"id":1,"type":"book.created","aggregate_type":"","aggregate_id":"","payload":{},"created_at":"0001-01-01T00:00:00Z"}`+"\n"+
+			`{"id":2,"type":"book.created","aggregate_type":"","aggregate_id":"","payload":{},"created_at":"0001-01-01T00:00:00Z"}`+"\n",
+		string(b),
+	)
+}
+
+func TestWebhookSink(t *testing.T) {
+	testCases := []struct {
+		name   string
+		status int
+		errMsg string
+	}{
+		{
+			name:   "success",
+			status: http.StatusNoContent,
+		},
+		{
+			name:   "error status",
+			status: http.StatusServiceUnavailable,
+			errMsg: "responded with status 503",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var received []byte
+			var eventType string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received, _ = io.ReadAll(r.Body)
+				eventType = r.Header.Get("X-Event-Type")
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			sink := outbox.NewWebhookSink(server.URL, server.Client())
+			err := sink.Publish(context.Background(), outbox.Event{ID: 1, Type: outbox.EVENT_BOOK_DELETED, Payload: json.RawMessage(`{}`)})
+			if tc.errMsg != "" {
+				require.ErrorContains(t, err, tc.errMsg)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Equal(t, outbox.EVENT_BOOK_DELETED, eventType)
+			require.JSONEq(t, `{"id":1,"type":"book.deleted","aggregate_type":"","aggregate_id":"","payload":{},"created_at":"0001-01-01T00:00:00Z"}`, string(received))
+		})
+	}
+}
+
+// recordingSink records the published events and fails the ones of the failing ids.
+type recordingSink struct {
+	published []uint64
+	failing   map[uint64]bool
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Publish(ctx context.Context, event outbox.Event) error {
+	if s.failing[event.ID] {
+		return errors.New("sink is down")
+	}
+	s.published = append(s.published, event.ID)
+	return nil
+}
+
+func TestRelay(t *testing.T) {
+	gdb, err := db.Init(config.DBConn{Driver: db.DRIVER_MEMORY}, zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, gdb.AutoMigrate(&outbox.Event{}))
+
+	for i := 0; i < 4; i++ {
+		event := outbox.Event{Type: outbox.EVENT_BOOK_CREATED, AggregateType: outbox.AGGREGATE_BOOK, AggregateID: "1", Payload: json.RawMessage(`{}`)}
+		require.NoError(t, gdb.Create(&event).Error)
+	}
+
+	sink := &recordingSink{failing: map[uint64]bool{2: true}}
+	conf := config.Outbox{BatchSize: 10, Lease: time.Minute, MaxAttempts: 2, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+	relay := outbox.NewRelay(gdb, []outbox.Sink{sink}, conf, zap.NewNop())
+
+	getEvent := func(id uint64) outbox.Event {
+		var event outbox.Event
+		require.NoError(t, gdb.First(&event, id).Error)
+		return event
+	}
+
+	// a claimed event is left to the relay holding it until its lease expires.
+	now := time.Now()
+	require.NoError(t, gdb.Model(&outbox.Event{}).Where("id = ?", 4).Update("next_attempt_at", now.Add(time.Minute)).Error)
+
+	// the failed event doesn't hold back the later ones.
+	n, err := relay.PublishPending(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []uint64{1, 3}, sink.published)
+	require.NotNil(t, getEvent(1).PublishedAt)
+
+	failed := getEvent(2)
+	require.Nil(t, failed.PublishedAt)
+	require.Nil(t, failed.DeadAt)
+	require.Equal(t, 1, failed.Attempts)
+	require.Equal(t, "sink recording: sink is down", failed.LastError)
+	require.WithinDuration(t, time.Now().Add(time.Hour), *failed.NextAttemptAt, time.Minute)
+
+	// the failed event is retried after its backoff, the lease of the claimed one expired.
+	now = now.Add(2 * time.Hour)
+	n, err = relay.PublishPending(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []uint64{1, 3, 4}, sink.published)
+
+	// the failed event is dead after the maximum number of attempts.
+	dead := getEvent(2)
+	require.Nil(t, dead.PublishedAt)
+	require.NotNil(t, dead.DeadAt)
+	require.Equal(t, 2, dead.Attempts)
+
+	n, err = relay.PublishPending(context.Background(), now.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
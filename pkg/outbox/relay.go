@@ -0,0 +1,157 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// Relay publishes the events of the outbox to the sinks in the order they are written. An event
+// is published to every sink at least once: if a sink fails, the event is published to all sinks
+// again after a backoff, without holding back the later events. After the maximum number of
+// attempts the event is dead and is no longer published.
+type Relay struct {
+	db    *gorm.DB
+	sinks []Sink
+	conf  config.Outbox
+	log   *zap.Logger
+}
+
+// NewRelay creates a new relay that polls the outbox every conf.PollInterval
+func NewRelay(db *gorm.DB, sinks []Sink, conf config.Outbox, log *zap.Logger) *Relay {
+	return &Relay{
+		db:    db,
+		sinks: sinks,
+		conf:  conf,
+		log:   log,
+	}
+}
+
+// Run publishes the pending events periodically until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := r.PublishPending(ctx, time.Now())
+		if err != nil {
+			r.log.Error(fmt.Sprintf("failed to publish outbox events: %v", err))
+		}
+
+		// a full batch means there may be more events waiting, so poll again right away.
+		if err == nil && n == r.conf.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publishes a batch of the events of every tenant due at the given time and returns
+// the number of attempted events. The batch is claimed for the lease before it is published, so
+// the relays of other replicas skip it without waiting for the sinks.
+func (r *Relay) PublishPending(ctx context.Context, now time.Time) (int, error) {
+	db := r.db.WithContext(tenant.AllTenants(ctx))
+
+	events, err := r.claim(db, now)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		updates := map[string]any{"published_at": time.Now()}
+		if err := r.publish(ctx, event); err != nil {
+			updates = r.failure(event, err)
+		}
+
+		if err := db.Model(&event).Updates(updates).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// claim locks the due events, skipping the ones locked by other relays, and postpones their next
+// attempt by the lease.
+func (r *Relay) claim(db *gorm.DB, now time.Time) ([]Event, error) {
+	var events []Event
+	err := db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND dead_at IS NULL").
+			Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+			Order("id").
+			Limit(r.conf.BatchSize).
+			Find(&events).Error
+		if err != nil || len(events) == 0 {
+			return err
+		}
+
+		ids := make([]uint64, 0, len(events))
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+
+		return tx.Model(&Event{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(r.conf.Lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// failure returns the updates recording a failed attempt to publish the event.
+func (r *Relay) failure(event Event, err error) map[string]any {
+	now := time.Now()
+	attempts := event.Attempts + 1
+	updates := map[string]any{
+		"attempts":   attempts,
+		"last_error": err.Error(),
+	}
+
+	if attempts >= r.conf.MaxAttempts {
+		r.log.Error(fmt.Sprintf("event %d is dead after %d attempts: %v", event.ID, attempts, err))
+		updates["dead_at"] = now
+		return updates
+	}
+
+	r.log.Error(fmt.Sprintf("failed to publish event %d: %v", event.ID, err))
+	updates["next_attempt_at"] = now.Add(r.backoff(attempts))
+	return updates
+}
+
+func (r *Relay) publish(ctx context.Context, event Event) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("sink %s: %w", sink.Name(), err)
+		}
+	}
+
+	return nil
+}
+
+// backoff returns the delay before the next attempt: the initial backoff doubled after
+// every failed attempt, capped at the maximum backoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.conf.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.conf.MaxBackoff {
+			return r.conf.MaxBackoff
+		}
+	}
+
+	return delay
+}
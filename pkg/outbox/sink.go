@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+)
+
+// Sink is the interface for a destination of the published events
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event Event) error
+}
+
+// NewSinks creates the sinks enabled in the configuration.
+func NewSinks(conf config.OutboxSinks, log *zap.Logger) []Sink {
+	var sinks []Sink
+	if conf.Log {
+		sinks = append(sinks, NewLogSink(log))
+	}
+	if conf.File != "" {
+		sinks = append(sinks, NewFileSink(conf.File))
+	}
+	if conf.WebhookURL != "" {
+		sinks = append(sinks, NewWebhookSink(conf.WebhookURL, &http.Client{Timeout: conf.WebhookTimeout}))
+	}
+
+	return sinks
+}
+
+var (
+	_ Sink = &LogSink{}
+	_ Sink = &FileSink{}
+	_ Sink = &WebhookSink{}
+)
+
+// LogSink publishes the events into the logger.
+type LogSink struct {
+	log *zap.Logger
+}
+
+// NewLogSink creates a new sink that logs the events
+func NewLogSink(log *zap.Logger) *LogSink {
+	return &LogSink{log}
+}
+
+func (s *LogSink) Name() string { return "log" }
+
+func (s *LogSink) Publish(ctx context.Context, event Event) error {
+	s.log.Info(
+		fmt.Sprintf("event %d: %s", event.ID, event.Type),
+		zap.String("aggregate_type", event.AggregateType),
+		zap.String("aggregate_id", event.AggregateID),
+		zap.ByteString("payload", event.Payload),
+	)
+	return nil
+}
+
+// FileSink publishes the events into a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileSink creates a new sink that appends the events into the file of the given path
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Name() string { return "file" }
+
+func (s *FileSink) Publish(ctx context.Context, event Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// WebhookSink publishes the events to an HTTP endpoint as JSON.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink creates a new sink that posts the events to the given url
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	return &WebhookSink{url, client}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Publish(ctx context.Context, event Event) error {
+	b, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Type", event.Type)
+	req.Header.Set("X-Event-ID", fmt.Sprint(event.ID))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s responded with status %d", s.url, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package response
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedType is returned by an encoder that cannot represent the given data.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// Encoder encodes response data into a specific representation.
+type Encoder interface {
+	// ContentType returns the value of the Content-Type header of the encoded data.
+	ContentType() string
+	// MediaTypes returns the media types in the Accept header that are served by the encoder.
+	MediaTypes() []string
+	// Encode writes the representation of the data into w. It returns ErrUnsupportedType
+	// if the data cannot be represented by the encoder.
+	Encode(w io.Writer, data any) error
+}
+
+// CSVMarshaler is implemented by types that can be represented as CSV records.
+// The first record is the header row.
+type CSVMarshaler interface {
+	MarshalCSV() ([][]string, error)
+}
+
+// JSONEncoder encodes data as JSON.
+type JSONEncoder struct{}
+
+func (JSONEncoder) ContentType() string { return "application/json" }
+
+func (JSONEncoder) MediaTypes() []string { return []string{"application/json"} }
+
+func (JSONEncoder) Encode(w io.Writer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// CSVEncoder encodes data implementing CSVMarshaler as CSV.
+type CSVEncoder struct{}
+
+func (CSVEncoder) ContentType() string { return "text/csv; charset=utf-8" }
+
+func (CSVEncoder) MediaTypes() []string { return []string{"text/csv"} }
+
+func (CSVEncoder) Encode(w io.Writer, data any) error {
+	m, ok := data.(CSVMarshaler)
+	if !ok {
+		return fmt.Errorf("%w: %T cannot be represented as csv", ErrUnsupportedType, data)
+	}
+
+	records, err := m.MarshalCSV()
+	if err != nil {
+		return err
+	}
+
+	return csv.NewWriter(w).WriteAll(records)
+}
+
+// YAMLEncoder encodes data as YAML.
+type YAMLEncoder struct{}
+
+func (YAMLEncoder) ContentType() string { return "application/yaml; charset=utf-8" }
+
+func (YAMLEncoder) MediaTypes() []string {
+	return []string{"application/yaml", "application/x-yaml", "text/yaml"}
+}
+
+func (YAMLEncoder) Encode(w io.Writer, data any) error {
+	b, err := yaml.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// XMLEncoder encodes data as XML.
+type XMLEncoder struct{}
+
+func (XMLEncoder) ContentType() string { return "application/xml; charset=utf-8" }
+
+func (XMLEncoder) MediaTypes() []string { return []string{"application/xml", "text/xml"} }
+
+func (XMLEncoder) Encode(w io.Writer, data any) error {
+	b, err := xml.Marshal(data)
+	if err != nil {
+		var unsupportedErr *xml.UnsupportedTypeError
+		if errors.As(err, &unsupportedErr) {
+			return fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+		}
+		return err
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
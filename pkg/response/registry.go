@@ -0,0 +1,164 @@
+package response
+
+import (
+	"mime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+)
+
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatYAML = "yaml"
+	FormatXML  = "xml"
+)
+
+// DefaultRegistry is the registry used by Write to select an encoder.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	DefaultRegistry.Register(FormatJSON, JSONEncoder{})
+	DefaultRegistry.Register(FormatCSV, CSVEncoder{})
+	DefaultRegistry.Register(FormatYAML, YAMLEncoder{})
+	DefaultRegistry.Register(FormatXML, XMLEncoder{})
+}
+
+// Register adds an encoder for the given format into the default registry.
+func Register(format string, e Encoder) {
+	DefaultRegistry.Register(format, e)
+}
+
+// Registry holds the encoders available for writing responses, keyed by format name.
+// The first registered encoder is the default one.
+type Registry struct {
+	mu       sync.RWMutex
+	formats  []string
+	encoders map[string]Encoder
+}
+
+// NewRegistry creates a new empty encoder registry.
+func NewRegistry() *Registry {
+	return &Registry{encoders: make(map[string]Encoder)}
+}
+
+// Register adds an encoder for the given format, replacing the existing one if any.
+func (r *Registry) Register(format string, e Encoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	format = strings.ToLower(format)
+	if _, ok := r.encoders[format]; !ok {
+		r.formats = append(r.formats, format)
+	}
+	r.encoders[format] = e
+}
+
+// Lookup returns the encoder of the given format.
+func (r *Registry) Lookup(format string) (Encoder, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.encoders[strings.ToLower(format)]
+	return e, ok
+}
+
+// Negotiation is a representation acceptable to the client.
+type Negotiation struct {
+	Format  string
+	Encoder Encoder
+}
+
+// Negotiate returns the representations acceptable to the client, the preferred one first. An
+// explicit format takes precedence over the Accept header. It returns nothing if nothing matches.
+func (r *Registry) Negotiate(accepted middleware.AcceptedFormat) []Negotiation {
+	if accepted.Format != "" {
+		format := strings.ToLower(accepted.Format)
+		if e, ok := r.Lookup(format); ok {
+			return []Negotiation{{format, e}}
+		}
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.formats) == 0 {
+		return nil
+	}
+
+	if strings.TrimSpace(accepted.Accept) == "" {
+		return []Negotiation{{r.formats[0], r.encoders[r.formats[0]]}}
+	}
+
+	var negotiated []Negotiation
+	seen := make(map[string]bool)
+	for _, mediaRange := range parseAccept(accepted.Accept) {
+		for _, format := range r.formats {
+			e := r.encoders[format]
+			if seen[format] || !slices.ContainsFunc(e.MediaTypes(), func(mediaType string) bool {
+				return matchMediaRange(mediaRange, mediaType)
+			}) {
+				continue
+			}
+
+			seen[format] = true
+			negotiated = append(negotiated, Negotiation{format, e})
+		}
+	}
+
+	return negotiated
+}
+
+// parseAccept returns the media ranges of the Accept header ordered by their quality value.
+// Media ranges with a zero quality value are dropped.
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		value   string
+		quality float64
+	}
+
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{value: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	values := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		values = append(values, r.value)
+	}
+	return values
+}
+
+// matchMediaRange checks if the media type is covered by the media range, e.g. `text/*`.
+func matchMediaRange(mediaRange string, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
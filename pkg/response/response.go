@@ -1,8 +1,10 @@
 package response
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 
@@ -37,10 +39,42 @@ func Write(ctx context.Context, w http.ResponseWriter, code int, data any, log *
 		reqID = middleware.RequestIDUnknown
 	}
 
-	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Vary", "Accept")
+
+	negotiated := DefaultRegistry.Negotiate(middleware.GetAcceptedFormat(ctx))
+	if len(negotiated) == 0 {
+		WriteError(ctx, w, apierror.ErrNotAcceptable.WithMessage("no acceptable representation is available"), log)
+		return
+	}
+
+	// Encode into a buffer first, so the next acceptable representation can still be used when
+	// the data cannot be represented in the preferred one.
+	var buf bytes.Buffer
+	var encoder Encoder
+	for _, n := range negotiated {
+		buf.Reset()
+		err := n.Encoder.Encode(&buf, data)
+		if errors.Is(err, ErrUnsupportedType) {
+			continue
+		}
+		if err != nil {
+			log.Error(fmt.Sprintf("failed to encode response: %v", err), zap.String(middleware.LogKeyID, reqID))
+			http.Error(w, apierror.ErrInternal.Error(), apierror.ErrInternal.Code)
+			return
+		}
+
+		encoder = n.Encoder
+		break
+	}
+	if encoder == nil {
+		msg := fmt.Sprintf("%s representation is not supported for this resource", negotiated[0].Format)
+		WriteError(ctx, w, apierror.ErrNotAcceptable.WithMessage(msg), log)
+		return
+	}
+
+	w.Header().Add("Content-Type", encoder.ContentType())
 	w.WriteHeader(code)
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Error(fmt.Sprintf("failed to write error response: %v", err), zap.String(middleware.LogKeyID, reqID))
-		http.Error(w, apierror.ErrInternal.Error(), apierror.ErrInternal.Code)
+	if _, err := buf.WriteTo(w); err != nil {
+		log.Error(fmt.Sprintf("failed to write response: %v", err), zap.String(middleware.LogKeyID, reqID))
 	}
 }
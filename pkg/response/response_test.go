@@ -0,0 +1,195 @@
+package response_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+func TestWrite(t *testing.T) {
+	books := types.GetBooksResponse{
+		Books: []types.Book{
+			{ID: 2, Title: "title-2", Author: "author-2", Description: "desc, with comma"},
+			{ID: 1, Title: "title-1", Author: "author-1", Description: "desc-1"},
+		},
+		Pagination: &db.Pagination{Limit: 2, Page: 1, Sort: "id desc", TotalRows: 2, TotalPages: 1},
+	}
+
+	type output struct {
+		code        int
+		contentType string
+		body        string
+		errMsg      string
+	}
+	testCases := []struct {
+		name     string
+		accepted middleware.AcceptedFormat
+		data     any
+		output   output
+	}{
+		{
+			name: "default json",
+			data: types.AddBookResponse{ID: 1},
+			output: output{
+				code:        http.StatusOK,
+				contentType: "application/json",
+				body:        "{\"id\":1}\n",
+			},
+		},
+		{
+			name:     "csv from format query",
+			accepted: middleware.AcceptedFormat{Format: "csv", Accept: "application/json"},
+			data:     books,
+			output: output{
+				code:        http.StatusOK,
+				contentType: "text/csv; charset=utf-8",
+				body:        "id,title,author,description,isbn13,isbn10,publisher,published_date,edition,language,page_count,format,series_name,series_position,price,currency\n2,title-2,author-2,\"desc, with comma\",,,,,,,,,,,,\n1,title-1,author-1,desc-1,,,,,,,,,,,,\n",
+			},
+		},
+		{
+			name:     "yaml from accept header",
+			accepted: middleware.AcceptedFormat{Accept: "application/yaml"},
+			data:     types.Book{ID: 1, Title: "title-1", Author: "author-1", Description: "desc-1"},
+			output: output{
+				code:        http.StatusOK,
+				contentType: "application/yaml; charset=utf-8",
+				body:        "id: 1\ntitle: title-1\nauthor: author-1\ndescription: desc-1\n",
+			},
+		},
+		{
+			name:     "xml with quality values",
+			accepted: middleware.AcceptedFormat{Accept: "application/json;q=0.5, application/xml"},
+			data:     &types.Book{ID: 1, Title: "title-1", Author: "author-1", Description: "desc-1"},
+			output: output{
+				code:        http.StatusOK,
+				contentType: "application/xml; charset=utf-8",
+				body: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n" +
+					"<Book><id>1</id><title>title-1</title><author>author-1</author><description>desc-1</description></Book>",
+			},
+		},
+		{
+			name:     "wildcard accept",
+			accepted: middleware.AcceptedFormat{Accept: "text/*"},
+			data:     types.Book{ID: 1, Title: "title-1", Author: "author-1", Description: "desc-1"},
+			output: output{
+				code:        http.StatusOK,
+				contentType: "text/csv; charset=utf-8",
+				body:        "id,title,author,description,isbn13,isbn10,publisher,published_date,edition,language,page_count,format,series_name,series_position,price,currency\n1,title-1,author-1,desc-1,,,,,,,,,,,,\n",
+			},
+		},
+		{
+			name:     "wildcard accept falls back to the next representation",
+			accepted: middleware.AcceptedFormat{Accept: "text/*"},
+			data:     types.AddBookResponse{ID: 1},
+			output: output{
+				code:        http.StatusOK,
+				contentType: "application/yaml; charset=utf-8",
+				body:        "id: 1\n",
+			},
+		},
+		{
+			name:     "accept falls back to a lower quality",
+			accepted: middleware.AcceptedFormat{Accept: "text/csv, application/json;q=0.1"},
+			data:     types.AddBookResponse{ID: 1},
+			output: output{
+				code:        http.StatusOK,
+				contentType: "application/json",
+				body:        "{\"id\":1}\n",
+			},
+		},
+		{
+			name:     "unknown format",
+			accepted: middleware.AcceptedFormat{Format: "pdf"},
+			data:     books,
+			output: output{
+				code:   http.StatusNotAcceptable,
+				errMsg: "no acceptable representation is available",
+			},
+		},
+		{
+			name:     "unknown accept",
+			accepted: middleware.AcceptedFormat{Accept: "image/png"},
+			data:     books,
+			output: output{
+				code:   http.StatusNotAcceptable,
+				errMsg: "no acceptable representation is available",
+			},
+		},
+		{
+			name:     "unsupported csv type",
+			accepted: middleware.AcceptedFormat{Format: "csv"},
+			data:     types.AddBookResponse{ID: 1},
+			output: output{
+				code:   http.StatusNotAcceptable,
+				errMsg: "csv representation is not supported for this resource",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), middleware.ContextKeyAcceptedFormat, tc.accepted)
+			resp := httptest.NewRecorder()
+
+			response.Write(ctx, resp, http.StatusOK, tc.data, zap.NewNop())
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				require.Equal(t, tc.output.contentType, resp.Header().Get("Content-Type"))
+				require.Equal(t, tc.output.body, resp.Body.String())
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestWriteNegotiatesGetOnly(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.Write(r.Context(), w, http.StatusOK, types.Book{ID: 1, Title: "title-1"}, zap.NewNop())
+	})
+	server := httptest.NewServer(middleware.Wraps(handler, zap.NewNop()))
+	defer server.Close()
+
+	testCases := []struct {
+		method      string
+		contentType string
+	}{
+		{http.MethodGet, "text/csv; charset=utf-8"},
+		// the change of a request is done when its response is written, so it is not refused.
+		{http.MethodPost, "application/json"},
+		{http.MethodPut, "application/json"},
+		{http.MethodDelete, "application/json"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, server.URL+"?format=csv", nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept", "text/csv")
+
+			resp, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, tc.contentType, resp.Header.Get("Content-Type"))
+		})
+	}
+}
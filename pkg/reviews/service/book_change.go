@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+)
+
+var (
+	_ booktypes.ChangeWriter = WriteBookChange
+)
+
+// WriteBookChange is the change writer that removes the reviews of a deleted book.
+func WriteBookChange(ctx context.Context, tx *gorm.DB, change booktypes.BookChange) error {
+	if change.After != nil {
+		return nil
+	}
+
+	return tx.Where("book_id = ?", change.BookID).Delete(&types.Review{}).Error
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the reviews service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the reviews service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/books/{id}/reviews", h.GetReviews)
+	mux.HandleFunc("POST /api/v1/books/{id}/reviews", h.AddReview)
+	mux.HandleFunc("GET /api/v1/books/{id}/reviews/{review_id}", h.GetReview)
+	mux.HandleFunc("PUT /api/v1/books/{id}/reviews/{review_id}", h.UpdateReview)
+	mux.HandleFunc("DELETE /api/v1/books/{id}/reviews/{review_id}", h.DeleteReview)
+	mux.HandleFunc("PUT /api/v1/books/{id}/reviews/{review_id}/status", h.ModerateReview)
+	return mux
+}
+
+// parseIntParam parses an integer path or query parameter
+func parseIntParam(name string, value string) (int, error) {
+	v, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid %s: %s", name, value))
+	}
+
+	return int(v), nil
+}
+
+// parseReviewPath parses the book and the review ids of the path
+func parseReviewPath(r *http.Request) (bookID int, id int, err error) {
+	if bookID, err = parseIntParam("id", r.PathValue("id")); err != nil {
+		return 0, 0, err
+	}
+	if id, err = parseIntParam("review_id", r.PathValue("review_id")); err != nil {
+		return 0, 0, err
+	}
+
+	return bookID, id, nil
+}
+
+// @Summary get a list of reviews of a book
+// @Description The latest reviews come first. Only the approved reviews are listed unless another status is given.
+// @ID get-reviews
+// @Param id path int true "Book ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Param status query string false "moderation status of the reviews" enums(pending,approved,rejected)
+// @Produce json
+// @Success 200 {object} types.GetReviewsResponse
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/reviews [get]
+func (h Handler) GetReviews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+	page, err := parseIntParam("page", r.URL.Query().Get("page"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+	limit, err := parseIntParam("limit", r.URL.Query().Get("limit"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	filter := types.ReviewFilter{
+		Status: types.ReviewStatus(r.URL.Query().Get("status")),
+	}
+	result, err := h.serv.WithContext(ctx).GetReviews(bookID, filter, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get a review of a book
+// @ID get-review
+// @Param id path int true "Book ID"
+// @Param review_id path int true "Review ID"
+// @Produce json
+// @Success 200 {object} types.Review
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/reviews/{review_id} [get]
+func (h Handler) GetReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseReviewPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetReview(bookID, id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary review a book
+// @Description The reviewer is the caller given by the X-Actor header; a reader reviews a book once. A new review is pending until it is approved.
+// @ID add-review
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param X-Actor header string true "Reviewer"
+// @Param Body body types.AddReviewRequest true "Review"
+// @Success 201 {object} types.AddReviewResponse
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 409 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/reviews [post]
+func (h Handler) AddReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, err := parseIntParam("id", r.PathValue("id"))
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.AddReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).AddReview(ctx, bookID, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, result, h.log)
+}
+
+// @Summary edit a review
+// @Description Only the reviewer edits a review, which is pending again until it is approved.
+// @ID update-review
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param review_id path int true "Review ID"
+// @Param X-Actor header string true "Reviewer"
+// @Param Body body types.UpdateReviewRequest true "Review information that needs to be updated"
+// @Success 200 {object} types.Review
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/reviews/{review_id} [put]
+func (h Handler) UpdateReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseReviewPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateReview(ctx, bookID, id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary delete a review
+// @Description Only the reviewer deletes a review.
+// @ID delete-review
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param review_id path int true "Review ID"
+// @Param X-Actor header string true "Reviewer"
+// @Success 200 {object} types.DeleteReviewResponse
+// @Failure 400 {object} errors.Error
+// @Failure 401 {object} errors.Error
+// @Failure 403 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/reviews/{review_id} [delete]
+func (h Handler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseReviewPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteReview(ctx, bookID, id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary moderate a review
+// @Description Only the approved reviews are listed by default and counted in the rating of the book.
+// @ID moderate-review
+// @Produce json
+// @Param id path int true "Book ID"
+// @Param review_id path int true "Review ID"
+// @Param Body body types.ModerateReviewRequest true "Moderation status"
+// @Success 200 {object} types.Review
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /books/{id}/reviews/{review_id}/status [put]
+func (h Handler) ModerateReview(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	bookID, id, err := parseReviewPath(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.ModerateReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).ModerateReview(bookID, id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
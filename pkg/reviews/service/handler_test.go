@@ -0,0 +1,393 @@
+package service_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware/middlewaretest"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+)
+
+// serve serves a request through the routes of the reviews service on behalf of the actor,
+// anonymously if empty.
+func serve(t *testing.T, s testutil.TestSuite, method string, url string, actor string, input any) *httptest.ResponseRecorder {
+	router := service.InitializeRoutes(http.NewServeMux(), *s.Handler)
+	return middlewaretest.Serve(t, router, method, url, actor, input)
+}
+
+func TestAddReview(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.AddReviewResponse
+		errMsg string
+		errID  int
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		actor      string
+		input      types.AddReviewRequest
+		output     output
+	}{
+		{
+			name:  "rating out of range",
+			actor: "alice",
+			input: types.AddReviewRequest{Rating: 6},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Rating":"It is invalid"}`,
+			},
+		},
+		{
+			name:  "anonymous reviewer",
+			input: types.AddReviewRequest{Rating: 4},
+			output: output{
+				code:   http.StatusUnauthorized,
+				errMsg: "reviewer is required: set the X-Actor header",
+			},
+		},
+		{
+			name:  "book not found",
+			actor: "alice",
+			input: types.AddReviewRequest{Rating: 4},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "book not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "book already reviewed",
+			actor: "alice",
+			input: types.AddReviewRequest{Rating: 4},
+			output: output{
+				code:   http.StatusConflict,
+				errMsg: "review already exists: 3",
+				errID:  3,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReviewByReviewer(1, "alice").Return(&types.Review{ID: 3}, nil)
+			},
+		},
+		{
+			name:  "success",
+			actor: "alice",
+			input: types.AddReviewRequest{Rating: 4, Text: "A classic."},
+			output: output{
+				code: http.StatusCreated,
+				body: types.AddReviewResponse{ID: 3},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReviewByReviewer(1, "alice").Return(nil, gorm.ErrRecordNotFound)
+				s.Repository.EXPECT().CreateReview(types.Review{
+					BookID:   1,
+					Reviewer: "alice",
+					Rating:   4,
+					Text:     "A classic.",
+					Status:   types.REVIEW_STATUS_PENDING,
+				}).Return(types.Review{ID: 3}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodPost, "/api/v1/books/1/reviews", tc.actor, tc.input)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.AddReviewResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+				require.Equal(t, tc.output.errID, res.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateReview(t *testing.T) {
+	approved := types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_APPROVED}
+
+	type output struct {
+		code   int
+		body   types.Review
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		actor      string
+		input      types.UpdateReviewRequest
+		output     output
+	}{
+		{
+			name:  "review of another reviewer",
+			actor: "bob",
+			input: types.UpdateReviewRequest{Rating: 1},
+			output: output{
+				code:   http.StatusForbidden,
+				errMsg: "review belongs to another reviewer",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				review := approved
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReview(1, 3).Return(&review, nil)
+			},
+		},
+		{
+			name:  "review not found",
+			actor: "alice",
+			input: types.UpdateReviewRequest{Rating: 5},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "review not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReview(1, 3).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:  "approved review is withdrawn from the rating until moderated again",
+			actor: "alice",
+			input: types.UpdateReviewRequest{Rating: 5},
+			output: output{
+				code: http.StatusOK,
+				body: types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 5, Status: types.REVIEW_STATUS_PENDING},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				review := approved
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReview(1, 3).Return(&review, nil)
+				s.Repository.EXPECT().UpdateReview(&types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 5, Status: types.REVIEW_STATUS_PENDING}).Return(nil)
+				s.Repository.EXPECT().AddBookRating(1, -1, -4).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodPut, "/api/v1/books/1/reviews/3", tc.actor, tc.input)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Review
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestModerateReview(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.Review
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.ModerateReviewRequest
+		output     output
+	}{
+		{
+			name:  "invalid status",
+			input: types.ModerateReviewRequest{Status: "hidden"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: `{"Status":"It is invalid"}`,
+			},
+		},
+		{
+			name:  "approved review is counted in the rating",
+			input: types.ModerateReviewRequest{Status: types.REVIEW_STATUS_APPROVED},
+			output: output{
+				code: http.StatusOK,
+				body: types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_APPROVED},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReview(1, 3).
+					Return(&types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_PENDING}, nil)
+				s.Repository.EXPECT().UpdateReview(&types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_APPROVED}).Return(nil)
+				s.Repository.EXPECT().AddBookRating(1, 1, 4).Return(nil)
+			},
+		},
+		{
+			name:  "rejected pending review leaves the rating unchanged",
+			input: types.ModerateReviewRequest{Status: types.REVIEW_STATUS_REJECTED},
+			output: output{
+				code: http.StatusOK,
+				body: types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_REJECTED},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.ExpectTransaction()
+				s.Repository.EXPECT().LockBook(1).Return(nil)
+				s.Repository.EXPECT().GetReview(1, 3).
+					Return(&types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_PENDING}, nil)
+				s.Repository.EXPECT().UpdateReview(&types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 4, Status: types.REVIEW_STATUS_REJECTED}).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodPut, "/api/v1/books/1/reviews/3/status", "", tc.input)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Review
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestDeleteReview(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+
+	review := types.Review{ID: 3, BookID: 1, Reviewer: "alice", Rating: 2, Status: types.REVIEW_STATUS_APPROVED}
+	s.ExpectTransaction()
+	s.Repository.EXPECT().LockBook(1).Return(nil)
+	s.Repository.EXPECT().GetReview(1, 3).Return(&review, nil)
+	s.Repository.EXPECT().DeleteReview(&review).Return(nil)
+	s.Repository.EXPECT().AddBookRating(1, -1, -2).Return(nil)
+
+	resp := serve(t, s, http.MethodDelete, "/api/v1/books/1/reviews/3", "alice", nil)
+
+	require.Equal(t, http.StatusOK, resp.Code)
+}
+
+func TestGetReviews(t *testing.T) {
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		query      string
+		code       int
+		errMsg     string
+	}{
+		{
+			name:   "invalid status",
+			query:  "&status=hidden",
+			code:   http.StatusBadRequest,
+			errMsg: "invalid status: hidden",
+		},
+		{
+			name:   "book not found",
+			code:   http.StatusNotFound,
+			errMsg: "book not found",
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(false, nil)
+			},
+		},
+		{
+			name: "approved reviews by default",
+			code: http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().GetReviews(1, types.ReviewFilter{Status: types.REVIEW_STATUS_APPROVED}, 1, 10).
+					Return(&db.Pagination{}, []types.Review{}, nil)
+			},
+		},
+		{
+			name:  "pending reviews",
+			query: "&status=pending",
+			code:  http.StatusOK,
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().BookExists(1).Return(true, nil)
+				s.Repository.EXPECT().GetReviews(1, types.ReviewFilter{Status: types.REVIEW_STATUS_PENDING}, 1, 10).
+					Return(&db.Pagination{}, []types.Review{}, nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			resp := serve(t, s, http.MethodGet, fmt.Sprintf("/api/v1/books/1/reviews?page=1&limit=10%s", tc.query), "", nil)
+
+			require.Equal(t, tc.code, resp.Code)
+
+			if tc.code != http.StatusOK {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.errMsg, res.Message)
+			}
+		})
+	}
+}
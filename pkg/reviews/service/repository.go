@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	booktypes "github.com/nkitlabs/go-http-gorm-example/pkg/books/types"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in a reviews service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new reviews-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// Transaction runs fn with a repository bound to a single database transaction.
+func (r *Repository) Transaction(fn func(d types.DataProvider) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		repo := NewRepository(tx, r.log)
+		return fn(&repo)
+	})
+}
+
+// BookExists checks if the book exists in the database
+func (r *Repository) BookExists(bookID int) (bool, error) {
+	var count int64
+	err := r.db.Model(&booktypes.Book{}).Where("id = ?", bookID).Count(&count).Error
+	return count > 0, err
+}
+
+// LockBook locks the row of a book in the database until the end of the transaction
+func (r *Repository) LockBook(bookID int) error {
+	var book booktypes.Book
+	return r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&book, bookID).Error
+}
+
+// AddBookRating adds to the rating of a book in the database. The rating columns are read-only
+// for the books service, so they are updated with a raw statement.
+func (r *Repository) AddBookRating(bookID int, count int, sum int) error {
+	return r.db.Exec(`UPDATE books SET
+		rating_count = rating_count + ?,
+		rating_sum = rating_sum + ?,
+		rating_average = CASE WHEN rating_count + ? > 0 THEN (rating_sum + ?) * 1.0 / (rating_count + ?) ELSE 0 END
+		WHERE id = ?`, count, sum, count, sum, count, bookID).Error
+}
+
+// CreateReview creates a new review in the database
+func (r *Repository) CreateReview(review types.Review) (types.Review, error) {
+	tx := r.db.Create(&review)
+	return review, tx.Error
+}
+
+// UpdateReview updates a review in the database
+func (r *Repository) UpdateReview(review *types.Review) error {
+	return r.db.Save(review).Error
+}
+
+// DeleteReview deletes a review from the database
+func (r *Repository) DeleteReview(review *types.Review) error {
+	return r.db.Delete(review).Error
+}
+
+// GetReview retrieves a review of a book from the database
+func (r *Repository) GetReview(bookID int, id int) (*types.Review, error) {
+	var review types.Review
+	if result := r.db.Where("book_id = ?", bookID).First(&review, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &review, nil
+}
+
+// GetReviewByReviewer retrieves the review of a book by a reviewer from the database
+func (r *Repository) GetReviewByReviewer(bookID int, reviewer string) (*types.Review, error) {
+	var review types.Review
+	if result := r.db.Where("book_id = ? AND reviewer = ?", bookID, reviewer).First(&review); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &review, nil
+}
+
+// GetReviews retrieves the reviews of a book matching the filter from the database, the latest first
+func (r *Repository) GetReviews(bookID int, filter types.ReviewFilter, page int, limit int) (*db.Pagination, []types.Review, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id desc",
+	}
+
+	tx := r.db.Where("book_id = ?", bookID)
+	if filter.Status != "" {
+		tx = tx.Where("status = ?", filter.Status)
+	}
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	tx = tx.Session(&gorm.Session{})
+
+	var reviews []types.Review
+	if result := tx.Scopes(db.Paginate(&reviews, &p, tx)).Find(&reviews); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, reviews, nil
+}
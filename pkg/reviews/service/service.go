@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+)
+
+// Service is the service layer for the reviews of books
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new reviews service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// AddReview adds the review of a book by the caller, pending moderation
+func (s *Service) AddReview(ctx context.Context, bookID int, req types.AddReviewRequest) (types.AddReviewResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.AddReviewResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	reviewer, err := getReviewer(ctx)
+	if err != nil {
+		return types.AddReviewResponse{}, err
+	}
+
+	var review types.Review
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		if err := lockBook(d, bookID); err != nil {
+			return err
+		}
+
+		existing, err := d.GetReviewByReviewer(bookID, reviewer)
+		if err == nil {
+			return apierror.NewConflictError(fmt.Sprintf("review already exists: %d", existing.ID), existing.ID)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		review, err = d.CreateReview(types.Review{
+			BookID:   bookID,
+			Reviewer: reviewer,
+			Rating:   req.Rating,
+			Text:     req.Text,
+			Status:   types.REVIEW_STATUS_PENDING,
+		})
+		return err
+	})
+	if err != nil {
+		return types.AddReviewResponse{}, err
+	}
+
+	return types.AddReviewResponse{
+		ID: review.ID,
+	}, nil
+}
+
+// UpdateReview edits a review of the caller, which is moderated again
+func (s *Service) UpdateReview(ctx context.Context, bookID int, id int, req types.UpdateReviewRequest) (types.Review, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Review{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	reviewer, err := getReviewer(ctx)
+	if err != nil {
+		return types.Review{}, err
+	}
+
+	var review *types.Review
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		if review, err = lockReview(d, bookID, id); err != nil {
+			return err
+		}
+		if review.Reviewer != reviewer {
+			return apierror.ErrForbidden.WithMessage("review belongs to another reviewer")
+		}
+		before := *review
+
+		if req.Rating != 0 {
+			review.Rating = req.Rating
+		}
+		if req.Text != "" {
+			review.Text = req.Text
+		}
+		review.Status = types.REVIEW_STATUS_PENDING
+		if err := d.UpdateReview(review); err != nil {
+			return err
+		}
+
+		return updateRating(d, bookID, &before, review)
+	})
+	if err != nil {
+		return types.Review{}, err
+	}
+
+	return *review, nil
+}
+
+// DeleteReview deletes a review of the caller
+func (s *Service) DeleteReview(ctx context.Context, bookID int, id int) (types.DeleteReviewResponse, error) {
+	reviewer, err := getReviewer(ctx)
+	if err != nil {
+		return types.DeleteReviewResponse{}, err
+	}
+
+	err = s.dataProvider.Transaction(func(d types.DataProvider) error {
+		review, err := lockReview(d, bookID, id)
+		if err != nil {
+			return err
+		}
+		if review.Reviewer != reviewer {
+			return apierror.ErrForbidden.WithMessage("review belongs to another reviewer")
+		}
+
+		if err := d.DeleteReview(review); err != nil {
+			return err
+		}
+
+		return updateRating(d, bookID, review, nil)
+	})
+	if err != nil {
+		return types.DeleteReviewResponse{}, err
+	}
+
+	return types.DeleteReviewResponse{}, nil
+}
+
+// ModerateReview sets the moderation status of a review
+func (s *Service) ModerateReview(bookID int, id int, req types.ModerateReviewRequest) (types.Review, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Review{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	var review *types.Review
+	err := s.dataProvider.Transaction(func(d types.DataProvider) error {
+		var err error
+		if review, err = lockReview(d, bookID, id); err != nil {
+			return err
+		}
+		before := *review
+
+		review.Status = req.Status
+		if err := d.UpdateReview(review); err != nil {
+			return err
+		}
+
+		return updateRating(d, bookID, &before, review)
+	})
+	if err != nil {
+		return types.Review{}, err
+	}
+
+	return *review, nil
+}
+
+// GetReviews returns the reviews of a book matching the filter, the latest first. Only the
+// approved reviews are listed unless another status is given.
+func (s *Service) GetReviews(bookID int, filter types.ReviewFilter, page int, limit int) (types.GetReviewsResponse, error) {
+	if filter.Status == "" {
+		filter.Status = types.REVIEW_STATUS_APPROVED
+	}
+	validate := validator.New()
+	if err := validate.Struct(filter); err != nil {
+		return types.GetReviewsResponse{}, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid status: %s", filter.Status))
+	}
+
+	exists, err := s.dataProvider.BookExists(bookID)
+	if err != nil {
+		return types.GetReviewsResponse{}, err
+	} else if !exists {
+		return types.GetReviewsResponse{}, apierror.NewNotFoundError("book not found")
+	}
+
+	pagination, reviews, err := s.dataProvider.GetReviews(bookID, filter, page, limit)
+	if err != nil {
+		return types.GetReviewsResponse{}, err
+	}
+
+	return types.GetReviewsResponse{
+		Reviews:    reviews,
+		Pagination: pagination,
+	}, nil
+}
+
+// GetReview returns a review of a book from the given id
+func (s *Service) GetReview(bookID int, id int) (*types.Review, error) {
+	review, err := s.dataProvider.GetReview(bookID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("review not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// getReviewer returns the identity of the caller, who must not be anonymous
+func getReviewer(ctx context.Context) (string, error) {
+	reviewer := middleware.GetActor(ctx)
+	if reviewer == middleware.ActorAnonymous {
+		return "", apierror.ErrUnauthorized.WithMessage(fmt.Sprintf("reviewer is required: set the %s header", middleware.HeaderActor))
+	}
+
+	return reviewer, nil
+}
+
+// lockBook locks the book of the reviews for the rest of the transaction
+func lockBook(d types.DataProvider, bookID int) error {
+	err := d.LockBook(bookID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apierror.NewNotFoundError("book not found")
+	}
+
+	return err
+}
+
+// lockReview locks the book of a review and returns the review
+func lockReview(d types.DataProvider, bookID int, id int) (*types.Review, error) {
+	if err := lockBook(d, bookID); err != nil {
+		return nil, err
+	}
+
+	review, err := d.GetReview(bookID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("review not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// updateRating applies the change of a review, nil when it does not exist, to the rating of its
+// locked book. Only the approved reviews are counted.
+func updateRating(d types.DataProvider, bookID int, before *types.Review, after *types.Review) error {
+	countBefore, sumBefore := ratingOf(before)
+	countAfter, sumAfter := ratingOf(after)
+	if countBefore == countAfter && sumBefore == sumAfter {
+		return nil
+	}
+
+	return d.AddBookRating(bookID, countAfter-countBefore, sumAfter-sumBefore)
+}
+
+// ratingOf returns the number of ratings and their sum a review adds to the rating of its book
+func ratingOf(review *types.Review) (count int, sum int) {
+	if review == nil || review.Status != types.REVIEW_STATUS_APPROVED {
+		return 0, 0
+	}
+
+	return 1, review.Rating
+}
@@ -0,0 +1,202 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/reviews/types/data_provider.go
+//
+// Generated by this command:
+//
+//	mockgen --destination ./pkg/reviews/testutil/mock_data_provider.go --source ./pkg/reviews/types/data_provider.go --package testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	db "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	types "github.com/nkitlabs/go-http-gorm-example/pkg/reviews/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDataProvider is a mock of DataProvider interface.
+type MockDataProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataProviderMockRecorder
+}
+
+// MockDataProviderMockRecorder is the mock recorder for MockDataProvider.
+type MockDataProviderMockRecorder struct {
+	mock *MockDataProvider
+}
+
+// NewMockDataProvider creates a new mock instance.
+func NewMockDataProvider(ctrl *gomock.Controller) *MockDataProvider {
+	mock := &MockDataProvider{ctrl: ctrl}
+	mock.recorder = &MockDataProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataProvider) EXPECT() *MockDataProviderMockRecorder {
+	return m.recorder
+}
+
+// AddBookRating mocks base method.
+func (m *MockDataProvider) AddBookRating(bookID, count, sum int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBookRating", bookID, count, sum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddBookRating indicates an expected call of AddBookRating.
+func (mr *MockDataProviderMockRecorder) AddBookRating(bookID, count, sum any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBookRating", reflect.TypeOf((*MockDataProvider)(nil).AddBookRating), bookID, count, sum)
+}
+
+// BookExists mocks base method.
+func (m *MockDataProvider) BookExists(bookID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BookExists", bookID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BookExists indicates an expected call of BookExists.
+func (mr *MockDataProviderMockRecorder) BookExists(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BookExists", reflect.TypeOf((*MockDataProvider)(nil).BookExists), bookID)
+}
+
+// CreateReview mocks base method.
+func (m *MockDataProvider) CreateReview(review types.Review) (types.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReview", review)
+	ret0, _ := ret[0].(types.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReview indicates an expected call of CreateReview.
+func (mr *MockDataProviderMockRecorder) CreateReview(review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReview", reflect.TypeOf((*MockDataProvider)(nil).CreateReview), review)
+}
+
+// DeleteReview mocks base method.
+func (m *MockDataProvider) DeleteReview(review *types.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReview", review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReview indicates an expected call of DeleteReview.
+func (mr *MockDataProviderMockRecorder) DeleteReview(review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReview", reflect.TypeOf((*MockDataProvider)(nil).DeleteReview), review)
+}
+
+// GetReview mocks base method.
+func (m *MockDataProvider) GetReview(bookID, id int) (*types.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReview", bookID, id)
+	ret0, _ := ret[0].(*types.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReview indicates an expected call of GetReview.
+func (mr *MockDataProviderMockRecorder) GetReview(bookID, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReview", reflect.TypeOf((*MockDataProvider)(nil).GetReview), bookID, id)
+}
+
+// GetReviewByReviewer mocks base method.
+func (m *MockDataProvider) GetReviewByReviewer(bookID int, reviewer string) (*types.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewByReviewer", bookID, reviewer)
+	ret0, _ := ret[0].(*types.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewByReviewer indicates an expected call of GetReviewByReviewer.
+func (mr *MockDataProviderMockRecorder) GetReviewByReviewer(bookID, reviewer any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewByReviewer", reflect.TypeOf((*MockDataProvider)(nil).GetReviewByReviewer), bookID, reviewer)
+}
+
+// GetReviews mocks base method.
+func (m *MockDataProvider) GetReviews(bookID int, filter types.ReviewFilter, page, limit int) (*db.Pagination, []types.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviews", bookID, filter, page, limit)
+	ret0, _ := ret[0].(*db.Pagination)
+	ret1, _ := ret[1].([]types.Review)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReviews indicates an expected call of GetReviews.
+func (mr *MockDataProviderMockRecorder) GetReviews(bookID, filter, page, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviews", reflect.TypeOf((*MockDataProvider)(nil).GetReviews), bookID, filter, page, limit)
+}
+
+// LockBook mocks base method.
+func (m *MockDataProvider) LockBook(bookID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockBook", bookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockBook indicates an expected call of LockBook.
+func (mr *MockDataProviderMockRecorder) LockBook(bookID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockBook", reflect.TypeOf((*MockDataProvider)(nil).LockBook), bookID)
+}
+
+// Transaction mocks base method.
+func (m *MockDataProvider) Transaction(fn func(types.DataProvider) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Transaction", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Transaction indicates an expected call of Transaction.
+func (mr *MockDataProviderMockRecorder) Transaction(fn any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transaction", reflect.TypeOf((*MockDataProvider)(nil).Transaction), fn)
+}
+
+// UpdateReview mocks base method.
+func (m *MockDataProvider) UpdateReview(review *types.Review) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReview", review)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReview indicates an expected call of UpdateReview.
+func (mr *MockDataProviderMockRecorder) UpdateReview(review any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReview", reflect.TypeOf((*MockDataProvider)(nil).UpdateReview), review)
+}
+
+// WithContext mocks base method.
+func (m *MockDataProvider) WithContext(ctx context.Context) types.DataProvider {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithContext", ctx)
+	ret0, _ := ret[0].(types.DataProvider)
+	return ret0
+}
+
+// WithContext indicates an expected call of WithContext.
+func (mr *MockDataProviderMockRecorder) WithContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithContext", reflect.TypeOf((*MockDataProvider)(nil).WithContext), ctx)
+}
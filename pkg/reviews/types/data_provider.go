@@ -0,0 +1,35 @@
+package types
+
+import (
+	"context"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for a reviews service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	// Transaction runs fn with a data provider bound to a single database transaction.
+	// The transaction is committed if fn returns nil, otherwise it is rolled back.
+	Transaction(fn func(d DataProvider) error) error
+
+	BookExists(bookID int) (bool, error)
+	// LockBook locks the row of a book until the end of the transaction, so the rating of the
+	// book is changed by one review at a time and the book is not deleted meanwhile. It returns
+	// gorm.ErrRecordNotFound if the book does not exist.
+	LockBook(bookID int) error
+	// AddBookRating adds to the number and the sum of the ratings of a locked book and updates
+	// its average.
+	AddBookRating(bookID int, count int, sum int) error
+
+	CreateReview(review Review) (Review, error)
+	UpdateReview(review *Review) error
+	DeleteReview(review *Review) error
+	GetReview(bookID int, id int) (*Review, error)
+	GetReviewByReviewer(bookID int, reviewer string) (*Review, error)
+	// GetReviews lists the reviews of a book matching the filter, the latest first.
+	GetReviews(bookID int, filter ReviewFilter, page int, limit int) (*db.Pagination, []Review, error)
+}
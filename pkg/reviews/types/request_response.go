@@ -0,0 +1,34 @@
+package types
+
+import "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+
+// AddReviewRequest is the request for reviewing a book
+type AddReviewRequest struct {
+	Rating int    `json:"rating" example:"4" validate:"required,min=1,max=5"`
+	Text   string `json:"text" example:"A classic." validate:"max=5000"`
+}
+
+// AddReviewResponse is the response for reviewing a book
+type AddReviewResponse struct {
+	ID int `json:"id" example:"1"`
+}
+
+// UpdateReviewRequest is the request for editing a review, which is moderated again
+type UpdateReviewRequest struct {
+	Rating int    `json:"rating" example:"5" validate:"omitempty,min=1,max=5"`
+	Text   string `json:"text" example:"A classic, better on a second read." validate:"max=5000"`
+}
+
+// ModerateReviewRequest is the request for setting the moderation status of a review
+type ModerateReviewRequest struct {
+	Status ReviewStatus `json:"status" example:"approved" validate:"required,oneof=pending approved rejected"`
+}
+
+// GetReviewsResponse is the response for getting the reviews of a book
+type GetReviewsResponse struct {
+	Reviews    []Review       `json:"reviews"`
+	Pagination *db.Pagination `json:"pagination"`
+}
+
+// DeleteReviewResponse is the response for deleting a review
+type DeleteReviewResponse struct{}
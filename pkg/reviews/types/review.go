@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+type ReviewStatus string
+
+const (
+	// REVIEW_STATUS_PENDING is a new or edited review waiting for moderation.
+	REVIEW_STATUS_PENDING  ReviewStatus = "pending"
+	REVIEW_STATUS_APPROVED ReviewStatus = "approved"
+	REVIEW_STATUS_REJECTED ReviewStatus = "rejected"
+)
+
+// Review is the model for the rating and the review of a book by a reader. A reader reviews a
+// book once; only the approved reviews are counted in the rating of the book.
+type Review struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	BookID   int    `json:"book_id" gorm:"not null;uniqueIndex:idx_reviews_book_reviewer" example:"1"`
+	// Reviewer is the identity of the caller who wrote the review.
+	Reviewer  string       `json:"reviewer" gorm:"not null;uniqueIndex:idx_reviews_book_reviewer" example:"alice"`
+	Rating    int          `json:"rating" gorm:"not null" example:"4"`
+	Text      string       `json:"text,omitempty" gorm:"not null;default:''" example:"A classic."`
+	Status    ReviewStatus `json:"status" gorm:"not null;index" example:"approved"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// ReviewFilter narrows down the reviews listed by GetReviews.
+type ReviewFilter struct {
+	Status ReviewStatus `validate:"omitempty,oneof=pending approved rejected"`
+}
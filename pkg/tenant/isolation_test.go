@@ -0,0 +1,295 @@
+package tenant_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	auditservice "github.com/nkitlabs/go-http-gorm-example/pkg/audit/service"
+	authorservice "github.com/nkitlabs/go-http-gorm-example/pkg/authors/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/blob"
+	bookservice "github.com/nkitlabs/go-http-gorm-example/pkg/books/service"
+	classificationservice "github.com/nkitlabs/go-http-gorm-example/pkg/classification/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	coverservice "github.com/nkitlabs/go-http-gorm-example/pkg/covers/service"
+	covertestutil "github.com/nkitlabs/go-http-gorm-example/pkg/covers/testutil"
+	inventoryservice "github.com/nkitlabs/go-http-gorm-example/pkg/inventory/service"
+	listservice "github.com/nkitlabs/go-http-gorm-example/pkg/lists/service"
+	loanservice "github.com/nkitlabs/go-http-gorm-example/pkg/loans/service"
+	loantestutil "github.com/nkitlabs/go-http-gorm-example/pkg/loans/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/middleware"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	reviewservice "github.com/nkitlabs/go-http-gorm-example/pkg/reviews/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+	webhookservice "github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/service"
+)
+
+// dryRunPool is the connection pool of a dry-run database; it only supports the transactions.
+type dryRunPool struct{}
+
+func (dryRunPool) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return nil, errors.New("dry run")
+}
+
+func (dryRunPool) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (dryRunPool) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("dry run")
+}
+
+func (dryRunPool) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+func (p dryRunPool) BeginTx(ctx context.Context, opts *sql.TxOptions) (gorm.ConnPool, error) {
+	return p, nil
+}
+
+func (dryRunPool) Commit() error   { return nil }
+func (dryRunPool) Rollback() error { return nil }
+
+// statement is a statement run on a table of a model owned by a tenant.
+type statement struct {
+	sql  string
+	vars []any
+	err  error
+}
+
+// recorder records the statements run on the tables of the models owned by a tenant.
+type recorder struct {
+	mu         sync.Mutex
+	statements []statement
+}
+
+func (r *recorder) record(db *gorm.DB) {
+	if db.Statement.Schema == nil || db.Statement.Schema.LookUpField(tenant.FieldName) == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statements = append(r.statements, statement{
+		sql:  db.Statement.SQL.String(),
+		vars: append([]any(nil), db.Statement.Vars...),
+		err:  db.Error,
+	})
+}
+
+func (r *recorder) reset() []statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	statements := r.statements
+	r.statements = nil
+	return statements
+}
+
+// newRouter returns the routes of the application on a dry-run database, with the recorder of
+// their statements.
+func newRouter(t *testing.T) (http.Handler, *recorder) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: dryRunPool{}}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenant.Plugin{}))
+
+	rec := &recorder{}
+	callbacks := db.Callback()
+	require.NoError(t, callbacks.Create().After("gorm:create").Register("test:create", rec.record))
+	require.NoError(t, callbacks.Query().After("gorm:query").Register("test:query", rec.record))
+	require.NoError(t, callbacks.Row().After("gorm:row").Register("test:row", rec.record))
+	require.NoError(t, callbacks.Update().After("gorm:update").Register("test:update", rec.record))
+	require.NoError(t, callbacks.Delete().After("gorm:delete").Register("test:delete", rec.record))
+
+	log := zap.NewNop()
+
+	bookRepository := bookservice.NewRepository(db, log, classificationservice.BookClassifier{}, inventoryservice.BookInventory{}, outbox.WriteBookChange, auditservice.WriteBookChange, authorservice.WriteBookChange, classificationservice.WriteBookChange, inventoryservice.WriteBookChange, reviewservice.WriteBookChange, listservice.WriteBookChange)
+	bookService := bookservice.NewService(&bookRepository, log)
+
+	store, err := blob.NewFSStore(t.TempDir())
+	require.NoError(t, err)
+	coverService := coverservice.NewService(&bookRepository, store, covertestutil.TestConfig, log)
+
+	auditRepository := auditservice.NewRepository(db, log)
+	auditService := auditservice.NewService(&auditRepository, log)
+	authorRepository := authorservice.NewRepository(db, log)
+	authorService := authorservice.NewService(&authorRepository, log)
+	classificationRepository := classificationservice.NewRepository(db, log)
+	classificationService := classificationservice.NewService(&classificationRepository, log)
+	inventoryRepository := inventoryservice.NewRepository(db, log)
+	inventoryService := inventoryservice.NewService(&inventoryRepository, log)
+	loanRepository := loanservice.NewRepository(db, log)
+	loanService := loanservice.NewService(&loanRepository, loantestutil.TestConfig, log)
+	reviewRepository := reviewservice.NewRepository(db, log)
+	reviewService := reviewservice.NewService(&reviewRepository, log)
+	listRepository := listservice.NewRepository(db, log)
+	listService := listservice.NewService(&listRepository, log)
+	webhookRepository := webhookservice.NewRepository(db, log)
+	webhookService := webhookservice.NewService(&webhookRepository, log)
+
+	router := http.NewServeMux()
+	router = bookservice.InitializeRoutes(router, bookservice.NewHandler(&bookService, log))
+	router = coverservice.InitializeRoutes(router, coverservice.NewHandler(&coverService, log))
+	router = auditservice.InitializeRoutes(router, auditservice.NewHandler(&auditService, log))
+	router = authorservice.InitializeRoutes(router, authorservice.NewHandler(&authorService, log))
+	router = classificationservice.InitializeRoutes(router, classificationservice.NewHandler(&classificationService, log))
+	router = inventoryservice.InitializeRoutes(router, inventoryservice.NewHandler(&inventoryService, log))
+	router = loanservice.InitializeRoutes(router, loanservice.NewHandler(&loanService, log))
+	router = reviewservice.InitializeRoutes(router, reviewservice.NewHandler(&reviewService, log))
+	router = listservice.InitializeRoutes(router, listservice.NewHandler(&listService, log))
+	router = webhookservice.InitializeRoutes(router, webhookservice.NewHandler(&webhookService, log))
+
+	conf := config.Tenancy{
+		Enabled: true,
+		Header:  "X-Tenant",
+		Tenants: map[string]config.Tenant{"school-a": {}, "school-b": {}},
+	}
+	handler := tenant.Middleware(conf, log)(router)
+	return middleware.InjectCaller(handler), rec
+}
+
+func pngImage(t *testing.T) string {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))))
+	return buf.String()
+}
+
+// TestIsolation checks that every statement run by the endpoints on the tables owned by a tenant
+// is scoped to the tenant of the request.
+func TestIsolation(t *testing.T) {
+	handler, rec := newRouter(t)
+
+	testCases := []struct {
+		method      string
+		url         string
+		contentType string
+		body        string
+	}{
+		{method: http.MethodGet, url: "/api/v1/books?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/books?page=1&limit=10&facets=tags&tag=fantasy&subject_id=1"},
+		{method: http.MethodGet, url: "/api/v1/books/1"},
+		{method: http.MethodGet, url: "/api/v1/books/isbn/9780306406157"},
+		{method: http.MethodGet, url: "/api/v1/books/export"},
+		{method: http.MethodPost, url: "/api/v1/books", body: `{"title":"a","author":"b & c","description":"d"}`},
+		{method: http.MethodPost, url: "/api/v1/books/import", contentType: "text/csv", body: "title,author,description\na,b,c\n"},
+		{method: http.MethodPost, url: "/api/v1/books/batch", body: `{"mode":"atomic","operations":[{"op":"create","body":{"title":"a","author":"b","description":"c"}},{"op":"update","id":1,"body":{"title":"b"}},{"op":"delete","id":2}]}`},
+		{method: http.MethodPut, url: "/api/v1/books/1", body: `{"title":"b","author":"c"}`},
+		{method: http.MethodDelete, url: "/api/v1/books/1"},
+		{method: http.MethodGet, url: "/api/v1/books/1/revisions?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/books/1/revisions/1"},
+		{method: http.MethodGet, url: "/api/v1/books/1/revisions/diff?from=1&to=2"},
+		{method: http.MethodPost, url: "/api/v1/books/1/revisions/1/restore"},
+		{method: http.MethodGet, url: "/api/v1/books/1/cover"},
+		{method: http.MethodGet, url: "/api/v1/books/1/cover/small"},
+		{method: http.MethodPut, url: "/api/v1/books/1/cover", contentType: "image/png", body: pngImage(t)},
+		{method: http.MethodDelete, url: "/api/v1/books/1/cover"},
+		{method: http.MethodGet, url: "/api/v1/audit?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/audit/verify"},
+		{method: http.MethodGet, url: "/api/v1/authors?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/authors/1"},
+		{method: http.MethodGet, url: "/api/v1/authors/1/books?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/authors", body: `{"name":"a"}`},
+		{method: http.MethodPut, url: "/api/v1/authors/1", body: `{"name":"b"}`},
+		{method: http.MethodDelete, url: "/api/v1/authors/1"},
+		{method: http.MethodGet, url: "/api/v1/books/1/authors"},
+		{method: http.MethodPut, url: "/api/v1/books/1/authors", body: `{"authors":[{"author_id":1,"role":"editor"}]}`},
+		{method: http.MethodGet, url: "/api/v1/tags?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/books/1/tags"},
+		{method: http.MethodPut, url: "/api/v1/books/1/tags", body: `{"tags":["fantasy"]}`},
+		{method: http.MethodGet, url: "/api/v1/subjects?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/subjects/1"},
+		{method: http.MethodPost, url: "/api/v1/subjects", body: `{"name":"a"}`},
+		{method: http.MethodPut, url: "/api/v1/subjects/1", body: `{"name":"b"}`},
+		{method: http.MethodDelete, url: "/api/v1/subjects/1"},
+		{method: http.MethodGet, url: "/api/v1/books/1/subjects"},
+		{method: http.MethodPut, url: "/api/v1/books/1/subjects", body: `{"subject_ids":[1]}`},
+		{method: http.MethodGet, url: "/api/v1/books/1/copies?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/books/1/copies", body: `{"barcode":"a","branch":"b"}`},
+		{method: http.MethodGet, url: "/api/v1/books/1/copies/1"},
+		{method: http.MethodPut, url: "/api/v1/books/1/copies/1", body: `{"branch":"c"}`},
+		{method: http.MethodDelete, url: "/api/v1/books/1/copies/1"},
+		{method: http.MethodGet, url: "/api/v1/members?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/members", body: `{"name":"a","email":"a@example.com"}`},
+		{method: http.MethodGet, url: "/api/v1/members/1"},
+		{method: http.MethodPut, url: "/api/v1/members/1", body: `{"name":"b"}`},
+		{method: http.MethodGet, url: "/api/v1/members/1/loans?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/members/1/holds?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/members/1/account?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/members/1/payments", body: `{"amount":1}`},
+		{method: http.MethodGet, url: "/api/v1/loans?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/loans", body: `{"member_id":1,"copy_id":1}`},
+		{method: http.MethodGet, url: "/api/v1/loans/1"},
+		{method: http.MethodPost, url: "/api/v1/loans/1/return"},
+		{method: http.MethodPost, url: "/api/v1/loans/1/renew"},
+		{method: http.MethodGet, url: "/api/v1/holds?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/holds", body: `{"member_id":1,"book_id":1}`},
+		{method: http.MethodGet, url: "/api/v1/holds/1"},
+		{method: http.MethodPost, url: "/api/v1/holds/1/cancel"},
+		{method: http.MethodGet, url: "/api/v1/books/1/reviews?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/books/1/reviews", body: `{"rating":4}`},
+		{method: http.MethodGet, url: "/api/v1/books/1/reviews/1"},
+		{method: http.MethodPut, url: "/api/v1/books/1/reviews/1", body: `{"rating":5}`},
+		{method: http.MethodDelete, url: "/api/v1/books/1/reviews/1"},
+		{method: http.MethodPut, url: "/api/v1/books/1/reviews/1/status", body: `{"status":"approved"}`},
+		{method: http.MethodGet, url: "/api/v1/lists?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/lists", body: `{"name":"To read"}`},
+		{method: http.MethodGet, url: "/api/v1/lists/1"},
+		{method: http.MethodPut, url: "/api/v1/lists/1", body: `{"name":"Read"}`},
+		{method: http.MethodDelete, url: "/api/v1/lists/1"},
+		{method: http.MethodPost, url: "/api/v1/lists/1/items", body: `{"book_id":1}`},
+		{method: http.MethodPut, url: "/api/v1/lists/1/items", body: `{"book_ids":[]}`},
+		{method: http.MethodDelete, url: "/api/v1/lists/1/items/1"},
+		{method: http.MethodGet, url: "/api/v1/shared-lists/alice-to-read"},
+		{method: http.MethodGet, url: "/api/v1/webhooks?page=1&limit=10"},
+		{method: http.MethodGet, url: "/api/v1/webhooks/1"},
+		{method: http.MethodPost, url: "/api/v1/webhooks", body: `{"url":"https://example.com","secret":"a-secret-of-16-chars","events":["book.created"]}`},
+		{method: http.MethodPut, url: "/api/v1/webhooks/1", body: `{"events":["book.deleted"]}`},
+		{method: http.MethodDelete, url: "/api/v1/webhooks/1"},
+		{method: http.MethodGet, url: "/api/v1/webhooks/1/deliveries?page=1&limit=10"},
+		{method: http.MethodPost, url: "/api/v1/webhooks/deliveries/1/redeliver"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.method+" "+tc.url, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.url, strings.NewReader(tc.body))
+			req.Header.Set("X-Tenant", "school-a")
+			req.Header.Set(middleware.HeaderActor, "alice")
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			} else if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			statements := rec.reset()
+			require.NotEmpty(t, statements, "no statement run: %d %s", resp.Code, resp.Body.String())
+			for _, s := range statements {
+				require.NotErrorIs(t, s.err, tenant.ErrMissingTenant, s.sql)
+				require.Contains(t, s.sql, `"tenant_id"`, s.sql)
+				require.Contains(t, s.vars, "school-a", s.sql)
+				require.NotContains(t, fmt.Sprint(s.vars), "school-b", s.sql)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package tenant
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+)
+
+// Middleware puts the tenant of the request into its context, which scopes the queries of the
+// repositories to the tenant. When tenancy is disabled every request is in the DEFAULT tenant.
+// Otherwise the tenant is named by the claim of the bearer token, the subdomain or the tenant
+// header of the request; the ones given must agree and name a configured tenant.
+func Middleware(conf config.Tenancy, log *zap.Logger) func(http.Handler) http.HandlerFunc {
+	return func(next http.Handler) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if !conf.Enabled {
+				next.ServeHTTP(w, r.WithContext(NewContext(ctx, DEFAULT)))
+				return
+			}
+
+			id, err := resolve(r, conf, time.Now())
+			if err != nil {
+				response.WriteError(ctx, w, err, log)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(NewContext(ctx, id)))
+		}
+	}
+}
+
+// resolve finds the tenant of the request.
+func resolve(r *http.Request, conf config.Tenancy, now time.Time) (string, error) {
+	var found []string
+
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && conf.TokenSecret != "" {
+		claim, err := tokenClaim(token, []byte(conf.TokenSecret), conf.Claim, now)
+		if err != nil {
+			return "", apierror.ErrUnauthorized.WithMessage(err.Error())
+		}
+		if claim != "" {
+			found = append(found, claim)
+		}
+	}
+	if sub := subdomain(r.Host, conf.Domain); sub != "" {
+		found = append(found, sub)
+	}
+	if header := r.Header.Get(conf.Header); header != "" {
+		found = append(found, header)
+	}
+
+	if len(found) == 0 {
+		return "", apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("tenant is required: set the %s header", conf.Header))
+	}
+
+	id := NormalizeID(found[0])
+	for _, other := range found[1:] {
+		if NormalizeID(other) != id {
+			return "", apierror.ErrForbidden.WithMessage(fmt.Sprintf("tenant mismatch: %s and %s", found[0], other))
+		}
+	}
+	if _, ok := conf.Tenants[id]; !ok || !IsValidID(id) {
+		return "", apierror.NewNotFoundError(fmt.Sprintf("tenant not found: %s", found[0]))
+	}
+
+	return id, nil
+}
+
+// subdomain returns the label of the host right under the domain, if any.
+func subdomain(host string, domain string) string {
+	if domain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	sub, ok := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(domain))
+	if !ok || strings.Contains(sub, ".") {
+		return ""
+	}
+
+	return sub
+}
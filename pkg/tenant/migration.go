@@ -0,0 +1,43 @@
+package tenant
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// ScopeUniqueIndexes is the migration dropping the unique indexes replaced by the ones starting
+// with the tenant, so an ISBN, a barcode or an email can be used once per tenant. The rows
+// existing before are in the DEFAULT tenant.
+var ScopeUniqueIndexes = db.Migration{
+	ID:      "0002_scope_unique_indexes_by_tenant",
+	Migrate: scopeUniqueIndexes,
+}
+
+// replacedIndexes are the unique indexes replaced by the ones starting with the tenant, by table.
+var replacedIndexes = []struct {
+	table string
+	index string
+}{
+	{"books", "idx_books_isbn13"},
+	{"authors", "idx_authors_normalized_name"},
+	{"tags", "idx_tags_name"},
+	{"subjects", "idx_subjects_code"},
+	{"book_copies", "idx_book_copies_barcode"},
+	{"members", "idx_members_email"},
+	{"reading_lists", "idx_reading_lists_slug"},
+}
+
+func scopeUniqueIndexes(tx *gorm.DB) error {
+	m := tx.Migrator()
+	for _, r := range replacedIndexes {
+		if !m.HasIndex(r.table, r.index) {
+			continue
+		}
+		if err := m.DropIndex(r.table, r.index); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package tenant
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FieldName is the name of the field holding the tenant of the models owned by a tenant.
+const FieldName = "TenantID"
+
+// ErrMissingTenant is returned by a query of a model owned by a tenant whose context has no
+// tenant and is not explicitly for all tenants.
+var ErrMissingTenant = errors.New("tenant: query without a tenant in its context")
+
+var (
+	_ gorm.Plugin = Plugin{}
+)
+
+// Plugin is the gorm plugin scoping the queries of the models having a TenantID field to the
+// tenant of their context: the rows of other tenants are neither read, updated nor deleted, and
+// created rows are in the tenant of the context. Raw SQL is not scoped.
+type Plugin struct{}
+
+func (Plugin) Name() string { return "tenant" }
+
+// Initialize registers the callbacks of the plugin.
+func (Plugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("tenant:create", setTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Query().Before("gorm:query").Register("tenant:query", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("tenant:row", scopeTenant); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("tenant:update", scopeTenant); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:delete").Register("tenant:delete", scopeTenant)
+}
+
+// tenantField returns the tenant field of the model of the statement, or nil if the model is not
+// owned by a tenant.
+func tenantField(db *gorm.DB) *schema.Field {
+	if db.Statement.Schema == nil {
+		return nil
+	}
+
+	return db.Statement.Schema.LookUpField(FieldName)
+}
+
+// currentTenant returns the tenant of the statement, or false if it is for all tenants. It adds
+// ErrMissingTenant to the statement if its context has neither.
+func currentTenant(db *gorm.DB) (string, bool) {
+	id, ok, err := Scope(db.Statement.Context)
+	if err != nil {
+		db.AddError(err)
+	}
+
+	return id, ok
+}
+
+// scopeTenant narrows the statement down to the rows of the tenant.
+func scopeTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || db.Error != nil {
+		return
+	}
+
+	id, ok := currentTenant(db)
+	if !ok {
+		return
+	}
+
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id},
+	}})
+}
+
+// setTenant sets the tenant of the created rows. An upsert does not update the row of another
+// tenant.
+func setTenant(db *gorm.DB) {
+	field := tenantField(db)
+	if field == nil || db.Error != nil {
+		return
+	}
+
+	id, ok := currentTenant(db)
+	if !ok {
+		return
+	}
+
+	ctx := db.Statement.Context
+	switch rv := db.Statement.ReflectValue; rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			db.AddError(field.Set(ctx, reflect.Indirect(rv.Index(i)), id))
+		}
+	case reflect.Struct:
+		db.AddError(field.Set(ctx, rv, id))
+	}
+
+	if c, ok := db.Statement.Clauses["ON CONFLICT"]; ok {
+		if onConflict, ok := c.Expression.(clause.OnConflict); ok && !onConflict.DoNothing {
+			onConflict.Where.Exprs = append(onConflict.Where.Exprs,
+				clause.Eq{Column: clause.Column{Table: db.Statement.Table, Name: field.DBName}, Value: id})
+			db.Statement.AddClause(onConflict)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package tenant
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// DEFAULT is the tenant of every request when tenancy is disabled, and of the rows written
+// before it was enabled.
+const DEFAULT = "default"
+
+// idPattern is the pattern of a tenant id, usable as a subdomain.
+var idPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+type contextKey string
+
+const (
+	contextKeyTenant     contextKey = "tenant"
+	contextKeyAllTenants contextKey = "all_tenants"
+)
+
+// NewContext returns a copy of ctx whose queries are scoped to the tenant.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKeyTenant, id)
+}
+
+// FromContext retrieves the tenant from the context.
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(contextKeyTenant).(string)
+	return id, ok
+}
+
+// AllTenants returns a copy of ctx whose queries are not scoped to a tenant, for the jobs
+// processing the rows of every tenant. A tenant set in the context still takes precedence.
+func AllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKeyAllTenants, true)
+}
+
+// Scope returns the tenant the queries of ctx are scoped to, or false if they are explicitly for
+// all tenants. It returns ErrMissingTenant if ctx has neither.
+func Scope(ctx context.Context) (string, bool, error) {
+	if id, ok := FromContext(ctx); ok {
+		return id, true, nil
+	}
+	if !isAllTenants(ctx) {
+		return "", false, ErrMissingTenant
+	}
+
+	return "", false, nil
+}
+
+// isAllTenants reports whether the queries of ctx are explicitly not scoped to a tenant.
+func isAllTenants(ctx context.Context) bool {
+	all, _ := ctx.Value(contextKeyAllTenants).(bool)
+	return all
+}
+
+// NormalizeID returns the tenant id in lower case without surrounding spaces, as the ids of the
+// configured tenants.
+func NormalizeID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// IsValidID reports whether the normalized id is a valid tenant id.
+func IsValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
@@ -0,0 +1,288 @@
+package tenant_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+)
+
+// signToken returns a JSON web token with the claims signed with HS256 by the secret.
+func signToken(t *testing.T, secret string, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestMiddleware(t *testing.T) {
+	conf := config.Tenancy{
+		Enabled:     true,
+		Header:      "X-Tenant",
+		Domain:      "catalog.example.com",
+		Claim:       "tenant",
+		TokenSecret: "secret",
+		Tenants:     map[string]config.Tenant{"school-a": {}, "school-b": {}},
+	}
+
+	testCases := []struct {
+		name    string
+		conf    config.Tenancy
+		host    string
+		headers map[string]string
+		code    int
+		tenant  string
+		errMsg  string
+	}{
+		{
+			name:   "disabled",
+			conf:   config.Tenancy{Header: "X-Tenant"},
+			host:   "school-a.catalog.example.com",
+			code:   http.StatusOK,
+			tenant: tenant.DEFAULT,
+		},
+		{
+			name:    "header",
+			conf:    conf,
+			headers: map[string]string{"X-Tenant": "School-A"},
+			code:    http.StatusOK,
+			tenant:  "school-a",
+		},
+		{
+			name:   "subdomain",
+			conf:   conf,
+			host:   "school-b.catalog.example.com:8080",
+			code:   http.StatusOK,
+			tenant: "school-b",
+		},
+		{
+			name:    "token claim",
+			conf:    conf,
+			headers: map[string]string{"Authorization": "Bearer " + signToken(t, "secret", map[string]any{"tenant": "school-a"})},
+			code:    http.StatusOK,
+			tenant:  "school-a",
+		},
+		{
+			name:    "token and subdomain agree",
+			conf:    conf,
+			host:    "school-a.catalog.example.com",
+			headers: map[string]string{"Authorization": "Bearer " + signToken(t, "secret", map[string]any{"tenant": "school-a"})},
+			code:    http.StatusOK,
+			tenant:  "school-a",
+		},
+		{
+			name:    "token for another tenant",
+			conf:    conf,
+			host:    "school-b.catalog.example.com",
+			headers: map[string]string{"Authorization": "Bearer " + signToken(t, "secret", map[string]any{"tenant": "school-a"})},
+			code:    http.StatusForbidden,
+			errMsg:  "tenant mismatch: school-a and school-b",
+		},
+		{
+			name:    "token signed by another secret",
+			conf:    conf,
+			headers: map[string]string{"Authorization": "Bearer " + signToken(t, "other", map[string]any{"tenant": "school-a"})},
+			code:    http.StatusUnauthorized,
+			errMsg:  "invalid token",
+		},
+		{
+			name:    "expired token",
+			conf:    conf,
+			headers: map[string]string{"Authorization": "Bearer " + signToken(t, "secret", map[string]any{"tenant": "school-a", "exp": time.Now().Add(-time.Minute).Unix()})},
+			code:    http.StatusUnauthorized,
+			errMsg:  "token is expired",
+		},
+		{
+			name:   "no tenant",
+			conf:   conf,
+			host:   "catalog.example.com",
+			code:   http.StatusBadRequest,
+			errMsg: "tenant is required: set the X-Tenant header",
+		},
+		{
+			name:    "unknown tenant",
+			conf:    conf,
+			headers: map[string]string{"X-Tenant": "school-c"},
+			code:    http.StatusNotFound,
+			errMsg:  "tenant not found: school-c",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			handler := tenant.Middleware(tc.conf, zap.NewNop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got, _ = tenant.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/books", nil)
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+			for k, v := range tc.headers {
+				req.Header.Set(k, v)
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.code, resp.Code)
+
+			if tc.code == http.StatusOK {
+				require.Equal(t, tc.tenant, got)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+// record is a model owned by a tenant.
+type record struct {
+	ID       int
+	TenantID string `gorm:"<-:create;not null;default:'default';index"`
+	Name     string
+}
+
+// setting is a model shared by the tenants.
+type setting struct {
+	ID   int
+	Name string
+}
+
+// newDryRunDB opens a database generating the SQL of the statements without running them.
+func newDryRunDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Use(tenant.Plugin{}))
+
+	return db
+}
+
+func TestPlugin(t *testing.T) {
+	db := newDryRunDB(t)
+	ctx := tenant.NewContext(context.Background(), "school-a")
+
+	testCases := []struct {
+		name string
+		run  func(db *gorm.DB) *gorm.DB
+		sql  string
+		vars []any
+	}{
+		{
+			name: "find",
+			run: func(db *gorm.DB) *gorm.DB {
+				var records []record
+				return db.WithContext(ctx).Where("name = ?", "x").Find(&records)
+			},
+			sql:  `SELECT * FROM "records" WHERE name = $1 AND "records"."tenant_id" = $2`,
+			vars: []any{"x", "school-a"},
+		},
+		{
+			name: "count",
+			run: func(db *gorm.DB) *gorm.DB {
+				var count int64
+				return db.WithContext(ctx).Model(&record{}).Count(&count)
+			},
+			sql:  `SELECT count(*) FROM "records" WHERE "records"."tenant_id" = $1`,
+			vars: []any{"school-a"},
+		},
+		{
+			name: "create",
+			run: func(db *gorm.DB) *gorm.DB {
+				return db.WithContext(ctx).Create(&record{Name: "x", TenantID: "school-b"})
+			},
+			sql:  `INSERT INTO "records" ("tenant_id","name") VALUES ($1,$2) RETURNING "id"`,
+			vars: []any{"school-a", "x"},
+		},
+		{
+			name: "upsert",
+			run: func(db *gorm.DB) *gorm.DB {
+				return db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&record{ID: 1, Name: "x"})
+			},
+			sql:  `INSERT INTO "records" ("tenant_id","name","id") VALUES ($1,$2,$3) ON CONFLICT ("id") DO UPDATE SET "name"="excluded"."name" WHERE "records"."tenant_id" = $4  RETURNING "id"`,
+			vars: []any{"school-a", "x", 1, "school-a"},
+		},
+		{
+			name: "save",
+			run: func(db *gorm.DB) *gorm.DB {
+				return db.WithContext(ctx).Save(&record{ID: 1, Name: "x", TenantID: "school-b"})
+			},
+			sql:  `UPDATE "records" SET "name"=$1 WHERE "records"."tenant_id" = $2 AND "id" = $3`,
+			vars: []any{"x", "school-a", 1},
+		},
+		{
+			name: "delete",
+			run: func(db *gorm.DB) *gorm.DB {
+				return db.WithContext(ctx).Delete(&record{ID: 1})
+			},
+			sql:  `DELETE FROM "records" WHERE "records"."tenant_id" = $1 AND "records"."id" = $2`,
+			vars: []any{"school-a", 1},
+		},
+		{
+			name: "all tenants",
+			run: func(db *gorm.DB) *gorm.DB {
+				var records []record
+				return db.WithContext(tenant.AllTenants(context.Background())).Find(&records)
+			},
+			sql: `SELECT * FROM "records"`,
+		},
+		{
+			name: "model shared by the tenants",
+			run: func(db *gorm.DB) *gorm.DB {
+				var settings []setting
+				return db.Find(&settings)
+			},
+			sql: `SELECT * FROM "settings"`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := tc.run(db)
+			require.NoError(t, tx.Error)
+
+			require.Equal(t, tc.sql, tx.Statement.SQL.String())
+			require.Equal(t, len(tc.vars), len(tx.Statement.Vars))
+			for i, v := range tc.vars {
+				require.EqualValues(t, v, tx.Statement.Vars[i])
+			}
+		})
+	}
+}
+
+func TestPluginMissingTenant(t *testing.T) {
+	db := newDryRunDB(t)
+
+	var records []record
+	err := db.Find(&records).Error
+
+	require.ErrorIs(t, err, tenant.ErrMissingTenant)
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/tenant"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
+)
+
+var (
+	_ outbox.Sink = &Sink{}
+)
+
+// Sink is the outbox sink that turns the published events into webhook deliveries.
+type Sink struct {
+	serv *Service
+}
+
+// NewSink creates a new outbox sink for the webhook subscriptions
+func NewSink(serv *Service) *Sink {
+	return &Sink{serv}
+}
+
+func (s *Sink) Name() string { return "webhooks" }
+
+// Publish enqueues the deliveries of the event to the subscriptions of the tenant of the event.
+func (s *Sink) Publish(ctx context.Context, event outbox.Event) error {
+	return s.serv.WithContext(tenant.NewContext(ctx, event.TenantID)).EnqueueEvent(event)
+}
+
+// Dispatcher sends the pending deliveries to the subscribers. A failed attempt is retried with
+// an exponential backoff until the maximum number of attempts, then the delivery is dead.
+type Dispatcher struct {
+	dataProvider types.DataProvider
+	client       *http.Client
+	conf         config.Webhooks
+	log          *zap.Logger
+}
+
+// NewDispatcher creates a new dispatcher of webhook deliveries
+func NewDispatcher(d types.DataProvider, client *http.Client, conf config.Webhooks, log *zap.Logger) *Dispatcher {
+	return &Dispatcher{
+		dataProvider: d,
+		client:       client,
+		conf:         conf,
+		log:          log,
+	}
+}
+
+// Run sends the due deliveries periodically until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.conf.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := d.DispatchDue(ctx, time.Now())
+		if err != nil {
+			d.log.Error(fmt.Sprintf("failed to dispatch webhook deliveries: %v", err))
+		}
+
+		// a full batch means there may be more deliveries waiting, so poll again right away.
+		if err == nil && n == d.conf.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue sends a batch of deliveries of every tenant due at the given time concurrently and
+// returns the number of attempted deliveries.
+func (d *Dispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	dataProvider := d.dataProvider.WithContext(tenant.AllTenants(ctx))
+
+	// the lease covers the time to send the batch, after that the deliveries can be claimed again.
+	deliveries, err := dataProvider.ClaimDueDeliveries(now, d.conf.Timeout+time.Minute, d.conf.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := make(map[int]*types.Subscription)
+	for _, delivery := range deliveries {
+		if _, ok := subs[delivery.SubscriptionID]; ok {
+			continue
+		}
+
+		sub, err := dataProvider.GetSubscription(delivery.SubscriptionID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, err
+		}
+		subs[delivery.SubscriptionID] = sub
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *types.Delivery) {
+			defer wg.Done()
+
+			d.attempt(ctx, subs[delivery.SubscriptionID], delivery)
+			if err := dataProvider.UpdateDelivery(delivery); err != nil {
+				d.log.Error(fmt.Sprintf("failed to update webhook delivery %d: %v", delivery.ID, err))
+			}
+		}(&deliveries[i])
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery once and records the result into it.
+func (d *Dispatcher) attempt(ctx context.Context, sub *types.Subscription, delivery *types.Delivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.LastStatusCode = 0
+	delivery.LastError = ""
+
+	if sub == nil || !sub.Active {
+		delivery.Status = types.DELIVERY_STATUS_DEAD
+		delivery.LastError = "subscription is not active"
+		return
+	}
+
+	code, err := d.send(ctx, sub, delivery)
+	delivery.LastStatusCode = code
+	if err == nil {
+		delivery.Status = types.DELIVERY_STATUS_SUCCEEDED
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= d.conf.MaxAttempts {
+		delivery.Status = types.DELIVERY_STATUS_DEAD
+		return
+	}
+
+	delivery.Status = types.DELIVERY_STATUS_PENDING
+	delivery.NextAttemptAt = now.Add(d.backoff(delivery.Attempts))
+}
+
+// send posts the signed payload of the delivery to the subscriber and returns the response status.
+func (d *Dispatcher) send(ctx context.Context, sub *types.Subscription, delivery *types.Delivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.conf.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, strconv.Itoa(delivery.ID))
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, time.Now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt: the initial backoff doubled after
+// every failed attempt, capped at the maximum backoff.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.conf.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.conf.MaxBackoff {
+			return d.conf.MaxBackoff
+		}
+	}
+
+	return delay
+}
@@ -0,0 +1,164 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"go.uber.org/zap"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/config"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
+)
+
+const testSecret = "test-secret-0123456789"
+
+func TestDispatchDue(t *testing.T) {
+	conf := config.Webhooks{
+		BatchSize:      10,
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+	}
+
+	testCases := []struct {
+		name       string
+		status     int
+		attempts   int
+		active     bool
+		out        types.DeliveryStatus
+		outBackoff time.Duration
+	}{
+		{
+			name:     "success",
+			status:   http.StatusOK,
+			attempts: 0,
+			active:   true,
+			out:      types.DELIVERY_STATUS_SUCCEEDED,
+		},
+		{
+			name:       "retry with backoff",
+			status:     http.StatusInternalServerError,
+			attempts:   1,
+			active:     true,
+			out:        types.DELIVERY_STATUS_PENDING,
+			outBackoff: 2 * time.Second,
+		},
+		{
+			name:     "dead after max attempts",
+			status:   http.StatusInternalServerError,
+			attempts: 2,
+			active:   true,
+			out:      types.DELIVERY_STATUS_DEAD,
+		},
+		{
+			name:     "dead on inactive subscription",
+			attempts: 0,
+			active:   false,
+			out:      types.DELIVERY_STATUS_DEAD,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload := []byte(`{"id":7,"type":"book.created"}`)
+
+			var received int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				received++
+				body, _ := io.ReadAll(r.Body)
+				require.Equal(t, payload, body)
+				require.Equal(t, "book.created", r.Header.Get(service.HeaderEvent))
+				require.Equal(t, "1", r.Header.Get(service.HeaderDelivery))
+				require.NoError(t, service.Verify(testSecret, r.Header.Get(service.HeaderSignature), body, time.Now(), time.Minute))
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			s := testutil.NewTestSuite(t)
+			now := time.Now()
+			s.Repository.EXPECT().ClaimDueDeliveries(now, gomock.Any(), conf.BatchSize).Return([]types.Delivery{{
+				ID:             1,
+				SubscriptionID: 1,
+				EventID:        7,
+				EventType:      "book.created",
+				Payload:        payload,
+				Status:         types.DELIVERY_STATUS_PENDING,
+				Attempts:       tc.attempts,
+			}}, nil)
+			s.Repository.EXPECT().GetSubscription(1).Return(&types.Subscription{
+				ID:     1,
+				URL:    server.URL,
+				Secret: testSecret,
+				Events: []string{"*"},
+				Active: tc.active,
+			}, nil)
+
+			var updated types.Delivery
+			s.Repository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(d *types.Delivery) error {
+				updated = *d
+				return nil
+			})
+
+			dispatcher := service.NewDispatcher(s.Repository, server.Client(), conf, zap.NewNop())
+			n, err := dispatcher.DispatchDue(context.Background(), now)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			require.Equal(t, tc.out, updated.Status)
+			if tc.active {
+				require.Equal(t, 1, received)
+				require.Equal(t, tc.status, updated.LastStatusCode)
+				require.Equal(t, tc.attempts+1, updated.Attempts)
+			} else {
+				require.Zero(t, received)
+			}
+			if tc.outBackoff > 0 {
+				require.WithinDuration(t, time.Now().Add(tc.outBackoff), updated.NextAttemptAt, time.Second)
+			}
+		})
+	}
+}
+
+func TestEnqueueEvent(t *testing.T) {
+	s := testutil.NewTestSuite(t)
+	s.Repository.EXPECT().GetActiveSubscriptions().Return([]types.Subscription{
+		{ID: 1, Events: []string{"book.*"}},
+		{ID: 2, Events: []string{"book.deleted"}},
+		{ID: 3, Events: []string{"*"}},
+	}, nil)
+	s.Repository.EXPECT().CreateDeliveries(gomock.Any()).DoAndReturn(func(deliveries []types.Delivery) error {
+		require.Len(t, deliveries, 2)
+		require.Equal(t, 1, deliveries[0].SubscriptionID)
+		require.Equal(t, 3, deliveries[1].SubscriptionID)
+		for _, d := range deliveries {
+			require.Equal(t, uint64(7), d.EventID)
+			require.Equal(t, types.DELIVERY_STATUS_PENDING, d.Status)
+		}
+		return nil
+	})
+
+	err := s.Service.EnqueueEvent(outbox.Event{ID: 7, Type: outbox.EVENT_BOOK_CREATED, Payload: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	payload := []byte(`{}`)
+	now := time.Now()
+	header := service.Sign(testSecret, now, payload)
+
+	require.NoError(t, service.Verify(testSecret, header, payload, now, time.Minute))
+	require.ErrorContains(t, service.Verify("another-secret-0123456", header, payload, now, time.Minute), "signature mismatch")
+	require.ErrorContains(t, service.Verify(testSecret, header, []byte(`{"a":1}`), now, time.Minute), "signature mismatch")
+	require.ErrorContains(t, service.Verify(testSecret, header, payload, now.Add(time.Hour), time.Minute), "out of tolerance")
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/response"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
+)
+
+type Handler struct {
+	serv *Service
+	log  *zap.Logger
+}
+
+// NewHandler creates a new handler for the webhooks service
+func NewHandler(serv *Service, log *zap.Logger) Handler {
+	return Handler{serv, log}
+}
+
+// InitializeRoutes initializes the routes for the webhooks service
+func InitializeRoutes(mux *http.ServeMux, h Handler) *http.ServeMux {
+	mux.HandleFunc("GET /api/v1/webhooks", h.GetSubscriptions)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}", h.GetSubscription)
+	mux.HandleFunc("POST /api/v1/webhooks", h.AddSubscription)
+	mux.HandleFunc("PUT /api/v1/webhooks/{id}", h.UpdateSubscription)
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", h.DeleteSubscription)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries", h.GetDeliveries)
+	mux.HandleFunc("POST /api/v1/webhooks/deliveries/{id}/redeliver", h.Redeliver)
+	return mux
+}
+
+// pathID reads the dynamic id parameter
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 0)
+	if err != nil {
+		return 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid id: %s", r.PathValue("id")))
+	}
+
+	return int(id), nil
+}
+
+// pageAndLimit reads the pagination query parameters
+func pageAndLimit(r *http.Request) (int, int, error) {
+	page, err := strconv.ParseInt(r.URL.Query().Get("page"), 10, 0)
+	if err != nil {
+		return 0, 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid page: %s", r.URL.Query().Get("page")))
+	}
+
+	limit, err := strconv.ParseInt(r.URL.Query().Get("limit"), 10, 0)
+	if err != nil {
+		return 0, 0, apierror.ErrInvalidInput.WithMessage(fmt.Sprintf("invalid limit: %s", r.URL.Query().Get("limit")))
+	}
+
+	return int(page), int(limit), nil
+}
+
+// @Summary get webhook subscription information from given id
+// @ID get-webhook
+// @Param id path int true "Subscription ID"
+// @Produce json
+// @Success 200 {object} types.Subscription
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /webhooks/{id} [get]
+func (h Handler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := pathID(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	sub, err := h.serv.WithContext(ctx).GetSubscription(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, sub, h.log)
+}
+
+// @Summary get list of webhook subscriptions
+// @ID get-webhooks
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Produce json
+// @Success 200 {object} types.GetSubscriptionsResponse
+// @Failure 500 {object} errors.Error
+// @Router /webhooks [get]
+func (h Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	page, limit, err := pageAndLimit(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetSubscriptions(page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary subscribe a webhook to book events
+// @Description Deliveries are signed with the secret in the X-Webhook-Signature header.
+// @ID add-webhook
+// @Produce json
+// @Param Body body types.AddSubscriptionRequest true "Subscription that needs to be added"
+// @Success 201 {object} types.AddSubscriptionResponse
+// @Failure 400 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /webhooks [post]
+func (h Handler) AddSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// Read to request body
+	var req types.AddSubscriptionRequest
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	resp, err := h.serv.WithContext(ctx).AddSubscription(req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusCreated, resp, h.log)
+}
+
+// @Summary update webhook subscription with the given id
+// @ID update-webhook
+// @Produce json
+// @Param id path int true "Subscription ID"
+// @Param Body body types.UpdateSubscriptionRequest true "Subscription information that needs to be updated"
+// @Success 200 {object} types.Subscription
+// @Failure 400 {object} errors.Error
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /webhooks/{id} [put]
+func (h Handler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := pathID(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	// Read request body
+	defer r.Body.Close()
+	var req types.UpdateSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).UpdateSubscription(id, req)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary delete webhook subscription and its delivery log
+// @ID delete-webhook
+// @Param id path int true "Subscription ID"
+// @Produce json
+// @Success 200 {object} types.DeleteSubscriptionResponse
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /webhooks/{id} [delete]
+func (h Handler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := pathID(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).DeleteSubscription(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary get delivery log of a webhook subscription, latest first
+// @ID get-webhook-deliveries
+// @Param id path int true "Subscription ID"
+// @Param page query int true "Page number"
+// @Param limit query int true "Limit per page"
+// @Produce json
+// @Success 200 {object} types.GetDeliveriesResponse
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /webhooks/{id}/deliveries [get]
+func (h Handler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := pathID(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	page, limit, err := pageAndLimit(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).GetDeliveries(id, page, limit)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusOK, result, h.log)
+}
+
+// @Summary send a webhook delivery again, including a dead one
+// @ID redeliver-webhook
+// @Param id path int true "Delivery ID"
+// @Produce json
+// @Success 202 {object} types.Delivery
+// @Failure 404 {object} errors.Error
+// @Failure 500 {object} errors.Error
+// @Router /webhooks/deliveries/{id}/redeliver [post]
+func (h Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id, err := pathID(r)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	result, err := h.serv.WithContext(ctx).Redeliver(id)
+	if err != nil {
+		response.WriteError(ctx, w, err, h.log)
+		return
+	}
+
+	response.Write(ctx, w, http.StatusAccepted, result, h.log)
+}
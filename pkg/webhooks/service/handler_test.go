@@ -0,0 +1,318 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	gomock "go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+
+	apierrors "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/service"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/testutil"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
+)
+
+func TestAddSubscription(t *testing.T) {
+	type output struct {
+		code   int
+		body   types.AddSubscriptionResponse
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		input      types.AddSubscriptionRequest
+		output     output
+	}{
+		{
+			name:  "invalid body",
+			input: types.AddSubscriptionRequest{},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "{\"Events\":\"It is required\",\"Secret\":\"It is required\",\"URL\":\"It is required\"}",
+			},
+		},
+		{
+			name: "url not over http",
+			input: types.AddSubscriptionRequest{
+				URL:    "file:///etc/passwd",
+				Secret: "test-secret-0123456789",
+				Events: []string{"book.*"},
+			},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "{\"URL\":\"It is invalid\"}",
+			},
+		},
+		{
+			name: "success",
+			input: types.AddSubscriptionRequest{
+				URL:    "https://example.com/webhooks",
+				Secret: "test-secret-0123456789",
+				Events: []string{"book.*"},
+			},
+			output: output{
+				code: http.StatusCreated,
+				body: types.AddSubscriptionResponse{ID: 1},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().CreateSubscription(types.Subscription{
+					URL:    "https://example.com/webhooks",
+					Secret: "test-secret-0123456789",
+					Events: []string{"book.*"},
+					Active: true,
+				}).Return(types.Subscription{ID: 1}, nil)
+			},
+		},
+		{
+			name: "error database",
+			input: types.AddSubscriptionRequest{
+				URL:    "https://example.com/webhooks",
+				Secret: "test-secret-0123456789",
+				Events: []string{"book.*"},
+			},
+			output: output{
+				code:   http.StatusInternalServerError,
+				errMsg: "Internal Server Error",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().CreateSubscription(gomock.Any()).Return(types.Subscription{}, errors.New("database error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/webhooks", &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusCreated {
+				var res types.AddSubscriptionResponse
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	inactive := false
+
+	type output struct {
+		code   int
+		body   types.Subscription
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		pathID     string
+		input      types.UpdateSubscriptionRequest
+		output     output
+	}{
+		{
+			name:   "invalid id",
+			pathID: "not-an-int",
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "invalid id: not-an-int",
+			},
+		},
+		{
+			name:   "url not over http",
+			pathID: "1",
+			input:  types.UpdateSubscriptionRequest{URL: "ftp://example.com/webhooks"},
+			output: output{
+				code:   http.StatusBadRequest,
+				errMsg: "{\"URL\":\"It is invalid\"}",
+			},
+		},
+		{
+			name:   "id not found",
+			pathID: "1",
+			input:  types.UpdateSubscriptionRequest{Active: &inactive},
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "subscription not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubscription(1).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:   "success",
+			pathID: "1",
+			input:  types.UpdateSubscriptionRequest{Events: []string{"book.deleted"}, Active: &inactive},
+			output: output{
+				code: http.StatusOK,
+				body: types.Subscription{ID: 1, URL: "https://example.com/webhooks", Events: []string{"book.deleted"}},
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetSubscription(1).Return(&types.Subscription{
+					ID:     1,
+					URL:    "https://example.com/webhooks",
+					Secret: "test-secret-0123456789",
+					Events: []string{"*"},
+					Active: true,
+				}, nil)
+				s.Repository.EXPECT().UpdateSubscription(&types.Subscription{
+					ID:     1,
+					URL:    "https://example.com/webhooks",
+					Secret: "test-secret-0123456789",
+					Events: []string{"book.deleted"},
+					Active: false,
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			var body bytes.Buffer
+			err := json.NewEncoder(&body).Encode(tc.input)
+			require.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/v1/webhooks/%s", tc.pathID), &body)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusOK {
+				var res types.Subscription
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.body, res)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	type output struct {
+		code   int
+		status types.DeliveryStatus
+		errMsg string
+	}
+	testCases := []struct {
+		name       string
+		preProcess func(s *testutil.TestSuite)
+		pathID     string
+		output     output
+	}{
+		{
+			name:   "id not found",
+			pathID: "1",
+			output: output{
+				code:   http.StatusNotFound,
+				errMsg: "delivery not found",
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetDelivery(1).Return(nil, gorm.ErrRecordNotFound)
+			},
+		},
+		{
+			name:   "dead delivery",
+			pathID: "1",
+			output: output{
+				code:   http.StatusAccepted,
+				status: types.DELIVERY_STATUS_PENDING,
+			},
+			preProcess: func(s *testutil.TestSuite) {
+				s.Repository.EXPECT().GetDelivery(1).Return(&types.Delivery{
+					ID:       1,
+					Status:   types.DELIVERY_STATUS_DEAD,
+					Attempts: 5,
+				}, nil)
+				s.Repository.EXPECT().UpdateDelivery(gomock.Any()).DoAndReturn(func(d *types.Delivery) error {
+					require.Equal(t, types.DELIVERY_STATUS_PENDING, d.Status)
+					require.Zero(t, d.Attempts)
+					return nil
+				})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := testutil.NewTestSuite(t)
+
+			if tc.preProcess != nil {
+				tc.preProcess(&s)
+			}
+
+			req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/api/v1/webhooks/deliveries/%s/redeliver", tc.pathID), nil)
+			require.NoError(t, err)
+
+			resp := httptest.NewRecorder()
+			router := http.NewServeMux()
+			router = service.InitializeRoutes(router, *s.Handler)
+			router.ServeHTTP(resp, req)
+
+			require.Equal(t, tc.output.code, resp.Code)
+
+			if tc.output.code == http.StatusAccepted {
+				var res types.Delivery
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.status, res.Status)
+			} else {
+				var res apierrors.Error
+				err := json.NewDecoder(resp.Body).Decode(&res)
+				require.NoError(t, err)
+
+				require.Equal(t, tc.output.errMsg, res.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
+)
+
+var (
+	_ types.DataProvider = &Repository{}
+)
+
+// Repository is the data provider that connect to the database being used in a webhooks service.
+type Repository struct {
+	db  *gorm.DB
+	log *zap.Logger
+}
+
+// NewRepository creates a new webhooks-service repository
+func NewRepository(db *gorm.DB, log *zap.Logger) Repository {
+	return Repository{db, log}
+}
+
+// WithContext returns a copy of the repository whose queries run with ctx.
+func (r *Repository) WithContext(ctx context.Context) types.DataProvider {
+	repo := NewRepository(r.db.WithContext(ctx), r.log)
+	return &repo
+}
+
+// CreateSubscription creates a new subscription in the database
+func (r *Repository) CreateSubscription(sub types.Subscription) (types.Subscription, error) {
+	tx := r.db.Create(&sub)
+	return sub, tx.Error
+}
+
+// UpdateSubscription updates a subscription in the database
+func (r *Repository) UpdateSubscription(sub *types.Subscription) error {
+	return r.db.Save(sub).Error
+}
+
+// DeleteSubscription deletes a subscription and its deliveries from the database
+func (r *Repository) DeleteSubscription(sub *types.Subscription) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", sub.ID).Delete(&types.Delivery{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(sub).Error
+	})
+}
+
+// GetSubscriptions retrieves a list of subscriptions from the database
+func (r *Repository) GetSubscriptions(page int, limit int) (*db.Pagination, []types.Subscription, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id asc",
+	}
+
+	var subs []types.Subscription
+	if result := r.db.Scopes(db.Paginate(&subs, &p, r.db)).Find(&subs); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, subs, nil
+}
+
+// GetSubscription retrieves a subscription from the database
+func (r *Repository) GetSubscription(id int) (*types.Subscription, error) {
+	var sub types.Subscription
+	if result := r.db.First(&sub, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &sub, nil
+}
+
+// GetActiveSubscriptions retrieves every active subscription from the database
+func (r *Repository) GetActiveSubscriptions() ([]types.Subscription, error) {
+	var subs []types.Subscription
+	if result := r.db.Where("active = ?", true).Order("id").Find(&subs); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return subs, nil
+}
+
+// CreateDeliveries creates new deliveries in the database
+func (r *Repository) CreateDeliveries(deliveries []types.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+}
+
+// UpdateDelivery updates a delivery in the database
+func (r *Repository) UpdateDelivery(delivery *types.Delivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// GetDeliveries retrieves the deliveries of a subscription from the database, latest first
+func (r *Repository) GetDeliveries(subscriptionID int, page int, limit int) (*db.Pagination, []types.Delivery, error) {
+	p := db.Pagination{
+		Page:  page,
+		Limit: limit,
+		Sort:  "id desc",
+	}
+
+	// a new session makes the filtered query safe to reuse for counting and finding.
+	query := r.db.Where("subscription_id = ?", subscriptionID).Session(&gorm.Session{})
+
+	var deliveries []types.Delivery
+	if result := query.Scopes(db.Paginate(&deliveries, &p, query)).Find(&deliveries); result.Error != nil {
+		return nil, nil, result.Error
+	}
+
+	return &p, deliveries, nil
+}
+
+// GetDelivery retrieves a delivery from the database
+func (r *Repository) GetDelivery(id int) (*types.Delivery, error) {
+	var delivery types.Delivery
+	if result := r.db.First(&delivery, id); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &delivery, nil
+}
+
+// ClaimDueDeliveries locks the due deliveries, skipping the ones locked by other dispatchers,
+// and postpones their next attempt by the lease
+func (r *Repository) ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]types.Delivery, error) {
+	var deliveries []types.Delivery
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", types.DELIVERY_STATUS_PENDING, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]int, 0, len(deliveries))
+		for _, d := range deliveries {
+			ids = append(ids, d.ID)
+		}
+
+		return tx.Model(&types.Delivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	apierror "github.com/nkitlabs/go-http-gorm-example/pkg/errors"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/outbox"
+	"github.com/nkitlabs/go-http-gorm-example/pkg/webhooks/types"
+)
+
+// Service is the service layer for webhooks
+type Service struct {
+	dataProvider types.DataProvider
+	log          *zap.Logger
+}
+
+// NewService creates a new webhooks service
+func NewService(d types.DataProvider, log *zap.Logger) Service {
+	return Service{
+		dataProvider: d,
+		log:          log,
+	}
+}
+
+// WithContext returns a copy of the service whose queries run with ctx, so they are scoped to
+// the tenant of the request.
+func (s *Service) WithContext(ctx context.Context) *Service {
+	serv := *s
+	serv.dataProvider = s.dataProvider.WithContext(ctx)
+	return &serv
+}
+
+// AddSubscription adds a new webhook subscription into the system
+func (s *Service) AddSubscription(req types.AddSubscriptionRequest) (types.AddSubscriptionResponse, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.AddSubscriptionResponse{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	sub := types.Subscription{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+		Active: true,
+	}
+
+	sub, err := s.dataProvider.CreateSubscription(sub)
+	if err != nil {
+		return types.AddSubscriptionResponse{}, err
+	}
+
+	return types.AddSubscriptionResponse{
+		ID: sub.ID,
+	}, nil
+}
+
+// UpdateSubscription updates a webhook subscription in the system
+func (s *Service) UpdateSubscription(id int, req types.UpdateSubscriptionRequest) (types.Subscription, error) {
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		return types.Subscription{}, apierror.ConvertValidatorErrorsToError(err)
+	}
+
+	sub, err := s.GetSubscription(id)
+	if err != nil {
+		return types.Subscription{}, err
+	}
+
+	if req.URL != "" {
+		sub.URL = req.URL
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if len(req.Events) > 0 {
+		sub.Events = req.Events
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	if err := s.dataProvider.UpdateSubscription(sub); err != nil {
+		return types.Subscription{}, err
+	}
+
+	return *sub, nil
+}
+
+// DeleteSubscription deletes a webhook subscription and its delivery log from the system
+func (s *Service) DeleteSubscription(id int) (types.DeleteSubscriptionResponse, error) {
+	sub, err := s.GetSubscription(id)
+	if err != nil {
+		return types.DeleteSubscriptionResponse{}, err
+	}
+
+	return types.DeleteSubscriptionResponse{}, s.dataProvider.DeleteSubscription(sub)
+}
+
+// GetSubscriptions returns a list of webhook subscriptions
+func (s *Service) GetSubscriptions(page int, limit int) (types.GetSubscriptionsResponse, error) {
+	pagination, subs, err := s.dataProvider.GetSubscriptions(page, limit)
+	if err != nil {
+		return types.GetSubscriptionsResponse{}, err
+	}
+
+	return types.GetSubscriptionsResponse{
+		Subscriptions: subs,
+		Pagination:    pagination,
+	}, nil
+}
+
+// GetSubscription returns a webhook subscription from the given id
+func (s *Service) GetSubscription(id int) (*types.Subscription, error) {
+	sub, err := s.dataProvider.GetSubscription(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apierror.NewNotFoundError("subscription not found")
+	} else if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// GetDeliveries returns the delivery log of a webhook subscription, latest first
+func (s *Service) GetDeliveries(subscriptionID int, page int, limit int) (types.GetDeliveriesResponse, error) {
+	if _, err := s.GetSubscription(subscriptionID); err != nil {
+		return types.GetDeliveriesResponse{}, err
+	}
+
+	pagination, deliveries, err := s.dataProvider.GetDeliveries(subscriptionID, page, limit)
+	if err != nil {
+		return types.GetDeliveriesResponse{}, err
+	}
+
+	return types.GetDeliveriesResponse{
+		Deliveries: deliveries,
+		Pagination: pagination,
+	}, nil
+}
+
+// Redeliver schedules a delivery to be sent again right away with a fresh set of attempts,
+// including a dead or already succeeded one
+func (s *Service) Redeliver(id int) (types.Delivery, error) {
+	delivery, err := s.dataProvider.GetDelivery(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return types.Delivery{}, apierror.NewNotFoundError("delivery not found")
+	} else if err != nil {
+		return types.Delivery{}, err
+	}
+
+	delivery.Status = types.DELIVERY_STATUS_PENDING
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.dataProvider.UpdateDelivery(delivery); err != nil {
+		return types.Delivery{}, err
+	}
+
+	return *delivery, nil
+}
+
+// EnqueueEvent creates a pending delivery of the event for every active subscription whose
+// event filter matches it
+func (s *Service) EnqueueEvent(event outbox.Event) error {
+	subs, err := s.dataProvider.GetActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []types.Delivery
+	for _, sub := range subs {
+		if !sub.Matches(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, types.Delivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         types.DELIVERY_STATUS_PENDING,
+			NextAttemptAt:  now,
+		})
+	}
+
+	return s.dataProvider.CreateDeliveries(deliveries)
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderSignature is the header carrying the signature of a delivery, formatted as `t=<unix time>,v1=<hex hmac>`.
+	HeaderSignature = "X-Webhook-Signature"
+	// HeaderEvent is the header carrying the event type of a delivery.
+	HeaderEvent = "X-Webhook-Event"
+	// HeaderDelivery is the header carrying the id of a delivery, which is the same across redeliveries.
+	HeaderDelivery = "X-Webhook-Delivery"
+)
+
+// Sign returns the signature header value of the payload: an HMAC-SHA256 with the subscription
+// secret over the timestamp and the payload, so receivers can reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, computeSignature(secret, t, payload))
+}
+
+// Verify checks the signature header value of the payload and that its timestamp is within
+// the tolerance of now.
+func Verify(secret string, header string, payload []byte, now time.Time, tolerance time.Duration) error {
+	var t, sig string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(part, "=")
+		switch k {
+		case "t":
+			t = v
+		case "v1":
+			sig = v
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid signature timestamp: %q", t)
+	}
+
+	if d := now.Sub(time.Unix(unix, 0)); d > tolerance || d < -tolerance {
+		return fmt.Errorf("signature timestamp is out of tolerance")
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(computeSignature(secret, t, payload))) {
+		return fmt.Errorf("signature mismatch")
+	}
+
+	return nil
+}
+
+func computeSignature(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/nkitlabs/go-http-gorm-example/pkg/db"
+)
+
+// DataProvider is the interface for the data provider for a webhooks service
+type DataProvider interface {
+	// WithContext returns a data provider whose queries run with ctx, so they are scoped to
+	// the tenant of ctx.
+	WithContext(ctx context.Context) DataProvider
+
+	CreateSubscription(sub Subscription) (Subscription, error)
+	UpdateSubscription(sub *Subscription) error
+	// DeleteSubscription deletes the subscription together with its deliveries.
+	DeleteSubscription(sub *Subscription) error
+
+	GetSubscriptions(page int, limit int) (*db.Pagination, []Subscription, error)
+	GetSubscription(id int) (*Subscription, error)
+	GetActiveSubscriptions() ([]Subscription, error)
+
+	// CreateDeliveries creates the deliveries, skipping the ones of an event already
+	// delivered to the same subscription.
+	CreateDeliveries(deliveries []Delivery) error
+	UpdateDelivery(delivery *Delivery) error
+
+	GetDeliveries(subscriptionID int, page int, limit int) (*db.Pagination, []Delivery, error)
+	GetDelivery(id int) (*Delivery, error)
+	// ClaimDueDeliveries returns up to limit pending deliveries due at the given time and
+	// postpones their next attempt by lease, so other dispatchers do not send them meanwhile.
+	ClaimDueDeliveries(now time.Time, lease time.Duration, limit int) ([]Delivery, error)
+}
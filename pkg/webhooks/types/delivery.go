@@ -0,0 +1,40 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type DeliveryStatus string
+
+const (
+	// DELIVERY_STATUS_PENDING is a delivery waiting for its next attempt.
+	DELIVERY_STATUS_PENDING DeliveryStatus = "pending"
+	// DELIVERY_STATUS_SUCCEEDED is a delivery acknowledged by the receiver.
+	DELIVERY_STATUS_SUCCEEDED DeliveryStatus = "succeeded"
+	// DELIVERY_STATUS_DEAD is a delivery that ran out of attempts; it is only sent again if redelivered.
+	DELIVERY_STATUS_DEAD DeliveryStatus = "dead"
+)
+
+// Delivery is the model for delivering an event to a subscription, including its last attempt.
+type Delivery struct {
+	ID             int             `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID       string          `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	SubscriptionID int             `json:"subscription_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_event" example:"1"`
+	EventID        uint64          `json:"event_id" gorm:"not null;uniqueIndex:idx_webhook_deliveries_subscription_event" example:"1"`
+	EventType      string          `json:"event_type" gorm:"not null" example:"book.created"`
+	Payload        json.RawMessage `json:"payload" gorm:"type:jsonb;not null" swaggertype:"object"`
+	Status         DeliveryStatus  `json:"status" gorm:"not null;index:idx_webhook_deliveries_due" example:"pending"`
+	Attempts       int             `json:"attempts" example:"1"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	LastStatusCode int             `json:"last_status_code,omitempty" example:"500"`
+	LastError      string          `json:"last_error,omitempty" example:"webhook responded with status 500"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
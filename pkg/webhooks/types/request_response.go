@@ -0,0 +1,38 @@
+package types
+
+import "github.com/nkitlabs/go-http-gorm-example/pkg/db"
+
+// AddSubscriptionRequest is the request for adding a new webhook subscription
+type AddSubscriptionRequest struct {
+	URL    string   `json:"url" example:"https://example.com/webhooks" validate:"required,http_url"`
+	Secret string   `json:"secret" example:"a-secret-of-at-least-16-chars" validate:"required,min=16"`
+	Events []string `json:"events" example:"book.created,book.updated" validate:"required,min=1,dive,required"`
+}
+
+// AddSubscriptionResponse is the response for adding a new webhook subscription
+type AddSubscriptionResponse struct {
+	ID int `json:"id" example:"1"`
+}
+
+// UpdateSubscriptionRequest is the request for updating a webhook subscription
+type UpdateSubscriptionRequest struct {
+	URL    string   `json:"url" example:"https://example.com/webhooks" validate:"omitempty,http_url"`
+	Secret string   `json:"secret" example:"a-secret-of-at-least-16-chars" validate:"omitempty,min=16"`
+	Events []string `json:"events" example:"book.deleted" validate:"omitempty,dive,required"`
+	Active *bool    `json:"active" example:"false"`
+}
+
+// GetSubscriptionsResponse is the response for getting a list of webhook subscriptions
+type GetSubscriptionsResponse struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	Pagination    *db.Pagination `json:"pagination"`
+}
+
+// DeleteSubscriptionResponse is the response for deleting a webhook subscription
+type DeleteSubscriptionResponse struct{}
+
+// GetDeliveriesResponse is the response for getting the delivery log of a webhook subscription
+type GetDeliveriesResponse struct {
+	Deliveries []Delivery     `json:"deliveries"`
+	Pagination *db.Pagination `json:"pagination"`
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// Subscription is the model for a webhook subscription to book events.
+type Subscription struct {
+	ID       int    `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	TenantID string `json:"-" gorm:"<-:create;not null;default:'default';index"`
+	URL      string `json:"url" gorm:"not null" example:"https://example.com/webhooks"`
+	Secret   string `json:"-" gorm:"not null"`
+	// Events is the event filter; `*` matches every event and `book.*` matches every book event.
+	Events    []string  `json:"events" gorm:"serializer:json;not null" example:"book.created,book.updated"`
+	Active    bool      `json:"active" example:"true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName overrides the table name used by gorm.
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Matches checks if the event type passes the event filter of the subscription.
+func (s Subscription) Matches(eventType string) bool {
+	for _, filter := range s.Events {
+		if filter == "*" || filter == eventType {
+			return true
+		}
+
+		if prefix, ok := strings.CutSuffix(filter, "*"); ok && strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+
+	return false
+}